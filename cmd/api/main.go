@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"os"
 	"os/signal"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/encryptcookie"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/gofiber/fiber/v2/middleware/session"
@@ -20,8 +22,12 @@ import (
 
 	"github.com/herdiagusthio/password-manager/config"
 	authHttp "github.com/herdiagusthio/password-manager/internal/delivery/http"
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/internal/repository/blob"
 	postgresRepo "github.com/herdiagusthio/password-manager/internal/repository/postgres"
 	"github.com/herdiagusthio/password-manager/internal/usecase"
+	"github.com/herdiagusthio/password-manager/pkg/crypto"
+	"github.com/herdiagusthio/password-manager/pkg/seal"
 )
 
 // @title Password Manager API
@@ -43,6 +49,28 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Refusing to start, the configuration has problems:\n%v", err)
+	}
+
+	// In sealed mode the master keys stay encrypted on disk until enough
+	// unseal shares are posted to /sys/unseal.
+	unsealedKeys := crypto.NewSealedProvider()
+	var keyProvider crypto.KeyProvider = unsealedKeys
+	var sealedKeys *seal.File
+	if cfg.Sealed() {
+		sealedKeys, err = seal.Load(cfg.SealedKeysFile)
+		if err != nil {
+			log.Fatalf("Failed to load sealed keys: %v", err)
+		}
+		log.Printf("Starting sealed: %d of %d unseal shares required", sealedKeys.Threshold, sealedKeys.Shares)
+	} else {
+		keyProvider, err = cfg.MasterKeyProvider()
+		if err != nil {
+			log.Fatalf("Invalid encryption keys: %v", err)
+		}
+	}
+	keyProvider = crypto.WithOptions(keyProvider, cfg.KeyringOptions())
 
 	// 2. Database Connection (Postgres)
 	dbPool, err := pgxpool.New(context.Background(), cfg.DBSource)
@@ -74,8 +102,9 @@ func main() {
 	engine := html.New("./views", ".html")
 	
 	app := fiber.New(fiber.Config{
-		AppName: "Password Manager API",
-		Views:   engine,
+		AppName:   "Password Manager API",
+		Views:     engine,
+		BodyLimit: cfg.BodyLimit(), // Attachments and backups holding them
 	})
 
 	// Static Files
@@ -83,31 +112,100 @@ func main() {
 
 	app.Use(logger.New())
 	app.Use(recover.New())
+	// The session ID cookie is encrypted and authenticated with SESSION_SECRET,
+	// so a guessed or tampered ID is rejected before it reaches Redis.
+	app.Use(encryptcookie.New(encryptcookie.Config{Key: cfg.CookieKey()}))
 
 	// 5. Dependency Injection
 	// Repositories
 	userRepo := postgresRepo.NewUserRepository(dbPool)
 	secretRepo := postgresRepo.NewSecretRepository(dbPool)
+	secretVersionRepo := postgresRepo.NewSecretVersionRepository(dbPool)
+	folderRepo := postgresRepo.NewFolderRepository(dbPool)
+	tagRepo := postgresRepo.NewTagRepository(dbPool)
+	userKeyRepo := postgresRepo.NewUserKeyRepository(dbPool)
+	settingsRepo := postgresRepo.NewSettingsRepository(dbPool)
+	vaultRepo := postgresRepo.NewVaultRepository(dbPool)
+	attachmentRepo := postgresRepo.NewAttachmentRepository(dbPool)
+
+	// Attachments are encrypted before they reach the blob store.
+	blobStore, err := newBlobStore(&cfg)
+	if err != nil {
+		log.Fatalf("Failed to open attachment storage: %v", err)
+	}
 
 	// Usecases
 	authUC := usecase.NewAuthUsecase(&cfg, userRepo)
-	secretUC := usecase.NewSecretUsecase(secretRepo, &cfg)
-	backupUC := usecase.NewBackupUsecase(secretRepo, &cfg)
+	secretUC := usecase.NewSecretUsecase(secretRepo, secretVersionRepo, folderRepo, userKeyRepo, settingsRepo, vaultRepo, keyProvider)
+	backupUC := usecase.NewBackupUsecase(secretRepo, secretVersionRepo, tagRepo, userKeyRepo, settingsRepo, vaultRepo, attachmentRepo, blobStore, keyProvider, cfg.AttachmentMaxSize, cfg.AttachmentQuota)
+	rotationUC := usecase.NewRotationUsecase(secretRepo, userKeyRepo, settingsRepo, keyProvider)
+	settingsUC := usecase.NewSettingsUsecase(settingsRepo, secretRepo, secretVersionRepo, userKeyRepo, keyProvider)
+	vaultUC := usecase.NewVaultUsecase(vaultRepo, settingsRepo)
+	sealUC := usecase.NewSealUsecase(unsealedKeys, sealedKeys)
+	generatorUC := usecase.NewGeneratorUsecase()
+	folderUC := usecase.NewFolderUsecase(folderRepo, secretRepo)
+	tagUC := usecase.NewTagUsecase(tagRepo, secretRepo)
+	trashUC := usecase.NewTrashUsecase(secretRepo, cfg.TrashRetention, cfg.TrashPurgeInterval)
+	attachmentUC := usecase.NewAttachmentUsecase(attachmentRepo, secretRepo, userKeyRepo, vaultRepo, blobStore, keyProvider, cfg.AttachmentMaxSize, cfg.AttachmentQuota, cfg.TrashPurgeInterval)
 
 	// Swagger
 	app.Get("/swagger/*", swagger.HandlerDefault)
 
+	// Nothing under /api can work without master keys.
+	app.Use("/api", authHttp.RequireUnsealed(sealUC))
+
 	// Handlers
+	// Logging in does not unlock the vault; plaintext needs a separate unlock.
+	vaultLock := authHttp.NewVaultLock(sessionStore, vaultUC)
 	authHttp.NewAuthHandler(app, authUC, sessionStore)
-	authHttp.NewSecretHandler(app, secretUC, sessionStore)
-	authHttp.NewBackupHandler(app, backupUC, sessionStore)
+	authHttp.NewSecretHandler(app, secretUC, sessionStore, vaultLock)
+	authHttp.NewBackupHandler(app, backupUC, sessionStore, vaultLock)
+	authHttp.NewSettingsHandler(app, settingsUC, sessionStore, vaultLock)
+	authHttp.NewVaultHandler(app, vaultUC, sessionStore, vaultLock)
+	authHttp.NewGeneratorHandler(app, generatorUC, sessionStore)
+	authHttp.NewTrashHandler(app, trashUC, sessionStore)
+	authHttp.NewFolderHandler(app, folderUC, sessionStore)
+	authHttp.NewTagHandler(app, tagUC, sessionStore)
+	authHttp.NewAttachmentHandler(app, attachmentUC, sessionStore, vaultLock)
 	authHttp.NewUIHandler(app, secretUC, sessionStore)
+	authHttp.NewSysHandler(app, rotationUC, sealUC)
 
 	// Health Check
 	app.Get("/health", func(c *fiber.Ctx) error {
 		return c.SendString("OK")
 	})
 
+	// Move data written under retired keys onto the current master key.
+	// Decryption falls back to old keys by ID, so this runs while serving traffic.
+	jobCtx, cancelJobs := context.WithCancel(context.Background())
+	defer cancelJobs()
+	if cfg.KeyRotationEnabled {
+		go func() {
+			select {
+			case <-sealUC.Unsealed():
+			case <-jobCtx.Done():
+				return
+			}
+			if err := rotationUC.Run(jobCtx); err != nil {
+				log.Printf("Key rotation stopped: %v", err)
+			}
+		}()
+	}
+
+	// Purge secrets that have been in the trash longer than the retention period.
+	go func() {
+		if err := trashUC.Run(jobCtx); err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("Trash purge stopped: %v", err)
+		}
+	}()
+
+	// Delete the attachments of secrets purged from the trash.
+	go func() {
+		if err := attachmentUC.Run(jobCtx); err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("Attachment sweep stopped: %v", err)
+		}
+	}()
+
 	// 6. Graceful Shutdown & Server Start
 	go func() {
 		if err := app.Listen(cfg.ServerPort); err != nil {
@@ -123,5 +221,22 @@ func main() {
 	<-c
 
 	log.Println("Shutting down...")
+	cancelJobs()
 	app.Shutdown()
 }
+
+// newBlobStore opens the storage selected by ATTACHMENT_STORAGE.
+func newBlobStore(cfg *config.Config) (domain.BlobStore, error) {
+	if cfg.AttachmentStorage == "s3" {
+		return blob.NewS3Store(context.Background(), blob.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			UseSSL:    cfg.S3UseSSL,
+			Prefix:    cfg.S3Prefix,
+		})
+	}
+	return blob.NewLocalStore(cfg.AttachmentDir)
+}
@@ -0,0 +1,145 @@
+// Command keystore manages the local keystore file used with KEY_PROVIDER=file.
+// The passphrase is read from KEYSTORE_PASSPHRASE.
+//
+//	keystore init   [-file path] [-from-env]
+//	keystore rotate [-file path] [-key-id id]
+//
+// init writes either the master keys configured in the environment or a newly
+// generated key. rotate adds a new current key; older keys are kept so that
+// existing data can still be decrypted while the rotation job runs.
+package main
+
+import (
+	"crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/herdiagusthio/password-manager/config"
+	"github.com/herdiagusthio/password-manager/pkg/crypto"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	cfg, err := config.LoadConfig(".")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "keystore:", err)
+		os.Exit(1)
+	}
+	if cfg.KeystorePassphrase == "" {
+		fmt.Fprintln(os.Stderr, "keystore: KEYSTORE_PASSPHRASE is not set")
+		os.Exit(1)
+	}
+
+	switch os.Args[1] {
+	case "init":
+		err = runInit(&cfg, os.Args[2:])
+	case "rotate":
+		err = runRotate(&cfg, os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "keystore:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: keystore init|rotate [flags]")
+	os.Exit(2)
+}
+
+func runInit(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	file := fs.String("file", defaultFile(cfg), "keystore file to create")
+	fromEnv := fs.Bool("from-env", false, "store ENCRYPTION_KEY and ENCRYPTION_OLD_KEYS instead of generating a new key")
+	fs.Parse(args)
+
+	if _, err := os.Stat(*file); err == nil {
+		return fmt.Errorf("%s already exists; use rotate to add a key", *file)
+	}
+
+	currentID := "1"
+	var keys map[string][]byte
+	if *fromEnv {
+		var err error
+		if keys, err = cfg.MasterKeys(); err != nil {
+			return err
+		}
+		currentID = cfg.EncryptionKeyID
+	} else {
+		key, err := randomKey()
+		if err != nil {
+			return err
+		}
+		keys = map[string][]byte{currentID: key}
+	}
+
+	if err := crypto.WriteKeystore(*file, cfg.KeystorePassphrase, currentID, keys); err != nil {
+		return err
+	}
+	fmt.Printf("Keystore written to %s with current key %s\n", *file, currentID)
+	return nil
+}
+
+func runRotate(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("rotate", flag.ExitOnError)
+	file := fs.String("file", defaultFile(cfg), "keystore file")
+	keyID := fs.String("key-id", "", "ID for the new key (default: next number)")
+	fs.Parse(args)
+
+	_, keys, err := crypto.ReadKeystore(*file, cfg.KeystorePassphrase)
+	if err != nil {
+		return err
+	}
+	newID := *keyID
+	if newID == "" {
+		newID = nextKeyID(keys)
+	}
+	if _, ok := keys[newID]; ok {
+		return fmt.Errorf("key id %q is already in use", newID)
+	}
+	key, err := randomKey()
+	if err != nil {
+		return err
+	}
+	keys[newID] = key
+
+	if err := crypto.WriteKeystore(*file, cfg.KeystorePassphrase, newID, keys); err != nil {
+		return err
+	}
+	fmt.Printf("Key %s is now current in %s. Restart the server to start using it.\n", newID, *file)
+	return nil
+}
+
+func defaultFile(cfg *config.Config) string {
+	if cfg.KeystoreFile != "" {
+		return cfg.KeystoreFile
+	}
+	return "keystore.json"
+}
+
+func randomKey() ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, errors.New("failed to generate key")
+	}
+	return key, nil
+}
+
+// nextKeyID returns one more than the largest numeric key ID.
+func nextKeyID(keys map[string][]byte) string {
+	highest := 0
+	for id := range keys {
+		if n, err := strconv.Atoi(id); err == nil && n > highest {
+			highest = n
+		}
+	}
+	return strconv.Itoa(highest + 1)
+}
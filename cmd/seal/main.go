@@ -0,0 +1,212 @@
+// Command seal manages the sealed keys file used when SEALED_KEYS_FILE is set.
+//
+//	seal init   [-file path] [-shares 5] [-threshold 3] [-from-env]
+//	seal rekey  [-file path] [-shares 5] [-threshold 3]
+//	seal rotate [-file path] [-key-id id]
+//
+// init seals either the master keys configured in the environment or a newly
+// generated key, and prints the unseal shares. rekey and rotate read the
+// current shares from stdin, one per line.
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/herdiagusthio/password-manager/config"
+	"github.com/herdiagusthio/password-manager/pkg/seal"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "init":
+		err = runInit(os.Args[2:])
+	case "rekey":
+		err = runRekey(os.Args[2:])
+	case "rotate":
+		err = runRotate(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "seal:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: seal init|rekey|rotate [flags]")
+	os.Exit(2)
+}
+
+func runInit(args []string) error {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	file := fs.String("file", "sealed_keys.json", "sealed keys file to create")
+	shares := fs.Int("shares", 5, "number of unseal shares to issue")
+	threshold := fs.Int("threshold", 3, "shares required to unseal")
+	fromEnv := fs.Bool("from-env", false, "seal ENCRYPTION_KEY and ENCRYPTION_OLD_KEYS instead of generating a new key")
+	fs.Parse(args)
+
+	if _, err := os.Stat(*file); err == nil {
+		return fmt.Errorf("%s already exists; use rekey to change its shares", *file)
+	}
+
+	keys := &seal.Keys{}
+	if *fromEnv {
+		cfg, err := config.LoadConfig(".")
+		if err != nil {
+			return err
+		}
+		keys.CurrentID = cfg.EncryptionKeyID
+		if keys.Keys, err = cfg.MasterKeys(); err != nil {
+			return err
+		}
+	} else {
+		key, err := randomKey()
+		if err != nil {
+			return err
+		}
+		keys.CurrentID = "1"
+		keys.Keys = map[string][]byte{"1": key}
+	}
+
+	f, issued, err := seal.Init(keys, *shares, *threshold)
+	if err != nil {
+		return err
+	}
+	if err := f.Save(*file); err != nil {
+		return err
+	}
+	printShares(*file, issued, *threshold)
+	return nil
+}
+
+func runRekey(args []string) error {
+	fs := flag.NewFlagSet("rekey", flag.ExitOnError)
+	file := fs.String("file", "sealed_keys.json", "sealed keys file")
+	shares := fs.Int("shares", 5, "number of new unseal shares to issue")
+	threshold := fs.Int("threshold", 3, "new shares required to unseal")
+	fs.Parse(args)
+
+	f, err := seal.Load(*file)
+	if err != nil {
+		return err
+	}
+	current, err := readShares(f.Threshold)
+	if err != nil {
+		return err
+	}
+	rekeyed, issued, err := f.Rekey(current, *shares, *threshold)
+	if err != nil {
+		return err
+	}
+	if err := rekeyed.Save(*file); err != nil {
+		return err
+	}
+	printShares(*file, issued, *threshold)
+	fmt.Println("The previous shares no longer unseal this file.")
+	return nil
+}
+
+func runRotate(args []string) error {
+	fs := flag.NewFlagSet("rotate", flag.ExitOnError)
+	file := fs.String("file", "sealed_keys.json", "sealed keys file")
+	keyID := fs.String("key-id", "", "ID for the new master key (default: next number)")
+	fs.Parse(args)
+
+	f, err := seal.Load(*file)
+	if err != nil {
+		return err
+	}
+	current, err := readShares(f.Threshold)
+	if err != nil {
+		return err
+	}
+
+	var newID string
+	updated, err := f.Update(current, func(keys *seal.Keys) error {
+		newID = *keyID
+		if newID == "" {
+			newID = nextKeyID(keys.Keys)
+		}
+		if _, ok := keys.Keys[newID]; ok {
+			return fmt.Errorf("key id %q is already in use", newID)
+		}
+		key, err := randomKey()
+		if err != nil {
+			return err
+		}
+		keys.Keys[newID] = key
+		keys.CurrentID = newID
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := updated.Save(*file); err != nil {
+		return err
+	}
+	fmt.Printf("Master key %s is now current in %s. Restart and unseal the server to start using it.\n", newID, *file)
+	return nil
+}
+
+func randomKey() ([]byte, error) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	return key, err
+}
+
+// nextKeyID returns one more than the largest numeric key ID.
+func nextKeyID(keys map[string][]byte) string {
+	highest := 0
+	for id := range keys {
+		if n, err := strconv.Atoi(id); err == nil && n > highest {
+			highest = n
+		}
+	}
+	return strconv.Itoa(highest + 1)
+}
+
+func readShares(threshold int) ([][]byte, error) {
+	fmt.Fprintf(os.Stderr, "Enter %d unseal shares, one per line:\n", threshold)
+	scanner := bufio.NewScanner(os.Stdin)
+	var shares [][]byte
+	for len(shares) < threshold && scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		share, err := seal.DecodeShare(line)
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, share)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(shares) < threshold {
+		return nil, errors.New("not enough shares")
+	}
+	return shares, nil
+}
+
+func printShares(file string, shares []string, threshold int) {
+	fmt.Printf("Sealed keys written to %s\n\n", file)
+	for i, s := range shares {
+		fmt.Printf("Unseal share %d: %s\n", i+1, s)
+	}
+	fmt.Printf("\nGive each share to a different operator. %d of them are required to unseal.\n", threshold)
+	fmt.Println("The shares are not stored anywhere else; if fewer than that remain, the keys are lost.")
+}
@@ -1,21 +1,64 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"log"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
+
+	"github.com/herdiagusthio/password-manager/pkg/crypto"
 )
 
 type Config struct {
-	ServerPort       string `mapstructure:"SERVER_PORT"`
-	DBSource         string `mapstructure:"DB_SOURCE"`
-	RedisAddr        string `mapstructure:"REDIS_ADDR"`
-	EncryptionKey    string `mapstructure:"ENCRYPTION_KEY"` // Master key for backup encryption
-	GoogleClientID   string `mapstructure:"GOOGLE_CLIENT_ID"`
-	GoogleClientSecret string `mapstructure:"GOOGLE_CLIENT_SECRET"`
-	GoogleRedirectURL  string `mapstructure:"GOOGLE_REDIRECT_URL"`
-	SessionSecret      string `mapstructure:"SESSION_SECRET"`
+	ServerPort      string `mapstructure:"SERVER_PORT"`
+	DBSource        string `mapstructure:"DB_SOURCE"`
+	RedisAddr       string `mapstructure:"REDIS_ADDR"`
+	EncryptionKey   string `mapstructure:"ENCRYPTION_KEY"`    // Master key for backups and for wrapping per-user data keys
+	EncryptionKeyID string `mapstructure:"ENCRYPTION_KEY_ID"` // Key ID recorded in ciphertext headers for ENCRYPTION_KEY
+	// Retired master keys that are still needed to decrypt old data, as "id:key,id:key"
+	EncryptionOldKeys  string `mapstructure:"ENCRYPTION_OLD_KEYS"`
+	KeyRotationEnabled bool   `mapstructure:"KEY_ROTATION_ENABLED"` // Re-encrypt stale data in the background at startup
+	// Reject ciphertexts not bound to their row; enable once key rotation reports no failures
+	EncryptionRequireBinding bool   `mapstructure:"ENCRYPTION_REQUIRE_BINDING"`
+	EncryptionAlgorithm      string `mapstructure:"ENCRYPTION_ALGORITHM"` // Cipher for new writes: xchacha20-poly1305 or aes-256-gcm
+	GoogleClientID           string `mapstructure:"GOOGLE_CLIENT_ID"`
+	GoogleClientSecret       string `mapstructure:"GOOGLE_CLIENT_SECRET"`
+	GoogleRedirectURL        string `mapstructure:"GOOGLE_REDIRECT_URL"`
+	SessionSecret            string `mapstructure:"SESSION_SECRET"` // Key material for encrypting cookies, see CookieKey
+
+	// Start sealed and load master keys from this file once enough unseal shares are provided
+	SealedKeysFile string `mapstructure:"SEALED_KEYS_FILE"`
+
+	// Where master keys come from: env (ENCRYPTION_KEY), file (a local keystore) or kms
+	KeyProvider        string `mapstructure:"KEY_PROVIDER"`
+	KeystoreFile       string `mapstructure:"KEYSTORE_FILE"`
+	KeystorePassphrase string `mapstructure:"KEYSTORE_PASSPHRASE"`
+	KMSURL             string `mapstructure:"KMS_URL"`
+	KMSToken           string `mapstructure:"KMS_TOKEN"`
+	KMSKeyID           string `mapstructure:"KMS_KEY_ID"` // Key used for new data; older IDs are read from ciphertext headers
+
+	// How long deleted secrets stay in the trash before they are purged; 0 keeps them until emptied
+	TrashRetention     time.Duration `mapstructure:"TRASH_RETENTION"`
+	TrashPurgeInterval time.Duration `mapstructure:"TRASH_PURGE_INTERVAL"` // How often expired trash is purged
+
+	// Where encrypted attachments are kept: local (ATTACHMENT_DIR) or s3
+	AttachmentStorage string `mapstructure:"ATTACHMENT_STORAGE"`
+	AttachmentDir     string `mapstructure:"ATTACHMENT_DIR"`
+	AttachmentMaxSize int64  `mapstructure:"ATTACHMENT_MAX_SIZE"` // Largest file that can be attached, in bytes
+	AttachmentQuota   int64  `mapstructure:"ATTACHMENT_QUOTA"`    // Bytes of attachments per user; 0 means no limit
+	S3Endpoint        string `mapstructure:"S3_ENDPOINT"`         // host:port of an S3-compatible service
+	S3Region          string `mapstructure:"S3_REGION"`
+	S3Bucket          string `mapstructure:"S3_BUCKET"`
+	S3AccessKey       string `mapstructure:"S3_ACCESS_KEY"`
+	S3SecretKey       string `mapstructure:"S3_SECRET_KEY"`
+	S3UseSSL          bool   `mapstructure:"S3_USE_SSL"`
+	S3Prefix          string `mapstructure:"S3_PREFIX"` // Prepended to every object key
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -29,6 +72,31 @@ func LoadConfig(path string) (config Config, err error) {
 	// Defaults
 	viper.SetDefault("SERVER_PORT", ":8080")
 	viper.SetDefault("GOOGLE_REDIRECT_URL", "http://localhost:8080/auth/callback")
+	viper.SetDefault("ENCRYPTION_KEY_ID", "1")
+	viper.SetDefault("ENCRYPTION_OLD_KEYS", "")
+	viper.SetDefault("KEY_ROTATION_ENABLED", true)
+	viper.SetDefault("ENCRYPTION_REQUIRE_BINDING", false)
+	viper.SetDefault("ENCRYPTION_ALGORITHM", crypto.DefaultAlgorithm.String())
+	viper.SetDefault("SEALED_KEYS_FILE", "")
+	viper.SetDefault("KEY_PROVIDER", "env")
+	viper.SetDefault("KEYSTORE_FILE", "")
+	viper.SetDefault("KEYSTORE_PASSPHRASE", "")
+	viper.SetDefault("KMS_URL", "")
+	viper.SetDefault("KMS_TOKEN", "")
+	viper.SetDefault("KMS_KEY_ID", "")
+	viper.SetDefault("TRASH_RETENTION", "720h")
+	viper.SetDefault("TRASH_PURGE_INTERVAL", "1h")
+	viper.SetDefault("ATTACHMENT_STORAGE", "local")
+	viper.SetDefault("ATTACHMENT_DIR", "./data/attachments")
+	viper.SetDefault("ATTACHMENT_MAX_SIZE", 25<<20)
+	viper.SetDefault("ATTACHMENT_QUOTA", 100<<20)
+	viper.SetDefault("S3_ENDPOINT", "")
+	viper.SetDefault("S3_REGION", "")
+	viper.SetDefault("S3_BUCKET", "")
+	viper.SetDefault("S3_ACCESS_KEY", "")
+	viper.SetDefault("S3_SECRET_KEY", "")
+	viper.SetDefault("S3_USE_SSL", true)
+	viper.SetDefault("S3_PREFIX", "")
 
 	err = viper.ReadInConfig()
 	if err != nil {
@@ -43,3 +111,245 @@ func LoadConfig(path string) (config Config, err error) {
 	err = viper.Unmarshal(&config)
 	return
 }
+
+// Sealed reports whether master keys come from a sealed keys file instead of
+// the key provider.
+func (c *Config) Sealed() bool {
+	return c.SealedKeysFile != ""
+}
+
+// CookieKey returns the AES-256 key cookies are encrypted with, derived from
+// SESSION_SECRET and base64 encoded as the encryptcookie middleware expects.
+func (c *Config) CookieKey() string {
+	key := sha256.Sum256([]byte("cookie:" + c.SessionSecret))
+	return base64.StdEncoding.EncodeToString(key[:])
+}
+
+// KeyringOptions returns how keyrings encrypt and which ciphertexts they accept.
+// Validate reports an unknown algorithm; here it falls back to the default.
+func (c *Config) KeyringOptions() crypto.KeyringOptions {
+	alg, err := c.algorithm()
+	if err != nil {
+		alg = crypto.DefaultAlgorithm
+	}
+	return crypto.KeyringOptions{Algorithm: alg, RequireBinding: c.EncryptionRequireBinding}
+}
+
+// BodyLimit is the largest request body the server accepts: enough for an
+// attachment upload, and for a backup holding a full quota of attachments,
+// which are base64 encoded twice on the way in.
+func (c *Config) BodyLimit() int {
+	limit := c.AttachmentMaxSize
+	if c.AttachmentQuota > limit {
+		limit = c.AttachmentQuota
+	}
+	return int(2*limit) + defaultBodyLimit
+}
+
+// defaultBodyLimit is Fiber's default, left for everything but file contents.
+const defaultBodyLimit = 4 << 20
+
+func (c *Config) algorithm() (crypto.Algorithm, error) {
+	if c.EncryptionAlgorithm == "" {
+		return crypto.DefaultAlgorithm, nil
+	}
+	return crypto.ParseAlgorithm(c.EncryptionAlgorithm)
+}
+
+// MasterKeyProvider returns the provider selected by KEY_PROVIDER.
+func (c *Config) MasterKeyProvider() (crypto.KeyProvider, error) {
+	var (
+		provider crypto.KeyProvider
+		err      error
+	)
+	switch c.KeyProvider {
+	case "env":
+		var keys map[string][]byte
+		if keys, err = c.MasterKeys(); err == nil {
+			provider, err = crypto.NewStaticProvider(c.EncryptionKeyID, keys)
+		}
+	case "file":
+		if c.KeystoreFile == "" {
+			return nil, fmt.Errorf("KEYSTORE_FILE is required for KEY_PROVIDER=file")
+		}
+		provider, err = crypto.NewFileProvider(c.KeystoreFile, c.KeystorePassphrase)
+	case "kms":
+		provider, err = crypto.NewHTTPProvider(c.KMSURL, c.KMSToken, c.KMSKeyID, nil)
+	default:
+		return nil, fmt.Errorf("KEY_PROVIDER: unknown provider %q (want env, file or kms)", c.KeyProvider)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+// legacyKeyPrefix marks a retired key given as its raw bytes, the way keys
+// were read before they had to be hex or base64 encoded. Such keys are only
+// accepted in ENCRYPTION_OLD_KEYS, so that old data can be re-encrypted.
+const legacyKeyPrefix = "raw:"
+
+// MasterKeys returns the configured master keys by ID; the current one is
+// stored under EncryptionKeyID. Every problem found is reported at once.
+func (c *Config) MasterKeys() (map[string][]byte, error) {
+	var problems Problems
+	keys := map[string][]byte{}
+
+	if c.EncryptionKey == "" {
+		problems.add("ENCRYPTION_KEY is not set (generate one with: openssl rand -hex 32)")
+	} else if key, err := crypto.ParseMasterKey(c.EncryptionKey); err != nil {
+		problems.add("ENCRYPTION_KEY: %v (generate one with: openssl rand -hex 32)", err)
+	} else {
+		keys[c.EncryptionKeyID] = key
+	}
+
+	for i, entry := range strings.Split(c.EncryptionOldKeys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok || id == "" {
+			problems.add("ENCRYPTION_OLD_KEYS: entry %d must be id:key", i+1)
+			continue
+		}
+		if id == c.EncryptionKeyID {
+			problems.add("ENCRYPTION_OLD_KEYS: key id %q is already used by ENCRYPTION_KEY", id)
+			continue
+		}
+		if raw, ok := strings.CutPrefix(encoded, legacyKeyPrefix); ok {
+			if n := len(raw); n != 16 && n != 24 && n != 32 {
+				problems.add("ENCRYPTION_OLD_KEYS: key %q is %d bytes, must be 16, 24 or 32", id, n)
+				continue
+			}
+			keys[id] = []byte(raw)
+			continue
+		}
+		key, err := crypto.ParseMasterKey(encoded)
+		if err != nil {
+			problems.add("ENCRYPTION_OLD_KEYS: key %q: %v", id, err)
+			continue
+		}
+		keys[id] = key
+	}
+
+	if err := problems.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Validate checks everything the API server needs to boot and reports every
+// problem at once.
+func (c *Config) Validate() error {
+	var problems Problems
+
+	switch {
+	case c.Sealed():
+		if c.KeyProvider != "env" {
+			problems.add("SEALED_KEYS_FILE cannot be combined with KEY_PROVIDER=%s", c.KeyProvider)
+		}
+	case c.KeyProvider == "env":
+		if _, err := c.MasterKeys(); err != nil {
+			problems.merge(err)
+		}
+	case c.KeyProvider == "file":
+		if c.KeystoreFile == "" {
+			problems.add("KEYSTORE_FILE is required for KEY_PROVIDER=file")
+		}
+		if c.KeystorePassphrase == "" {
+			problems.add("KEYSTORE_PASSPHRASE is required for KEY_PROVIDER=file")
+		}
+	case c.KeyProvider == "kms":
+		if _, err := url.ParseRequestURI(c.KMSURL); err != nil {
+			problems.add("KMS_URL must be an absolute URL for KEY_PROVIDER=kms")
+		}
+		if c.KMSKeyID == "" {
+			problems.add("KMS_KEY_ID is required for KEY_PROVIDER=kms")
+		}
+	default:
+		problems.add("KEY_PROVIDER: unknown provider %q (want env, file or kms)", c.KeyProvider)
+	}
+
+	if _, err := c.algorithm(); err != nil {
+		problems.add("ENCRYPTION_ALGORITHM: %v", err)
+	}
+
+	if c.GoogleClientID == "" {
+		problems.add("GOOGLE_CLIENT_ID is not set")
+	}
+	if c.GoogleClientSecret == "" {
+		problems.add("GOOGLE_CLIENT_SECRET is not set")
+	}
+	if u, err := url.Parse(c.GoogleRedirectURL); err != nil || u.Scheme == "" || u.Host == "" {
+		problems.add("GOOGLE_REDIRECT_URL must be an absolute URL")
+	}
+
+	if c.TrashRetention < 0 {
+		problems.add("TRASH_RETENTION must not be negative")
+	}
+	if c.TrashRetention > 0 && c.TrashPurgeInterval <= 0 {
+		problems.add("TRASH_PURGE_INTERVAL must be positive when TRASH_RETENTION is set")
+	}
+
+	switch c.AttachmentStorage {
+	case "local":
+		if c.AttachmentDir == "" {
+			problems.add("ATTACHMENT_DIR is required for ATTACHMENT_STORAGE=local")
+		}
+	case "s3":
+		if c.S3Endpoint == "" {
+			problems.add("S3_ENDPOINT is required for ATTACHMENT_STORAGE=s3")
+		}
+		if c.S3Bucket == "" {
+			problems.add("S3_BUCKET is required for ATTACHMENT_STORAGE=s3")
+		}
+	default:
+		problems.add("ATTACHMENT_STORAGE: unknown storage %q (want local or s3)", c.AttachmentStorage)
+	}
+	if c.AttachmentMaxSize <= 0 {
+		problems.add("ATTACHMENT_MAX_SIZE must be positive")
+	}
+	if c.AttachmentQuota < 0 {
+		problems.add("ATTACHMENT_QUOTA must not be negative")
+	}
+
+	switch {
+	case c.SessionSecret == "":
+		problems.add("SESSION_SECRET is not set (generate one with: openssl rand -hex 32)")
+	case len(c.SessionSecret) < minSessionSecretLength:
+		problems.add("SESSION_SECRET is %d characters, must be at least %d", len(c.SessionSecret), minSessionSecretLength)
+	}
+
+	return problems.Err()
+}
+
+const minSessionSecretLength = 32
+
+// Problems collects configuration errors so they can be reported together.
+type Problems []string
+
+func (p *Problems) add(format string, args ...any) {
+	*p = append(*p, fmt.Sprintf(format, args...))
+}
+
+func (p *Problems) merge(err error) {
+	var other Problems
+	if errors.As(err, &other) {
+		*p = append(*p, other...)
+		return
+	}
+	*p = append(*p, err.Error())
+}
+
+// Err returns p as an error, or nil if there are no problems.
+func (p Problems) Err() error {
+	if len(p) == 0 {
+		return nil
+	}
+	return p
+}
+
+func (p Problems) Error() string {
+	return "  - " + strings.Join(p, "\n  - ")
+}
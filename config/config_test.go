@@ -0,0 +1,161 @@
+package config
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	hexKey    = "8f3a61c2d94b07e5a1f6c38d2e90b47a5c1d8e2f0a9b3c64d7e18f25a6b0c9d3"
+	base64Key = "ySr1R0C8kZ3yq2v7Ue4oJ9dFmXtN6aLpQwHsBcVzGiE="
+)
+
+func validConfig() Config {
+	return Config{
+		EncryptionKey:      hexKey,
+		EncryptionKeyID:    "2",
+		KeyProvider:        "env",
+		GoogleClientID:     "client-id",
+		GoogleClientSecret: "client-secret",
+		GoogleRedirectURL:  "http://localhost:8080/auth/callback",
+		SessionSecret:      strings.Repeat("s", 32),
+		AttachmentStorage:  "local",
+		AttachmentDir:      "./data/attachments",
+		AttachmentMaxSize:  25 << 20,
+		AttachmentQuota:    100 << 20,
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(c *Config)
+		problems []string
+	}{
+		{name: "Valid", modify: func(c *Config) {}},
+		{name: "Base64 Key", modify: func(c *Config) { c.EncryptionKey = base64Key }},
+		{name: "Legacy Old Key", modify: func(c *Config) { c.EncryptionOldKeys = "1:raw:12345678901234567890123456789012" }},
+		{name: "AES-GCM", modify: func(c *Config) { c.EncryptionAlgorithm = "aes-256-gcm" }},
+		{name: "Unknown Algorithm", modify: func(c *Config) { c.EncryptionAlgorithm = "rot13" }, problems: []string{"ENCRYPTION_ALGORITHM"}},
+		{name: "Sealed Without Key", modify: func(c *Config) { c.EncryptionKey = ""; c.SealedKeysFile = "sealed.json" }},
+		{
+			name: "Everything Missing",
+			modify: func(c *Config) {
+				*c = Config{KeyProvider: "env"}
+			},
+			problems: []string{"ENCRYPTION_KEY is not set", "GOOGLE_CLIENT_ID", "GOOGLE_CLIENT_SECRET", "GOOGLE_REDIRECT_URL", "ATTACHMENT_STORAGE", "ATTACHMENT_MAX_SIZE", "SESSION_SECRET is not set"},
+		},
+		{
+			name: "Weak Values",
+			modify: func(c *Config) {
+				c.EncryptionKey = "your_32_byte_hex_key_here_000000"
+				c.EncryptionOldKeys = "1:" + strings.Repeat("00", 32) + ",bad"
+				c.SessionSecret = "short"
+			},
+			problems: []string{"ENCRYPTION_KEY: crypto: key is 192 bits", `ENCRYPTION_OLD_KEYS: key "1"`, "ENCRYPTION_OLD_KEYS: entry 2", "SESSION_SECRET is 5 characters"},
+		},
+		{
+			name:     "Raw Current Key",
+			modify:   func(c *Config) { c.EncryptionKey = "raw:12345678901234567890123456789012" },
+			problems: []string{"ENCRYPTION_KEY"},
+		},
+		{
+			name:     "Keystore Without Passphrase",
+			modify:   func(c *Config) { c.KeyProvider = "file"; c.KeystoreFile = "keystore.json" },
+			problems: []string{"KEYSTORE_PASSPHRASE"},
+		},
+		{
+			name:     "KMS Without URL",
+			modify:   func(c *Config) { c.KeyProvider = "kms"; c.KMSKeyID = "k1" },
+			problems: []string{"KMS_URL"},
+		},
+		{name: "Trash Kept Until Emptied", modify: func(c *Config) { c.TrashRetention = 0 }},
+		{
+			name:     "Trash Without Purge Interval",
+			modify:   func(c *Config) { c.TrashRetention = 24 * time.Hour; c.TrashPurgeInterval = 0 },
+			problems: []string{"TRASH_PURGE_INTERVAL"},
+		},
+		{
+			name:     "Negative Trash Retention",
+			modify:   func(c *Config) { c.TrashRetention = -time.Hour },
+			problems: []string{"TRASH_RETENTION"},
+		},
+		{name: "Unlimited Attachments", modify: func(c *Config) { c.AttachmentQuota = 0 }},
+		{
+			name: "S3 Attachments",
+			modify: func(c *Config) {
+				c.AttachmentStorage = "s3"
+				c.S3Endpoint = "localhost:9000"
+				c.S3Bucket = "attachments"
+			},
+		},
+		{
+			name:     "S3 Without Bucket",
+			modify:   func(c *Config) { c.AttachmentStorage = "s3"; c.S3Endpoint = "localhost:9000" },
+			problems: []string{"S3_BUCKET"},
+		},
+		{
+			name:     "Unknown Attachment Storage",
+			modify:   func(c *Config) { c.AttachmentStorage = "ftp" },
+			problems: []string{"ATTACHMENT_STORAGE"},
+		},
+		{
+			name:     "Negative Attachment Quota",
+			modify:   func(c *Config) { c.AttachmentQuota = -1 },
+			problems: []string{"ATTACHMENT_QUOTA"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := validConfig()
+			tt.modify(&c)
+
+			err := c.Validate()
+			if len(tt.problems) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			var problems Problems
+			require.ErrorAs(t, err, &problems)
+			assert.Len(t, problems, len(tt.problems))
+			for _, want := range tt.problems {
+				assert.Contains(t, err.Error(), want)
+			}
+		})
+	}
+}
+
+func TestMasterKeys(t *testing.T) {
+	c := validConfig()
+	c.EncryptionOldKeys = "1:raw:12345678901234567890123456789012, 0:" + base64Key
+
+	keys, err := c.MasterKeys()
+	require.NoError(t, err)
+	assert.Len(t, keys, 3)
+	assert.Len(t, keys["2"], 32)
+	assert.Equal(t, []byte("12345678901234567890123456789012"), keys["1"])
+
+	// Key material never appears in error messages.
+	c.EncryptionOldKeys = "1:" + hexKey[:32]
+	_, err = c.MasterKeys()
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), hexKey[:32])
+}
+
+func TestCookieKey(t *testing.T) {
+	c := validConfig()
+	key, err := base64.StdEncoding.DecodeString(c.CookieKey())
+	require.NoError(t, err)
+	assert.Len(t, key, 32)
+
+	// A new secret invalidates existing cookies
+	other := validConfig()
+	other.SessionSecret = strings.Repeat("t", 32)
+	assert.NotEqual(t, c.CookieKey(), other.CookieKey())
+}
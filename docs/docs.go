@@ -23,6 +23,26 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/api/attachments/usage": {
+            "get": {
+                "description": "Total size of the user's attachments, their quota (0 if unlimited) and the largest file that can be attached, in bytes.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Attachments"
+                ],
+                "summary": "Attachment Usage",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AttachmentUsage"
+                        }
+                    }
+                }
+            }
+        },
         "/api/backup/export": {
             "get": {
                 "description": "Download all secrets as an encrypted JSON file",
@@ -39,6 +59,15 @@ const docTemplate = `{
                         "schema": {
                             "type": "file"
                         }
+                    },
+                    "423": {
+                        "description": "Vault is locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
@@ -74,34 +103,43 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "423": {
+                        "description": "Vault is locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
         },
-        "/api/secrets": {
+        "/api/folders": {
             "get": {
-                "description": "Get all secrets (without passwords)",
+                "description": "Get all folders as a flat list, ordered by name; parent_id links them into a tree.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Secrets"
+                    "Folders"
                 ],
-                "summary": "List Secrets",
+                "summary": "List Folders",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/domain.Secret"
+                                "$ref": "#/definitions/domain.Folder"
                             }
                         }
                     }
                 }
             },
             "post": {
-                "description": "Create a new encrypted secret",
+                "description": "Create a folder, inside parent_id or at the top level. Folders nest up to 8 levels deep.",
                 "consumes": [
                     "application/json"
                 ],
@@ -109,17 +147,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Secrets"
+                    "Folders"
                 ],
-                "summary": "Create Secret",
+                "summary": "Create Folder",
                 "parameters": [
                     {
-                        "description": "Secret Data",
-                        "name": "secret",
+                        "description": "Folder",
+                        "name": "folder",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "object"
+                            "$ref": "#/definitions/http.folderRequest"
                         }
                     }
                 ],
@@ -127,42 +165,79 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/domain.Secret"
+                            "$ref": "#/definitions/domain.Folder"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid name or too deep",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown parent",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/api/secrets/{id}": {
-            "get": {
-                "description": "Get a secret by ID with decrypted password",
+        "/api/folders/move": {
+            "post": {
+                "description": "Move secrets into folder_id, or out of any folder when it is null. Unknown secret IDs are skipped; moving does not change a secret's version.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Secrets"
+                    "Folders"
                 ],
-                "summary": "Get Secret",
+                "summary": "Move Secrets",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Secret ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Secrets and target folder",
+                        "name": "move",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.moveSecretsRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Number of secrets moved",
                         "schema": {
-                            "$ref": "#/definitions/domain.Secret"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown folder",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/folders/{id}": {
             "put": {
-                "description": "Update secret details",
+                "description": "Rename a folder and set its parent; a null parent_id moves it to the top level. A folder cannot be moved into its own subtree.",
                 "consumes": [
                     "application/json"
                 ],
@@ -170,24 +245,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Secrets"
+                    "Folders"
                 ],
-                "summary": "Update Secret",
+                "summary": "Update Folder",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Secret ID",
+                        "description": "Folder ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Secret Data",
-                        "name": "secret",
+                        "description": "Folder",
+                        "name": "folder",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "object"
+                            "$ref": "#/definitions/http.folderRequest"
                         }
                     }
                 ],
@@ -195,21 +270,39 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Secret"
+                            "$ref": "#/definitions/domain.Folder"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid name, cycle or too deep",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown folder or parent",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             },
             "delete": {
-                "description": "Remove a secret by ID",
+                "description": "Delete a folder. Its subfolders and secrets move up to its parent folder, or to the top level.",
                 "tags": [
-                    "Secrets"
+                    "Folders"
                 ],
-                "summary": "Delete Secret",
+                "summary": "Delete Folder",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Secret ID",
+                        "description": "Folder ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -218,87 +311,183 @@ const docTemplate = `{
                 "responses": {
                     "204": {
                         "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Unknown folder",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
         },
-        "/auth/callback": {
-            "get": {
-                "description": "Exchanges code for token and creates user session",
+        "/api/generate": {
+            "post": {
+                "description": "In \"password\" mode (the default), generate a random password of 8-64 characters. Each class (uppercase, lowercase, numbers, symbols) can be turned off and every selected class appears at least once. Set rules to a site's passwordrules string (e.g. \"maxlength: 20; required: upper; allowed: lower, digit, [-_]\") to follow it instead of the class toggles; its length limits then apply. In \"passphrase\" mode, join 3-20 words from the EFF diceware list with a separator of up to 5 characters, optionally capitalized and with a digit added to one word. Omitted fields take the defaults: the \"Strong\" 16-character password, or six words joined by \"-\". The response reports the entropy in bits.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Auth"
+                    "Generator"
                 ],
-                "summary": "Google Callback",
+                "summary": "Generate Password",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Auth Code",
-                        "name": "code",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "State",
-                        "name": "state",
-                        "in": "query",
-                        "required": true
+                        "description": "Generator options",
+                        "name": "options",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.GeneratorOptions"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.User"
+                            "$ref": "#/definitions/domain.GeneratedPassword"
                         }
-                    }
-                }
-            }
-        },
-        "/auth/login": {
-            "get": {
-                "description": "Redirects user to Google for authentication",
-                "tags": [
-                    "Auth"
-                ],
-                "summary": "Login with Google",
-                "responses": {
-                    "307": {
-                        "description": "Redirect to Google",
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/auth/logout": {
+        "/api/secret-types": {
             "get": {
-                "description": "Destroys user session",
+                "description": "Get the schema of every secret type: the metadata fields it takes, which are required, and which are sensitive and so always encrypted. In zero-knowledge mode the browser encrypts sensitive fields along with the password.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Auth"
+                    "Secrets"
                 ],
-                "summary": "Logout",
+                "summary": "List Secret Types",
                 "responses": {
                     "200": {
-                        "description": "Logged out",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.SecretSchema"
+                            }
                         }
                     }
                 }
             }
         },
-        "/auth/me": {
+        "/api/secrets": {
             "get": {
-                "description": "Returns the authenticated user's ID and Email",
+                "description": "Get a page of secrets (without passwords), optionally only those in a folder and its subfolders, with a tag, marked as favorites, of a type or matching a search. q matches every word against the title, username, URL and notes, ignoring case; fields sealed at rest are not searched. X-Total-Count holds the number of matching secrets on all pages; pass X-Next-Cursor back as cursor, with the same filters and sort, to get the next page. It is absent on the last page.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Auth"
+                    "Secrets"
+                ],
+                "summary": "List Secrets",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Folder ID, or \\",
+                        "name": "folder_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only secrets with this tag",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only favorites",
+                        "name": "favorite",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only secrets of this type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search words",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "title",
+                            "updated_at",
+                            "last_used"
+                        ],
+                        "type": "string",
+                        "default": "created_at",
+                        "description": "Order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "X-Next-Cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Page size, up to 200",
+                        "name": "limit",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get Current User",
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Secret"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "Cursor of the next page"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Number of matching secrets"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid sort or cursor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown folder",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -307,35 +496,2213 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "domain.Secret": {
-            "type": "object",
-            "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "metadata": {
-                    "type": "object",
-                    "additionalProperties": true
-                },
-                "password": {
-                    "description": "Decrypted password, only populated when needed",
-                    "type": "string"
-                },
-                "title": {
-                    "type": "string"
-                },
-                "updated_at": {
-                    "type": "string"
-                },
-                "user_id": {
-                    "type": "string"
+            },
+            "post": {
+                "description": "Create a new encrypted secret of a type from /api/secret-types (login if omitted); its typed fields go in metadata and are validated against the type's schema. The password's strength score (0-4) is stored alongside logins; in zero-knowledge mode the browser may send strength_score itself.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Create Secret",
+                "parameters": [
+                    {
+                        "description": "Secret Data",
+                        "name": "secret",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Secret"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid secret or unknown folder",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "423": {
+                        "description": "Vault is locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/secrets/{id}": {
+            "get": {
+                "description": "Get a secret by ID with decrypted password. The ETag header carries the version to send back in If-Match when updating.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Get Secret",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Secret"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version, e.g. \\\"3\\"
+                            }
+                        }
+                    },
+                    "423": {
+                        "description": "Vault is locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update secret details. The type cannot change and may be omitted. The version the edit is based on is required, either as \"version\" in the body or as an If-Match ETag from GET. If the secret changed since, nothing is saved and the stored state (plaintext fields only) is returned under \"current\".",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Update Secret",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being updated, e.g. \\",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Secret Data",
+                        "name": "secret",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Secret"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid secret",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Changed since it was loaded",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "423": {
+                        "description": "Vault is locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "428": {
+                        "description": "No version given",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Move a secret to the trash. It can be restored from /api/trash until it is purged.",
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Delete Secret",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "423": {
+                        "description": "Vault is locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Change some fields of a secret with a JSON Merge Patch (RFC 7396): omitted fields are kept, null removes a field, and metadata is merged key by key, nested objects included. Patchable fields are title, username, password and metadata; client_encrypted and strength_score apply along with a new password. \"version\" or If-Match is optional here; when given, the patch is rejected with 409 if the secret changed since. A patch that sets no field returns the secret as it is, without a new version.",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Patch Secret",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being patched, e.g. \\",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Merge patch, e.g. {\\",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Secret"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid patch",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Changed since it was loaded",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "415": {
+                        "description": "Not JSON",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "423": {
+                        "description": "Vault is locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/secrets/{id}/attachments": {
+            "get": {
+                "description": "List the files attached to a secret, oldest first, without their contents.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Attachments"
+                ],
+                "summary": "List Attachments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Attachment"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Secret not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Attach a file to a secret, such as an SSH key or a PDF of recovery codes. The file is encrypted with a key of its own as it is stored, and counts toward the user's quota (see /api/attachments/usage). In zero-knowledge mode the browser must encrypt the file with the vault key first and set client_encrypted.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Attachments"
+                ],
+                "summary": "Upload Attachment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "File",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "The file was encrypted with the vault key",
+                        "name": "client_encrypted",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Attachment"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid file",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Secret not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "File too large or quota exceeded",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "423": {
+                        "description": "Vault is locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/secrets/{id}/attachments/{attachmentId}": {
+            "get": {
+                "description": "Download a file attached to a secret. Client-encrypted files (X-Client-Encrypted: true) are returned as the browser uploaded them, for it to decrypt with the vault key. If the stored file was tampered with, the download is cut off.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Attachments"
+                ],
+                "summary": "Download Attachment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Attachment ID",
+                        "name": "attachmentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        },
+                        "headers": {
+                            "X-Client-Encrypted": {
+                                "type": "string",
+                                "description": "Whether the file is encrypted with the vault key"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "423": {
+                        "description": "Vault is locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a file attached to a secret. This cannot be undone.",
+                "tags": [
+                    "Attachments"
+                ],
+                "summary": "Delete Attachment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Attachment ID",
+                        "name": "attachmentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "423": {
+                        "description": "Vault is locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/secrets/{id}/favorite": {
+            "put": {
+                "description": "Mark a secret as a favorite, or unmark it with false. This does not change the secret's version.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Set Favorite",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "e.g. {\\",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Secret"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    }
+                }
+            }
+        },
+        "/api/secrets/{id}/history": {
+            "get": {
+                "description": "List the previous versions of a secret, newest first. Each entry names the fields changed by the version that replaced it (\"title\", \"username\", \"password\" or \"metadata.\u003ckey\u003e\"); passwords are not included. Only the newest history_limit versions (see settings) are kept.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Secret History",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.SecretVersion"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "423": {
+                        "description": "Vault is locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/secrets/{id}/history/{version}": {
+            "get": {
+                "description": "Get a previous version of a secret with its decrypted password",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Get Secret Version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SecretVersion"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid version",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "423": {
+                        "description": "Vault is locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/secrets/{id}/restore/{version}": {
+            "post": {
+                "description": "Restore a previous version of a secret. The restored state is saved as a new version, and the state it replaces is kept in history.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Restore Secret Version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Secret"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid version",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Changed during restore",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "423": {
+                        "description": "Vault is locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/secrets/{id}/rotate": {
+            "post": {
+                "description": "Generate a new password with the secret's saved generator options (metadata password_rules, else metadata generator, else the Strong preset). The previous state is kept in history and the version is bumped. The response is the only time the new password is returned unprompted. Only logins can be rotated, and not in zero-knowledge mode.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Rotate Password",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Secret"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid saved options, not a login or zero-knowledge mode",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Changed during rotation",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "423": {
+                        "description": "Vault is locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/secrets/{id}/tags": {
+            "post": {
+                "description": "Add tags to a secret, creating new ones as needed. Tag names are lowercased. Tagging does not change the secret's version.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tags"
+                ],
+                "summary": "Add Tags",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tags, e.g. {\\",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "All tags of the secret",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid tag",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    }
+                }
+            }
+        },
+        "/api/secrets/{id}/tags/{tag}": {
+            "delete": {
+                "description": "Remove a tag from one secret. Tags no secret carries any more are deleted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tags"
+                ],
+                "summary": "Remove Tag",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tag name",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Remaining tags of the secret",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "array",
+                                "items": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    }
+                }
+            }
+        },
+        "/api/secrets/{id}/totp": {
+            "get": {
+                "description": "Get the current RFC 6238 code of the TOTP seed stored in a login's totp field, and the seconds it stays valid. Reading a code marks the secret as used. In zero-knowledge mode the seed is encrypted by the browser, which computes codes itself.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Get TOTP Code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.TOTPCode"
+                        }
+                    },
+                    "404": {
+                        "description": "Not found, or no TOTP seed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "423": {
+                        "description": "Vault is locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/settings": {
+            "get": {
+                "description": "Get the user's settings, e.g. which secret fields are stored unencrypted",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Settings"
+                ],
+                "summary": "Get Settings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.UserSettings"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Choose which fields stay in plaintext (\"username\" or metadata keys); everything else except the title is encrypted and existing secrets are re-encrypted to match. Set auto_lock_minutes to lock the vault after inactivity (0 = until logout) and history_limit to the number of previous versions kept per secret (1-100). Omitted fields keep their current value.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Settings"
+                ],
+                "summary": "Update Settings",
+                "parameters": [
+                    {
+                        "description": "Settings",
+                        "name": "settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UserSettings"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.UserSettings"
+                        }
+                    },
+                    "423": {
+                        "description": "Vault is locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/strength": {
+            "post": {
+                "description": "Score a password from 0 (too guessable) to 4 (very unguessable), zxcvbn-style. Dictionary words, common passwords, l33t substitutions, keyboard walks, repeats, sequences and dates are detected; user_inputs (e.g. the title and username) are treated as easy to guess too. The response includes an entropy estimate, crack times for four attack scenarios and feedback for weak passwords.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Generator"
+                ],
+                "summary": "Password Strength",
+                "parameters": [
+                    {
+                        "description": "password and optional user_inputs",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.PasswordStrength"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tags": {
+            "get": {
+                "description": "Get every tag in the vault with the number of secrets carrying it (the trash not included).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tags"
+                ],
+                "summary": "List Tags",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Tag"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tags/{tag}": {
+            "put": {
+                "description": "Rename a tag on every secret. If a tag with the new name exists, the two are merged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tags"
+                ],
+                "summary": "Rename Tag",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tag name",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New name, e.g. {\\",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid name",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown tag",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a tag from every secret; the secrets themselves are kept.",
+                "tags": [
+                    "Tags"
+                ],
+                "summary": "Delete Tag",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tag name",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Unknown tag",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/totp/decode": {
+            "post": {
+                "description": "Read the otpauth://totp/ URI from a PNG, JPEG or GIF image of a QR code, such as the one a site shows when enabling two-factor login. The image is decoded on the server and not stored; save the returned uri in a login's totp field.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Secrets"
+                ],
+                "summary": "Decode TOTP QR Code",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "QR code image (max 2 MiB)",
+                        "name": "image",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.TOTPKeyInfo"
+                        }
+                    },
+                    "400": {
+                        "description": "No TOTP QR code in the image",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "Image too large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/trash": {
+            "get": {
+                "description": "List deleted secrets, most recently deleted first, without passwords. They are purged automatically once the retention period (X-Trash-Retention header, e.g. \"720h0m0s\"; absent if kept until emptied) has passed since deleted_at.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Trash"
+                ],
+                "summary": "List Trash",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Secret"
+                            }
+                        },
+                        "headers": {
+                            "X-Trash-Retention": {
+                                "type": "string",
+                                "description": "How long secrets stay in the trash"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Permanently delete every secret in the trash. This cannot be undone.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Trash"
+                ],
+                "summary": "Empty Trash",
+                "responses": {
+                    "200": {
+                        "description": "Number of secrets purged",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/trash/{id}": {
+            "delete": {
+                "description": "Permanently delete a secret in the trash, with its version history. This cannot be undone.",
+                "tags": [
+                    "Trash"
+                ],
+                "summary": "Purge Secret",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not in the trash",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/trash/{id}/restore": {
+            "post": {
+                "description": "Move a deleted secret back into the vault, unchanged.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Trash"
+                ],
+                "summary": "Restore From Trash",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Secret"
+                        }
+                    },
+                    "404": {
+                        "description": "Not in the trash",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/vault": {
+            "get": {
+                "description": "Get the KDF parameters and wrapped vault key the browser needs to derive keys from the master password",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vault"
+                ],
+                "summary": "Get Vault",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Vault"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/vault/lock": {
+            "post": {
+                "description": "Lock the vault; the login session stays valid",
+                "tags": [
+                    "Vault"
+                ],
+                "summary": "Lock Vault",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/api/vault/master-password": {
+            "put": {
+                "description": "Replace the KDF parameters, verifier and wrapped vault key. Secrets are not re-encrypted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vault"
+                ],
+                "summary": "Change Master Password",
+                "parameters": [
+                    {
+                        "description": "current_auth_key plus the new Vault Setup",
+                        "name": "setup",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Vault"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/vault/pin": {
+            "put": {
+                "description": "Set or change the PIN used to unlock the vault (6-64 characters). Requires an unlocked vault.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vault"
+                ],
+                "summary": "Set Unlock PIN",
+                "parameters": [
+                    {
+                        "description": "pin",
+                        "name": "pin",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove the unlock PIN. Without a PIN or master password the vault no longer locks. Requires an unlocked vault.",
+                "tags": [
+                    "Vault"
+                ],
+                "summary": "Remove Unlock PIN",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/vault/status": {
+            "get": {
+                "description": "Whether the vault is locked in this session, how it can be unlocked and when it locks again",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vault"
+                ],
+                "summary": "Vault Lock Status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/api/vault/unlock": {
+            "post": {
+                "description": "Unlock with the PIN or, in zero-knowledge mode, the auth key derived from the master password. After 5 failed attempts the session is ended.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vault"
+                ],
+                "summary": "Unlock Vault",
+                "parameters": [
+                    {
+                        "description": "PIN or auth_key",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UnlockRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/vault/verify": {
+            "post": {
+                "description": "Check the auth key derived from the master password against the stored verifier",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vault"
+                ],
+                "summary": "Verify Master Password",
+                "parameters": [
+                    {
+                        "description": "auth_key",
+                        "name": "auth",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/vault/zero-knowledge": {
+            "post": {
+                "description": "Store the Argon2id parameters, the auth key verifier and the browser-wrapped vault key. From then on passwords must be encrypted by the browser.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vault"
+                ],
+                "summary": "Enable Zero-Knowledge Mode",
+                "parameters": [
+                    {
+                        "description": "Vault Setup",
+                        "name": "setup",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.VaultSetup"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Vault"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/callback": {
+            "get": {
+                "description": "Exchanges code for token and creates user session",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Google Callback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Auth Code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "get": {
+                "description": "Redirects user to Google for authentication",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Login with Google",
+                "responses": {
+                    "307": {
+                        "description": "Redirect to Google",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "get": {
+                "description": "Destroys user session",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Logout",
+                "responses": {
+                    "200": {
+                        "description": "Logged out",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/me": {
+            "get": {
+                "description": "Returns the authenticated user's ID and Email",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Get Current User",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/sys/rotation": {
+            "get": {
+                "description": "Whether the background job that re-encrypts data under the current master key is running, has finished, and has failed for any item. This endpoint is unauthenticated, so detailed progress is only written to the server log.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Key Rotation Status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.RotationStatus"
+                        }
+                    }
+                }
+            }
+        },
+        "/sys/seal-status": {
+            "get": {
+                "description": "Whether the master keys are loaded, and how many unseal shares have been collected",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Seal Status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SealStatus"
+                        }
+                    }
+                }
+            }
+        },
+        "/sys/unseal": {
+            "post": {
+                "description": "Provide one unseal share. Once the threshold is reached the master keys are decrypted and the API becomes available. A wrong combination resets progress.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Unseal",
+                "parameters": [
+                    {
+                        "description": "share",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SealStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.Attachment": {
+            "type": "object",
+            "properties": {
+                "client_encrypted": {
+                    "description": "ClientEncrypted attachments were encrypted by the browser with the vault\nkey before upload (zero-knowledge mode); the server adds its own layer\non top, like it does for passwords.",
+                    "type": "boolean"
+                },
+                "content_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "secret_id": {
+                    "type": "string"
+                },
+                "size": {
+                    "description": "Size is the size of the file as uploaded, which counts toward the quota.",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.AttachmentUsage": {
+            "type": "object",
+            "properties": {
+                "max_size": {
+                    "description": "MaxSize is the largest file that can be attached.",
+                    "type": "integer"
+                },
+                "quota": {
+                    "description": "Quota is 0 if attachments are not limited.",
+                    "type": "integer"
+                },
+                "used": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.CrackTime": {
+            "type": "object",
+            "properties": {
+                "display": {
+                    "type": "string",
+                    "example": "centuries"
+                },
+                "seconds": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.CrackTimes": {
+            "type": "object",
+            "properties": {
+                "offline_fast_hashing": {
+                    "description": "10B guesses per second against a fast hash",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.CrackTime"
+                        }
+                    ]
+                },
+                "offline_slow_hashing": {
+                    "description": "10k guesses per second against a slow hash such as Argon2",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.CrackTime"
+                        }
+                    ]
+                },
+                "online_throttled": {
+                    "description": "100 guesses per hour, e.g. a rate-limited login form",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.CrackTime"
+                        }
+                    ]
+                },
+                "online_unthrottled": {
+                    "description": "10 guesses per second",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.CrackTime"
+                        }
+                    ]
+                }
+            }
+        },
+        "domain.Folder": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.GeneratedPassword": {
+            "type": "object",
+            "properties": {
+                "entropy_bits": {
+                    "description": "Entropy is the strength in bits, assuming the attacker knows the options.",
+                    "type": "number",
+                    "example": 104.9
+                },
+                "options": {
+                    "$ref": "#/definitions/domain.GeneratorOptions"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.GeneratorOptions": {
+            "type": "object",
+            "properties": {
+                "capitalize": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "include_number": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "length": {
+                    "type": "integer",
+                    "example": 16
+                },
+                "lowercase": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "password",
+                        "passphrase"
+                    ],
+                    "example": "password"
+                },
+                "numbers": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "rules": {
+                    "type": "string",
+                    "example": "minlength: 8; maxlength: 20; required: upper; required: digit; allowed: lower, [-_]"
+                },
+                "separator": {
+                    "type": "string",
+                    "example": "-"
+                },
+                "symbols": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "uppercase": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "words": {
+                    "type": "integer",
+                    "example": 6
+                }
+            }
+        },
+        "domain.KDFParams": {
+            "type": "object",
+            "properties": {
+                "algorithm": {
+                    "description": "Always \"argon2id\"",
+                    "type": "string"
+                },
+                "iterations": {
+                    "description": "Passes over memory",
+                    "type": "integer"
+                },
+                "memory": {
+                    "description": "KiB",
+                    "type": "integer"
+                },
+                "parallelism": {
+                    "description": "Lanes",
+                    "type": "integer"
+                },
+                "salt": {
+                    "description": "Base64, at least 16 bytes",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.PasswordStrength": {
+            "type": "object",
+            "properties": {
+                "crack_times": {
+                    "$ref": "#/definitions/domain.CrackTimes"
+                },
+                "entropy_bits": {
+                    "type": "number",
+                    "example": 53.2
+                },
+                "guesses": {
+                    "type": "number"
+                },
+                "score": {
+                    "description": "Score runs from 0 (too guessable) to 4 (very unguessable).",
+                    "type": "integer",
+                    "example": 4
+                },
+                "sequence": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.StrengthMatch"
+                    }
+                },
+                "suggestions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "warning": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.RotationStatus": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "description": "Failed is set if any data key or secret could not be moved.",
+                    "type": "boolean"
+                },
+                "finished": {
+                    "type": "boolean"
+                },
+                "running": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.SealStatus": {
+            "type": "object",
+            "properties": {
+                "progress": {
+                    "description": "shares collected so far",
+                    "type": "integer"
+                },
+                "sealed": {
+                    "type": "boolean"
+                },
+                "shares": {
+                    "description": "shares that were issued",
+                    "type": "integer"
+                },
+                "threshold": {
+                    "description": "shares needed to unseal",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.Secret": {
+            "type": "object",
+            "properties": {
+                "client_encrypted": {
+                    "description": "Set in zero-knowledge mode: Password was encrypted in the browser and is an\nopaque blob only the owner can decrypt.",
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "description": "Set while the secret is in the trash",
+                    "type": "string"
+                },
+                "favorite": {
+                    "type": "boolean"
+                },
+                "folder_id": {
+                    "description": "Nil for secrets that are not in a folder; moving is not an edit",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "description": "When the password was last revealed; nil if it never was",
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "password": {
+                    "description": "Decrypted password, only populated when needed",
+                    "type": "string"
+                },
+                "strength_score": {
+                    "description": "Strength score (0-4) of the password; the password itself is never\nanalysed after it is stored. Nil when unknown, e.g. a client-encrypted\npassword whose score the browser did not send.",
+                    "type": "integer"
+                },
+                "tags": {
+                    "description": "Tags and Favorite organize the vault; changing them is not an edit either",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Login unless set; it cannot change once the secret is created",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.SecretType"
+                        }
+                    ]
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.SecretField": {
+            "type": "object",
+            "properties": {
+                "format": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "multiline": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "required": {
+                    "type": "boolean"
+                },
+                "sensitive": {
+                    "description": "Sensitive fields are always sealed, whatever the owner keeps in\nplaintext. In zero-knowledge mode the browser encrypts them along with\nthe password instead, so the server never sees them.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.SecretSchema": {
+            "type": "object",
+            "properties": {
+                "allow_custom": {
+                    "description": "AllowCustom accepts metadata keys beyond Fields. Only logins, which\npredate types, take them.",
+                    "type": "boolean"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.SecretField"
+                    }
+                },
+                "label": {
+                    "type": "string"
+                },
+                "password_label": {
+                    "type": "string"
+                },
+                "scored": {
+                    "description": "Scored types get a strength score and can be rotated.",
+                    "type": "boolean"
+                },
+                "type": {
+                    "$ref": "#/definitions/domain.SecretType"
+                },
+                "username_label": {
+                    "description": "UsernameLabel and PasswordLabel name what the username and the\n(always encrypted) password hold for this type; empty if the type has\nno such field.",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.SecretType": {
+            "type": "string",
+            "enum": [
+                "login",
+                "card",
+                "note",
+                "ssh_key",
+                "api_key",
+                "identity"
+            ],
+            "x-enum-varnames": [
+                "SecretTypeLogin",
+                "SecretTypeCard",
+                "SecretTypeNote",
+                "SecretTypeSSHKey",
+                "SecretTypeAPIKey",
+                "SecretTypeIdentity"
+            ]
+        },
+        "domain.SecretVersion": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "description": "Changes lists the fields (\"title\", \"username\", \"password\" or\n\"metadata.\u003ckey\u003e\") that differ in the version that replaced this one.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "client_encrypted": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "password": {
+                    "description": "Decrypted password, only populated when viewing one version",
+                    "type": "string"
+                },
+                "secret_id": {
+                    "type": "string"
+                },
+                "strength_score": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is when this version was written; CreatedAt when it was\nreplaced and moved to history.",
+                    "type": "string"
                 },
                 "username": {
                     "type": "string"
@@ -345,6 +2712,90 @@ const docTemplate = `{
                 }
             }
         },
+        "domain.StrengthMatch": {
+            "type": "object",
+            "properties": {
+                "guesses": {
+                    "type": "number"
+                },
+                "pattern": {
+                    "type": "string",
+                    "example": "dictionary"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "password"
+                }
+            }
+        },
+        "domain.TOTPCode": {
+            "type": "object",
+            "properties": {
+                "algorithm": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "digits": {
+                    "type": "integer"
+                },
+                "period": {
+                    "type": "integer"
+                },
+                "remaining": {
+                    "description": "Remaining is the number of seconds the code stays valid.",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.TOTPKeyInfo": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "string"
+                },
+                "algorithm": {
+                    "type": "string"
+                },
+                "digits": {
+                    "type": "integer"
+                },
+                "issuer": {
+                    "type": "string"
+                },
+                "period": {
+                    "type": "integer"
+                },
+                "uri": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Tag": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "secrets": {
+                    "description": "Secrets counts the secrets carrying the tag, excluding the trash.",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.UnlockRequest": {
+            "type": "object",
+            "properties": {
+                "auth_key": {
+                    "description": "Derived from the master password in zero-knowledge mode",
+                    "type": "string"
+                },
+                "pin": {
+                    "type": "string"
+                }
+            }
+        },
         "domain.User": {
             "type": "object",
             "properties": {
@@ -361,6 +2812,99 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "domain.UserSettings": {
+            "type": "object",
+            "properties": {
+                "auto_lock_minutes": {
+                    "description": "AutoLockMinutes locks the vault after this much inactivity; 0 keeps it\nunlocked until logout.",
+                    "type": "integer"
+                },
+                "history_limit": {
+                    "description": "HistoryLimit is how many previous versions of each secret are kept\n(1-MaxHistoryLimit); older ones are deleted.",
+                    "type": "integer"
+                },
+                "plaintext_fields": {
+                    "description": "PlaintextFields lists the secret fields stored unencrypted for listing and\nsearching: \"username\" and/or metadata keys such as \"url\". The title is\nalways plaintext and the password is always encrypted.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Vault": {
+            "type": "object",
+            "properties": {
+                "has_pin": {
+                    "type": "boolean"
+                },
+                "kdf": {
+                    "$ref": "#/definitions/domain.KDFParams"
+                },
+                "key_id": {
+                    "description": "KeyID identifies the vault key; it survives master password changes,\nwhich only re-wrap the key.",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "wrapped_key": {
+                    "description": "WrappedKey is the vault key encrypted by the browser with the master key.",
+                    "type": "string"
+                },
+                "zero_knowledge": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.VaultSetup": {
+            "type": "object",
+            "properties": {
+                "auth_key": {
+                    "description": "Base64, 32 bytes",
+                    "type": "string"
+                },
+                "kdf": {
+                    "$ref": "#/definitions/domain.KDFParams"
+                },
+                "key_id": {
+                    "type": "string"
+                },
+                "wrapped_key": {
+                    "type": "string"
+                }
+            }
+        },
+        "http.folderRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "parent_id": {
+                    "description": "Null or omitted for a top-level folder",
+                    "type": "string"
+                }
+            }
+        },
+        "http.moveSecretsRequest": {
+            "type": "object",
+            "properties": {
+                "folder_id": {
+                    "description": "Null to take the secrets out of their folders",
+                    "type": "string"
+                },
+                "secret_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,186 @@
+package http
+
+import (
+	"errors"
+	"mime"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/session"
+	"github.com/herdiagusthio/password-manager/internal/domain"
+)
+
+type AttachmentHandler struct {
+	usecase domain.AttachmentUsecase
+	store   *session.Store
+}
+
+func NewAttachmentHandler(app *fiber.App, uc domain.AttachmentUsecase, store *session.Store, lock *VaultLock) {
+	h := &AttachmentHandler{
+		usecase: uc,
+		store:   store,
+	}
+
+	api := app.Group("/api", h.requireAuth)
+	api.Get("/secrets/:id/attachments", h.List)
+	api.Post("/secrets/:id/attachments", lock.RequireUnlocked, h.Upload)
+	api.Get("/secrets/:id/attachments/:attachmentId", lock.RequireUnlocked, h.Download)
+	api.Delete("/secrets/:id/attachments/:attachmentId", lock.RequireUnlocked, h.Delete)
+	api.Get("/attachments/usage", h.Usage)
+}
+
+func (h *AttachmentHandler) requireAuth(c *fiber.Ctx) error {
+	sess, err := h.store.Get(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	userID := sess.Get("user_id")
+	if userID == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	c.Locals("user_id", userID)
+	return c.Next()
+}
+
+// attachmentError maps usecase errors to responses.
+func attachmentError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrAttachmentNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidAttachment):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrAttachmentTooLarge), errors.Is(err, domain.ErrQuotaExceeded):
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
+
+// List returns the attachments of a secret
+// @Summary List Attachments
+// @Description List the files attached to a secret, oldest first, without their contents.
+// @Tags Attachments
+// @Produce json
+// @Param id path string true "Secret ID"
+// @Success 200 {array} domain.Attachment
+// @Failure 404 {object} map[string]string "Secret not found"
+// @Router /api/secrets/{id}/attachments [get]
+func (h *AttachmentHandler) List(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	attachments, err := h.usecase.ListAttachments(c.Context(), c.Params("id"), userID)
+	if err != nil {
+		return attachmentError(c, err)
+	}
+	return c.JSON(attachments)
+}
+
+// Upload attaches a file to a secret
+// @Summary Upload Attachment
+// @Description Attach a file to a secret, such as an SSH key or a PDF of recovery codes. The file is encrypted with a key of its own as it is stored, and counts toward the user's quota (see /api/attachments/usage). In zero-knowledge mode the browser must encrypt the file with the vault key first and set client_encrypted.
+// @Tags Attachments
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Secret ID"
+// @Param file formData file true "File"
+// @Param client_encrypted formData bool false "The file was encrypted with the vault key"
+// @Success 201 {object} domain.Attachment
+// @Failure 400 {object} map[string]string "Invalid file"
+// @Failure 404 {object} map[string]string "Secret not found"
+// @Failure 413 {object} map[string]string "File too large or quota exceeded"
+// @Failure 423 {object} map[string]string "Vault is locked"
+// @Router /api/secrets/{id}/attachments [post]
+func (h *AttachmentHandler) Upload(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "missing file"})
+	}
+	clientEncrypted, _ := strconv.ParseBool(c.FormValue("client_encrypted"))
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	defer file.Close()
+
+	attachment := &domain.Attachment{
+		SecretID:        c.Params("id"),
+		UserID:          userID,
+		Name:            fileHeader.Filename,
+		ContentType:     fileHeader.Header.Get("Content-Type"),
+		Size:            fileHeader.Size,
+		ClientEncrypted: clientEncrypted,
+	}
+	if err := h.usecase.AddAttachment(c.Context(), attachment, file); err != nil {
+		return attachmentError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(attachment)
+}
+
+// Download returns the decrypted contents of an attachment
+// @Summary Download Attachment
+// @Description Download a file attached to a secret. Client-encrypted files (X-Client-Encrypted: true) are returned as the browser uploaded them, for it to decrypt with the vault key. If the stored file was tampered with, the download is cut off.
+// @Tags Attachments
+// @Produce octet-stream
+// @Param id path string true "Secret ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Success 200 {file} []byte
+// @Header 200 {string} X-Client-Encrypted "Whether the file is encrypted with the vault key"
+// @Failure 404 {object} map[string]string "Not found"
+// @Failure 423 {object} map[string]string "Vault is locked"
+// @Router /api/secrets/{id}/attachments/{attachmentId} [get]
+func (h *AttachmentHandler) Download(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	attachment, content, err := h.usecase.OpenAttachment(c.Context(), c.Params("attachmentId"), c.Params("id"), userID)
+	if err != nil {
+		return attachmentError(c, err)
+	}
+
+	contentType := attachment.ContentType
+	if attachment.ClientEncrypted {
+		contentType = "application/octet-stream"
+	}
+	c.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name}))
+	c.Set("Content-Type", contentType)
+	c.Set("X-Client-Encrypted", strconv.FormatBool(attachment.ClientEncrypted))
+	// The stream is closed once it has been sent
+	return c.SendStream(content, int(attachment.Size))
+}
+
+// Delete removes an attachment
+// @Summary Delete Attachment
+// @Description Delete a file attached to a secret. This cannot be undone.
+// @Tags Attachments
+// @Param id path string true "Secret ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} map[string]string "Not found"
+// @Failure 423 {object} map[string]string "Vault is locked"
+// @Router /api/secrets/{id}/attachments/{attachmentId} [delete]
+func (h *AttachmentHandler) Delete(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	if err := h.usecase.DeleteAttachment(c.Context(), c.Params("attachmentId"), c.Params("id"), userID); err != nil {
+		return attachmentError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Usage reports how much of their attachment quota the user has used
+// @Summary Attachment Usage
+// @Description Total size of the user's attachments, their quota (0 if unlimited) and the largest file that can be attached, in bytes.
+// @Tags Attachments
+// @Produce json
+// @Success 200 {object} domain.AttachmentUsage
+// @Router /api/attachments/usage [get]
+func (h *AttachmentHandler) Usage(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	usage, err := h.usecase.Usage(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(usage)
+}
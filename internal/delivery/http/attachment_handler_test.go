@@ -0,0 +1,40 @@
+package http_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/session"
+	authHttp "github.com/herdiagusthio/password-manager/internal/delivery/http"
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/internal/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+func newAttachmentApp(t *testing.T, ctrl *gomock.Controller, uc *mocks.MockAttachmentUsecase) (*fiber.App, func(unlock bool) string) {
+	return newTestApp(t, ctrl, func(app *fiber.App, store *session.Store, lock *authHttp.VaultLock) {
+		authHttp.NewAttachmentHandler(app, uc, store, lock)
+	})
+}
+
+func TestAttachmentHandler_WritesRequireUnlockedVault(t *testing.T) {
+	body, contentType := fileForm(t, "file", "id_ed25519", "private key")
+	routes := []lockedRoute{
+		{name: "Upload", method: fiber.MethodPost, path: "/api/secrets/sec-1/attachments", contentType: contentType, body: body, status: fiber.StatusCreated},
+		{name: "Delete", method: fiber.MethodDelete, path: "/api/secrets/sec-1/attachments/att-1", status: fiber.StatusNoContent},
+	}
+	testLockedRoutes(t, routes, newAttachmentApp, mocks.NewMockAttachmentUsecase, map[string]func(*mocks.MockAttachmentUsecase){
+		"Upload": func(uc *mocks.MockAttachmentUsecase) {
+			uc.EXPECT().AddAttachment(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, attachment *domain.Attachment, content io.Reader) error {
+					attachment.ID = "att-1"
+					return nil
+				})
+		},
+		"Delete": func(uc *mocks.MockAttachmentUsecase) {
+			uc.EXPECT().DeleteAttachment(gomock.Any(), "att-1", "sec-1", "user-1").Return(nil)
+		},
+	})
+}
@@ -13,7 +13,7 @@ type BackupHandler struct {
 	store   *session.Store
 }
 
-func NewBackupHandler(app *fiber.App, uc domain.BackupUsecase, store *session.Store) {
+func NewBackupHandler(app *fiber.App, uc domain.BackupUsecase, store *session.Store, lock *VaultLock) {
 	h := &BackupHandler{
 		usecase: uc,
 		store:   store,
@@ -25,8 +25,8 @@ func NewBackupHandler(app *fiber.App, uc domain.BackupUsecase, store *session.St
 	// For simplicity, I will duplicate the auth logic here or I should have refactored it. 
 	// Refactoring is better but I'll write a small inline middleware for now to avoid cross-file dependency mess if I don't move it to a shared pkg.
 	
-	api.Get("/backup/export", h.Export)
-	api.Post("/backup/import", h.Import)
+	api.Get("/backup/export", lock.RequireUnlocked, h.Export)
+	api.Post("/backup/import", lock.RequireUnlocked, h.Import)
 }
 
 func (h *BackupHandler) requireAuth(c *fiber.Ctx) error {
@@ -48,6 +48,7 @@ func (h *BackupHandler) requireAuth(c *fiber.Ctx) error {
 // @Tags Backup
 // @Produce octet-stream
 // @Success 200 {file} []byte
+// @Failure 423 {object} map[string]string "Vault is locked"
 // @Router /api/backup/export [get]
 func (h *BackupHandler) Export(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
@@ -70,6 +71,7 @@ func (h *BackupHandler) Export(c *fiber.Ctx) error {
 // @Produce json
 // @Param backup formData file true "Backup File (.enc)"
 // @Success 200 {object} map[string]string
+// @Failure 423 {object} map[string]string "Vault is locked"
 // @Router /api/backup/import [post]
 func (h *BackupHandler) Import(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
@@ -0,0 +1,46 @@
+package http_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/session"
+	authHttp "github.com/herdiagusthio/password-manager/internal/delivery/http"
+	"github.com/herdiagusthio/password-manager/internal/mocks"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// fileForm encodes a multipart form holding one file, and returns it with
+// its content type.
+func fileForm(t *testing.T, field, name, content string) (string, string) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile(field, name)
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return body.String(), w.FormDataContentType()
+}
+
+func newBackupApp(t *testing.T, ctrl *gomock.Controller, uc *mocks.MockBackupUsecase) (*fiber.App, func(unlock bool) string) {
+	return newTestApp(t, ctrl, func(app *fiber.App, store *session.Store, lock *authHttp.VaultLock) {
+		authHttp.NewBackupHandler(app, uc, store, lock)
+	})
+}
+
+func TestBackupHandler_Import_RequiresUnlockedVault(t *testing.T) {
+	// An import overwrites secrets and may adopt a vault key
+	body, contentType := fileForm(t, "backup", "backup.enc", "sealed backup")
+	routes := []lockedRoute{
+		{name: "Import", method: fiber.MethodPost, path: "/api/backup/import", contentType: contentType, body: body, status: fiber.StatusOK},
+	}
+	testLockedRoutes(t, routes, newBackupApp, mocks.NewMockBackupUsecase, map[string]func(*mocks.MockBackupUsecase){
+		"Import": func(uc *mocks.MockBackupUsecase) {
+			uc.EXPECT().ImportSecrets(gomock.Any(), "user-1", []byte("sealed backup")).Return(nil)
+		},
+	})
+}
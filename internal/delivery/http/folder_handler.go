@@ -0,0 +1,178 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/session"
+	"github.com/herdiagusthio/password-manager/internal/domain"
+)
+
+type FolderHandler struct {
+	usecase domain.FolderUsecase
+	store   *session.Store
+}
+
+func NewFolderHandler(app *fiber.App, uc domain.FolderUsecase, store *session.Store) {
+	h := &FolderHandler{
+		usecase: uc,
+		store:   store,
+	}
+
+	api := app.Group("/api", h.requireAuth)
+	api.Get("/folders", h.List)
+	api.Post("/folders", h.Create)
+	api.Put("/folders/:id", h.Update)
+	api.Delete("/folders/:id", h.Delete)
+	api.Post("/folders/move", h.MoveSecrets)
+}
+
+func (h *FolderHandler) requireAuth(c *fiber.Ctx) error {
+	sess, err := h.store.Get(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	userID := sess.Get("user_id")
+	if userID == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	c.Locals("user_id", userID)
+	return c.Next()
+}
+
+// folderError maps folder validation errors to 400 and unknown folders to 404.
+func folderError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrFolderNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidFolderName), errors.Is(err, domain.ErrFolderCycle), errors.Is(err, domain.ErrFolderTooDeep):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
+
+type folderRequest struct {
+	Name string `json:"name"`
+	// Null or omitted for a top-level folder
+	ParentID *string `json:"parent_id"`
+}
+
+// List returns the user's folders
+// @Summary List Folders
+// @Description Get all folders as a flat list, ordered by name; parent_id links them into a tree.
+// @Tags Folders
+// @Produce json
+// @Success 200 {array} domain.Folder
+// @Router /api/folders [get]
+func (h *FolderHandler) List(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	folders, err := h.usecase.ListFolders(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(folders)
+}
+
+// Create creates a folder
+// @Summary Create Folder
+// @Description Create a folder, inside parent_id or at the top level. Folders nest up to 8 levels deep.
+// @Tags Folders
+// @Accept json
+// @Produce json
+// @Param folder body folderRequest true "Folder"
+// @Success 201 {object} domain.Folder
+// @Failure 400 {object} map[string]string "Invalid name or too deep"
+// @Failure 404 {object} map[string]string "Unknown parent"
+// @Router /api/folders [post]
+func (h *FolderHandler) Create(c *fiber.Ctx) error {
+	var req folderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	folder := &domain.Folder{
+		UserID:   c.Locals("user_id").(string),
+		Name:     req.Name,
+		ParentID: req.ParentID,
+	}
+	if err := h.usecase.CreateFolder(c.Context(), folder); err != nil {
+		return folderError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(folder)
+}
+
+// Update renames or moves a folder
+// @Summary Update Folder
+// @Description Rename a folder and set its parent; a null parent_id moves it to the top level. A folder cannot be moved into its own subtree.
+// @Tags Folders
+// @Accept json
+// @Produce json
+// @Param id path string true "Folder ID"
+// @Param folder body folderRequest true "Folder"
+// @Success 200 {object} domain.Folder
+// @Failure 400 {object} map[string]string "Invalid name, cycle or too deep"
+// @Failure 404 {object} map[string]string "Unknown folder or parent"
+// @Router /api/folders/{id} [put]
+func (h *FolderHandler) Update(c *fiber.Ctx) error {
+	var req folderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	folder := &domain.Folder{
+		ID:       c.Params("id"),
+		UserID:   c.Locals("user_id").(string),
+		Name:     req.Name,
+		ParentID: req.ParentID,
+	}
+	if err := h.usecase.UpdateFolder(c.Context(), folder); err != nil {
+		return folderError(c, err)
+	}
+	return c.JSON(folder)
+}
+
+// Delete removes a folder
+// @Summary Delete Folder
+// @Description Delete a folder. Its subfolders and secrets move up to its parent folder, or to the top level.
+// @Tags Folders
+// @Param id path string true "Folder ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} map[string]string "Unknown folder"
+// @Router /api/folders/{id} [delete]
+func (h *FolderHandler) Delete(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	if err := h.usecase.DeleteFolder(c.Context(), c.Params("id"), userID); err != nil {
+		return folderError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+type moveSecretsRequest struct {
+	SecretIDs []string `json:"secret_ids"`
+	// Null to take the secrets out of their folders
+	FolderID *string `json:"folder_id"`
+}
+
+// MoveSecrets puts secrets into a folder in bulk
+// @Summary Move Secrets
+// @Description Move secrets into folder_id, or out of any folder when it is null. Unknown secret IDs are skipped; moving does not change a secret's version.
+// @Tags Folders
+// @Accept json
+// @Produce json
+// @Param move body moveSecretsRequest true "Secrets and target folder"
+// @Success 200 {object} map[string]int64 "Number of secrets moved"
+// @Failure 404 {object} map[string]string "Unknown folder"
+// @Router /api/folders/move [post]
+func (h *FolderHandler) MoveSecrets(c *fiber.Ctx) error {
+	var req moveSecretsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	userID := c.Locals("user_id").(string)
+	moved, err := h.usecase.MoveSecrets(c.Context(), userID, req.SecretIDs, req.FolderID)
+	if err != nil {
+		return folderError(c, err)
+	}
+	return c.JSON(fiber.Map{"moved": moved})
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/session"
+	"github.com/herdiagusthio/password-manager/internal/domain"
+)
+
+type GeneratorHandler struct {
+	usecase domain.GeneratorUsecase
+	store   *session.Store
+}
+
+func NewGeneratorHandler(app *fiber.App, uc domain.GeneratorUsecase, store *session.Store) {
+	h := &GeneratorHandler{
+		usecase: uc,
+		store:   store,
+	}
+
+	api := app.Group("/api", h.requireAuth)
+	api.Post("/generate", h.Generate)
+	api.Post("/strength", h.Strength)
+}
+
+func (h *GeneratorHandler) requireAuth(c *fiber.Ctx) error {
+	sess, err := h.store.Get(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	userID := sess.Get("user_id")
+	if userID == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	c.Locals("user_id", userID)
+	return c.Next()
+}
+
+// Generate creates a random password or passphrase
+// @Summary Generate Password
+// @Description In "password" mode (the default), generate a random password of 8-64 characters. Each class (uppercase, lowercase, numbers, symbols) can be turned off and every selected class appears at least once. Set rules to a site's passwordrules string (e.g. "maxlength: 20; required: upper; allowed: lower, digit, [-_]") to follow it instead of the class toggles; its length limits then apply. In "passphrase" mode, join 3-20 words from the EFF diceware list with a separator of up to 5 characters, optionally capitalized and with a digit added to one word. Omitted fields take the defaults: the "Strong" 16-character password, or six words joined by "-". The response reports the entropy in bits.
+// @Tags Generator
+// @Accept json
+// @Produce json
+// @Param options body domain.GeneratorOptions false "Generator options"
+// @Success 200 {object} domain.GeneratedPassword
+// @Failure 400 {object} map[string]string
+// @Router /api/generate [post]
+func (h *GeneratorHandler) Generate(c *fiber.Ctx) error {
+	opts := h.usecase.DefaultOptions()
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&opts); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	generated, err := h.usecase.Generate(c.Context(), opts)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidGeneratorOptions) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(generated)
+}
+
+// Strength estimates how hard a password is to guess
+// @Summary Password Strength
+// @Description Score a password from 0 (too guessable) to 4 (very unguessable), zxcvbn-style. Dictionary words, common passwords, l33t substitutions, keyboard walks, repeats, sequences and dates are detected; user_inputs (e.g. the title and username) are treated as easy to guess too. The response includes an entropy estimate, crack times for four attack scenarios and feedback for weak passwords.
+// @Tags Generator
+// @Accept json
+// @Produce json
+// @Param request body object true "password and optional user_inputs"
+// @Success 200 {object} domain.PasswordStrength
+// @Failure 400 {object} map[string]string
+// @Router /api/strength [post]
+func (h *GeneratorHandler) Strength(c *fiber.Ctx) error {
+	type Request struct {
+		Password   string   `json:"password"`
+		UserInputs []string `json:"user_inputs"`
+	}
+	var req Request
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(h.usecase.EstimateStrength(c.Context(), req.Password, req.UserInputs))
+}
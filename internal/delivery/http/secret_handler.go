@@ -1,6 +1,11 @@
 package http
 
 import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/session"
 	"github.com/herdiagusthio/password-manager/internal/domain"
@@ -11,18 +16,29 @@ type SecretHandler struct {
 	store   *session.Store
 }
 
-func NewSecretHandler(app *fiber.App, uc domain.SecretUsecase, store *session.Store) {
+func NewSecretHandler(app *fiber.App, uc domain.SecretUsecase, store *session.Store, lock *VaultLock) {
 	h := &SecretHandler{
 		usecase: uc,
 		store:   store,
 	}
 
 	api := app.Group("/api", h.requireAuth)
-	api.Post("/secrets", h.Create)
+	api.Get("/secret-types", h.Types)
+	// Writes need an unlocked vault too, so a locked session cannot replace
+	// or trash secrets.
+	api.Post("/secrets", lock.RequireUnlocked, h.Create)
 	api.Get("/secrets", h.List)
-	api.Get("/secrets/:id", h.Get)
-	api.Put("/secrets/:id", h.Update)
-	api.Delete("/secrets/:id", h.Delete)
+	api.Get("/secrets/:id", lock.RequireUnlocked, h.Get)
+	api.Put("/secrets/:id", lock.RequireUnlocked, h.Update)
+	api.Patch("/secrets/:id", lock.RequireUnlocked, h.Patch)
+	api.Delete("/secrets/:id", lock.RequireUnlocked, h.Delete)
+	api.Put("/secrets/:id/favorite", h.Favorite)
+	api.Post("/secrets/:id/rotate", lock.RequireUnlocked, h.Rotate)
+	api.Get("/secrets/:id/history", lock.RequireUnlocked, h.History)
+	api.Get("/secrets/:id/history/:version", lock.RequireUnlocked, h.GetVersion)
+	api.Post("/secrets/:id/restore/:version", lock.RequireUnlocked, h.Restore)
+	api.Get("/secrets/:id/totp", lock.RequireUnlocked, h.TOTP)
+	api.Post("/totp/decode", h.DecodeTOTP)
 }
 
 func (h *SecretHandler) requireAuth(c *fiber.Ctx) error {
@@ -38,21 +54,67 @@ func (h *SecretHandler) requireAuth(c *fiber.Ctx) error {
 	return c.Next()
 }
 
+// setETag exposes the secret's version as a strong ETag, e.g. "3", for use in
+// If-Match on updates.
+func setETag(c *fiber.Ctx, version int) {
+	c.Set(fiber.HeaderETag, strconv.Quote(strconv.Itoa(version)))
+}
+
+// expectedVersion returns the version an update is based on, from If-Match or
+// the body. 0 means neither was given.
+func expectedVersion(c *fiber.Ctx, bodyVersion int) (int, error) {
+	ifMatch := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if ifMatch == "" {
+		return bodyVersion, nil
+	}
+	tag, err := strconv.Unquote(strings.TrimPrefix(ifMatch, "W/"))
+	if err != nil {
+		return 0, errors.New("If-Match must be a single ETag such as \"3\"")
+	}
+	version, err := strconv.Atoi(tag)
+	if err != nil || version < 1 {
+		return 0, errors.New("If-Match does not name a version of this secret")
+	}
+	if bodyVersion != 0 && bodyVersion != version {
+		return 0, errors.New("If-Match and version disagree")
+	}
+	return version, nil
+}
+
+// conflict answers 409 with the stored state when err is a version conflict.
+func conflict(c *fiber.Ctx, err error) (bool, error) {
+	var conflictErr *domain.VersionConflictError
+	if !errors.As(err, &conflictErr) {
+		return false, nil
+	}
+	setETag(c, conflictErr.Current.Version)
+	return true, c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error(), "current": conflictErr.Current})
+}
+
 // Create creates a new secret
 // @Summary Create Secret
-// @Description Create a new encrypted secret
+// @Description Create a new encrypted secret of a type from /api/secret-types (login if omitted); its typed fields go in metadata and are validated against the type's schema. The password's strength score (0-4) is stored alongside logins; in zero-knowledge mode the browser may send strength_score itself.
 // @Tags Secrets
 // @Accept json
 // @Produce json
 // @Param secret body object true "Secret Data"
 // @Success 201 {object} domain.Secret
+// @Failure 400 {object} map[string]string "Invalid secret or unknown folder"
+// @Failure 423 {object} map[string]string "Vault is locked"
 // @Router /api/secrets [post]
 func (h *SecretHandler) Create(c *fiber.Ctx) error {
 	type Request struct {
+		Type     domain.SecretType      `json:"type"`
 		Title    string                 `json:"title"`
 		Username string                 `json:"username"`
 		Password string                 `json:"password"`
 		Metadata map[string]interface{} `json:"metadata"`
+		// Set in zero-knowledge mode, where password is encrypted by the browser
+		ClientEncrypted bool `json:"client_encrypted"`
+		// Only used with client_encrypted, since the server cannot score the password
+		StrengthScore *int `json:"strength_score"`
+		// Omit to create the secret outside any folder
+		FolderID *string `json:"folder_id"`
 	}
 	var req Request
 	if err := c.BodyParser(&req); err != nil {
@@ -62,42 +124,103 @@ func (h *SecretHandler) Create(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	secret := &domain.Secret{
 		UserID:   userID,
+		Type:     req.Type,
 		Title:    req.Title,
 		Username: req.Username,
 		Password: req.Password,
 		Metadata: req.Metadata,
+
+		ClientEncrypted: req.ClientEncrypted,
+		StrengthScore:   req.StrengthScore,
+		FolderID:        req.FolderID,
 	}
 
-	if err := h.usecase.CreateSecret(c.Context(), secret); err != nil {
+	err := h.usecase.CreateSecret(c.Context(), secret)
+	if errors.Is(err, domain.ErrFolderNotFound) || errors.Is(err, domain.ErrInvalidSecret) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	setETag(c, secret.Version)
 	return c.Status(fiber.StatusCreated).JSON(secret)
 }
 
-// List returns all secrets for the user
+// Types lists the secret types and their fields
+// @Summary List Secret Types
+// @Description Get the schema of every secret type: the metadata fields it takes, which are required, and which are sensitive and so always encrypted. In zero-knowledge mode the browser encrypts sensitive fields along with the password.
+// @Tags Secrets
+// @Produce json
+// @Success 200 {array} domain.SecretSchema
+// @Router /api/secret-types [get]
+func (h *SecretHandler) Types(c *fiber.Ctx) error {
+	return c.JSON(domain.SecretSchemas())
+}
+
+// List returns the user's secrets one page at a time
 // @Summary List Secrets
-// @Description Get all secrets (without passwords)
+// @Description Get a page of secrets (without passwords), optionally only those in a folder and its subfolders, with a tag, marked as favorites, of a type or matching a search. q matches every word against the title, username, URL and notes, ignoring case; fields sealed at rest are not searched. X-Total-Count holds the number of matching secrets on all pages; pass X-Next-Cursor back as cursor, with the same filters and sort, to get the next page. It is absent on the last page.
 // @Tags Secrets
 // @Produce json
+// @Param folder_id query string false "Folder ID, or \"none\" for secrets in no folder"
+// @Param tag query string false "Only secrets with this tag"
+// @Param favorite query bool false "Only favorites"
+// @Param type query string false "Only secrets of this type"
+// @Param q query string false "Search words"
+// @Param sort query string false "Order" Enums(created_at, title, updated_at, last_used) default(created_at)
+// @Param cursor query string false "X-Next-Cursor of the previous page"
+// @Param limit query int false "Page size, up to 200" default(50)
 // @Success 200 {array} domain.Secret
+// @Header 200 {integer} X-Total-Count "Number of matching secrets"
+// @Header 200 {string} X-Next-Cursor "Cursor of the next page"
+// @Failure 400 {object} map[string]string "Invalid sort or cursor"
+// @Failure 404 {object} map[string]string "Unknown folder"
 // @Router /api/secrets [get]
 func (h *SecretHandler) List(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
-	secrets, err := h.usecase.ListSecrets(c.Context(), userID)
+	filter := domain.SecretFilter{
+		FolderID: c.Query("folder_id"),
+		Tag:      c.Query("tag"),
+		Favorite: c.QueryBool("favorite"),
+		Type:     domain.SecretType(c.Query("type")),
+		Query:    c.Query("q"),
+	}
+	page := domain.PageRequest{
+		Sort:   domain.SecretSort(c.Query("sort")),
+		Cursor: c.Query("cursor"),
+		Limit:  c.QueryInt("limit"),
+	}
+	result, err := h.usecase.ListSecrets(c.Context(), userID, filter, page)
+	if errors.Is(err, domain.ErrFolderNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, domain.ErrInvalidSort) || errors.Is(err, domain.ErrInvalidCursor) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
-	return c.JSON(secrets)
+
+	c.Set("X-Total-Count", strconv.Itoa(result.Total))
+	if result.NextCursor != "" {
+		c.Set("X-Next-Cursor", result.NextCursor)
+	}
+	if result.Secrets == nil {
+		return c.JSON([]*domain.Secret{})
+	}
+	return c.JSON(result.Secrets)
 }
 
 // Get returns a single secret (decrypted)
 // @Summary Get Secret
-// @Description Get a secret by ID with decrypted password
+// @Description Get a secret by ID with decrypted password. The ETag header carries the version to send back in If-Match when updating.
 // @Tags Secrets
 // @Produce json
 // @Param id path string true "Secret ID"
 // @Success 200 {object} domain.Secret
+// @Header 200 {string} ETag "Version, e.g. \"3\""
+// @Failure 423 {object} map[string]string "Vault is locked"
 // @Router /api/secrets/{id} [get]
 func (h *SecretHandler) Get(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
@@ -110,31 +233,48 @@ func (h *SecretHandler) Get(c *fiber.Ctx) error {
 	if secret == nil {
 		return c.SendStatus(fiber.StatusNotFound)
 	}
+	setETag(c, secret.Version)
 	return c.JSON(secret)
 }
 
 // Update modifies an existing secret
 // @Summary Update Secret
-// @Description Update secret details
+// @Description Update secret details. The type cannot change and may be omitted. The version the edit is based on is required, either as "version" in the body or as an If-Match ETag from GET. If the secret changed since, nothing is saved and the stored state (plaintext fields only) is returned under "current".
 // @Tags Secrets
 // @Accept json
 // @Produce json
 // @Param id path string true "Secret ID"
+// @Param If-Match header string false "ETag of the version being updated, e.g. \"3\""
 // @Param secret body object true "Secret Data"
 // @Success 200 {object} domain.Secret
+// @Failure 400 {object} map[string]string "Invalid secret"
+// @Failure 409 {object} map[string]interface{} "Changed since it was loaded"
+// @Failure 423 {object} map[string]string "Vault is locked"
+// @Failure 428 {object} map[string]string "No version given"
 // @Router /api/secrets/{id} [put]
 func (h *SecretHandler) Update(c *fiber.Ctx) error {
 	// Simplied update...
 	type Request struct {
+		Type     domain.SecretType      `json:"type"`
 		Title    string                 `json:"title"`
 		Username string                 `json:"username"`
 		Password string                 `json:"password"`
 		Metadata map[string]interface{} `json:"metadata"`
+		// Set in zero-knowledge mode, where password is encrypted by the browser
+		ClientEncrypted bool `json:"client_encrypted"`
+		// Only used with client_encrypted, since the server cannot score the password
+		StrengthScore *int `json:"strength_score"`
+		// The version being edited; If-Match may be used instead
+		Version int `json:"version"`
 	}
 	var req Request
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
+	version, err := expectedVersion(c, req.Version)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
 
 	userID := c.Locals("user_id").(string)
 	id := c.Params("id")
@@ -142,25 +282,106 @@ func (h *SecretHandler) Update(c *fiber.Ctx) error {
 	secret := &domain.Secret{
 		ID:       id,
 		UserID:   userID,
+		Type:     req.Type,
 		Title:    req.Title,
 		Username: req.Username,
 		Password: req.Password,
 		Metadata: req.Metadata,
+
+		ClientEncrypted: req.ClientEncrypted,
+		StrengthScore:   req.StrengthScore,
+		Version:         version,
 	}
 
 	if err := h.usecase.UpdateSecret(c.Context(), secret); err != nil {
+		if errors.Is(err, domain.ErrVersionRequired) {
+			return c.Status(fiber.StatusPreconditionRequired).JSON(fiber.Map{"error": err.Error()})
+		}
+		if errors.Is(err, domain.ErrInvalidSecret) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		if handled, err := conflict(c, err); handled {
+			return err
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	setETag(c, secret.Version)
 	return c.JSON(secret)
 }
 
-// Delete removes a secret
+// mimeMergePatch is the media type of JSON Merge Patch (RFC 7396).
+const mimeMergePatch = "application/merge-patch+json"
+
+// Patch partially updates a secret
+// @Summary Patch Secret
+// @Description Change some fields of a secret with a JSON Merge Patch (RFC 7396): omitted fields are kept, null removes a field, and metadata is merged key by key, nested objects included. Patchable fields are title, username, password and metadata; client_encrypted and strength_score apply along with a new password. "version" or If-Match is optional here; when given, the patch is rejected with 409 if the secret changed since. A patch that sets no field returns the secret as it is, without a new version.
+// @Tags Secrets
+// @Accept json
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "Secret ID"
+// @Param If-Match header string false "ETag of the version being patched, e.g. \"3\""
+// @Param patch body object true "Merge patch, e.g. {\"metadata\": {\"notes\": null}}"
+// @Success 200 {object} domain.Secret
+// @Failure 400 {object} map[string]string "Invalid patch"
+// @Failure 404 "Not Found"
+// @Failure 409 {object} map[string]interface{} "Changed since it was loaded"
+// @Failure 415 {object} map[string]string "Not JSON"
+// @Failure 423 {object} map[string]string "Vault is locked"
+// @Router /api/secrets/{id} [patch]
+func (h *SecretHandler) Patch(c *fiber.Ctx) error {
+	contentType := strings.ToLower(c.Get(fiber.HeaderContentType))
+	if !strings.HasPrefix(contentType, mimeMergePatch) && !strings.HasPrefix(contentType, fiber.MIMEApplicationJSON) {
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{"error": "patch must be " + mimeMergePatch})
+	}
+	var patch map[string]interface{}
+	if err := json.Unmarshal(c.Body(), &patch); err != nil || patch == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "patch must be a JSON object"})
+	}
+
+	// "version" says what the patch is based on; it is not a field to change.
+	bodyVersion := 0
+	if v, ok := patch["version"]; ok {
+		n, isNumber := v.(float64)
+		if !isNumber || n < 1 || n != float64(int(n)) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "version must be a positive integer"})
+		}
+		bodyVersion = int(n)
+		delete(patch, "version")
+	}
+	version, err := expectedVersion(c, bodyVersion)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	userID := c.Locals("user_id").(string)
+	id := c.Params("id")
+
+	secret, err := h.usecase.PatchSecret(c.Context(), id, userID, version, patch)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidPatch) || errors.Is(err, domain.ErrInvalidSecret) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		if handled, err := conflict(c, err); handled {
+			return err
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if secret == nil {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	setETag(c, secret.Version)
+	return c.JSON(secret)
+}
+
+// Delete moves a secret to the trash
 // @Summary Delete Secret
-// @Description Remove a secret by ID
+// @Description Move a secret to the trash. It can be restored from /api/trash until it is purged.
 // @Tags Secrets
 // @Param id path string true "Secret ID"
 // @Success 204 "No Content"
+// @Failure 423 {object} map[string]string "Vault is locked"
 // @Router /api/secrets/{id} [delete]
 func (h *SecretHandler) Delete(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
@@ -171,3 +392,221 @@ func (h *SecretHandler) Delete(c *fiber.Ctx) error {
 	}
 	return c.SendStatus(fiber.StatusNoContent)
 }
+
+// Favorite marks or unmarks a secret as a favorite
+// @Summary Set Favorite
+// @Description Mark a secret as a favorite, or unmark it with false. This does not change the secret's version.
+// @Tags Secrets
+// @Accept json
+// @Produce json
+// @Param id path string true "Secret ID"
+// @Param body body object true "e.g. {\"favorite\": true}"
+// @Success 200 {object} domain.Secret
+// @Failure 404 "Not Found"
+// @Router /api/secrets/{id}/favorite [put]
+func (h *SecretHandler) Favorite(c *fiber.Ctx) error {
+	var req struct {
+		Favorite bool `json:"favorite"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	userID := c.Locals("user_id").(string)
+	secret, err := h.usecase.SetFavorite(c.Context(), c.Params("id"), userID, req.Favorite)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if secret == nil {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	return c.JSON(secret)
+}
+
+// Rotate replaces a secret's password with a generated one
+// @Summary Rotate Password
+// @Description Generate a new password with the secret's saved generator options (metadata password_rules, else metadata generator, else the Strong preset). The previous state is kept in history and the version is bumped. The response is the only time the new password is returned unprompted. Only logins can be rotated, and not in zero-knowledge mode.
+// @Tags Secrets
+// @Produce json
+// @Param id path string true "Secret ID"
+// @Success 200 {object} domain.Secret
+// @Failure 400 {object} map[string]string "Invalid saved options, not a login or zero-knowledge mode"
+// @Failure 404 "Not Found"
+// @Failure 409 {object} map[string]interface{} "Changed during rotation"
+// @Failure 423 {object} map[string]string "Vault is locked"
+// @Router /api/secrets/{id}/rotate [post]
+func (h *SecretHandler) Rotate(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	id := c.Params("id")
+
+	secret, err := h.usecase.RotatePassword(c.Context(), id, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrRotationUnsupported) || errors.Is(err, domain.ErrInvalidGeneratorOptions) || errors.Is(err, domain.ErrInvalidSecret) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		if handled, err := conflict(c, err); handled {
+			return err
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if secret == nil {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	setETag(c, secret.Version)
+	return c.JSON(secret)
+}
+
+// TOTP returns the current one-time code of a secret
+// @Summary Get TOTP Code
+// @Description Get the current RFC 6238 code of the TOTP seed stored in a login's totp field, and the seconds it stays valid. Reading a code marks the secret as used. In zero-knowledge mode the seed is encrypted by the browser, which computes codes itself.
+// @Tags Secrets
+// @Produce json
+// @Param id path string true "Secret ID"
+// @Success 200 {object} domain.TOTPCode
+// @Failure 404 {object} map[string]string "Not found, or no TOTP seed"
+// @Failure 423 {object} map[string]string "Vault is locked"
+// @Router /api/secrets/{id}/totp [get]
+func (h *SecretHandler) TOTP(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	id := c.Params("id")
+
+	code, err := h.usecase.GetTOTP(c.Context(), id, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNoTOTP) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if code == nil {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	return c.JSON(code)
+}
+
+// maxQRImageSize caps uploads to DecodeTOTP; QR code screenshots are small.
+const maxQRImageSize = 2 << 20
+
+// DecodeTOTP reads the otpauth:// URI from a QR code image
+// @Summary Decode TOTP QR Code
+// @Description Read the otpauth://totp/ URI from a PNG, JPEG or GIF image of a QR code, such as the one a site shows when enabling two-factor login. The image is decoded on the server and not stored; save the returned uri in a login's totp field.
+// @Tags Secrets
+// @Accept multipart/form-data
+// @Produce json
+// @Param image formData file true "QR code image (max 2 MiB)"
+// @Success 200 {object} domain.TOTPKeyInfo
+// @Failure 400 {object} map[string]string "No TOTP QR code in the image"
+// @Failure 413 {object} map[string]string "Image too large"
+// @Router /api/totp/decode [post]
+func (h *SecretHandler) DecodeTOTP(c *fiber.Ctx) error {
+	fileHeader, err := c.FormFile("image")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "missing image file"})
+	}
+	if fileHeader.Size > maxQRImageSize {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": "image is too large"})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	defer file.Close()
+
+	info, err := h.usecase.DecodeTOTPImage(file)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidTOTP) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(info)
+}
+
+// History lists the previous versions of a secret
+// @Summary Secret History
+// @Description List the previous versions of a secret, newest first. Each entry names the fields changed by the version that replaced it ("title", "username", "password" or "metadata.<key>"); passwords are not included. Only the newest history_limit versions (see settings) are kept.
+// @Tags Secrets
+// @Produce json
+// @Param id path string true "Secret ID"
+// @Success 200 {array} domain.SecretVersion
+// @Failure 404 "Not Found"
+// @Failure 423 {object} map[string]string "Vault is locked"
+// @Router /api/secrets/{id}/history [get]
+func (h *SecretHandler) History(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	id := c.Params("id")
+
+	history, err := h.usecase.ListHistory(c.Context(), id, userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if history == nil {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	return c.JSON(history)
+}
+
+// GetVersion returns one previous version of a secret (decrypted)
+// @Summary Get Secret Version
+// @Description Get a previous version of a secret with its decrypted password
+// @Tags Secrets
+// @Produce json
+// @Param id path string true "Secret ID"
+// @Param version path int true "Version"
+// @Success 200 {object} domain.SecretVersion
+// @Failure 400 {object} map[string]string "Invalid version"
+// @Failure 404 "Not Found"
+// @Failure 423 {object} map[string]string "Vault is locked"
+// @Router /api/secrets/{id}/history/{version} [get]
+func (h *SecretHandler) GetVersion(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	id := c.Params("id")
+	version, err := c.ParamsInt("version")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "version must be a number"})
+	}
+
+	v, err := h.usecase.GetVersion(c.Context(), id, userID, version)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if v == nil {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	return c.JSON(v)
+}
+
+// Restore makes a previous version current again
+// @Summary Restore Secret Version
+// @Description Restore a previous version of a secret. The restored state is saved as a new version, and the state it replaces is kept in history.
+// @Tags Secrets
+// @Produce json
+// @Param id path string true "Secret ID"
+// @Param version path int true "Version"
+// @Success 200 {object} domain.Secret
+// @Failure 400 {object} map[string]string "Invalid version"
+// @Failure 404 "Not Found"
+// @Failure 409 {object} map[string]interface{} "Changed during restore"
+// @Failure 423 {object} map[string]string "Vault is locked"
+// @Router /api/secrets/{id}/restore/{version} [post]
+func (h *SecretHandler) Restore(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	id := c.Params("id")
+	version, err := c.ParamsInt("version")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "version must be a number"})
+	}
+
+	secret, err := h.usecase.RestoreVersion(c.Context(), id, userID, version)
+	if err != nil {
+		if handled, err := conflict(c, err); handled {
+			return err
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if secret == nil {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	setETag(c, secret.Version)
+	return c.JSON(secret)
+}
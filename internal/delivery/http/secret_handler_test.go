@@ -0,0 +1,175 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/session"
+	authHttp "github.com/herdiagusthio/password-manager/internal/delivery/http"
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// newTestApp serves the routes added by register for user-1, who has a PIN
+// and so a vault that can be locked. login logs in, unlocking the vault if
+// asked, and returns the session cookie to send along.
+func newTestApp(t *testing.T, ctrl *gomock.Controller, register func(app *fiber.App, store *session.Store, lock *authHttp.VaultLock)) (app *fiber.App, login func(unlock bool) string) {
+	policy := &domain.LockPolicy{Methods: []string{"pin"}}
+	vaultUC := mocks.NewMockVaultUsecase(ctrl)
+	vaultUC.EXPECT().GetLockPolicy(gomock.Any(), "user-1").Return(policy, nil).AnyTimes()
+
+	store := session.New()
+	lock := authHttp.NewVaultLock(store, vaultUC)
+	app = fiber.New()
+	app.Get("/test/login", func(c *fiber.Ctx) error {
+		sess, err := store.Get(c)
+		if err != nil {
+			return err
+		}
+		sess.Set("user_id", "user-1")
+		return sess.Save()
+	})
+	app.Get("/test/unlock", func(c *fiber.Ctx) error {
+		return lock.Unlock(c, policy)
+	})
+	register(app, store, lock)
+
+	login = func(unlock bool) string {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/test/login", nil))
+		require.NoError(t, err)
+		cookie := resp.Header.Get(fiber.HeaderSetCookie)
+		cookie = cookie[:strings.Index(cookie, ";")]
+		if unlock {
+			req := httptest.NewRequest(fiber.MethodGet, "/test/unlock", nil)
+			req.Header.Set(fiber.HeaderCookie, cookie)
+			_, err := app.Test(req)
+			require.NoError(t, err)
+		}
+		return cookie
+	}
+	return app, login
+}
+
+// newSecretApp serves the secret routes backed by uc.
+func newSecretApp(t *testing.T, ctrl *gomock.Controller, uc *mocks.MockSecretUsecase) (*fiber.App, func(unlock bool) string) {
+	return newTestApp(t, ctrl, func(app *fiber.App, store *session.Store, lock *authHttp.VaultLock) {
+		authHttp.NewSecretHandler(app, uc, store, lock)
+	})
+}
+
+// lockedRoute is a request that must answer 423 while the vault is locked,
+// and status once it is unlocked.
+type lockedRoute struct {
+	name        string
+	method      string
+	path        string
+	contentType string
+	body        string
+	status      int
+}
+
+// testLockedRoutes sends each route once locked, where the usecase must not
+// be called, and once unlocked, after setting up the usecase calls expected
+// for that route.
+func testLockedRoutes[U any](t *testing.T, routes []lockedRoute, newApp func(*testing.T, *gomock.Controller, U) (*fiber.App, func(unlock bool) string), newUsecase func(*gomock.Controller) U, expect map[string]func(U)) {
+	for _, route := range routes {
+		for _, unlock := range []bool{false, true} {
+			name := route.name + "/Locked"
+			if unlock {
+				name = route.name + "/Unlocked"
+			}
+			t.Run(name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				defer ctrl.Finish()
+
+				uc := newUsecase(ctrl)
+				expectStatus := fiber.StatusLocked
+				if unlock {
+					expect[route.name](uc)
+					expectStatus = route.status
+				}
+				app, login := newApp(t, ctrl, uc)
+
+				req := httptest.NewRequest(route.method, route.path, strings.NewReader(route.body))
+				if route.contentType != "" {
+					req.Header.Set(fiber.HeaderContentType, route.contentType)
+				}
+				req.Header.Set(fiber.HeaderCookie, login(unlock))
+				resp, err := app.Test(req)
+				require.NoError(t, err)
+				assert.Equal(t, expectStatus, resp.StatusCode)
+			})
+		}
+	}
+}
+
+func TestSecretHandler_WritesRequireUnlockedVault(t *testing.T) {
+	routes := []lockedRoute{
+		{name: "Create", method: fiber.MethodPost, path: "/api/secrets", contentType: fiber.MIMEApplicationJSON, body: `{"title":"Mail","password":"pw"}`, status: fiber.StatusCreated},
+		{name: "Update", method: fiber.MethodPut, path: "/api/secrets/sec-1", contentType: fiber.MIMEApplicationJSON, body: `{"title":"Mail","version":1}`, status: fiber.StatusOK},
+		{name: "Delete", method: fiber.MethodDelete, path: "/api/secrets/sec-1", status: fiber.StatusNoContent},
+	}
+	testLockedRoutes(t, routes, newSecretApp, mocks.NewMockSecretUsecase, map[string]func(*mocks.MockSecretUsecase){
+		"Create": func(uc *mocks.MockSecretUsecase) {
+			uc.EXPECT().CreateSecret(gomock.Any(), gomock.Any()).Return(nil)
+		},
+		"Update": func(uc *mocks.MockSecretUsecase) {
+			uc.EXPECT().UpdateSecret(gomock.Any(), gomock.Any()).Return(nil)
+		},
+		"Delete": func(uc *mocks.MockSecretUsecase) {
+			uc.EXPECT().DeleteSecret(gomock.Any(), "sec-1", "user-1").Return(nil)
+		},
+	})
+}
+
+func TestSecretHandler_Patch_RequiresUnlockedVault(t *testing.T) {
+	tests := []struct {
+		name         string
+		unlock       bool
+		expectStatus int
+	}{
+		{name: "Locked", expectStatus: fiber.StatusLocked},
+		{name: "Unlocked", unlock: true, expectStatus: fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			uc := mocks.NewMockSecretUsecase(ctrl)
+			if tt.unlock {
+				uc.EXPECT().PatchSecret(gomock.Any(), "sec-1", "user-1", 0, map[string]interface{}{}).Return(&domain.Secret{
+					ID: "sec-1", UserID: "user-1", Title: "Mail", Username: "alice",
+					Metadata: map[string]interface{}{"notes": "recovery codes"},
+					Version:  1,
+				}, nil)
+			}
+			app, login := newSecretApp(t, ctrl, uc)
+
+			// An empty patch returns the secret as it is, sealed fields readable
+			req := httptest.NewRequest(fiber.MethodPatch, "/api/secrets/sec-1", strings.NewReader("{}"))
+			req.Header.Set(fiber.HeaderContentType, "application/merge-patch+json")
+			req.Header.Set(fiber.HeaderCookie, login(tt.unlock))
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectStatus, resp.StatusCode)
+			if !tt.unlock {
+				return
+			}
+
+			var secret domain.Secret
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&secret))
+			assert.Equal(t, "sec-1", secret.ID)
+			assert.Equal(t, "alice", secret.Username)
+			assert.Equal(t, "recovery codes", secret.Metadata["notes"])
+			assert.Equal(t, 1, secret.Version)
+		})
+	}
+}
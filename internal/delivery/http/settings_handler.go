@@ -0,0 +1,96 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/session"
+	"github.com/herdiagusthio/password-manager/internal/domain"
+)
+
+type SettingsHandler struct {
+	usecase domain.SettingsUsecase
+	store   *session.Store
+}
+
+func NewSettingsHandler(app *fiber.App, uc domain.SettingsUsecase, store *session.Store, lock *VaultLock) {
+	h := &SettingsHandler{
+		usecase: uc,
+		store:   store,
+	}
+
+	api := app.Group("/api", h.requireAuth)
+	api.Get("/settings", h.Get)
+	// Settings can expose sealed fields, turn off auto-lock or prune history.
+	api.Put("/settings", lock.RequireUnlocked, h.Update)
+}
+
+func (h *SettingsHandler) requireAuth(c *fiber.Ctx) error {
+	sess, err := h.store.Get(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	userID := sess.Get("user_id")
+	if userID == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	c.Locals("user_id", userID)
+	return c.Next()
+}
+
+// Get returns the user's settings
+// @Summary Get Settings
+// @Description Get the user's settings, e.g. which secret fields are stored unencrypted
+// @Tags Settings
+// @Produce json
+// @Success 200 {object} domain.UserSettings
+// @Router /api/settings [get]
+func (h *SettingsHandler) Get(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	settings, err := h.usecase.GetSettings(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(settings)
+}
+
+// Update changes the user's settings
+// @Summary Update Settings
+// @Description Choose which fields stay in plaintext ("username" or metadata keys); everything else except the title is encrypted and existing secrets are re-encrypted to match. Set auto_lock_minutes to lock the vault after inactivity (0 = until logout) and history_limit to the number of previous versions kept per secret (1-100). Omitted fields keep their current value.
+// @Tags Settings
+// @Accept json
+// @Produce json
+// @Param settings body domain.UserSettings true "Settings"
+// @Success 200 {object} domain.UserSettings
+// @Failure 423 {object} map[string]string "Vault is locked"
+// @Router /api/settings [put]
+func (h *SettingsHandler) Update(c *fiber.Ctx) error {
+	type Request struct {
+		PlaintextFields *[]string `json:"plaintext_fields"`
+		AutoLockMinutes *int      `json:"auto_lock_minutes"`
+		HistoryLimit    *int      `json:"history_limit"`
+	}
+	var req Request
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	userID := c.Locals("user_id").(string)
+	settings, err := h.usecase.GetSettings(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if req.PlaintextFields != nil {
+		settings.PlaintextFields = *req.PlaintextFields
+	}
+	if req.AutoLockMinutes != nil {
+		settings.AutoLockMinutes = *req.AutoLockMinutes
+	}
+	if req.HistoryLimit != nil {
+		settings.HistoryLimit = *req.HistoryLimit
+	}
+
+	if err := h.usecase.UpdateSettings(c.Context(), settings); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(settings)
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/herdiagusthio/password-manager/internal/domain"
+)
+
+type SysHandler struct {
+	rotationUC domain.RotationUsecase
+	sealUC     domain.SealUsecase
+}
+
+func NewSysHandler(app *fiber.App, rotationUC domain.RotationUsecase, sealUC domain.SealUsecase) {
+	h := &SysHandler{
+		rotationUC: rotationUC,
+		sealUC:     sealUC,
+	}
+
+	sys := app.Group("/sys")
+	sys.Get("/rotation", h.Rotation)
+	sys.Get("/seal-status", h.SealStatus)
+	sys.Post("/unseal", h.Unseal)
+}
+
+// RequireUnsealed rejects requests with 503 until the master keyring is unsealed.
+func RequireUnsealed(sealUC domain.SealUsecase) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if status := sealUC.Status(); status.Sealed {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "server is sealed", "seal": status})
+		}
+		return c.Next()
+	}
+}
+
+// Rotation reports the progress of the key rotation job
+// @Summary Key Rotation Status
+// @Description Whether the background job that re-encrypts data under the current master key is running, has finished, and has failed for any item. This endpoint is unauthenticated, so detailed progress is only written to the server log.
+// @Tags System
+// @Produce json
+// @Success 200 {object} domain.RotationStatus
+// @Router /sys/rotation [get]
+func (h *SysHandler) Rotation(c *fiber.Ctx) error {
+	return c.JSON(h.rotationUC.Progress().Status())
+}
+
+// SealStatus reports whether the server is sealed
+// @Summary Seal Status
+// @Description Whether the master keys are loaded, and how many unseal shares have been collected
+// @Tags System
+// @Produce json
+// @Success 200 {object} domain.SealStatus
+// @Router /sys/seal-status [get]
+func (h *SysHandler) SealStatus(c *fiber.Ctx) error {
+	return c.JSON(h.sealUC.Status())
+}
+
+// Unseal submits one unseal share
+// @Summary Unseal
+// @Description Provide one unseal share. Once the threshold is reached the master keys are decrypted and the API becomes available. A wrong combination resets progress.
+// @Tags System
+// @Accept json
+// @Produce json
+// @Param request body object true "share"
+// @Success 200 {object} domain.SealStatus
+// @Failure 400 {object} map[string]string
+// @Router /sys/unseal [post]
+func (h *SysHandler) Unseal(c *fiber.Ctx) error {
+	type Request struct {
+		Share string `json:"share"`
+	}
+	var req Request
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	status, err := h.sealUC.Unseal(req.Share)
+	if err != nil {
+		code := fiber.StatusInternalServerError
+		if errors.Is(err, domain.ErrMalformedUnsealShare) || errors.Is(err, domain.ErrInvalidUnsealShares) {
+			code = fiber.StatusBadRequest
+		}
+		return c.Status(code).JSON(fiber.Map{"error": err.Error(), "seal": status})
+	}
+	return c.JSON(status)
+}
@@ -0,0 +1,173 @@
+package http
+
+import (
+	"errors"
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/session"
+	"github.com/herdiagusthio/password-manager/internal/domain"
+)
+
+type TagHandler struct {
+	usecase domain.TagUsecase
+	store   *session.Store
+}
+
+func NewTagHandler(app *fiber.App, uc domain.TagUsecase, store *session.Store) {
+	h := &TagHandler{
+		usecase: uc,
+		store:   store,
+	}
+
+	api := app.Group("/api", h.requireAuth)
+	api.Get("/tags", h.List)
+	api.Put("/tags/:tag", h.Rename)
+	api.Delete("/tags/:tag", h.Delete)
+	api.Post("/secrets/:id/tags", h.AddToSecret)
+	api.Delete("/secrets/:id/tags/:tag", h.RemoveFromSecret)
+}
+
+func (h *TagHandler) requireAuth(c *fiber.Ctx) error {
+	sess, err := h.store.Get(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	userID := sess.Get("user_id")
+	if userID == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	c.Locals("user_id", userID)
+	return c.Next()
+}
+
+// tagParam returns the URL-decoded :tag parameter.
+func tagParam(c *fiber.Ctx) string {
+	tag, err := url.PathUnescape(c.Params("tag"))
+	if err != nil {
+		return c.Params("tag")
+	}
+	return tag
+}
+
+// tagError maps invalid names to 400 and unknown tags to 404.
+func tagError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalidTag):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, domain.ErrTagNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
+
+// List returns the user's tags
+// @Summary List Tags
+// @Description Get every tag in the vault with the number of secrets carrying it (the trash not included).
+// @Tags Tags
+// @Produce json
+// @Success 200 {array} domain.Tag
+// @Router /api/tags [get]
+func (h *TagHandler) List(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	tags, err := h.usecase.ListTags(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(tags)
+}
+
+// Rename renames or merges a tag
+// @Summary Rename Tag
+// @Description Rename a tag on every secret. If a tag with the new name exists, the two are merged.
+// @Tags Tags
+// @Accept json
+// @Param tag path string true "Tag name"
+// @Param body body object true "New name, e.g. {\"name\": \"prod-db\"}"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "Invalid name"
+// @Failure 404 {object} map[string]string "Unknown tag"
+// @Router /api/tags/{tag} [put]
+func (h *TagHandler) Rename(c *fiber.Ctx) error {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	userID := c.Locals("user_id").(string)
+	if err := h.usecase.RenameTag(c.Context(), userID, tagParam(c), req.Name); err != nil {
+		return tagError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Delete removes a tag from every secret
+// @Summary Delete Tag
+// @Description Remove a tag from every secret; the secrets themselves are kept.
+// @Tags Tags
+// @Param tag path string true "Tag name"
+// @Success 204 "No Content"
+// @Failure 404 {object} map[string]string "Unknown tag"
+// @Router /api/tags/{tag} [delete]
+func (h *TagHandler) Delete(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	if err := h.usecase.DeleteTag(c.Context(), userID, tagParam(c)); err != nil {
+		return tagError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// AddToSecret tags a secret
+// @Summary Add Tags
+// @Description Add tags to a secret, creating new ones as needed. Tag names are lowercased. Tagging does not change the secret's version.
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Param id path string true "Secret ID"
+// @Param body body object true "Tags, e.g. {\"tags\": [\"prod-db\", \"on-call\"]}"
+// @Success 200 {object} map[string][]string "All tags of the secret"
+// @Failure 400 {object} map[string]string "Invalid tag"
+// @Failure 404 "Not Found"
+// @Router /api/secrets/{id}/tags [post]
+func (h *TagHandler) AddToSecret(c *fiber.Ctx) error {
+	var req struct {
+		Tags []string `json:"tags"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	userID := c.Locals("user_id").(string)
+	tags, err := h.usecase.AddTags(c.Context(), userID, c.Params("id"), req.Tags)
+	if err != nil {
+		return tagError(c, err)
+	}
+	if tags == nil {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	return c.JSON(fiber.Map{"tags": tags})
+}
+
+// RemoveFromSecret untags a secret
+// @Summary Remove Tag
+// @Description Remove a tag from one secret. Tags no secret carries any more are deleted.
+// @Tags Tags
+// @Produce json
+// @Param id path string true "Secret ID"
+// @Param tag path string true "Tag name"
+// @Success 200 {object} map[string][]string "Remaining tags of the secret"
+// @Failure 404 "Not Found"
+// @Router /api/secrets/{id}/tags/{tag} [delete]
+func (h *TagHandler) RemoveFromSecret(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	tags, err := h.usecase.RemoveTag(c.Context(), userID, c.Params("id"), tagParam(c))
+	if err != nil {
+		return tagError(c, err)
+	}
+	if tags == nil {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	return c.JSON(fiber.Map{"tags": tags})
+}
@@ -0,0 +1,126 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/session"
+	"github.com/herdiagusthio/password-manager/internal/domain"
+)
+
+type TrashHandler struct {
+	usecase domain.TrashUsecase
+	store   *session.Store
+}
+
+func NewTrashHandler(app *fiber.App, uc domain.TrashUsecase, store *session.Store) {
+	h := &TrashHandler{
+		usecase: uc,
+		store:   store,
+	}
+
+	api := app.Group("/api", h.requireAuth)
+	api.Get("/trash", h.List)
+	api.Post("/trash/:id/restore", h.Restore)
+	api.Delete("/trash/:id", h.Purge)
+	api.Delete("/trash", h.Empty)
+}
+
+func (h *TrashHandler) requireAuth(c *fiber.Ctx) error {
+	sess, err := h.store.Get(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	userID := sess.Get("user_id")
+	if userID == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	c.Locals("user_id", userID)
+	return c.Next()
+}
+
+// List returns the secrets in the trash
+// @Summary List Trash
+// @Description List deleted secrets, most recently deleted first, without passwords. They are purged automatically once the retention period (X-Trash-Retention header, e.g. "720h0m0s"; absent if kept until emptied) has passed since deleted_at.
+// @Tags Trash
+// @Produce json
+// @Success 200 {array} domain.Secret
+// @Header 200 {string} X-Trash-Retention "How long secrets stay in the trash"
+// @Router /api/trash [get]
+func (h *TrashHandler) List(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	secrets, err := h.usecase.ListTrash(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if retention := h.usecase.Retention(); retention > 0 {
+		c.Set("X-Trash-Retention", retention.String())
+	}
+	return c.JSON(secrets)
+}
+
+// Restore takes a secret out of the trash
+// @Summary Restore From Trash
+// @Description Move a deleted secret back into the vault, unchanged.
+// @Tags Trash
+// @Produce json
+// @Param id path string true "Secret ID"
+// @Success 200 {object} domain.Secret
+// @Failure 404 {object} map[string]string "Not in the trash"
+// @Router /api/trash/{id}/restore [post]
+func (h *TrashHandler) Restore(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	id := c.Params("id")
+
+	secret, err := h.usecase.RestoreSecret(c.Context(), id, userID)
+	if errors.Is(err, domain.ErrNotInTrash) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if secret == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": domain.ErrNotInTrash.Error()})
+	}
+	setETag(c, secret.Version)
+	return c.JSON(secret)
+}
+
+// Purge deletes a secret in the trash for good
+// @Summary Purge Secret
+// @Description Permanently delete a secret in the trash, with its version history. This cannot be undone.
+// @Tags Trash
+// @Param id path string true "Secret ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} map[string]string "Not in the trash"
+// @Router /api/trash/{id} [delete]
+func (h *TrashHandler) Purge(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	id := c.Params("id")
+
+	err := h.usecase.PurgeSecret(c.Context(), id, userID)
+	if errors.Is(err, domain.ErrNotInTrash) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Empty purges every secret in the trash
+// @Summary Empty Trash
+// @Description Permanently delete every secret in the trash. This cannot be undone.
+// @Tags Trash
+// @Produce json
+// @Success 200 {object} map[string]int64 "Number of secrets purged"
+// @Router /api/trash [delete]
+func (h *TrashHandler) Empty(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	purged, err := h.usecase.EmptyTrash(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"purged": purged})
+}
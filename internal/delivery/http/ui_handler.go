@@ -1,6 +1,9 @@
 package http
 
 import (
+	"errors"
+	"net/url"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/session"
 	"github.com/herdiagusthio/password-manager/internal/domain"
@@ -54,14 +57,49 @@ func (h *UIHandler) Dashboard(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
 	email := c.Locals("email").(string)
 
-	secrets, err := h.secretUC.ListSecrets(c.Context(), userID)
+	filter := domain.SecretFilter{
+		FolderID: c.Query("folder"),
+		Tag:      c.Query("tag"),
+		Favorite: c.QueryBool("favorite"),
+		Type:     domain.SecretType(c.Query("type")),
+		Query:    c.Query("q"),
+	}
+	page := domain.PageRequest{
+		Sort:   domain.SecretSort(c.Query("sort")),
+		Cursor: c.Query("cursor"),
+	}
+	result, err := h.secretUC.ListSecrets(c.Context(), userID, filter, page)
+	if errors.Is(err, domain.ErrFolderNotFound) || errors.Is(err, domain.ErrInvalidSort) || errors.Is(err, domain.ErrInvalidCursor) {
+		return c.Redirect("/dashboard")
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).SendString("Error fetching secrets")
 	}
 
+	// The next page keeps every query parameter but the cursor
+	var nextURL string
+	if result.NextCursor != "" {
+		query := url.Values{}
+		c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+			query.Add(string(key), string(value))
+		})
+		query.Set("cursor", result.NextCursor)
+		nextURL = "/dashboard?" + query.Encode()
+	}
+
 	return c.Render("dashboard/index", fiber.Map{
 		"Authenticated": true,
 		"UserEmail":     email,
-		"Secrets":       secrets,
+		"Secrets":       result.Secrets,
+		"Total":         result.Total,
+		"NextURL":       nextURL,
+		"Paged":         page.Cursor != "",
+		"FolderID":      filter.FolderID,
+		"Tag":           filter.Tag,
+		"Favorite":      filter.Favorite,
+		"Query":         filter.Query,
+		"Type":          filter.Type,
+		"SecretTypes":   domain.SecretSchemas(),
+		"Sort":          c.Query("sort"),
 	}, "layouts/main")
 }
@@ -0,0 +1,279 @@
+package http
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/session"
+	"github.com/herdiagusthio/password-manager/internal/domain"
+)
+
+type VaultHandler struct {
+	usecase domain.VaultUsecase
+	store   *session.Store
+	lock    *VaultLock
+}
+
+func NewVaultHandler(app *fiber.App, uc domain.VaultUsecase, store *session.Store, lock *VaultLock) {
+	h := &VaultHandler{
+		usecase: uc,
+		store:   store,
+		lock:    lock,
+	}
+
+	api := app.Group("/api", h.requireAuth)
+	api.Get("/vault", h.Get)
+	api.Post("/vault/zero-knowledge", lock.RequireUnlocked, h.EnableZeroKnowledge)
+	api.Put("/vault/master-password", h.ChangeMasterPassword)
+	api.Post("/vault/verify", h.Verify)
+	api.Get("/vault/status", h.Status)
+	api.Post("/vault/unlock", h.Unlock)
+	api.Post("/vault/lock", h.Lock)
+	api.Put("/vault/pin", lock.RequireUnlocked, h.SetPIN)
+	api.Delete("/vault/pin", lock.RequireUnlocked, h.RemovePIN)
+}
+
+func (h *VaultHandler) requireAuth(c *fiber.Ctx) error {
+	sess, err := h.store.Get(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	userID := sess.Get("user_id")
+	if userID == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
+	}
+	c.Locals("user_id", userID)
+	return c.Next()
+}
+
+// Get returns the zero-knowledge setup
+// @Summary Get Vault
+// @Description Get the KDF parameters and wrapped vault key the browser needs to derive keys from the master password
+// @Tags Vault
+// @Produce json
+// @Success 200 {object} domain.Vault
+// @Router /api/vault [get]
+func (h *VaultHandler) Get(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	vault, err := h.usecase.GetVault(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(vault)
+}
+
+// EnableZeroKnowledge turns on zero-knowledge mode
+// @Summary Enable Zero-Knowledge Mode
+// @Description Store the Argon2id parameters, the auth key verifier and the browser-wrapped vault key. From then on passwords must be encrypted by the browser.
+// @Tags Vault
+// @Accept json
+// @Produce json
+// @Param setup body domain.VaultSetup true "Vault Setup"
+// @Success 200 {object} domain.Vault
+// @Router /api/vault/zero-knowledge [post]
+func (h *VaultHandler) EnableZeroKnowledge(c *fiber.Ctx) error {
+	var req domain.VaultSetup
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	userID := c.Locals("user_id").(string)
+	vault, err := h.usecase.EnableZeroKnowledge(c.Context(), userID, &req)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	// The master password was just entered, so keep the vault unlocked.
+	if err := h.unlock(c, userID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(vault)
+}
+
+// ChangeMasterPassword re-wraps the vault key under a new master password
+// @Summary Change Master Password
+// @Description Replace the KDF parameters, verifier and wrapped vault key. Secrets are not re-encrypted.
+// @Tags Vault
+// @Accept json
+// @Produce json
+// @Param setup body object true "current_auth_key plus the new Vault Setup"
+// @Success 200 {object} domain.Vault
+// @Failure 401 {object} map[string]string
+// @Router /api/vault/master-password [put]
+func (h *VaultHandler) ChangeMasterPassword(c *fiber.Ctx) error {
+	type Request struct {
+		CurrentAuthKey string `json:"current_auth_key"`
+		domain.VaultSetup
+	}
+	var req Request
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	userID := c.Locals("user_id").(string)
+	vault, err := h.usecase.ChangeMasterPassword(c.Context(), userID, req.CurrentAuthKey, &req.VaultSetup)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidMasterPassword) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(vault)
+}
+
+// Verify checks the master password
+// @Summary Verify Master Password
+// @Description Check the auth key derived from the master password against the stored verifier
+// @Tags Vault
+// @Accept json
+// @Param auth body object true "auth_key"
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string
+// @Router /api/vault/verify [post]
+func (h *VaultHandler) Verify(c *fiber.Ctx) error {
+	type Request struct {
+		AuthKey string `json:"auth_key"`
+	}
+	var req Request
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	userID := c.Locals("user_id").(string)
+	if err := h.usecase.VerifyMasterPassword(c.Context(), userID, req.AuthKey); err != nil {
+		if errors.Is(err, domain.ErrInvalidMasterPassword) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *VaultHandler) unlock(c *fiber.Ctx, userID string) error {
+	policy, err := h.usecase.GetLockPolicy(c.Context(), userID)
+	if err != nil {
+		return err
+	}
+	return h.lock.Unlock(c, policy)
+}
+
+// Status reports whether the vault is locked
+// @Summary Vault Lock Status
+// @Description Whether the vault is locked in this session, how it can be unlocked and when it locks again
+// @Tags Vault
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/vault/status [get]
+func (h *VaultHandler) Status(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	policy, err := h.usecase.GetLockPolicy(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	locked, lockAt, err := h.lock.Status(c, policy)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{
+		"locked":            locked,
+		"lockable":          policy.Lockable(),
+		"methods":           policy.Methods,
+		"auto_lock_minutes": int(policy.AutoLock / time.Minute),
+		"lock_at":           lockAt,
+	})
+}
+
+// Unlock unlocks the vault for this session
+// @Summary Unlock Vault
+// @Description Unlock with the PIN or, in zero-knowledge mode, the auth key derived from the master password. After 5 failed attempts the session is ended.
+// @Tags Vault
+// @Accept json
+// @Param credentials body domain.UnlockRequest true "PIN or auth_key"
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string
+// @Router /api/vault/unlock [post]
+func (h *VaultHandler) Unlock(c *fiber.Ctx) error {
+	var req domain.UnlockRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	userID := c.Locals("user_id").(string)
+	if err := h.usecase.Unlock(c.Context(), userID, &req); err != nil {
+		if errors.Is(err, domain.ErrInvalidPIN) || errors.Is(err, domain.ErrInvalidMasterPassword) {
+			loggedOut, failErr := h.lock.Fail(c)
+			if failErr != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": failErr.Error()})
+			}
+			if loggedOut {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "too many failed attempts, please log in again"})
+			}
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.unlock(c, userID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Lock locks the vault for this session
+// @Summary Lock Vault
+// @Description Lock the vault; the login session stays valid
+// @Tags Vault
+// @Success 204 "No Content"
+// @Router /api/vault/lock [post]
+func (h *VaultHandler) Lock(c *fiber.Ctx) error {
+	if err := h.lock.Lock(c); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// SetPIN sets or changes the unlock PIN
+// @Summary Set Unlock PIN
+// @Description Set or change the PIN used to unlock the vault (6-64 characters). Requires an unlocked vault.
+// @Tags Vault
+// @Accept json
+// @Param pin body object true "pin"
+// @Success 204 "No Content"
+// @Failure 423 {object} map[string]string
+// @Router /api/vault/pin [put]
+func (h *VaultHandler) SetPIN(c *fiber.Ctx) error {
+	type Request struct {
+		PIN string `json:"pin"`
+	}
+	var req Request
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	userID := c.Locals("user_id").(string)
+	if err := h.usecase.SetPIN(c.Context(), userID, req.PIN); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	// A first PIN makes the vault lockable; keep this session unlocked.
+	if err := h.unlock(c, userID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// RemovePIN removes the unlock PIN
+// @Summary Remove Unlock PIN
+// @Description Remove the unlock PIN. Without a PIN or master password the vault no longer locks. Requires an unlocked vault.
+// @Tags Vault
+// @Success 204 "No Content"
+// @Failure 423 {object} map[string]string
+// @Router /api/vault/pin [delete]
+func (h *VaultHandler) RemovePIN(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	if err := h.usecase.RemovePIN(c.Context(), userID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
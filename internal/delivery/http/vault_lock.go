@@ -0,0 +1,138 @@
+package http
+
+import (
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/session"
+	"github.com/herdiagusthio/password-manager/internal/domain"
+)
+
+// Session keys for the vault lock. Logging in with Google only sets user_id;
+// the vault stays locked until it is unlocked with a PIN or master password.
+const (
+	sessionVaultUnlocked    = "vault_unlocked"
+	sessionVaultLockAt      = "vault_lock_at" // Unix seconds; 0 means no auto-lock
+	sessionUnlockFailures   = "vault_unlock_failures"
+	maxUnlockFailures       = 5
+	vaultLockedErrorMessage = "vault is locked"
+)
+
+// VaultLock keeps track of whether the vault is unlocked in the current
+// session, independently of the login itself.
+type VaultLock struct {
+	store   *session.Store
+	vaultUC domain.VaultUsecase
+}
+
+func NewVaultLock(store *session.Store, vaultUC domain.VaultUsecase) *VaultLock {
+	return &VaultLock{
+		store:   store,
+		vaultUC: vaultUC,
+	}
+}
+
+// RequireUnlocked rejects requests with 423 Locked while the vault is locked,
+// and pushes the auto-lock deadline back on every request that gets through.
+// It must run after requireAuth.
+func (l *VaultLock) RequireUnlocked(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	policy, err := l.vaultUC.GetLockPolicy(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !policy.Lockable() {
+		// Without a PIN or master password there is nothing to unlock with.
+		return c.Next()
+	}
+
+	sess, err := l.store.Get(c)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !l.unlocked(sess) {
+		return c.Status(fiber.StatusLocked).JSON(fiber.Map{"error": vaultLockedErrorMessage})
+	}
+
+	if policy.AutoLock > 0 {
+		sess.Set(sessionVaultLockAt, time.Now().Add(policy.AutoLock).Unix())
+		if err := sess.Save(); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+	return c.Next()
+}
+
+// unlocked reports whether the session holds an unexpired unlock.
+func (l *VaultLock) unlocked(sess *session.Session) bool {
+	if ok, _ := sess.Get(sessionVaultUnlocked).(bool); !ok {
+		return false
+	}
+	lockAt, _ := sess.Get(sessionVaultLockAt).(int64)
+	return lockAt == 0 || time.Now().Unix() < lockAt
+}
+
+// Status returns the lock state for the current session.
+func (l *VaultLock) Status(c *fiber.Ctx, policy *domain.LockPolicy) (locked bool, lockAt *time.Time, err error) {
+	if !policy.Lockable() {
+		return false, nil, nil
+	}
+	sess, err := l.store.Get(c)
+	if err != nil {
+		return false, nil, err
+	}
+	if !l.unlocked(sess) {
+		return true, nil, nil
+	}
+	if at, _ := sess.Get(sessionVaultLockAt).(int64); at != 0 {
+		t := time.Unix(at, 0)
+		lockAt = &t
+	}
+	return false, lockAt, nil
+}
+
+// Unlock marks the vault unlocked in the session. The auto-lock timeout starts now.
+func (l *VaultLock) Unlock(c *fiber.Ctx, policy *domain.LockPolicy) error {
+	sess, err := l.store.Get(c)
+	if err != nil {
+		return err
+	}
+	var lockAt int64
+	if policy.AutoLock > 0 {
+		lockAt = time.Now().Add(policy.AutoLock).Unix()
+	}
+	sess.Set(sessionVaultUnlocked, true)
+	sess.Set(sessionVaultLockAt, lockAt)
+	sess.Delete(sessionUnlockFailures)
+	return sess.Save()
+}
+
+// Lock marks the vault locked in the session.
+func (l *VaultLock) Lock(c *fiber.Ctx) error {
+	sess, err := l.store.Get(c)
+	if err != nil {
+		return err
+	}
+	sess.Delete(sessionVaultUnlocked)
+	sess.Delete(sessionVaultLockAt)
+	return sess.Save()
+}
+
+// Fail records a failed unlock attempt. PINs are short, so after
+// maxUnlockFailures attempts the session is destroyed and the user has to log
+// in with Google again. It reports whether that happened.
+func (l *VaultLock) Fail(c *fiber.Ctx) (loggedOut bool, err error) {
+	sess, err := l.store.Get(c)
+	if err != nil {
+		return false, err
+	}
+	failures, _ := sess.Get(sessionUnlockFailures).(int)
+	failures++
+	if failures >= maxUnlockFailures {
+		log.Printf("vault unlock: too many failed attempts for user %v, ending session", sess.Get("user_id"))
+		return true, sess.Destroy()
+	}
+	sess.Set(sessionUnlockFailures, failures)
+	return false, sess.Save()
+}
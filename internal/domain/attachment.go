@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// MaxAttachmentNameLength caps attachment file names, in characters.
+const MaxAttachmentNameLength = 255
+
+var (
+	// ErrAttachmentNotFound is returned for attachments, or secrets, that do
+	// not exist or belong to another user.
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	// ErrInvalidAttachment is returned for uploads with a bad file name, and
+	// for uploads the browser should have encrypted (zero-knowledge mode) but
+	// did not, or the other way round.
+	ErrInvalidAttachment = errors.New("invalid attachment")
+	// ErrAttachmentTooLarge is returned for files over the size limit.
+	ErrAttachmentTooLarge = errors.New("attachment is too large")
+	// ErrQuotaExceeded is returned when a file would take the user's
+	// attachments over their quota.
+	ErrQuotaExceeded = errors.New("attachment quota exceeded")
+	// ErrBlobNotFound is returned by blob stores for keys they hold nothing under.
+	ErrBlobNotFound = errors.New("blob not found")
+)
+
+// Attachment is a file stored with a secret. Its contents are encrypted with
+// a key of its own, which is wrapped by the user's data key, and kept in a
+// BlobStore; only the metadata lives in the database.
+type Attachment struct {
+	ID          string `json:"id"`
+	SecretID    string `json:"secret_id"`
+	UserID      string `json:"-"`
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	// Size is the size of the file as uploaded, which counts toward the quota.
+	Size int64 `json:"size"`
+	// WrappedKey is the file key, encrypted with the user's data key.
+	WrappedKey string `json:"-"`
+	// ClientEncrypted attachments were encrypted by the browser with the vault
+	// key before upload (zero-knowledge mode); the server adds its own layer
+	// on top, like it does for passwords.
+	ClientEncrypted bool      `json:"client_encrypted"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// BlobKey is where the attachment's encrypted contents are stored.
+func (a *Attachment) BlobKey() string {
+	return a.UserID + "/" + a.ID
+}
+
+// AttachmentUsage is how much of their attachment quota a user has used.
+type AttachmentUsage struct {
+	Used int64 `json:"used"`
+	// Quota is 0 if attachments are not limited.
+	Quota int64 `json:"quota"`
+	// MaxSize is the largest file that can be attached.
+	MaxSize int64 `json:"max_size"`
+}
+
+type AttachmentRepository interface {
+	// Create stores the attachment unless that would take the user's
+	// attachments over quota bytes (0 means no limit), and reports whether
+	// it did.
+	Create(ctx context.Context, attachment *Attachment, quota int64) (bool, error)
+	// GetByID returns nil if the attachment does not exist.
+	GetByID(ctx context.Context, id string) (*Attachment, error)
+	// ListBySecret returns the attachments of a secret, oldest first.
+	ListBySecret(ctx context.Context, secretID string) ([]*Attachment, error)
+	// ListByUserID returns all of the user's attachments.
+	ListByUserID(ctx context.Context, userID string) ([]*Attachment, error)
+	Delete(ctx context.Context, id string) error
+	// Usage returns the total size of the user's attachments.
+	Usage(ctx context.Context, userID string) (int64, error)
+	// ListOrphans returns up to limit attachments whose secret was purged.
+	// Their blobs have to be deleted before the rows are.
+	ListOrphans(ctx context.Context, limit int) ([]*Attachment, error)
+}
+
+// BlobStore keeps the encrypted contents of attachments, e.g. on the local
+// filesystem or in an S3 bucket. Keys are slash-separated paths.
+type BlobStore interface {
+	// Put stores the size bytes read from r under key, replacing any blob
+	// already there. A blob is either stored whole or not at all.
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+	// Get returns ErrBlobNotFound if there is no blob under key.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete succeeds if there is no blob under key.
+	Delete(ctx context.Context, key string) error
+}
+
+type AttachmentUsecase interface {
+	// AddAttachment encrypts content into the blob store and records it as an
+	// attachment of attachment.SecretID. Name, ContentType, Size and
+	// ClientEncrypted come from the caller; content must hold exactly Size
+	// bytes. It returns ErrAttachmentNotFound if the secret does not exist,
+	// ErrInvalidAttachment for a bad upload, and ErrAttachmentTooLarge or
+	// ErrQuotaExceeded if the file does not fit.
+	AddAttachment(ctx context.Context, attachment *Attachment, content io.Reader) error
+	// ListAttachments returns the attachments of one of the user's secrets.
+	ListAttachments(ctx context.Context, secretID string, userID string) ([]*Attachment, error)
+	// OpenAttachment returns an attachment with a reader of its decrypted
+	// contents, which the caller must close. A read fails if the stored
+	// contents were tampered with.
+	OpenAttachment(ctx context.Context, id string, secretID string, userID string) (*Attachment, io.ReadCloser, error)
+	DeleteAttachment(ctx context.Context, id string, secretID string, userID string) error
+	Usage(ctx context.Context, userID string) (*AttachmentUsage, error)
+
+	// Run deletes the attachments of purged secrets, at startup and then
+	// periodically, until ctx is done.
+	Run(ctx context.Context) error
+}
@@ -6,9 +6,33 @@ import (
 
 // Backup represents the structure of the exported file
 type Backup struct {
-	Version   string    `json:"version"`
-	CreatedAt string    `json:"created_at"`
-	Secrets   []*Secret `json:"secrets"`
+	Version     string              `json:"version"`
+	CreatedAt   string              `json:"created_at"`
+	Secrets     []*Secret           `json:"secrets"`
+	Vault       *BackupVault        `json:"vault,omitempty"` // Zero-knowledge accounts only
+	Attachments []*BackupAttachment `json:"attachments,omitempty"`
+}
+
+// BackupAttachment is a file attached to one of the secrets in a backup. Its
+// contents are carried decrypted, like passwords, and sealed with the rest of
+// the backup; client-encrypted files stay opaque.
+type BackupAttachment struct {
+	ID              string `json:"id"`
+	SecretID        string `json:"secret_id"`
+	Name            string `json:"name"`
+	ContentType     string `json:"content_type"`
+	ClientEncrypted bool   `json:"client_encrypted"`
+	Data            []byte `json:"data"`
+}
+
+// BackupVault carries the browser-wrapped vault key, so client-encrypted
+// passwords in a backup can be read again after a restore. The vault key stays
+// wrapped by the master password, which may be changed without touching backups.
+type BackupVault struct {
+	KDF        *KDFParams `json:"kdf"`
+	KeyID      string     `json:"key_id"`
+	WrappedKey string     `json:"wrapped_key"`
+	Verifier   string     `json:"verifier"`
 }
 
 type BackupUsecase interface {
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MaxFolderDepth limits how deeply folders can be nested.
+const MaxFolderDepth = 8
+
+// MaxFolderNameLength caps folder names, in characters.
+const MaxFolderNameLength = 100
+
+// UnfiledFolderID selects the secrets that are not in any folder when listing.
+const UnfiledFolderID = "none"
+
+var (
+	// ErrFolderNotFound is returned for folders that do not exist or belong to
+	// another user.
+	ErrFolderNotFound = errors.New("folder not found")
+	// ErrInvalidFolderName is returned for empty or overlong names.
+	ErrInvalidFolderName = fmt.Errorf("folder name must be 1 to %d characters", MaxFolderNameLength)
+	// ErrFolderCycle is returned when a folder would be moved into itself or
+	// one of its subfolders.
+	ErrFolderCycle = errors.New("a folder cannot be moved into itself or one of its subfolders")
+	// ErrFolderTooDeep is returned when nesting would exceed MaxFolderDepth.
+	ErrFolderTooDeep = fmt.Errorf("folders can be nested at most %d levels deep", MaxFolderDepth)
+)
+
+// Folder groups secrets. Folders nest; ParentID is nil for top-level folders.
+type Folder struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"-"`
+	ParentID  *string   `json:"parent_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type FolderRepository interface {
+	Create(ctx context.Context, folder *Folder) error
+	// GetByID returns nil if the folder does not exist.
+	GetByID(ctx context.Context, id string) (*Folder, error)
+	// ListByUserID returns all of the user's folders, ordered by name.
+	ListByUserID(ctx context.Context, userID string) ([]*Folder, error)
+	// Update saves the name and parent.
+	Update(ctx context.Context, folder *Folder) error
+	// Delete removes a folder; its subfolders and secrets move to its parent.
+	Delete(ctx context.Context, id string) error
+}
+
+type FolderUsecase interface {
+	CreateFolder(ctx context.Context, folder *Folder) error
+	// ListFolders returns the user's folders as a flat list; ParentID links
+	// them into a tree.
+	ListFolders(ctx context.Context, userID string) ([]*Folder, error)
+	// UpdateFolder renames or moves a folder. A nil ParentID moves it to the top level.
+	UpdateFolder(ctx context.Context, folder *Folder) error
+	// DeleteFolder removes a folder, moving its contents up one level.
+	DeleteFolder(ctx context.Context, id string, userID string) error
+	// MoveSecrets puts the user's secrets into a folder, or into none when
+	// folderID is nil, and returns how many were moved.
+	MoveSecrets(ctx context.Context, userID string, secretIDs []string, folderID *string) (int64, error)
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrInvalidGeneratorOptions wraps generator option errors such as an
+// out-of-range length, so handlers can answer 400.
+var ErrInvalidGeneratorOptions = errors.New("invalid generator options")
+
+// Generator modes.
+const (
+	GeneratorModePassword   = "password"
+	GeneratorModePassphrase = "passphrase"
+)
+
+// GeneratorOptions selects what to generate. In "password" mode Length and
+// the character classes apply, and the zero value of a class excludes it;
+// Rules, when set, replaces the classes with a site's passwordrules. In
+// "passphrase" mode a diceware passphrase of Words words is built instead.
+type GeneratorOptions struct {
+	Mode      string `json:"mode" enums:"password,passphrase" example:"password"`
+	Length    int    `json:"length" example:"16"`
+	Uppercase bool   `json:"uppercase" example:"true"`
+	Lowercase bool   `json:"lowercase" example:"true"`
+	Numbers   bool   `json:"numbers" example:"true"`
+	Symbols   bool   `json:"symbols" example:"true"`
+	Rules     string `json:"rules,omitempty" example:"minlength: 8; maxlength: 20; required: upper; required: digit; allowed: lower, [-_]"`
+
+	Words         int    `json:"words" example:"6"`
+	Separator     string `json:"separator" example:"-"`
+	Capitalize    bool   `json:"capitalize" example:"false"`
+	IncludeNumber bool   `json:"include_number" example:"false"`
+}
+
+// GeneratedPassword is the result of a generate request.
+type GeneratedPassword struct {
+	Password string `json:"password"`
+	// Entropy is the strength in bits, assuming the attacker knows the options.
+	Entropy float64          `json:"entropy_bits" example:"104.9"`
+	Options GeneratorOptions `json:"options"`
+}
+
+// MinStrongScore is the lowest strength score not flagged as weak.
+const MinStrongScore = 3
+
+// CrackTime is an estimated time to guess a password, with a display bucket
+// such as "3 hours" or "centuries".
+type CrackTime struct {
+	Seconds float64 `json:"seconds"`
+	Display string  `json:"display" example:"centuries"`
+}
+
+// CrackTimes estimates crack times in four attack scenarios.
+type CrackTimes struct {
+	// 100 guesses per hour, e.g. a rate-limited login form
+	OnlineThrottled CrackTime `json:"online_throttled"`
+	// 10 guesses per second
+	OnlineUnthrottled CrackTime `json:"online_unthrottled"`
+	// 10k guesses per second against a slow hash such as Argon2
+	OfflineSlowHashing CrackTime `json:"offline_slow_hashing"`
+	// 10B guesses per second against a fast hash
+	OfflineFastHashing CrackTime `json:"offline_fast_hashing"`
+}
+
+// StrengthMatch is one pattern the estimator found in a password.
+type StrengthMatch struct {
+	Pattern string  `json:"pattern" example:"dictionary"`
+	Token   string  `json:"token" example:"password"`
+	Guesses float64 `json:"guesses"`
+}
+
+// PasswordStrength is a zxcvbn-style strength estimate.
+type PasswordStrength struct {
+	// Score runs from 0 (too guessable) to 4 (very unguessable).
+	Score       int             `json:"score" example:"4"`
+	Guesses     float64         `json:"guesses"`
+	Entropy     float64         `json:"entropy_bits" example:"53.2"`
+	CrackTimes  CrackTimes      `json:"crack_times"`
+	Sequence    []StrengthMatch `json:"sequence"`
+	Warning     string          `json:"warning,omitempty"`
+	Suggestions []string        `json:"suggestions,omitempty"`
+}
+
+// GeneratorUsecase generates random passwords and estimates their strength.
+type GeneratorUsecase interface {
+	// DefaultOptions returns the "Strong" password preset (16 characters, all
+	// classes) along with the passphrase defaults (six words joined by "-").
+	DefaultOptions() GeneratorOptions
+	Generate(ctx context.Context, opts GeneratorOptions) (*GeneratedPassword, error)
+	// EstimateStrength scores a password; userInputs such as the title or
+	// username count as easy to guess.
+	EstimateStrength(ctx context.Context, password string, userInputs []string) *PasswordStrength
+}
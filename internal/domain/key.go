@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// UserKey is a user's data encryption key (DEK), stored wrapped by the master key.
+// Every secret owned by the user is encrypted with the unwrapped DEK.
+type UserKey struct {
+	UserID     string    `json:"user_id"`
+	WrappedKey string    `json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// UserKeyRepository defines persistence methods for UserKey
+type UserKeyRepository interface {
+	GetByUserID(ctx context.Context, userID string) (*UserKey, error)
+	// Create stores a new key. If the user already has one, the existing key is kept.
+	Create(ctx context.Context, key *UserKey) error
+
+	// Maintenance methods used by the key rotation job.
+	Count(ctx context.Context) (int, error)
+	ListBatch(ctx context.Context, afterUserID string, limit int) ([]*UserKey, error)
+	// ReplaceWrappedKey swaps the wrapped key only if it still equals oldValue.
+	// It reports whether the row was updated.
+	ReplaceWrappedKey(ctx context.Context, userID, oldValue, newValue string) (bool, error)
+}
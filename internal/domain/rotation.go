@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// RotationProgress reports how far the background re-encryption job has got.
+type RotationProgress struct {
+	Running            bool       `json:"running"`
+	CurrentKeyID       string     `json:"current_key_id"`
+	StartedAt          *time.Time `json:"started_at,omitempty"`
+	FinishedAt         *time.Time `json:"finished_at,omitempty"`
+	KeysTotal          int        `json:"keys_total"`
+	KeysScanned        int        `json:"keys_scanned"`
+	KeysRewrapped      int        `json:"keys_rewrapped"`
+	SecretsTotal       int        `json:"secrets_total"`
+	SecretsScanned     int        `json:"secrets_scanned"`
+	SecretsReencrypted int        `json:"secrets_reencrypted"`
+	Failures           int        `json:"failures"`
+	LastError          string     `json:"last_error,omitempty"`
+}
+
+// RotationStatus is the part of RotationProgress that is safe to show without
+// authentication: no key IDs, counts or error messages, which are logged instead.
+type RotationStatus struct {
+	Running  bool `json:"running"`
+	Finished bool `json:"finished"`
+	// Failed is set if any data key or secret could not be moved.
+	Failed bool `json:"failed"`
+}
+
+// Status summarizes the progress.
+func (p RotationProgress) Status() RotationStatus {
+	return RotationStatus{
+		Running:  p.Running,
+		Finished: p.FinishedAt != nil,
+		Failed:   p.Failures > 0,
+	}
+}
+
+// RotationUsecase moves data written under retired keys onto the current ones.
+type RotationUsecase interface {
+	// Run re-wraps every data key under the current master key and re-encrypts
+	// secrets still in a legacy format. It is safe to run while serving traffic.
+	Run(ctx context.Context) error
+	Progress() RotationProgress
+}
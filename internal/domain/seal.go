@@ -0,0 +1,28 @@
+package domain
+
+import "errors"
+
+// ErrInvalidUnsealShares is returned when the collected shares do not unseal
+// the keyring. Progress is reset and all shares must be entered again.
+var ErrInvalidUnsealShares = errors.New("unseal shares do not match the sealed keyring")
+
+// ErrMalformedUnsealShare is returned for a share that cannot be decoded.
+var ErrMalformedUnsealShare = errors.New("malformed unseal share")
+
+// SealStatus reports whether the server holds its master keys yet.
+type SealStatus struct {
+	Sealed    bool `json:"sealed"`
+	Threshold int  `json:"threshold"` // shares needed to unseal
+	Shares    int  `json:"shares"`    // shares that were issued
+	Progress  int  `json:"progress"`  // shares collected so far
+}
+
+// SealUsecase collects unseal shares and loads the master keyring once enough
+// have been provided.
+type SealUsecase interface {
+	Status() SealStatus
+	// Unseal adds one share. Providing the same share twice has no effect.
+	Unseal(share string) (SealStatus, error)
+	// Unsealed is closed once the keyring is loaded.
+	Unsealed() <-chan struct{}
+}
@@ -2,34 +2,242 @@ package domain
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"time"
 )
 
+var (
+	// ErrVersionRequired is returned for updates that do not say which version
+	// of the secret they were based on.
+	ErrVersionRequired = errors.New("the version being updated is required")
+	// ErrVersionConflict is returned when a secret changed after the caller
+	// loaded it. UpdateSecret returns it as a *VersionConflictError.
+	ErrVersionConflict = errors.New("secret was changed since it was loaded")
+	// ErrInvalidPatch is returned for merge patches that do not fit a secret,
+	// e.g. a title that is not a string.
+	ErrInvalidPatch = errors.New("invalid patch")
+	// ErrInvalidSort is returned for a listing sorted by an unknown field.
+	ErrInvalidSort = errors.New("invalid sort order")
+	// ErrInvalidCursor is returned for page cursors that were not issued for
+	// the requested sort order.
+	ErrInvalidCursor = errors.New("invalid page cursor")
+)
+
+// VersionConflictError reports a stale update. Current is the stored state the
+// caller has to reconcile with; like a listed secret, it holds only the
+// plaintext fields.
+type VersionConflictError struct {
+	Expected int
+	Current  *Secret
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("%v: expected version %d, current version is %d", ErrVersionConflict, e.Expected, e.Current.Version)
+}
+
+func (e *VersionConflictError) Unwrap() error {
+	return ErrVersionConflict
+}
+
+// PasswordRulesKey is the metadata key holding a site's passwordrules string,
+// which the generator follows when regenerating the secret's password.
+const PasswordRulesKey = "password_rules"
+
 type Secret struct {
 	ID                string    `json:"id"`
 	UserID            string    `json:"user_id"`
 	Title             string    `json:"title"`
+	// Login unless set; it cannot change once the secret is created
+	Type              SecretType `json:"type"`
+	// Nil for secrets that are not in a folder; moving is not an edit
+	FolderID          *string   `json:"folder_id"`
+	// Tags and Favorite organize the vault; changing them is not an edit either
+	Tags              []string  `json:"tags"`
+	Favorite          bool      `json:"favorite"`
 	Username          string    `json:"username"`
 	EncryptedPassword string    `json:"-"` // Never expose directly in JSON without decryption
 	Password          string    `json:"password,omitempty"` // Decrypted password, only populated when needed
+	// Set in zero-knowledge mode: Password was encrypted in the browser and is an
+	// opaque blob only the owner can decrypt.
+	ClientEncrypted   bool      `json:"client_encrypted"`
+	// Strength score (0-4) of the password; the password itself is never
+	// analysed after it is stored. Nil when unknown, e.g. a client-encrypted
+	// password whose score the browser did not send.
+	StrengthScore     *int      `json:"strength_score,omitempty"`
+	// Sealed username and sensitive metadata (notes, ...). Username and Metadata
+	// hold only the user's plaintext fields until the payload is decrypted.
+	EncryptedPayload  string    `json:"-"`
 	Metadata          map[string]interface{} `json:"metadata,omitempty"`
 	Version           int       `json:"version"`
 	CreatedAt         time.Time `json:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at"`
+	// Set while the secret is in the trash
+	DeletedAt         *time.Time `json:"deleted_at,omitempty"`
+	// When the password was last revealed; nil if it never was
+	LastUsedAt        *time.Time `json:"last_used_at,omitempty"`
+}
+
+// WeakPassword reports whether the stored strength score is below MinStrongScore.
+func (s *Secret) WeakPassword() bool {
+	return s.StrengthScore != nil && *s.StrengthScore < MinStrongScore
+}
+
+// TypeLabel returns the display name of the secret's type.
+func (s *Secret) TypeLabel() string {
+	if schema, ok := SchemaFor(s.Type); ok {
+		return schema.Label
+	}
+	return string(s.Type)
+}
+
+// SecretFilter narrows down a secret listing.
+type SecretFilter struct {
+	// FolderID selects the secrets in a folder and all of its subfolders, or
+	// with UnfiledFolderID those in no folder. Empty lists every secret.
+	FolderID string
+	// Tag selects the secrets carrying this tag.
+	Tag string
+	// Favorite selects favorites only.
+	Favorite bool
+	// Type selects the secrets of one type.
+	Type SecretType
+	// Query selects the secrets whose title, username, URL or notes contain
+	// every word of it, ignoring case. Sealed fields are not searched.
+	Query string
+}
+
+// SecretSort is the order of a paged secret listing.
+type SecretSort string
+
+const (
+	// SortCreated lists the newest secrets first; it is the default.
+	SortCreated SecretSort = "created_at"
+	// SortTitle lists secrets by title, A to Z.
+	SortTitle SecretSort = "title"
+	// SortUpdated lists the most recently changed secrets first.
+	SortUpdated SecretSort = "updated_at"
+	// SortLastUsed lists the most recently used secrets first and those never
+	// used last.
+	SortLastUsed SecretSort = "last_used"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// PageRequest selects one page of a secret listing. Cursor is empty for the
+// first page and otherwise the NextCursor of the previous one.
+type PageRequest struct {
+	Sort   SecretSort
+	Cursor string
+	Limit  int
+}
+
+// SecretPage is one page of a secret listing. Total counts every secret
+// matching the filter, on all pages; NextCursor is empty on the last page.
+type SecretPage struct {
+	Secrets    []*Secret `json:"secrets"`
+	Total      int       `json:"total"`
+	NextCursor string    `json:"next_cursor,omitempty"`
 }
 
 type SecretRepository interface {
 	Create(ctx context.Context, secret *Secret) error
+	// GetByID also returns trashed secrets; check DeletedAt.
 	GetByID(ctx context.Context, id string) (*Secret, error)
+	// ListByUserID returns the user's secrets that are not in the trash.
 	ListByUserID(ctx context.Context, userID string) ([]*Secret, error)
+	// ListByFilter is ListByUserID narrowed down by filter.
+	ListByFilter(ctx context.Context, userID string, filter SecretFilter) ([]*Secret, error)
+	// ListPage returns one page of ListByFilter in the requested order. It
+	// returns ErrInvalidCursor if page.Cursor was not issued for page.Sort.
+	ListPage(ctx context.Context, userID string, filter SecretFilter, page PageRequest) (*SecretPage, error)
+	// Update saves the secret if the stored version still equals secret.Version
+	// and bumps it; otherwise it returns ErrVersionConflict.
 	Update(ctx context.Context, secret *Secret) error
+	// Reseal rewrites the username, metadata and sealed payload without
+	// bumping the version, if the stored version still equals secret.Version
+	// and the payload still equals oldPayload. It reports whether the row was
+	// updated.
+	Reseal(ctx context.Context, secret *Secret, oldPayload string) (bool, error)
+	// Delete removes a secret for good; Trash only marks it deleted.
 	Delete(ctx context.Context, id string) error
+	Trash(ctx context.Context, id string) error
+	// Restore takes a secret out of the trash and reports whether it was in it.
+	Restore(ctx context.Context, id string) (bool, error)
+	// Purge deletes a secret for good if it is in the trash, and reports
+	// whether it was.
+	Purge(ctx context.Context, id string) (bool, error)
+	ListTrash(ctx context.Context, userID string) ([]*Secret, error)
+	// EmptyTrash deletes the user's trashed secrets for good.
+	EmptyTrash(ctx context.Context, userID string) (int64, error)
+	// SetFavorite marks or unmarks a secret without bumping its version.
+	SetFavorite(ctx context.Context, id string, favorite bool) error
+	// MarkUsed records that the secret's password was revealed just now,
+	// without bumping its version.
+	MarkUsed(ctx context.Context, id string) error
+	// MoveToFolder sets the folder of those of ids that belong to the user,
+	// without bumping their version, and returns how many it updated.
+	MoveToFolder(ctx context.Context, userID string, ids []string, folderID *string) (int64, error)
+
+	// Maintenance methods used by background jobs; they span all users.
+	Count(ctx context.Context) (int, error)
+	ListBatch(ctx context.Context, afterID string, limit int) ([]*Secret, error)
+	// ReplaceEncryptedPassword swaps the ciphertext only if it still equals oldValue,
+	// without bumping the version. It reports whether the row was updated.
+	ReplaceEncryptedPassword(ctx context.Context, id, oldValue, newValue string) (bool, error)
+	// PurgeTrash deletes secrets trashed before the cutoff for good.
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
 }
 
 type SecretUsecase interface {
+	// CreateSecret and UpdateSecret return ErrInvalidSecret if the secret
+	// does not fit the schema of its type.
 	CreateSecret(ctx context.Context, secret *Secret) error
+	// GetSecret also marks the secret as used.
 	GetSecret(ctx context.Context, id string, userID string) (*Secret, error)
-	ListSecrets(ctx context.Context, userID string) ([]*Secret, error)
+	// ListSecrets returns one page of the user's secrets. It returns
+	// ErrFolderNotFound if the filter names an unknown folder, and
+	// ErrInvalidSort or ErrInvalidCursor for a bad page request. A zero
+	// page.Limit means DefaultPageSize.
+	ListSecrets(ctx context.Context, userID string, filter SecretFilter, page PageRequest) (*SecretPage, error)
+	// UpdateSecret saves secret on top of secret.Version, the version the
+	// caller loaded. If the secret changed since, it returns a
+	// *VersionConflictError.
 	UpdateSecret(ctx context.Context, secret *Secret) error
+	// PatchSecret applies a JSON Merge Patch (RFC 7396) to the title,
+	// username, password and metadata of a secret; client_encrypted and
+	// strength_score go with a new password. version is the version the patch
+	// is based on, or 0 to patch whatever is current. It returns nil if the
+	// secret does not exist.
+	PatchSecret(ctx context.Context, id string, userID string, version int, patch map[string]interface{}) (*Secret, error)
+	// SetFavorite marks or unmarks one of the user's secrets as a favorite. It
+	// returns nil if the secret does not exist.
+	SetFavorite(ctx context.Context, id string, userID string, favorite bool) (*Secret, error)
+	// DeleteSecret moves a secret to the trash.
 	DeleteSecret(ctx context.Context, id string, userID string) error
+	// RotatePassword replaces the password with a freshly generated one, keeping
+	// the previous state in history. The result carries the new plaintext.
+	RotatePassword(ctx context.Context, id string, userID string) (*Secret, error)
+
+	// ListHistory returns the previous versions of a secret, newest first,
+	// with the changed fields but without passwords. It returns nil if the
+	// secret does not exist and an empty slice if it has no history.
+	ListHistory(ctx context.Context, id string, userID string) ([]*SecretVersion, error)
+	// GetVersion returns one previous version, decrypted.
+	GetVersion(ctx context.Context, id string, userID string, version int) (*SecretVersion, error)
+	// RestoreVersion makes a previous version current again. The state it
+	// replaces goes to history like any other update.
+	RestoreVersion(ctx context.Context, id string, userID string, version int) (*Secret, error)
+
+	// GetTOTP returns the current one-time code of a login's TOTP seed. It
+	// returns nil if the secret does not exist and ErrNoTOTP if it has no
+	// seed the server can read.
+	GetTOTP(ctx context.Context, id string, userID string) (*TOTPCode, error)
+	// DecodeTOTPImage reads a QR code image of an otpauth://totp/ URI. It
+	// returns ErrInvalidTOTP if the image holds none.
+	DecodeTOTPImage(image io.Reader) (*TOTPKeyInfo, error)
 }
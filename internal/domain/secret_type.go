@@ -0,0 +1,169 @@
+package domain
+
+import "errors"
+
+// ErrInvalidSecret is returned for secrets that do not fit the schema of their
+// type, e.g. a card number that fails the checksum.
+var ErrInvalidSecret = errors.New("invalid secret")
+
+// SecretType says what a secret holds and so which fields it has.
+type SecretType string
+
+const (
+	SecretTypeLogin    SecretType = "login"
+	SecretTypeCard     SecretType = "card"
+	SecretTypeNote     SecretType = "note"
+	SecretTypeSSHKey   SecretType = "ssh_key"
+	SecretTypeAPIKey   SecretType = "api_key"
+	SecretTypeIdentity SecretType = "identity"
+)
+
+// Field formats checked and normalized by the server.
+const (
+	FormatText          = "text"
+	FormatURL           = "url"
+	FormatEmail         = "email"
+	FormatPhone         = "phone"
+	FormatDate          = "date"        // YYYY-MM-DD
+	FormatCardNumber    = "card_number" // digits only, Luhn-checked
+	FormatCardExpiry    = "card_expiry" // MM/YY
+	FormatCVV           = "cvv"
+	FormatSSHPrivateKey = "ssh_private_key"
+	FormatSSHPublicKey  = "ssh_public_key"
+)
+
+// SecretField is one typed field of a secret, stored in its metadata under Name.
+type SecretField struct {
+	Name     string `json:"name"`
+	Label    string `json:"label"`
+	Format   string `json:"format"`
+	Required bool   `json:"required"`
+	// Sensitive fields are always sealed, whatever the owner keeps in
+	// plaintext. In zero-knowledge mode the browser encrypts them along with
+	// the password instead, so the server never sees them.
+	Sensitive bool `json:"sensitive"`
+	Multiline bool `json:"multiline,omitempty"`
+}
+
+// SecretSchema describes the fields of one secret type.
+type SecretSchema struct {
+	Type  SecretType `json:"type"`
+	Label string     `json:"label"`
+	// UsernameLabel and PasswordLabel name what the username and the
+	// (always encrypted) password hold for this type; empty if the type has
+	// no such field.
+	UsernameLabel string `json:"username_label,omitempty"`
+	PasswordLabel string `json:"password_label,omitempty"`
+	// Scored types get a strength score and can be rotated.
+	Scored bool          `json:"scored"`
+	Fields []SecretField `json:"fields"`
+	// AllowCustom accepts metadata keys beyond Fields. Only logins, which
+	// predate types, take them.
+	AllowCustom bool `json:"allow_custom"`
+}
+
+// Field returns the schema's field with the given name.
+func (s *SecretSchema) Field(name string) (SecretField, bool) {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return SecretField{}, false
+}
+
+var notesField = SecretField{Name: "notes", Label: "Notes", Format: FormatText, Multiline: true}
+
+var sensitiveNotesField = SecretField{Name: "notes", Label: "Notes", Format: FormatText, Sensitive: true, Multiline: true}
+
+var secretSchemas = []SecretSchema{
+	{
+		Type:          SecretTypeLogin,
+		Label:         "Login",
+		UsernameLabel: "Username",
+		PasswordLabel: "Password",
+		Scored:        true,
+		Fields: []SecretField{
+			{Name: "url", Label: "URL", Format: FormatURL},
+			{Name: TOTPKey, Label: "TOTP secret or otpauth:// URI", Format: FormatTOTP, Sensitive: true},
+			notesField,
+		},
+		AllowCustom: true,
+	},
+	{
+		Type:          SecretTypeCard,
+		Label:         "Credit Card",
+		PasswordLabel: "PIN",
+		Fields: []SecretField{
+			{Name: "cardholder", Label: "Cardholder", Format: FormatText},
+			{Name: "number", Label: "Card number", Format: FormatCardNumber, Required: true, Sensitive: true},
+			{Name: "expiry", Label: "Expiry (MM/YY)", Format: FormatCardExpiry, Required: true},
+			{Name: "cvv", Label: "CVV", Format: FormatCVV, Sensitive: true},
+			sensitiveNotesField,
+		},
+	},
+	{
+		Type:  SecretTypeNote,
+		Label: "Secure Note",
+		Fields: []SecretField{
+			{Name: "notes", Label: "Note", Format: FormatText, Required: true, Sensitive: true, Multiline: true},
+		},
+	},
+	{
+		Type:          SecretTypeSSHKey,
+		Label:         "SSH Key",
+		UsernameLabel: "User",
+		PasswordLabel: "Passphrase",
+		Fields: []SecretField{
+			{Name: "host", Label: "Host", Format: FormatText},
+			{Name: "private_key", Label: "Private key", Format: FormatSSHPrivateKey, Required: true, Sensitive: true, Multiline: true},
+			{Name: "public_key", Label: "Public key", Format: FormatSSHPublicKey, Multiline: true},
+			sensitiveNotesField,
+		},
+	},
+	{
+		Type:          SecretTypeAPIKey,
+		Label:         "API Key",
+		UsernameLabel: "Key ID",
+		PasswordLabel: "Key",
+		Fields: []SecretField{
+			{Name: "url", Label: "URL", Format: FormatURL},
+			{Name: "expires", Label: "Expires", Format: FormatDate},
+			sensitiveNotesField,
+		},
+	},
+	{
+		Type:  SecretTypeIdentity,
+		Label: "Identity",
+		Fields: []SecretField{
+			{Name: "full_name", Label: "Full name", Format: FormatText, Required: true},
+			{Name: "email", Label: "Email", Format: FormatEmail},
+			{Name: "phone", Label: "Phone", Format: FormatPhone},
+			{Name: "address", Label: "Address", Format: FormatText, Sensitive: true, Multiline: true},
+			{Name: "birth_date", Label: "Date of birth", Format: FormatDate, Sensitive: true},
+			{Name: "document_number", Label: "ID or passport number", Format: FormatText, Sensitive: true},
+			sensitiveNotesField,
+		},
+	},
+}
+
+// SecretSchemas returns the schema of every secret type, logins first.
+func SecretSchemas() []SecretSchema {
+	schemas := make([]SecretSchema, len(secretSchemas))
+	copy(schemas, secretSchemas)
+	return schemas
+}
+
+// SchemaFor returns the schema of a type; an empty type is a login.
+func SchemaFor(t SecretType) (*SecretSchema, bool) {
+	if t == "" {
+		t = SecretTypeLogin
+	}
+	for i := range secretSchemas {
+		if secretSchemas[i].Type == t {
+			schema := secretSchemas[i]
+			return &schema, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrRotationUnsupported is returned when the server cannot generate a
+// password for a secret, e.g. in zero-knowledge mode where it would see the
+// plaintext.
+var ErrRotationUnsupported = errors.New("zero-knowledge secrets must be rotated in the browser")
+
+// GeneratorOptionsKey is the metadata key holding the GeneratorOptions used to
+// rotate a secret's password. PasswordRulesKey takes precedence over it.
+const GeneratorOptionsKey = "generator"
+
+// SecretVersion is an encrypted snapshot of a secret as it was before it
+// changed. The ciphertexts stay bound to the secret, so they decrypt like the
+// live row.
+type SecretVersion struct {
+	SecretID          string                 `json:"secret_id"`
+	UserID            string                 `json:"-"`
+	Version           int                    `json:"version"`
+	Title             string                 `json:"title"`
+	Username          string                 `json:"username"`
+	EncryptedPassword string                 `json:"-"`
+	EncryptedPayload  string                 `json:"-"`
+	Password          string                 `json:"password,omitempty"` // Decrypted password, only populated when viewing one version
+	ClientEncrypted   bool                   `json:"client_encrypted"`
+	StrengthScore     *int                   `json:"strength_score,omitempty"`
+	Metadata          map[string]interface{} `json:"metadata,omitempty"`
+	// Changes lists the fields ("title", "username", "password" or
+	// "metadata.<key>") that differ in the version that replaced this one.
+	Changes []string `json:"changes,omitempty"`
+	// UpdatedAt is when this version was written; CreatedAt when it was
+	// replaced and moved to history.
+	UpdatedAt time.Time `json:"updated_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewSecretVersion snapshots the stored (still encrypted) state of a secret.
+func NewSecretVersion(s *Secret) *SecretVersion {
+	var metadata map[string]interface{}
+	if s.Metadata != nil {
+		metadata = make(map[string]interface{}, len(s.Metadata))
+		for k, v := range s.Metadata {
+			metadata[k] = v
+		}
+	}
+	return &SecretVersion{
+		SecretID:          s.ID,
+		UserID:            s.UserID,
+		Version:           s.Version,
+		Title:             s.Title,
+		Username:          s.Username,
+		EncryptedPassword: s.EncryptedPassword,
+		EncryptedPayload:  s.EncryptedPayload,
+		ClientEncrypted:   s.ClientEncrypted,
+		StrengthScore:     s.StrengthScore,
+		Metadata:          metadata,
+		UpdatedAt:         s.UpdatedAt,
+	}
+}
+
+// Secret returns the snapshot as a stored secret, so it can be opened and
+// decrypted like the live row.
+func (v *SecretVersion) Secret() *Secret {
+	return &Secret{
+		ID:                v.SecretID,
+		UserID:            v.UserID,
+		Title:             v.Title,
+		Username:          v.Username,
+		EncryptedPassword: v.EncryptedPassword,
+		EncryptedPayload:  v.EncryptedPayload,
+		ClientEncrypted:   v.ClientEncrypted,
+		StrengthScore:     v.StrengthScore,
+		Metadata:          v.Metadata,
+		Version:           v.Version,
+		UpdatedAt:         v.UpdatedAt,
+	}
+}
+
+// SecretVersionRepository stores the history of secrets.
+type SecretVersionRepository interface {
+	Create(ctx context.Context, version *SecretVersion) error
+	// ListBySecretID returns a secret's history, newest first.
+	ListBySecretID(ctx context.Context, secretID string) ([]*SecretVersion, error)
+	// ListByUserID returns the history of all the user's secrets, trashed
+	// ones included.
+	ListByUserID(ctx context.Context, userID string) ([]*SecretVersion, error)
+	// Reseal rewrites the username, metadata and sealed payload of a version.
+	Reseal(ctx context.Context, version *SecretVersion) error
+	// GetByVersion returns nil if the version is not in history.
+	GetByVersion(ctx context.Context, secretID string, version int) (*SecretVersion, error)
+	// Prune keeps only the newest keep versions of each of the user's secrets.
+	Prune(ctx context.Context, userID string, keep int) error
+}
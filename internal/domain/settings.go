@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultPlaintextFields are left unencrypted unless the user chooses otherwise,
+// so the dashboard can list usernames and links without decrypting anything.
+var DefaultPlaintextFields = []string{"username", "url"}
+
+// DefaultAutoLockMinutes is how long an unlocked vault may sit idle.
+const DefaultAutoLockMinutes = 15
+
+// MaxAutoLockMinutes caps the auto-lock timeout at one day.
+const MaxAutoLockMinutes = 24 * 60
+
+// DefaultHistoryLimit is how many previous versions of each secret are kept.
+const DefaultHistoryLimit = 20
+
+// MaxHistoryLimit caps the history kept per secret.
+const MaxHistoryLimit = 100
+
+// UserSettings holds per-user vault preferences.
+type UserSettings struct {
+	UserID string `json:"-"`
+	// PlaintextFields lists the secret fields stored unencrypted for listing and
+	// searching: "username" and/or metadata keys such as "url". The title is
+	// always plaintext and the password is always encrypted.
+	PlaintextFields []string `json:"plaintext_fields"`
+	// AutoLockMinutes locks the vault after this much inactivity; 0 keeps it
+	// unlocked until logout.
+	AutoLockMinutes int `json:"auto_lock_minutes"`
+	// HistoryLimit is how many previous versions of each secret are kept
+	// (1-MaxHistoryLimit); older ones are deleted.
+	HistoryLimit int       `json:"history_limit"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// SettingsRepository defines persistence methods for UserSettings
+type SettingsRepository interface {
+	// Get returns the user's settings, or the defaults if none were saved.
+	Get(ctx context.Context, userID string) (*UserSettings, error)
+	Upsert(ctx context.Context, settings *UserSettings) error
+}
+
+// SettingsUsecase defines business logic for user settings
+type SettingsUsecase interface {
+	GetSettings(ctx context.Context, userID string) (*UserSettings, error)
+	// UpdateSettings saves the settings, re-encrypts existing secrets if the
+	// plaintext/encrypted split changed and trims history to HistoryLimit.
+	UpdateSettings(ctx context.Context, settings *UserSettings) error
+}
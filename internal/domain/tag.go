@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+// MaxTagLength caps tag names, in characters.
+const MaxTagLength = 50
+
+// ErrInvalidTag is returned for tag names that are empty, too long or contain
+// characters other than letters, digits, spaces and "-_.:".
+var ErrInvalidTag = errors.New("tags must be 1 to 50 letters, digits, spaces or -_.: and start with a letter or digit")
+
+// ErrTagNotFound is returned when renaming or deleting a tag the user does not have.
+var ErrTagNotFound = errors.New("tag not found")
+
+// Tag is a label on one or more secrets. Names are lowercase and unique per user.
+type Tag struct {
+	Name string `json:"name"`
+	// Secrets counts the secrets carrying the tag, excluding the trash.
+	Secrets int `json:"secrets"`
+}
+
+type TagRepository interface {
+	// ListByUserID returns the user's tags, ordered by name.
+	ListByUserID(ctx context.Context, userID string) ([]*Tag, error)
+	// AddToSecret tags a secret, creating tags the user does not have yet.
+	AddToSecret(ctx context.Context, userID string, secretID string, names []string) error
+	// RemoveFromSecret untags a secret and reports whether it had the tag.
+	RemoveFromSecret(ctx context.Context, userID string, secretID string, name string) (bool, error)
+	// Rename renames a tag on every secret; renaming onto an existing tag
+	// merges the two. It reports whether the tag existed.
+	Rename(ctx context.Context, userID string, from string, to string) (bool, error)
+	// Delete removes a tag from every secret and reports whether it existed.
+	Delete(ctx context.Context, userID string, name string) (bool, error)
+}
+
+type TagUsecase interface {
+	ListTags(ctx context.Context, userID string) ([]*Tag, error)
+	// AddTags tags one of the user's secrets and returns all of its tags, or
+	// nil if the secret does not exist.
+	AddTags(ctx context.Context, userID string, secretID string, names []string) ([]string, error)
+	// RemoveTag untags a secret and returns its remaining tags, or nil if the
+	// secret does not exist.
+	RemoveTag(ctx context.Context, userID string, secretID string, name string) ([]string, error)
+	// RenameTag renames a tag across the vault, merging it into an existing
+	// tag of the new name.
+	RenameTag(ctx context.Context, userID string, from string, to string) error
+	DeleteTag(ctx context.Context, userID string, name string) error
+}
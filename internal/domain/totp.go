@@ -0,0 +1,39 @@
+package domain
+
+import "errors"
+
+var (
+	// ErrNoTOTP is returned for codes of a secret that stores no TOTP seed the
+	// server can read, including one the browser encrypted.
+	ErrNoTOTP = errors.New("secret has no TOTP seed")
+	// ErrInvalidTOTP is returned for images without a QR code holding an
+	// otpauth://totp/ URI.
+	ErrInvalidTOTP = errors.New("invalid TOTP key")
+)
+
+// TOTPKey is the metadata key of a login's TOTP seed. The server stores it as
+// a canonical otpauth://totp/ URI, sealed like any sensitive field.
+const TOTPKey = "totp"
+
+// FormatTOTP is the format of a base32 TOTP seed or an otpauth://totp/ URI.
+const FormatTOTP = "totp"
+
+// TOTPCode is the one-time code of a secret at the time it was requested.
+type TOTPCode struct {
+	Code string `json:"code"`
+	// Remaining is the number of seconds the code stays valid.
+	Remaining int    `json:"remaining"`
+	Period    int    `json:"period"`
+	Digits    int    `json:"digits"`
+	Algorithm string `json:"algorithm"`
+}
+
+// TOTPKeyInfo describes a TOTP key read from a QR code image.
+type TOTPKeyInfo struct {
+	URI       string `json:"uri"`
+	Issuer    string `json:"issuer,omitempty"`
+	Account   string `json:"account,omitempty"`
+	Algorithm string `json:"algorithm"`
+	Digits    int    `json:"digits"`
+	Period    int    `json:"period"`
+}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotInTrash is returned when purging or restoring a secret that is not in
+// the trash.
+var ErrNotInTrash = errors.New("secret is not in the trash")
+
+// TrashUsecase manages deleted secrets. Deleting a secret only moves it to the
+// trash; it is purged for good by the user or once the retention period ends.
+type TrashUsecase interface {
+	ListTrash(ctx context.Context, userID string) ([]*Secret, error)
+	// RestoreSecret takes a secret out of the trash. It returns nil if the
+	// secret does not exist.
+	RestoreSecret(ctx context.Context, id string, userID string) (*Secret, error)
+	// PurgeSecret deletes a trashed secret and its history for good.
+	PurgeSecret(ctx context.Context, id string, userID string) error
+	// EmptyTrash purges all of the user's trashed secrets and returns how many.
+	EmptyTrash(ctx context.Context, userID string) (int64, error)
+
+	// Retention is how long secrets stay in the trash; 0 keeps them until purged.
+	Retention() time.Duration
+	// Run purges secrets trashed longer than Retention ago, at startup and then
+	// periodically, until ctx is done.
+	Run(ctx context.Context) error
+}
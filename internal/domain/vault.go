@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrInvalidMasterPassword is returned when a master password check fails.
+	ErrInvalidMasterPassword = errors.New("invalid master password")
+	// ErrInvalidPIN is returned when an unlock PIN check fails.
+	ErrInvalidPIN = errors.New("invalid PIN")
+)
+
+// Ways to unlock a vault after login.
+const (
+	UnlockMethodPIN            = "pin"
+	UnlockMethodMasterPassword = "master_password"
+)
+
+// KDFParams are the Argon2id parameters the browser uses to derive the master
+// key from the master password. The server stores them but never runs the KDF.
+type KDFParams struct {
+	Algorithm   string `json:"algorithm"`   // Always "argon2id"
+	Memory      uint32 `json:"memory"`      // KiB
+	Iterations  uint32 `json:"iterations"`  // Passes over memory
+	Parallelism uint8  `json:"parallelism"` // Lanes
+	Salt        string `json:"salt"`        // Base64, at least 16 bytes
+}
+
+// Vault describes a user's zero-knowledge setup. With zero knowledge enabled,
+// secrets are encrypted in the browser with a random vault key, which is itself
+// wrapped by the key derived from the master password. The server only ever
+// sees opaque ciphertext.
+type Vault struct {
+	UserID        string     `json:"-"`
+	ZeroKnowledge bool       `json:"zero_knowledge"`
+	KDF           *KDFParams `json:"kdf,omitempty"`
+	// KeyID identifies the vault key; it survives master password changes,
+	// which only re-wrap the key.
+	KeyID string `json:"key_id,omitempty"`
+	// WrappedKey is the vault key encrypted by the browser with the master key.
+	WrappedKey string `json:"wrapped_key,omitempty"`
+	// Verifier is a hash of the authentication key the browser derives next to
+	// the master key; it proves knowledge of the master password.
+	Verifier string `json:"-"`
+	// PINHash is the Argon2id hash of the optional unlock PIN.
+	PINHash   string     `json:"-"`
+	HasPIN    bool       `json:"has_pin"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// LockPolicy says how a user's vault is unlocked after login and when it
+// locks again. A vault without any unlock method cannot be locked.
+type LockPolicy struct {
+	Methods  []string      `json:"methods"`
+	AutoLock time.Duration `json:"-"` // Idle time before locking; 0 locks only on logout
+}
+
+// Lockable reports whether the user has set up a way to unlock the vault.
+func (p *LockPolicy) Lockable() bool {
+	return len(p.Methods) > 0
+}
+
+// UnlockRequest carries one of the unlock credentials.
+type UnlockRequest struct {
+	PIN     string `json:"pin,omitempty"`
+	AuthKey string `json:"auth_key,omitempty"` // Derived from the master password in zero-knowledge mode
+}
+
+// VaultSetup is what the browser sends to enable zero knowledge or to change
+// the master password.
+type VaultSetup struct {
+	KDF        KDFParams `json:"kdf"`
+	AuthKey    string    `json:"auth_key"` // Base64, 32 bytes
+	KeyID      string    `json:"key_id"`
+	WrappedKey string    `json:"wrapped_key"`
+}
+
+// VaultRepository defines persistence methods for the zero-knowledge settings stored in users
+type VaultRepository interface {
+	// GetByUserID returns the user's vault; ZeroKnowledge is false if it was never set up.
+	GetByUserID(ctx context.Context, userID string) (*Vault, error)
+	Save(ctx context.Context, vault *Vault) error
+}
+
+// VaultUsecase defines business logic for zero-knowledge mode
+type VaultUsecase interface {
+	GetVault(ctx context.Context, userID string) (*Vault, error)
+	EnableZeroKnowledge(ctx context.Context, userID string, setup *VaultSetup) (*Vault, error)
+	// ChangeMasterPassword re-wraps the vault key; secrets are not re-encrypted.
+	ChangeMasterPassword(ctx context.Context, userID, currentAuthKey string, setup *VaultSetup) (*Vault, error)
+	VerifyMasterPassword(ctx context.Context, userID, authKey string) error
+
+	GetLockPolicy(ctx context.Context, userID string) (*LockPolicy, error)
+	// Unlock checks the PIN or master password; it returns ErrInvalidPIN or
+	// ErrInvalidMasterPassword on a mismatch.
+	Unlock(ctx context.Context, userID string, req *UnlockRequest) error
+	SetPIN(ctx context.Context, userID, pin string) error
+	RemovePIN(ctx context.Context, userID string) error
+}
@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/backup.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/backup.go -destination=internal/mocks/mock_backup_repo.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockBackupUsecase is a mock of BackupUsecase interface.
+type MockBackupUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockBackupUsecaseMockRecorder
+	isgomock struct{}
+}
+
+// MockBackupUsecaseMockRecorder is the mock recorder for MockBackupUsecase.
+type MockBackupUsecaseMockRecorder struct {
+	mock *MockBackupUsecase
+}
+
+// NewMockBackupUsecase creates a new mock instance.
+func NewMockBackupUsecase(ctrl *gomock.Controller) *MockBackupUsecase {
+	mock := &MockBackupUsecase{ctrl: ctrl}
+	mock.recorder = &MockBackupUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBackupUsecase) EXPECT() *MockBackupUsecaseMockRecorder {
+	return m.recorder
+}
+
+// ExportSecrets mocks base method.
+func (m *MockBackupUsecase) ExportSecrets(ctx context.Context, userID string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportSecrets", ctx, userID)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportSecrets indicates an expected call of ExportSecrets.
+func (mr *MockBackupUsecaseMockRecorder) ExportSecrets(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportSecrets", reflect.TypeOf((*MockBackupUsecase)(nil).ExportSecrets), ctx, userID)
+}
+
+// ImportSecrets mocks base method.
+func (m *MockBackupUsecase) ImportSecrets(ctx context.Context, userID string, backupData []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportSecrets", ctx, userID, backupData)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ImportSecrets indicates an expected call of ImportSecrets.
+func (mr *MockBackupUsecaseMockRecorder) ImportSecrets(ctx, userID, backupData any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportSecrets", reflect.TypeOf((*MockBackupUsecase)(nil).ImportSecrets), ctx, userID, backupData)
+}
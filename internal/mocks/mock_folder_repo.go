@@ -0,0 +1,210 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/folder.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/folder.go -destination=internal/mocks/mock_folder_repo.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/herdiagusthio/password-manager/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFolderRepository is a mock of FolderRepository interface.
+type MockFolderRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFolderRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockFolderRepositoryMockRecorder is the mock recorder for MockFolderRepository.
+type MockFolderRepositoryMockRecorder struct {
+	mock *MockFolderRepository
+}
+
+// NewMockFolderRepository creates a new mock instance.
+func NewMockFolderRepository(ctrl *gomock.Controller) *MockFolderRepository {
+	mock := &MockFolderRepository{ctrl: ctrl}
+	mock.recorder = &MockFolderRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFolderRepository) EXPECT() *MockFolderRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockFolderRepository) Create(ctx context.Context, folder *domain.Folder) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, folder)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockFolderRepositoryMockRecorder) Create(ctx, folder any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockFolderRepository)(nil).Create), ctx, folder)
+}
+
+// Delete mocks base method.
+func (m *MockFolderRepository) Delete(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockFolderRepositoryMockRecorder) Delete(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockFolderRepository)(nil).Delete), ctx, id)
+}
+
+// GetByID mocks base method.
+func (m *MockFolderRepository) GetByID(ctx context.Context, id string) (*domain.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*domain.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockFolderRepositoryMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockFolderRepository)(nil).GetByID), ctx, id)
+}
+
+// ListByUserID mocks base method.
+func (m *MockFolderRepository) ListByUserID(ctx context.Context, userID string) ([]*domain.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*domain.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByUserID indicates an expected call of ListByUserID.
+func (mr *MockFolderRepositoryMockRecorder) ListByUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUserID", reflect.TypeOf((*MockFolderRepository)(nil).ListByUserID), ctx, userID)
+}
+
+// Update mocks base method.
+func (m *MockFolderRepository) Update(ctx context.Context, folder *domain.Folder) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, folder)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockFolderRepositoryMockRecorder) Update(ctx, folder any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockFolderRepository)(nil).Update), ctx, folder)
+}
+
+// MockFolderUsecase is a mock of FolderUsecase interface.
+type MockFolderUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockFolderUsecaseMockRecorder
+	isgomock struct{}
+}
+
+// MockFolderUsecaseMockRecorder is the mock recorder for MockFolderUsecase.
+type MockFolderUsecaseMockRecorder struct {
+	mock *MockFolderUsecase
+}
+
+// NewMockFolderUsecase creates a new mock instance.
+func NewMockFolderUsecase(ctrl *gomock.Controller) *MockFolderUsecase {
+	mock := &MockFolderUsecase{ctrl: ctrl}
+	mock.recorder = &MockFolderUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFolderUsecase) EXPECT() *MockFolderUsecaseMockRecorder {
+	return m.recorder
+}
+
+// CreateFolder mocks base method.
+func (m *MockFolderUsecase) CreateFolder(ctx context.Context, folder *domain.Folder) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFolder", ctx, folder)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateFolder indicates an expected call of CreateFolder.
+func (mr *MockFolderUsecaseMockRecorder) CreateFolder(ctx, folder any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFolder", reflect.TypeOf((*MockFolderUsecase)(nil).CreateFolder), ctx, folder)
+}
+
+// DeleteFolder mocks base method.
+func (m *MockFolderUsecase) DeleteFolder(ctx context.Context, id, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFolder", ctx, id, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFolder indicates an expected call of DeleteFolder.
+func (mr *MockFolderUsecaseMockRecorder) DeleteFolder(ctx, id, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFolder", reflect.TypeOf((*MockFolderUsecase)(nil).DeleteFolder), ctx, id, userID)
+}
+
+// ListFolders mocks base method.
+func (m *MockFolderUsecase) ListFolders(ctx context.Context, userID string) ([]*domain.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFolders", ctx, userID)
+	ret0, _ := ret[0].([]*domain.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFolders indicates an expected call of ListFolders.
+func (mr *MockFolderUsecaseMockRecorder) ListFolders(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFolders", reflect.TypeOf((*MockFolderUsecase)(nil).ListFolders), ctx, userID)
+}
+
+// MoveSecrets mocks base method.
+func (m *MockFolderUsecase) MoveSecrets(ctx context.Context, userID string, secretIDs []string, folderID *string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveSecrets", ctx, userID, secretIDs, folderID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveSecrets indicates an expected call of MoveSecrets.
+func (mr *MockFolderUsecaseMockRecorder) MoveSecrets(ctx, userID, secretIDs, folderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveSecrets", reflect.TypeOf((*MockFolderUsecase)(nil).MoveSecrets), ctx, userID, secretIDs, folderID)
+}
+
+// UpdateFolder mocks base method.
+func (m *MockFolderUsecase) UpdateFolder(ctx context.Context, folder *domain.Folder) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFolder", ctx, folder)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateFolder indicates an expected call of UpdateFolder.
+func (mr *MockFolderUsecaseMockRecorder) UpdateFolder(ctx, folder any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFolder", reflect.TypeOf((*MockFolderUsecase)(nil).UpdateFolder), ctx, folder)
+}
@@ -0,0 +1,116 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/key.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/key.go -destination=internal/mocks/mock_key_repo.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/herdiagusthio/password-manager/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockUserKeyRepository is a mock of UserKeyRepository interface.
+type MockUserKeyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserKeyRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockUserKeyRepositoryMockRecorder is the mock recorder for MockUserKeyRepository.
+type MockUserKeyRepositoryMockRecorder struct {
+	mock *MockUserKeyRepository
+}
+
+// NewMockUserKeyRepository creates a new mock instance.
+func NewMockUserKeyRepository(ctrl *gomock.Controller) *MockUserKeyRepository {
+	mock := &MockUserKeyRepository{ctrl: ctrl}
+	mock.recorder = &MockUserKeyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserKeyRepository) EXPECT() *MockUserKeyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Count mocks base method.
+func (m *MockUserKeyRepository) Count(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockUserKeyRepositoryMockRecorder) Count(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockUserKeyRepository)(nil).Count), ctx)
+}
+
+// Create mocks base method.
+func (m *MockUserKeyRepository) Create(ctx context.Context, key *domain.UserKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockUserKeyRepositoryMockRecorder) Create(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserKeyRepository)(nil).Create), ctx, key)
+}
+
+// GetByUserID mocks base method.
+func (m *MockUserKeyRepository) GetByUserID(ctx context.Context, userID string) (*domain.UserKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUserID", ctx, userID)
+	ret0, _ := ret[0].(*domain.UserKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUserID indicates an expected call of GetByUserID.
+func (mr *MockUserKeyRepositoryMockRecorder) GetByUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockUserKeyRepository)(nil).GetByUserID), ctx, userID)
+}
+
+// ListBatch mocks base method.
+func (m *MockUserKeyRepository) ListBatch(ctx context.Context, afterUserID string, limit int) ([]*domain.UserKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBatch", ctx, afterUserID, limit)
+	ret0, _ := ret[0].([]*domain.UserKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBatch indicates an expected call of ListBatch.
+func (mr *MockUserKeyRepositoryMockRecorder) ListBatch(ctx, afterUserID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBatch", reflect.TypeOf((*MockUserKeyRepository)(nil).ListBatch), ctx, afterUserID, limit)
+}
+
+// ReplaceWrappedKey mocks base method.
+func (m *MockUserKeyRepository) ReplaceWrappedKey(ctx context.Context, userID, oldValue, newValue string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceWrappedKey", ctx, userID, oldValue, newValue)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplaceWrappedKey indicates an expected call of ReplaceWrappedKey.
+func (mr *MockUserKeyRepositoryMockRecorder) ReplaceWrappedKey(ctx, userID, oldValue, newValue any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceWrappedKey", reflect.TypeOf((*MockUserKeyRepository)(nil).ReplaceWrappedKey), ctx, userID, oldValue, newValue)
+}
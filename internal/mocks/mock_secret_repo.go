@@ -11,7 +11,9 @@ package mocks
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
+	time "time"
 
 	domain "github.com/herdiagusthio/password-manager/internal/domain"
 	gomock "go.uber.org/mock/gomock"
@@ -41,6 +43,21 @@ func (m *MockSecretRepository) EXPECT() *MockSecretRepositoryMockRecorder {
 	return m.recorder
 }
 
+// Count mocks base method.
+func (m *MockSecretRepository) Count(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockSecretRepositoryMockRecorder) Count(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockSecretRepository)(nil).Count), ctx)
+}
+
 // Create mocks base method.
 func (m *MockSecretRepository) Create(ctx context.Context, secret *domain.Secret) error {
 	m.ctrl.T.Helper()
@@ -69,6 +86,21 @@ func (mr *MockSecretRepositoryMockRecorder) Delete(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSecretRepository)(nil).Delete), ctx, id)
 }
 
+// EmptyTrash mocks base method.
+func (m *MockSecretRepository) EmptyTrash(ctx context.Context, userID string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EmptyTrash", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EmptyTrash indicates an expected call of EmptyTrash.
+func (mr *MockSecretRepositoryMockRecorder) EmptyTrash(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EmptyTrash", reflect.TypeOf((*MockSecretRepository)(nil).EmptyTrash), ctx, userID)
+}
+
 // GetByID mocks base method.
 func (m *MockSecretRepository) GetByID(ctx context.Context, id string) (*domain.Secret, error) {
 	m.ctrl.T.Helper()
@@ -84,6 +116,36 @@ func (mr *MockSecretRepositoryMockRecorder) GetByID(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockSecretRepository)(nil).GetByID), ctx, id)
 }
 
+// ListBatch mocks base method.
+func (m *MockSecretRepository) ListBatch(ctx context.Context, afterID string, limit int) ([]*domain.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBatch", ctx, afterID, limit)
+	ret0, _ := ret[0].([]*domain.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBatch indicates an expected call of ListBatch.
+func (mr *MockSecretRepositoryMockRecorder) ListBatch(ctx, afterID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBatch", reflect.TypeOf((*MockSecretRepository)(nil).ListBatch), ctx, afterID, limit)
+}
+
+// ListByFilter mocks base method.
+func (m *MockSecretRepository) ListByFilter(ctx context.Context, userID string, filter domain.SecretFilter) ([]*domain.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByFilter", ctx, userID, filter)
+	ret0, _ := ret[0].([]*domain.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByFilter indicates an expected call of ListByFilter.
+func (mr *MockSecretRepositoryMockRecorder) ListByFilter(ctx, userID, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByFilter", reflect.TypeOf((*MockSecretRepository)(nil).ListByFilter), ctx, userID, filter)
+}
+
 // ListByUserID mocks base method.
 func (m *MockSecretRepository) ListByUserID(ctx context.Context, userID string) ([]*domain.Secret, error) {
 	m.ctrl.T.Helper()
@@ -99,6 +161,168 @@ func (mr *MockSecretRepositoryMockRecorder) ListByUserID(ctx, userID any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUserID", reflect.TypeOf((*MockSecretRepository)(nil).ListByUserID), ctx, userID)
 }
 
+// ListPage mocks base method.
+func (m *MockSecretRepository) ListPage(ctx context.Context, userID string, filter domain.SecretFilter, page domain.PageRequest) (*domain.SecretPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPage", ctx, userID, filter, page)
+	ret0, _ := ret[0].(*domain.SecretPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPage indicates an expected call of ListPage.
+func (mr *MockSecretRepositoryMockRecorder) ListPage(ctx, userID, filter, page any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPage", reflect.TypeOf((*MockSecretRepository)(nil).ListPage), ctx, userID, filter, page)
+}
+
+// ListTrash mocks base method.
+func (m *MockSecretRepository) ListTrash(ctx context.Context, userID string) ([]*domain.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrash", ctx, userID)
+	ret0, _ := ret[0].([]*domain.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrash indicates an expected call of ListTrash.
+func (mr *MockSecretRepositoryMockRecorder) ListTrash(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrash", reflect.TypeOf((*MockSecretRepository)(nil).ListTrash), ctx, userID)
+}
+
+// MarkUsed mocks base method.
+func (m *MockSecretRepository) MarkUsed(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkUsed", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkUsed indicates an expected call of MarkUsed.
+func (mr *MockSecretRepositoryMockRecorder) MarkUsed(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkUsed", reflect.TypeOf((*MockSecretRepository)(nil).MarkUsed), ctx, id)
+}
+
+// MoveToFolder mocks base method.
+func (m *MockSecretRepository) MoveToFolder(ctx context.Context, userID string, ids []string, folderID *string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveToFolder", ctx, userID, ids, folderID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveToFolder indicates an expected call of MoveToFolder.
+func (mr *MockSecretRepositoryMockRecorder) MoveToFolder(ctx, userID, ids, folderID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveToFolder", reflect.TypeOf((*MockSecretRepository)(nil).MoveToFolder), ctx, userID, ids, folderID)
+}
+
+// Purge mocks base method.
+func (m *MockSecretRepository) Purge(ctx context.Context, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockSecretRepositoryMockRecorder) Purge(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockSecretRepository)(nil).Purge), ctx, id)
+}
+
+// PurgeTrash mocks base method.
+func (m *MockSecretRepository) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTrash", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeTrash indicates an expected call of PurgeTrash.
+func (mr *MockSecretRepositoryMockRecorder) PurgeTrash(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTrash", reflect.TypeOf((*MockSecretRepository)(nil).PurgeTrash), ctx, before)
+}
+
+// ReplaceEncryptedPassword mocks base method.
+func (m *MockSecretRepository) ReplaceEncryptedPassword(ctx context.Context, id, oldValue, newValue string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceEncryptedPassword", ctx, id, oldValue, newValue)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplaceEncryptedPassword indicates an expected call of ReplaceEncryptedPassword.
+func (mr *MockSecretRepositoryMockRecorder) ReplaceEncryptedPassword(ctx, id, oldValue, newValue any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceEncryptedPassword", reflect.TypeOf((*MockSecretRepository)(nil).ReplaceEncryptedPassword), ctx, id, oldValue, newValue)
+}
+
+// Reseal mocks base method.
+func (m *MockSecretRepository) Reseal(ctx context.Context, secret *domain.Secret, oldPayload string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reseal", ctx, secret, oldPayload)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reseal indicates an expected call of Reseal.
+func (mr *MockSecretRepositoryMockRecorder) Reseal(ctx, secret, oldPayload any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reseal", reflect.TypeOf((*MockSecretRepository)(nil).Reseal), ctx, secret, oldPayload)
+}
+
+// Restore mocks base method.
+func (m *MockSecretRepository) Restore(ctx context.Context, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockSecretRepositoryMockRecorder) Restore(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockSecretRepository)(nil).Restore), ctx, id)
+}
+
+// SetFavorite mocks base method.
+func (m *MockSecretRepository) SetFavorite(ctx context.Context, id string, favorite bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFavorite", ctx, id, favorite)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetFavorite indicates an expected call of SetFavorite.
+func (mr *MockSecretRepositoryMockRecorder) SetFavorite(ctx, id, favorite any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFavorite", reflect.TypeOf((*MockSecretRepository)(nil).SetFavorite), ctx, id, favorite)
+}
+
+// Trash mocks base method.
+func (m *MockSecretRepository) Trash(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trash", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Trash indicates an expected call of Trash.
+func (mr *MockSecretRepositoryMockRecorder) Trash(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trash", reflect.TypeOf((*MockSecretRepository)(nil).Trash), ctx, id)
+}
+
 // Update mocks base method.
 func (m *MockSecretRepository) Update(ctx context.Context, secret *domain.Secret) error {
 	m.ctrl.T.Helper()
@@ -151,6 +375,21 @@ func (mr *MockSecretUsecaseMockRecorder) CreateSecret(ctx, secret any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSecret", reflect.TypeOf((*MockSecretUsecase)(nil).CreateSecret), ctx, secret)
 }
 
+// DecodeTOTPImage mocks base method.
+func (m *MockSecretUsecase) DecodeTOTPImage(image io.Reader) (*domain.TOTPKeyInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecodeTOTPImage", image)
+	ret0, _ := ret[0].(*domain.TOTPKeyInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecodeTOTPImage indicates an expected call of DecodeTOTPImage.
+func (mr *MockSecretUsecaseMockRecorder) DecodeTOTPImage(image any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecodeTOTPImage", reflect.TypeOf((*MockSecretUsecase)(nil).DecodeTOTPImage), image)
+}
+
 // DeleteSecret mocks base method.
 func (m *MockSecretUsecase) DeleteSecret(ctx context.Context, id, userID string) error {
 	m.ctrl.T.Helper()
@@ -180,19 +419,124 @@ func (mr *MockSecretUsecaseMockRecorder) GetSecret(ctx, id, userID any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSecret", reflect.TypeOf((*MockSecretUsecase)(nil).GetSecret), ctx, id, userID)
 }
 
+// GetTOTP mocks base method.
+func (m *MockSecretUsecase) GetTOTP(ctx context.Context, id, userID string) (*domain.TOTPCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTOTP", ctx, id, userID)
+	ret0, _ := ret[0].(*domain.TOTPCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTOTP indicates an expected call of GetTOTP.
+func (mr *MockSecretUsecaseMockRecorder) GetTOTP(ctx, id, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTOTP", reflect.TypeOf((*MockSecretUsecase)(nil).GetTOTP), ctx, id, userID)
+}
+
+// GetVersion mocks base method.
+func (m *MockSecretUsecase) GetVersion(ctx context.Context, id, userID string, version int) (*domain.SecretVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVersion", ctx, id, userID, version)
+	ret0, _ := ret[0].(*domain.SecretVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVersion indicates an expected call of GetVersion.
+func (mr *MockSecretUsecaseMockRecorder) GetVersion(ctx, id, userID, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVersion", reflect.TypeOf((*MockSecretUsecase)(nil).GetVersion), ctx, id, userID, version)
+}
+
+// ListHistory mocks base method.
+func (m *MockSecretUsecase) ListHistory(ctx context.Context, id, userID string) ([]*domain.SecretVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListHistory", ctx, id, userID)
+	ret0, _ := ret[0].([]*domain.SecretVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListHistory indicates an expected call of ListHistory.
+func (mr *MockSecretUsecaseMockRecorder) ListHistory(ctx, id, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHistory", reflect.TypeOf((*MockSecretUsecase)(nil).ListHistory), ctx, id, userID)
+}
+
 // ListSecrets mocks base method.
-func (m *MockSecretUsecase) ListSecrets(ctx context.Context, userID string) ([]*domain.Secret, error) {
+func (m *MockSecretUsecase) ListSecrets(ctx context.Context, userID string, filter domain.SecretFilter, page domain.PageRequest) (*domain.SecretPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListSecrets", ctx, userID)
-	ret0, _ := ret[0].([]*domain.Secret)
+	ret := m.ctrl.Call(m, "ListSecrets", ctx, userID, filter, page)
+	ret0, _ := ret[0].(*domain.SecretPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListSecrets indicates an expected call of ListSecrets.
-func (mr *MockSecretUsecaseMockRecorder) ListSecrets(ctx, userID any) *gomock.Call {
+func (mr *MockSecretUsecaseMockRecorder) ListSecrets(ctx, userID, filter, page any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSecrets", reflect.TypeOf((*MockSecretUsecase)(nil).ListSecrets), ctx, userID, filter, page)
+}
+
+// PatchSecret mocks base method.
+func (m *MockSecretUsecase) PatchSecret(ctx context.Context, id, userID string, version int, patch map[string]any) (*domain.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchSecret", ctx, id, userID, version, patch)
+	ret0, _ := ret[0].(*domain.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchSecret indicates an expected call of PatchSecret.
+func (mr *MockSecretUsecaseMockRecorder) PatchSecret(ctx, id, userID, version, patch any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchSecret", reflect.TypeOf((*MockSecretUsecase)(nil).PatchSecret), ctx, id, userID, version, patch)
+}
+
+// RestoreVersion mocks base method.
+func (m *MockSecretUsecase) RestoreVersion(ctx context.Context, id, userID string, version int) (*domain.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreVersion", ctx, id, userID, version)
+	ret0, _ := ret[0].(*domain.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreVersion indicates an expected call of RestoreVersion.
+func (mr *MockSecretUsecaseMockRecorder) RestoreVersion(ctx, id, userID, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreVersion", reflect.TypeOf((*MockSecretUsecase)(nil).RestoreVersion), ctx, id, userID, version)
+}
+
+// RotatePassword mocks base method.
+func (m *MockSecretUsecase) RotatePassword(ctx context.Context, id, userID string) (*domain.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotatePassword", ctx, id, userID)
+	ret0, _ := ret[0].(*domain.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotatePassword indicates an expected call of RotatePassword.
+func (mr *MockSecretUsecaseMockRecorder) RotatePassword(ctx, id, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotatePassword", reflect.TypeOf((*MockSecretUsecase)(nil).RotatePassword), ctx, id, userID)
+}
+
+// SetFavorite mocks base method.
+func (m *MockSecretUsecase) SetFavorite(ctx context.Context, id, userID string, favorite bool) (*domain.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFavorite", ctx, id, userID, favorite)
+	ret0, _ := ret[0].(*domain.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetFavorite indicates an expected call of SetFavorite.
+func (mr *MockSecretUsecaseMockRecorder) SetFavorite(ctx, id, userID, favorite any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSecrets", reflect.TypeOf((*MockSecretUsecase)(nil).ListSecrets), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFavorite", reflect.TypeOf((*MockSecretUsecase)(nil).SetFavorite), ctx, id, userID, favorite)
 }
 
 // UpdateSecret mocks base method.
@@ -0,0 +1,129 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/secret_version.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/secret_version.go -destination=internal/mocks/mock_secret_version_repo.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/herdiagusthio/password-manager/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSecretVersionRepository is a mock of SecretVersionRepository interface.
+type MockSecretVersionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSecretVersionRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockSecretVersionRepositoryMockRecorder is the mock recorder for MockSecretVersionRepository.
+type MockSecretVersionRepositoryMockRecorder struct {
+	mock *MockSecretVersionRepository
+}
+
+// NewMockSecretVersionRepository creates a new mock instance.
+func NewMockSecretVersionRepository(ctrl *gomock.Controller) *MockSecretVersionRepository {
+	mock := &MockSecretVersionRepository{ctrl: ctrl}
+	mock.recorder = &MockSecretVersionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSecretVersionRepository) EXPECT() *MockSecretVersionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockSecretVersionRepository) Create(ctx context.Context, version *domain.SecretVersion) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockSecretVersionRepositoryMockRecorder) Create(ctx, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSecretVersionRepository)(nil).Create), ctx, version)
+}
+
+// GetByVersion mocks base method.
+func (m *MockSecretVersionRepository) GetByVersion(ctx context.Context, secretID string, version int) (*domain.SecretVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByVersion", ctx, secretID, version)
+	ret0, _ := ret[0].(*domain.SecretVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByVersion indicates an expected call of GetByVersion.
+func (mr *MockSecretVersionRepositoryMockRecorder) GetByVersion(ctx, secretID, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByVersion", reflect.TypeOf((*MockSecretVersionRepository)(nil).GetByVersion), ctx, secretID, version)
+}
+
+// ListBySecretID mocks base method.
+func (m *MockSecretVersionRepository) ListBySecretID(ctx context.Context, secretID string) ([]*domain.SecretVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBySecretID", ctx, secretID)
+	ret0, _ := ret[0].([]*domain.SecretVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBySecretID indicates an expected call of ListBySecretID.
+func (mr *MockSecretVersionRepositoryMockRecorder) ListBySecretID(ctx, secretID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBySecretID", reflect.TypeOf((*MockSecretVersionRepository)(nil).ListBySecretID), ctx, secretID)
+}
+
+// ListByUserID mocks base method.
+func (m *MockSecretVersionRepository) ListByUserID(ctx context.Context, userID string) ([]*domain.SecretVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*domain.SecretVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByUserID indicates an expected call of ListByUserID.
+func (mr *MockSecretVersionRepositoryMockRecorder) ListByUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUserID", reflect.TypeOf((*MockSecretVersionRepository)(nil).ListByUserID), ctx, userID)
+}
+
+// Prune mocks base method.
+func (m *MockSecretVersionRepository) Prune(ctx context.Context, userID string, keep int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Prune", ctx, userID, keep)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Prune indicates an expected call of Prune.
+func (mr *MockSecretVersionRepositoryMockRecorder) Prune(ctx, userID, keep any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prune", reflect.TypeOf((*MockSecretVersionRepository)(nil).Prune), ctx, userID, keep)
+}
+
+// Reseal mocks base method.
+func (m *MockSecretVersionRepository) Reseal(ctx context.Context, version *domain.SecretVersion) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reseal", ctx, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reseal indicates an expected call of Reseal.
+func (mr *MockSecretVersionRepositoryMockRecorder) Reseal(ctx, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reseal", reflect.TypeOf((*MockSecretVersionRepository)(nil).Reseal), ctx, version)
+}
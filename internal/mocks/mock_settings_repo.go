@@ -0,0 +1,124 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/settings.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/settings.go -destination=internal/mocks/mock_settings_repo.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/herdiagusthio/password-manager/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSettingsRepository is a mock of SettingsRepository interface.
+type MockSettingsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSettingsRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockSettingsRepositoryMockRecorder is the mock recorder for MockSettingsRepository.
+type MockSettingsRepositoryMockRecorder struct {
+	mock *MockSettingsRepository
+}
+
+// NewMockSettingsRepository creates a new mock instance.
+func NewMockSettingsRepository(ctrl *gomock.Controller) *MockSettingsRepository {
+	mock := &MockSettingsRepository{ctrl: ctrl}
+	mock.recorder = &MockSettingsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSettingsRepository) EXPECT() *MockSettingsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockSettingsRepository) Get(ctx context.Context, userID string) (*domain.UserSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, userID)
+	ret0, _ := ret[0].(*domain.UserSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockSettingsRepositoryMockRecorder) Get(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSettingsRepository)(nil).Get), ctx, userID)
+}
+
+// Upsert mocks base method.
+func (m *MockSettingsRepository) Upsert(ctx context.Context, settings *domain.UserSettings) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, settings)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockSettingsRepositoryMockRecorder) Upsert(ctx, settings any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockSettingsRepository)(nil).Upsert), ctx, settings)
+}
+
+// MockSettingsUsecase is a mock of SettingsUsecase interface.
+type MockSettingsUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockSettingsUsecaseMockRecorder
+	isgomock struct{}
+}
+
+// MockSettingsUsecaseMockRecorder is the mock recorder for MockSettingsUsecase.
+type MockSettingsUsecaseMockRecorder struct {
+	mock *MockSettingsUsecase
+}
+
+// NewMockSettingsUsecase creates a new mock instance.
+func NewMockSettingsUsecase(ctrl *gomock.Controller) *MockSettingsUsecase {
+	mock := &MockSettingsUsecase{ctrl: ctrl}
+	mock.recorder = &MockSettingsUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSettingsUsecase) EXPECT() *MockSettingsUsecaseMockRecorder {
+	return m.recorder
+}
+
+// GetSettings mocks base method.
+func (m *MockSettingsUsecase) GetSettings(ctx context.Context, userID string) (*domain.UserSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSettings", ctx, userID)
+	ret0, _ := ret[0].(*domain.UserSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSettings indicates an expected call of GetSettings.
+func (mr *MockSettingsUsecaseMockRecorder) GetSettings(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSettings", reflect.TypeOf((*MockSettingsUsecase)(nil).GetSettings), ctx, userID)
+}
+
+// UpdateSettings mocks base method.
+func (m *MockSettingsUsecase) UpdateSettings(ctx context.Context, settings *domain.UserSettings) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSettings", ctx, settings)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSettings indicates an expected call of UpdateSettings.
+func (mr *MockSettingsUsecaseMockRecorder) UpdateSettings(ctx, settings any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSettings", reflect.TypeOf((*MockSettingsUsecase)(nil).UpdateSettings), ctx, settings)
+}
@@ -0,0 +1,213 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/tag.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/tag.go -destination=internal/mocks/mock_tag_repo.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/herdiagusthio/password-manager/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTagRepository is a mock of TagRepository interface.
+type MockTagRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTagRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockTagRepositoryMockRecorder is the mock recorder for MockTagRepository.
+type MockTagRepositoryMockRecorder struct {
+	mock *MockTagRepository
+}
+
+// NewMockTagRepository creates a new mock instance.
+func NewMockTagRepository(ctrl *gomock.Controller) *MockTagRepository {
+	mock := &MockTagRepository{ctrl: ctrl}
+	mock.recorder = &MockTagRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTagRepository) EXPECT() *MockTagRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddToSecret mocks base method.
+func (m *MockTagRepository) AddToSecret(ctx context.Context, userID, secretID string, names []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddToSecret", ctx, userID, secretID, names)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddToSecret indicates an expected call of AddToSecret.
+func (mr *MockTagRepositoryMockRecorder) AddToSecret(ctx, userID, secretID, names any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddToSecret", reflect.TypeOf((*MockTagRepository)(nil).AddToSecret), ctx, userID, secretID, names)
+}
+
+// Delete mocks base method.
+func (m *MockTagRepository) Delete(ctx context.Context, userID, name string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, userID, name)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockTagRepositoryMockRecorder) Delete(ctx, userID, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTagRepository)(nil).Delete), ctx, userID, name)
+}
+
+// ListByUserID mocks base method.
+func (m *MockTagRepository) ListByUserID(ctx context.Context, userID string) ([]*domain.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*domain.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByUserID indicates an expected call of ListByUserID.
+func (mr *MockTagRepositoryMockRecorder) ListByUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUserID", reflect.TypeOf((*MockTagRepository)(nil).ListByUserID), ctx, userID)
+}
+
+// RemoveFromSecret mocks base method.
+func (m *MockTagRepository) RemoveFromSecret(ctx context.Context, userID, secretID, name string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveFromSecret", ctx, userID, secretID, name)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveFromSecret indicates an expected call of RemoveFromSecret.
+func (mr *MockTagRepositoryMockRecorder) RemoveFromSecret(ctx, userID, secretID, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFromSecret", reflect.TypeOf((*MockTagRepository)(nil).RemoveFromSecret), ctx, userID, secretID, name)
+}
+
+// Rename mocks base method.
+func (m *MockTagRepository) Rename(ctx context.Context, userID, from, to string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rename", ctx, userID, from, to)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rename indicates an expected call of Rename.
+func (mr *MockTagRepositoryMockRecorder) Rename(ctx, userID, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rename", reflect.TypeOf((*MockTagRepository)(nil).Rename), ctx, userID, from, to)
+}
+
+// MockTagUsecase is a mock of TagUsecase interface.
+type MockTagUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockTagUsecaseMockRecorder
+	isgomock struct{}
+}
+
+// MockTagUsecaseMockRecorder is the mock recorder for MockTagUsecase.
+type MockTagUsecaseMockRecorder struct {
+	mock *MockTagUsecase
+}
+
+// NewMockTagUsecase creates a new mock instance.
+func NewMockTagUsecase(ctrl *gomock.Controller) *MockTagUsecase {
+	mock := &MockTagUsecase{ctrl: ctrl}
+	mock.recorder = &MockTagUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTagUsecase) EXPECT() *MockTagUsecaseMockRecorder {
+	return m.recorder
+}
+
+// AddTags mocks base method.
+func (m *MockTagUsecase) AddTags(ctx context.Context, userID, secretID string, names []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTags", ctx, userID, secretID, names)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddTags indicates an expected call of AddTags.
+func (mr *MockTagUsecaseMockRecorder) AddTags(ctx, userID, secretID, names any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTags", reflect.TypeOf((*MockTagUsecase)(nil).AddTags), ctx, userID, secretID, names)
+}
+
+// DeleteTag mocks base method.
+func (m *MockTagUsecase) DeleteTag(ctx context.Context, userID, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTag", ctx, userID, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTag indicates an expected call of DeleteTag.
+func (mr *MockTagUsecaseMockRecorder) DeleteTag(ctx, userID, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTag", reflect.TypeOf((*MockTagUsecase)(nil).DeleteTag), ctx, userID, name)
+}
+
+// ListTags mocks base method.
+func (m *MockTagUsecase) ListTags(ctx context.Context, userID string) ([]*domain.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTags", ctx, userID)
+	ret0, _ := ret[0].([]*domain.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTags indicates an expected call of ListTags.
+func (mr *MockTagUsecaseMockRecorder) ListTags(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTags", reflect.TypeOf((*MockTagUsecase)(nil).ListTags), ctx, userID)
+}
+
+// RemoveTag mocks base method.
+func (m *MockTagUsecase) RemoveTag(ctx context.Context, userID, secretID, name string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTag", ctx, userID, secretID, name)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveTag indicates an expected call of RemoveTag.
+func (mr *MockTagUsecaseMockRecorder) RemoveTag(ctx, userID, secretID, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTag", reflect.TypeOf((*MockTagUsecase)(nil).RemoveTag), ctx, userID, secretID, name)
+}
+
+// RenameTag mocks base method.
+func (m *MockTagUsecase) RenameTag(ctx context.Context, userID, from, to string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameTag", ctx, userID, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameTag indicates an expected call of RenameTag.
+func (mr *MockTagUsecaseMockRecorder) RenameTag(ctx, userID, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameTag", reflect.TypeOf((*MockTagUsecase)(nil).RenameTag), ctx, userID, from, to)
+}
@@ -0,0 +1,211 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/domain/vault.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/domain/vault.go -destination=internal/mocks/mock_vault_repo.go -package=mocks
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/herdiagusthio/password-manager/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockVaultRepository is a mock of VaultRepository interface.
+type MockVaultRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockVaultRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockVaultRepositoryMockRecorder is the mock recorder for MockVaultRepository.
+type MockVaultRepositoryMockRecorder struct {
+	mock *MockVaultRepository
+}
+
+// NewMockVaultRepository creates a new mock instance.
+func NewMockVaultRepository(ctrl *gomock.Controller) *MockVaultRepository {
+	mock := &MockVaultRepository{ctrl: ctrl}
+	mock.recorder = &MockVaultRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVaultRepository) EXPECT() *MockVaultRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetByUserID mocks base method.
+func (m *MockVaultRepository) GetByUserID(ctx context.Context, userID string) (*domain.Vault, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUserID", ctx, userID)
+	ret0, _ := ret[0].(*domain.Vault)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUserID indicates an expected call of GetByUserID.
+func (mr *MockVaultRepositoryMockRecorder) GetByUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockVaultRepository)(nil).GetByUserID), ctx, userID)
+}
+
+// Save mocks base method.
+func (m *MockVaultRepository) Save(ctx context.Context, vault *domain.Vault) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, vault)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockVaultRepositoryMockRecorder) Save(ctx, vault any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockVaultRepository)(nil).Save), ctx, vault)
+}
+
+// MockVaultUsecase is a mock of VaultUsecase interface.
+type MockVaultUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockVaultUsecaseMockRecorder
+	isgomock struct{}
+}
+
+// MockVaultUsecaseMockRecorder is the mock recorder for MockVaultUsecase.
+type MockVaultUsecaseMockRecorder struct {
+	mock *MockVaultUsecase
+}
+
+// NewMockVaultUsecase creates a new mock instance.
+func NewMockVaultUsecase(ctrl *gomock.Controller) *MockVaultUsecase {
+	mock := &MockVaultUsecase{ctrl: ctrl}
+	mock.recorder = &MockVaultUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVaultUsecase) EXPECT() *MockVaultUsecaseMockRecorder {
+	return m.recorder
+}
+
+// ChangeMasterPassword mocks base method.
+func (m *MockVaultUsecase) ChangeMasterPassword(ctx context.Context, userID, currentAuthKey string, setup *domain.VaultSetup) (*domain.Vault, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeMasterPassword", ctx, userID, currentAuthKey, setup)
+	ret0, _ := ret[0].(*domain.Vault)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeMasterPassword indicates an expected call of ChangeMasterPassword.
+func (mr *MockVaultUsecaseMockRecorder) ChangeMasterPassword(ctx, userID, currentAuthKey, setup any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeMasterPassword", reflect.TypeOf((*MockVaultUsecase)(nil).ChangeMasterPassword), ctx, userID, currentAuthKey, setup)
+}
+
+// EnableZeroKnowledge mocks base method.
+func (m *MockVaultUsecase) EnableZeroKnowledge(ctx context.Context, userID string, setup *domain.VaultSetup) (*domain.Vault, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableZeroKnowledge", ctx, userID, setup)
+	ret0, _ := ret[0].(*domain.Vault)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableZeroKnowledge indicates an expected call of EnableZeroKnowledge.
+func (mr *MockVaultUsecaseMockRecorder) EnableZeroKnowledge(ctx, userID, setup any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableZeroKnowledge", reflect.TypeOf((*MockVaultUsecase)(nil).EnableZeroKnowledge), ctx, userID, setup)
+}
+
+// GetLockPolicy mocks base method.
+func (m *MockVaultUsecase) GetLockPolicy(ctx context.Context, userID string) (*domain.LockPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLockPolicy", ctx, userID)
+	ret0, _ := ret[0].(*domain.LockPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLockPolicy indicates an expected call of GetLockPolicy.
+func (mr *MockVaultUsecaseMockRecorder) GetLockPolicy(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLockPolicy", reflect.TypeOf((*MockVaultUsecase)(nil).GetLockPolicy), ctx, userID)
+}
+
+// GetVault mocks base method.
+func (m *MockVaultUsecase) GetVault(ctx context.Context, userID string) (*domain.Vault, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVault", ctx, userID)
+	ret0, _ := ret[0].(*domain.Vault)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVault indicates an expected call of GetVault.
+func (mr *MockVaultUsecaseMockRecorder) GetVault(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVault", reflect.TypeOf((*MockVaultUsecase)(nil).GetVault), ctx, userID)
+}
+
+// RemovePIN mocks base method.
+func (m *MockVaultUsecase) RemovePIN(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovePIN", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemovePIN indicates an expected call of RemovePIN.
+func (mr *MockVaultUsecaseMockRecorder) RemovePIN(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePIN", reflect.TypeOf((*MockVaultUsecase)(nil).RemovePIN), ctx, userID)
+}
+
+// SetPIN mocks base method.
+func (m *MockVaultUsecase) SetPIN(ctx context.Context, userID, pin string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPIN", ctx, userID, pin)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPIN indicates an expected call of SetPIN.
+func (mr *MockVaultUsecaseMockRecorder) SetPIN(ctx, userID, pin any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPIN", reflect.TypeOf((*MockVaultUsecase)(nil).SetPIN), ctx, userID, pin)
+}
+
+// Unlock mocks base method.
+func (m *MockVaultUsecase) Unlock(ctx context.Context, userID string, req *domain.UnlockRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unlock", ctx, userID, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unlock indicates an expected call of Unlock.
+func (mr *MockVaultUsecaseMockRecorder) Unlock(ctx, userID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unlock", reflect.TypeOf((*MockVaultUsecase)(nil).Unlock), ctx, userID, req)
+}
+
+// VerifyMasterPassword mocks base method.
+func (m *MockVaultUsecase) VerifyMasterPassword(ctx context.Context, userID, authKey string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyMasterPassword", ctx, userID, authKey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyMasterPassword indicates an expected call of VerifyMasterPassword.
+func (mr *MockVaultUsecaseMockRecorder) VerifyMasterPassword(ctx, userID, authKey any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyMasterPassword", reflect.TypeOf((*MockVaultUsecase)(nil).VerifyMasterPassword), ctx, userID, authKey)
+}
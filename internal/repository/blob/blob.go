@@ -0,0 +1,27 @@
+// Package blob implements domain.BlobStore on the local filesystem, on
+// S3-compatible object storage and in memory.
+package blob
+
+import (
+	"fmt"
+	"strings"
+)
+
+// checkKey rejects keys that could escape the store's root, such as
+// "../etc/passwd" or "/tmp/x".
+func checkKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("blob: invalid key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("blob: invalid key %q", key)
+		}
+	}
+	return nil
+}
+
+// sizeError reports content that does not match the announced size.
+func sizeError(key string, expected, actual int64) error {
+	return fmt.Errorf("blob: %s: expected %d bytes, got %d", key, expected, actual)
+}
@@ -0,0 +1,19 @@
+package blob_test
+
+import (
+	"testing"
+
+	"github.com/herdiagusthio/password-manager/internal/repository/blob"
+	"github.com/herdiagusthio/password-manager/internal/repository/blob/blobtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore(t *testing.T) {
+	store, err := blob.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	blobtest.Run(t, store)
+}
+
+func TestMemoryStore(t *testing.T) {
+	blobtest.Run(t, blobtest.NewStore())
+}
@@ -0,0 +1,148 @@
+// Package blobtest provides an in-memory domain.BlobStore and a test suite
+// every BlobStore implementation must pass.
+package blobtest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Store keeps blobs in memory.
+type Store struct {
+	mu    sync.RWMutex
+	blobs map[string][]byte
+}
+
+func NewStore() *Store {
+	return &Store{blobs: map[string][]byte{}}
+}
+
+func (s *Store) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	if key == "" || strings.Contains(key, "..") {
+		return fmt.Errorf("blobtest: invalid key %q", key)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, size+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) != size {
+		return fmt.Errorf("blobtest: %s: expected %d bytes, got %d", key, size, len(data))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blobs[key] = data
+	return nil
+}
+
+func (s *Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.blobs[key]
+	if !ok {
+		return nil, domain.ErrBlobNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *Store) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.blobs, key)
+	return nil
+}
+
+// Bytes returns the blob stored under key, or nil. Changing it changes the
+// stored blob, which lets tests tamper with ciphertext.
+func (s *Store) Bytes(key string) []byte {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.blobs[key]
+}
+
+// Len returns the number of blobs stored.
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.blobs)
+}
+
+// failingReader returns its data and then an error, like an upload cut off
+// halfway.
+type failingReader struct {
+	data []byte
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, errors.New("connection reset")
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+// Run checks that store behaves like a domain.BlobStore.
+func Run(t *testing.T, store domain.BlobStore) {
+	ctx := context.Background()
+	read := func(t *testing.T, key string) []byte {
+		t.Helper()
+		r, err := store.Get(ctx, key)
+		require.NoError(t, err)
+		defer r.Close()
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return data
+	}
+
+	t.Run("Put And Get", func(t *testing.T) {
+		data := bytes.Repeat([]byte("attachment"), 10000)
+		require.NoError(t, store.Put(ctx, "user-1/a", bytes.NewReader(data), int64(len(data))))
+		assert.Equal(t, data, read(t, "user-1/a"))
+
+		// Putting again replaces the blob
+		require.NoError(t, store.Put(ctx, "user-1/a", strings.NewReader("v2"), 2))
+		assert.Equal(t, []byte("v2"), read(t, "user-1/a"))
+	})
+
+	t.Run("Empty Blob", func(t *testing.T) {
+		require.NoError(t, store.Put(ctx, "user-1/empty", bytes.NewReader(nil), 0))
+		assert.Empty(t, read(t, "user-1/empty"))
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		_, err := store.Get(ctx, "user-1/missing")
+		assert.ErrorIs(t, err, domain.ErrBlobNotFound)
+		assert.NoError(t, store.Delete(ctx, "user-1/missing"))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		require.NoError(t, store.Put(ctx, "user-1/b", strings.NewReader("bye"), 3))
+		require.NoError(t, store.Delete(ctx, "user-1/b"))
+		_, err := store.Get(ctx, "user-1/b")
+		assert.ErrorIs(t, err, domain.ErrBlobNotFound)
+	})
+
+	t.Run("Failed Put Stores Nothing", func(t *testing.T) {
+		assert.Error(t, store.Put(ctx, "user-1/short", strings.NewReader("abc"), 10))
+		assert.Error(t, store.Put(ctx, "user-1/cut", &failingReader{data: []byte("partial")}, 10))
+		for _, key := range []string{"user-1/short", "user-1/cut"} {
+			_, err := store.Get(ctx, key)
+			assert.ErrorIs(t, err, domain.ErrBlobNotFound, key)
+		}
+	})
+
+	t.Run("Invalid Key", func(t *testing.T) {
+		assert.Error(t, store.Put(ctx, "../escape", strings.NewReader("x"), 1))
+	})
+}
@@ -0,0 +1,112 @@
+package blob
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config locates a bucket on S3 or a compatible service such as MinIO.
+type S3Config struct {
+	// Endpoint is a host[:port], e.g. "s3.amazonaws.com" or "localhost:9000".
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// UseSSL selects https.
+	UseSSL bool
+	// Prefix is prepended to every key, so the bucket can be shared.
+	Prefix string
+}
+
+type s3Store struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+// NewS3Store keeps blobs as objects in a bucket, which is created if it does
+// not exist.
+func NewS3Store(ctx context.Context, cfg S3Config) (domain.BlobStore, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("blob: %w", err)
+	}
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("blob: bucket %s: %w", cfg.Bucket, err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+			return nil, fmt.Errorf("blob: create bucket %s: %w", cfg.Bucket, err)
+		}
+	}
+	return &s3Store{client: client, bucket: cfg.Bucket, prefix: cfg.Prefix}, nil
+}
+
+func (s *s3Store) object(key string) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	return s.prefix + key, nil
+}
+
+func (s *s3Store) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	object, err := s.object(key)
+	if err != nil {
+		return err
+	}
+	// Objects only appear once fully uploaded, and the size is checked
+	// against the content length
+	_, err = s.client.PutObject(ctx, s.bucket, object, r, size, minio.PutObjectOptions{
+		ContentType: "application/octet-stream",
+	})
+	if err != nil {
+		return fmt.Errorf("blob: write %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *s3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	object, err := s.object(key)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := s.client.GetObject(ctx, s.bucket, object, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("blob: %w", err)
+	}
+	// GetObject is lazy; Stat makes a missing object show up here and not
+	// on the first read
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		if minio.ToErrorResponse(err).StatusCode == http.StatusNotFound {
+			return nil, domain.ErrBlobNotFound
+		}
+		return nil, fmt.Errorf("blob: read %s: %w", key, err)
+	}
+	return obj, nil
+}
+
+func (s *s3Store) Delete(ctx context.Context, key string) error {
+	object, err := s.object(key)
+	if err != nil {
+		return err
+	}
+	// Removing a missing object succeeds
+	if err := s.client.RemoveObject(ctx, s.bucket, object, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("blob: delete %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// attachmentColumns is the column list read by scanAttachment. Orphans have no
+// secret_id; it reads as "".
+const attachmentColumns = `id, COALESCE(secret_id::text, ''), user_id, name, content_type, size, wrapped_key, client_encrypted, created_at`
+
+type attachmentRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewAttachmentRepository(db *pgxpool.Pool) domain.AttachmentRepository {
+	return &attachmentRepo{
+		db: db,
+	}
+}
+
+func scanAttachment(row pgx.Row) (*domain.Attachment, error) {
+	var a domain.Attachment
+	if err := row.Scan(&a.ID, &a.SecretID, &a.UserID, &a.Name, &a.ContentType, &a.Size, &a.WrappedKey, &a.ClientEncrypted, &a.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *attachmentRepo) Create(ctx context.Context, attachment *domain.Attachment, quota int64) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("attachmentRepo.Create: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Uploads of one user are checked against the quota one at a time, so
+	// concurrent uploads cannot both fit in the same remaining space.
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('attachments:' || $1::text))`, attachment.UserID); err != nil {
+		return false, fmt.Errorf("attachmentRepo.Create lock: %w", err)
+	}
+
+	query := `
+		INSERT INTO attachments (id, secret_id, user_id, name, content_type, size, wrapped_key, client_encrypted)
+		SELECT $1::uuid, $2::uuid, $3::uuid, $4::text, $5::text, $6::bigint, $7::text, $8::boolean
+		WHERE $9::bigint = 0
+		   OR (SELECT COALESCE(SUM(size), 0) FROM attachments WHERE user_id = $3::uuid AND secret_id IS NOT NULL) + $6::bigint <= $9::bigint
+		RETURNING created_at
+	`
+	row := tx.QueryRow(ctx, query,
+		attachment.ID,
+		attachment.SecretID,
+		attachment.UserID,
+		attachment.Name,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.WrappedKey,
+		attachment.ClientEncrypted,
+		quota,
+	)
+	if err := row.Scan(&attachment.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("attachmentRepo.Create: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("attachmentRepo.Create commit: %w", err)
+	}
+	return true, nil
+}
+
+func (r *attachmentRepo) GetByID(ctx context.Context, id string) (*domain.Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM attachments WHERE id = $1`
+	a, err := scanAttachment(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("attachmentRepo.GetByID: %w", err)
+	}
+	return a, nil
+}
+
+func (r *attachmentRepo) list(ctx context.Context, method, query string, args ...any) ([]*domain.Attachment, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("attachmentRepo.%s query: %w", method, err)
+	}
+	defer rows.Close()
+
+	var attachments []*domain.Attachment
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("attachmentRepo.%s scan: %w", method, err)
+		}
+		attachments = append(attachments, a)
+	}
+	return attachments, rows.Err()
+}
+
+func (r *attachmentRepo) ListBySecret(ctx context.Context, secretID string) ([]*domain.Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM attachments WHERE secret_id = $1 ORDER BY created_at, id`
+	return r.list(ctx, "ListBySecret", query, secretID)
+}
+
+func (r *attachmentRepo) ListByUserID(ctx context.Context, userID string) ([]*domain.Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM attachments WHERE user_id = $1 AND secret_id IS NOT NULL ORDER BY created_at, id`
+	return r.list(ctx, "ListByUserID", query, userID)
+}
+
+func (r *attachmentRepo) Delete(ctx context.Context, id string) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM attachments WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("attachmentRepo.Delete: %w", err)
+	}
+	return nil
+}
+
+func (r *attachmentRepo) Usage(ctx context.Context, userID string) (int64, error) {
+	// Orphans are on their way out and no longer count
+	query := `SELECT COALESCE(SUM(size), 0) FROM attachments WHERE user_id = $1 AND secret_id IS NOT NULL`
+	var used int64
+	if err := r.db.QueryRow(ctx, query, userID).Scan(&used); err != nil {
+		return 0, fmt.Errorf("attachmentRepo.Usage: %w", err)
+	}
+	return used, nil
+}
+
+func (r *attachmentRepo) ListOrphans(ctx context.Context, limit int) ([]*domain.Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM attachments WHERE secret_id IS NULL ORDER BY created_at, id LIMIT $1`
+	return r.list(ctx, "ListOrphans", query, limit)
+}
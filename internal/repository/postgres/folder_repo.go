@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// folderColumns is the column list read by scanFolder.
+const folderColumns = `id, user_id, parent_id, name, created_at, updated_at`
+
+type folderRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewFolderRepository(db *pgxpool.Pool) domain.FolderRepository {
+	return &folderRepo{
+		db: db,
+	}
+}
+
+func scanFolder(row pgx.Row) (*domain.Folder, error) {
+	var f domain.Folder
+	if err := row.Scan(&f.ID, &f.UserID, &f.ParentID, &f.Name, &f.CreatedAt, &f.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (r *folderRepo) Create(ctx context.Context, folder *domain.Folder) error {
+	query := `
+		INSERT INTO folders (user_id, parent_id, name)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at
+	`
+	row := r.db.QueryRow(ctx, query, folder.UserID, folder.ParentID, folder.Name)
+	if err := row.Scan(&folder.ID, &folder.CreatedAt, &folder.UpdatedAt); err != nil {
+		return fmt.Errorf("folderRepo.Create: %w", err)
+	}
+	return nil
+}
+
+func (r *folderRepo) GetByID(ctx context.Context, id string) (*domain.Folder, error) {
+	query := `SELECT ` + folderColumns + ` FROM folders WHERE id = $1`
+	f, err := scanFolder(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("folderRepo.GetByID: %w", err)
+	}
+	return f, nil
+}
+
+func (r *folderRepo) ListByUserID(ctx context.Context, userID string) ([]*domain.Folder, error) {
+	query := `SELECT ` + folderColumns + ` FROM folders WHERE user_id = $1 ORDER BY LOWER(name), id`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("folderRepo.ListByUserID query: %w", err)
+	}
+	defer rows.Close()
+
+	var folders []*domain.Folder
+	for rows.Next() {
+		f, err := scanFolder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("folderRepo.ListByUserID scan: %w", err)
+		}
+		folders = append(folders, f)
+	}
+	return folders, rows.Err()
+}
+
+func (r *folderRepo) Update(ctx context.Context, folder *domain.Folder) error {
+	query := `
+		UPDATE folders SET parent_id = $2, name = $3, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`
+	row := r.db.QueryRow(ctx, query, folder.ID, folder.ParentID, folder.Name)
+	if err := row.Scan(&folder.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrFolderNotFound
+		}
+		return fmt.Errorf("folderRepo.Update: %w", err)
+	}
+	return nil
+}
+
+func (r *folderRepo) Delete(ctx context.Context, id string) error {
+	// Move the contents up first, or the foreign keys would delete the
+	// subfolders and unfile the secrets.
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		var parentID *string
+		err := tx.QueryRow(ctx, `SELECT parent_id FROM folders WHERE id = $1 FOR UPDATE`, id).Scan(&parentID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil // Already gone
+			}
+			return err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE folders SET parent_id = $2 WHERE parent_id = $1`, id, parentID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE secrets SET folder_id = $2 WHERE folder_id = $1`, id, parentID); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `DELETE FROM folders WHERE id = $1`, id)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("folderRepo.Delete: %w", err)
+	}
+	return nil
+}
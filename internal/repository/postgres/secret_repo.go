@@ -2,14 +2,30 @@ package postgres
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/herdiagusthio/password-manager/internal/domain"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// nilUUID sorts before every generated ID; batch listings start after it.
+const nilUUID = "00000000-0000-0000-0000-000000000000"
+
+// searchText is the plaintext searched by SecretFilter.Query. It must stay in
+// line with the trigram index in the search migration.
+const searchText = `(title || ' ' || username || ' ' || COALESCE(metadata->>'url', '') || ' ' || COALESCE(metadata->>'notes', ''))`
+
+// secretColumns is the column list read by scanSecret.
+const secretColumns = `id, user_id, folder_id, type, title, username, encrypted_password, COALESCE(encrypted_payload, ''), client_encrypted, strength_score, metadata, version, created_at, updated_at, deleted_at, last_used_at, favorite,
+	ARRAY(SELECT t.name FROM secret_tags st JOIN tags t ON t.id = st.tag_id WHERE st.secret_id = secrets.id ORDER BY t.name)`
+
 type secretRepo struct {
 	db *pgxpool.Pool
 }
@@ -20,22 +36,43 @@ func NewSecretRepository(db *pgxpool.Pool) domain.SecretRepository {
 	}
 }
 
+func scanSecret(row pgx.Row) (*domain.Secret, error) {
+	var s domain.Secret
+	err := row.Scan(
+		&s.ID, &s.UserID, &s.FolderID, &s.Type, &s.Title, &s.Username, &s.EncryptedPassword, &s.EncryptedPayload, &s.ClientEncrypted, &s.StrengthScore, &s.Metadata, &s.Version, &s.CreatedAt, &s.UpdatedAt, &s.DeletedAt, &s.LastUsedAt, &s.Favorite, &s.Tags,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
 func (r *secretRepo) Create(ctx context.Context, secret *domain.Secret) error {
+	// The usecase may assign the ID up front (ciphertexts are bound to it);
+	// otherwise the database generates one. New secrets start at version 1;
+	// restored backups keep theirs. Secrets without a type are logins.
 	query := `
-		INSERT INTO secrets (user_id, title, username, encrypted_password, metadata, version)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, created_at, updated_at
+		INSERT INTO secrets (id, user_id, title, username, encrypted_password, encrypted_payload, client_encrypted, strength_score, metadata, version, folder_id, favorite, type)
+		VALUES (COALESCE(NULLIF($1, '')::uuid, gen_random_uuid()), $2, $3, $4, $5, NULLIF($6, ''), $7, $8, $9, GREATEST($10, 1), $11, $12, COALESCE(NULLIF($13, ''), 'login'))
+		RETURNING id, version, created_at, updated_at
 	`
 	row := r.db.QueryRow(ctx, query,
+		secret.ID,
 		secret.UserID,
 		secret.Title,
 		secret.Username,
 		secret.EncryptedPassword,
+		secret.EncryptedPayload,
+		secret.ClientEncrypted,
+		secret.StrengthScore,
 		secret.Metadata,
 		secret.Version,
+		secret.FolderID,
+		secret.Favorite,
+		secret.Type,
 	)
 
-	err := row.Scan(&secret.ID, &secret.CreatedAt, &secret.UpdatedAt)
+	err := row.Scan(&secret.ID, &secret.Version, &secret.CreatedAt, &secret.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("secretRepo.Create: %w", err)
 	}
@@ -44,74 +81,200 @@ func (r *secretRepo) Create(ctx context.Context, secret *domain.Secret) error {
 
 func (r *secretRepo) GetByID(ctx context.Context, id string) (*domain.Secret, error) {
 	query := `
-		SELECT id, user_id, title, username, encrypted_password, metadata, version, created_at, updated_at
+		SELECT ` + secretColumns + `
 		FROM secrets
 		WHERE id = $1
 	`
-	row := r.db.QueryRow(ctx, query, id)
-
-	var s domain.Secret
-	err := row.Scan(
-		&s.ID, &s.UserID, &s.Title, &s.Username, &s.EncryptedPassword, &s.Metadata, &s.Version, &s.CreatedAt, &s.UpdatedAt,
-	)
+	s, err := scanSecret(r.db.QueryRow(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("secretRepo.GetByID: %w", err)
 	}
-	return &s, nil
+	return s, nil
 }
 
 func (r *secretRepo) ListByUserID(ctx context.Context, userID string) ([]*domain.Secret, error) {
+	return r.ListByFilter(ctx, userID, domain.SecretFilter{})
+}
+
+func (r *secretRepo) ListByFilter(ctx context.Context, userID string, filter domain.SecretFilter) ([]*domain.Secret, error) {
+	conditions, args := filterConditions(userID, filter)
 	query := `
-		SELECT id, user_id, title, username, encrypted_password, metadata, version, created_at, updated_at
+		SELECT ` + secretColumns + `
 		FROM secrets
-		WHERE user_id = $1
+		WHERE ` + strings.Join(conditions, " AND ") + `
 		ORDER BY created_at DESC
 	`
-	rows, err := r.db.Query(ctx, query, userID)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("secretRepo.ListByUserID query: %w", err)
+		return nil, fmt.Errorf("secretRepo.ListByFilter query: %w", err)
 	}
 	defer rows.Close()
 
 	var secrets []*domain.Secret
 	for rows.Next() {
-		var s domain.Secret
-		err := rows.Scan(
-			&s.ID, &s.UserID, &s.Title, &s.Username, &s.EncryptedPassword, &s.Metadata, &s.Version, &s.CreatedAt, &s.UpdatedAt,
-		)
+		s, err := scanSecret(rows)
 		if err != nil {
-			return nil, fmt.Errorf("secretRepo.ListByUserID scan: %w", err)
+			return nil, fmt.Errorf("secretRepo.ListByFilter scan: %w", err)
 		}
-		secrets = append(secrets, &s)
+		secrets = append(secrets, s)
+	}
+	return secrets, rows.Err()
+}
+
+func (r *secretRepo) ListPage(ctx context.Context, userID string, filter domain.SecretFilter, page domain.PageRequest) (*domain.SecretPage, error) {
+	order, ok := secretOrders[page.Sort]
+	if !ok {
+		return nil, domain.ErrInvalidSort
+	}
+	conditions, args := filterConditions(userID, filter)
+
+	result := &domain.SecretPage{}
+	countQuery := `SELECT COUNT(*) FROM secrets WHERE ` + strings.Join(conditions, " AND ")
+	if err := r.db.QueryRow(ctx, countQuery, args...).Scan(&result.Total); err != nil {
+		return nil, fmt.Errorf("secretRepo.ListPage count: %w", err)
+	}
+
+	if page.Cursor != "" {
+		cursor, err := decodeCursor(page.Cursor, page.Sort)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, cursor.key(), cursor.ID)
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s (%s, $%d)",
+			order.expr, order.after(), fmt.Sprintf(order.param, len(args)-1), len(args)))
+	}
+	// One more than asked for tells whether there is a next page
+	args = append(args, page.Limit+1)
+	query := fmt.Sprintf(`
+		SELECT `+secretColumns+`
+		FROM secrets
+		WHERE %s
+		ORDER BY %s %s, id %[3]s
+		LIMIT $%d
+	`, strings.Join(conditions, " AND "), order.expr, order.direction(), len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("secretRepo.ListPage query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		s, err := scanSecret(rows)
+		if err != nil {
+			return nil, fmt.Errorf("secretRepo.ListPage scan: %w", err)
+		}
+		result.Secrets = append(result.Secrets, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("secretRepo.ListPage: %w", err)
 	}
-	return secrets, nil
+
+	if len(result.Secrets) > page.Limit {
+		result.Secrets = result.Secrets[:page.Limit]
+		result.NextCursor = encodeCursor(page.Sort, result.Secrets[page.Limit-1])
+	}
+	return result, nil
+}
+
+// filterConditions returns the WHERE conditions selecting the user's secrets
+// that match filter, with their arguments; $1 is the user ID.
+func filterConditions(userID string, filter domain.SecretFilter) ([]string, []interface{}) {
+	conditions := []string{"user_id = $1", "deleted_at IS NULL"}
+	args := []interface{}{userID}
+
+	switch filter.FolderID {
+	case "":
+	case domain.UnfiledFolderID:
+		conditions = append(conditions, "folder_id IS NULL")
+	default:
+		args = append(args, filter.FolderID)
+		conditions = append(conditions, fmt.Sprintf(`folder_id IN (
+			WITH RECURSIVE tree AS (
+				SELECT id FROM folders WHERE id = $%d AND user_id = $1
+				UNION ALL
+				SELECT f.id FROM folders f JOIN tree t ON f.parent_id = t.id
+			)
+			SELECT id FROM tree
+		)`, len(args)))
+	}
+
+	if filter.Tag != "" {
+		args = append(args, filter.Tag)
+		conditions = append(conditions, fmt.Sprintf(`id IN (
+			SELECT st.secret_id FROM secret_tags st JOIN tags t ON t.id = st.tag_id
+			WHERE t.user_id = $1 AND t.name = $%d
+		)`, len(args)))
+	}
+	if filter.Favorite {
+		conditions = append(conditions, "favorite")
+	}
+	if filter.Type != "" {
+		args = append(args, filter.Type)
+		conditions = append(conditions, fmt.Sprintf("type = $%d", len(args)))
+	}
+
+	for _, word := range strings.Fields(filter.Query) {
+		args = append(args, "%"+likeEscaper.Replace(word)+"%")
+		conditions = append(conditions, fmt.Sprintf("%s ILIKE $%d", searchText, len(args)))
+	}
+	return conditions, args
 }
 
+// likeEscaper makes a search word match literally in a LIKE pattern.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 func (r *secretRepo) Update(ctx context.Context, secret *domain.Secret) error {
 	query := `
 		UPDATE secrets
-		SET title = $1, username = $2, encrypted_password = $3, metadata = $4, version = version + 1, updated_at = NOW()
-		WHERE id = $5
+		SET title = $1, username = $2, encrypted_password = $3, encrypted_payload = NULLIF($4, ''), client_encrypted = $5,
+			strength_score = $6, metadata = $7, type = COALESCE(NULLIF($10, ''), type), version = version + 1, updated_at = NOW()
+		WHERE id = $8 AND version = $9
 		RETURNING version, updated_at
 	`
 	row := r.db.QueryRow(ctx, query,
 		secret.Title,
 		secret.Username,
 		secret.EncryptedPassword,
+		secret.EncryptedPayload,
+		secret.ClientEncrypted,
+		secret.StrengthScore,
 		secret.Metadata, // Metadata is interface{}, pgx handles JSONB mapping
 		secret.ID,
+		secret.Version,
+		secret.Type,
 	)
 
 	err := row.Scan(&secret.Version, &secret.UpdatedAt)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Deleted or updated by someone else since it was read
+			return domain.ErrVersionConflict
+		}
 		return fmt.Errorf("secretRepo.Update: %w", err)
 	}
 	return nil
 }
 
+func (r *secretRepo) Reseal(ctx context.Context, secret *domain.Secret, oldPayload string) (bool, error) {
+	// Moving fields between plaintext and the sealed payload is not a user
+	// edit, so version and updated_at are left alone. An edit or another
+	// reseal since the secret was read changes one of them, and wins.
+	query := `
+		UPDATE secrets
+		SET username = $1, encrypted_payload = NULLIF($2, ''), metadata = $3
+		WHERE id = $4 AND version = $5 AND COALESCE(encrypted_payload, '') = $6
+	`
+	tag, err := r.db.Exec(ctx, query, secret.Username, secret.EncryptedPayload, secret.Metadata, secret.ID, secret.Version, oldPayload)
+	if err != nil {
+		return false, fmt.Errorf("secretRepo.Reseal: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
 func (r *secretRepo) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM secrets WHERE id = $1`
 	_, err := r.db.Exec(ctx, query, id)
@@ -120,3 +283,229 @@ func (r *secretRepo) Delete(ctx context.Context, id string) error {
 	}
 	return nil
 }
+
+func (r *secretRepo) Trash(ctx context.Context, id string) error {
+	// Trashing is not an edit either: version and updated_at stay as they were.
+	query := `UPDATE secrets SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+	_, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("secretRepo.Trash: %w", err)
+	}
+	return nil
+}
+
+func (r *secretRepo) Restore(ctx context.Context, id string) (bool, error) {
+	query := `UPDATE secrets SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`
+	tag, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return false, fmt.Errorf("secretRepo.Restore: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *secretRepo) Purge(ctx context.Context, id string) (bool, error) {
+	// Checked in the same statement, so a concurrent restore cannot be undone.
+	query := `DELETE FROM secrets WHERE id = $1 AND deleted_at IS NOT NULL`
+	tag, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return false, fmt.Errorf("secretRepo.Purge: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *secretRepo) ListTrash(ctx context.Context, userID string) ([]*domain.Secret, error) {
+	query := `
+		SELECT ` + secretColumns + `
+		FROM secrets
+		WHERE user_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+	`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("secretRepo.ListTrash query: %w", err)
+	}
+	defer rows.Close()
+
+	var secrets []*domain.Secret
+	for rows.Next() {
+		s, err := scanSecret(rows)
+		if err != nil {
+			return nil, fmt.Errorf("secretRepo.ListTrash scan: %w", err)
+		}
+		secrets = append(secrets, s)
+	}
+	return secrets, rows.Err()
+}
+
+func (r *secretRepo) EmptyTrash(ctx context.Context, userID string) (int64, error) {
+	query := `DELETE FROM secrets WHERE user_id = $1 AND deleted_at IS NOT NULL`
+	tag, err := r.db.Exec(ctx, query, userID)
+	if err != nil {
+		return 0, fmt.Errorf("secretRepo.EmptyTrash: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *secretRepo) SetFavorite(ctx context.Context, id string, favorite bool) error {
+	query := `UPDATE secrets SET favorite = $2 WHERE id = $1`
+	if _, err := r.db.Exec(ctx, query, id, favorite); err != nil {
+		return fmt.Errorf("secretRepo.SetFavorite: %w", err)
+	}
+	return nil
+}
+
+func (r *secretRepo) MarkUsed(ctx context.Context, id string) error {
+	query := `UPDATE secrets SET last_used_at = NOW() WHERE id = $1`
+	if _, err := r.db.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("secretRepo.MarkUsed: %w", err)
+	}
+	return nil
+}
+
+func (r *secretRepo) MoveToFolder(ctx context.Context, userID string, ids []string, folderID *string) (int64, error) {
+	query := `UPDATE secrets SET folder_id = $3 WHERE user_id = $1 AND id = ANY($2::uuid[])`
+	tag, err := r.db.Exec(ctx, query, userID, ids, folderID)
+	if err != nil {
+		return 0, fmt.Errorf("secretRepo.MoveToFolder: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (r *secretRepo) Count(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM secrets`).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("secretRepo.Count: %w", err)
+	}
+	return count, nil
+}
+
+func (r *secretRepo) ListBatch(ctx context.Context, afterID string, limit int) ([]*domain.Secret, error) {
+	query := `
+		SELECT ` + secretColumns + `
+		FROM secrets
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2
+	`
+	if afterID == "" {
+		afterID = nilUUID
+	}
+	rows, err := r.db.Query(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("secretRepo.ListBatch query: %w", err)
+	}
+	defer rows.Close()
+
+	var secrets []*domain.Secret
+	for rows.Next() {
+		s, err := scanSecret(rows)
+		if err != nil {
+			return nil, fmt.Errorf("secretRepo.ListBatch scan: %w", err)
+		}
+		secrets = append(secrets, s)
+	}
+	return secrets, rows.Err()
+}
+
+func (r *secretRepo) ReplaceEncryptedPassword(ctx context.Context, id, oldValue, newValue string) (bool, error) {
+	// Re-encryption is not a user edit, so version and updated_at are left alone.
+	query := `UPDATE secrets SET encrypted_password = $1 WHERE id = $2 AND encrypted_password = $3`
+	tag, err := r.db.Exec(ctx, query, newValue, id, oldValue)
+	if err != nil {
+		return false, fmt.Errorf("secretRepo.ReplaceEncryptedPassword: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *secretRepo) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM secrets WHERE deleted_at < $1`
+	tag, err := r.db.Exec(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("secretRepo.PurgeTrash: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// secretOrder is how a listing is sorted: by expr, then by id to break ties,
+// so that every secret has a fixed place for keyset pagination.
+type secretOrder struct {
+	expr string
+	// param is expr applied to the cursor's key, with a verb for its position
+	param string
+	desc  bool
+}
+
+func (o secretOrder) direction() string {
+	if o.desc {
+		return "DESC"
+	}
+	return "ASC"
+}
+
+// after is the comparison selecting the rows that come after the cursor.
+func (o secretOrder) after() string {
+	if o.desc {
+		return "<"
+	}
+	return ">"
+}
+
+var secretOrders = map[domain.SecretSort]secretOrder{
+	domain.SortCreated:  {expr: "created_at", param: "$%d::timestamptz", desc: true},
+	domain.SortTitle:    {expr: "lower(title)", param: "lower($%d::text)"},
+	domain.SortUpdated:  {expr: "updated_at", param: "$%d::timestamptz", desc: true},
+	domain.SortLastUsed: {expr: "COALESCE(last_used_at, 'epoch')", param: "COALESCE($%d::timestamptz, 'epoch')", desc: true},
+}
+
+// pageCursor is the position of the last secret of a page: its sort key and
+// ID. The key is the raw column, which the query passes through the order's
+// expression, so the database alone decides how keys compare.
+type pageCursor struct {
+	Sort  domain.SecretSort `json:"s"`
+	Title string            `json:"t,omitempty"`
+	Time  *time.Time        `json:"at,omitempty"`
+	ID    string            `json:"id"`
+}
+
+func (c pageCursor) key() interface{} {
+	if c.Sort == domain.SortTitle {
+		return c.Title
+	}
+	return c.Time
+}
+
+func encodeCursor(sort domain.SecretSort, last *domain.Secret) string {
+	cursor := pageCursor{Sort: sort, ID: last.ID}
+	switch sort {
+	case domain.SortTitle:
+		cursor.Title = last.Title
+	case domain.SortUpdated:
+		cursor.Time = &last.UpdatedAt
+	case domain.SortLastUsed:
+		cursor.Time = last.LastUsedAt
+	default:
+		cursor.Time = &last.CreatedAt
+	}
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string, sort domain.SecretSort) (*pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	var cursor pageCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Sort != sort {
+		return nil, domain.ErrInvalidCursor
+	}
+	if _, err := uuid.Parse(cursor.ID); err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	// Only the last use may be unknown
+	if cursor.Time == nil && sort != domain.SortTitle && sort != domain.SortLastUsed {
+		return nil, domain.ErrInvalidCursor
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// secretVersionColumns is the column list read by scanSecretVersion.
+const secretVersionColumns = `secret_id, version, user_id, title, username, encrypted_password, COALESCE(encrypted_payload, ''), client_encrypted, strength_score, metadata, COALESCE(updated_at, created_at), created_at`
+
+type secretVersionRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewSecretVersionRepository(db *pgxpool.Pool) domain.SecretVersionRepository {
+	return &secretVersionRepo{
+		db: db,
+	}
+}
+
+func scanSecretVersion(row pgx.Row) (*domain.SecretVersion, error) {
+	var v domain.SecretVersion
+	err := row.Scan(
+		&v.SecretID, &v.Version, &v.UserID, &v.Title, &v.Username, &v.EncryptedPassword, &v.EncryptedPayload, &v.ClientEncrypted, &v.StrengthScore, &v.Metadata, &v.UpdatedAt, &v.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (r *secretVersionRepo) Create(ctx context.Context, v *domain.SecretVersion) error {
+	// Every update bumps the version, so a snapshot already stored under the
+	// same version (left by a failed attempt) holds the same state.
+	query := `
+		INSERT INTO secret_versions (secret_id, version, user_id, title, username, encrypted_password, encrypted_payload, client_encrypted, strength_score, metadata, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9, $10, $11)
+		ON CONFLICT (secret_id, version) DO NOTHING
+	`
+	_, err := r.db.Exec(ctx, query,
+		v.SecretID,
+		v.Version,
+		v.UserID,
+		v.Title,
+		v.Username,
+		v.EncryptedPassword,
+		v.EncryptedPayload,
+		v.ClientEncrypted,
+		v.StrengthScore,
+		v.Metadata,
+		v.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("secretVersionRepo.Create: %w", err)
+	}
+	return nil
+}
+
+func (r *secretVersionRepo) ListBySecretID(ctx context.Context, secretID string) ([]*domain.SecretVersion, error) {
+	query := `
+		SELECT ` + secretVersionColumns + `
+		FROM secret_versions
+		WHERE secret_id = $1
+		ORDER BY version DESC
+	`
+	rows, err := r.db.Query(ctx, query, secretID)
+	if err != nil {
+		return nil, fmt.Errorf("secretVersionRepo.ListBySecretID query: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []*domain.SecretVersion
+	for rows.Next() {
+		v, err := scanSecretVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("secretVersionRepo.ListBySecretID scan: %w", err)
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+func (r *secretVersionRepo) ListByUserID(ctx context.Context, userID string) ([]*domain.SecretVersion, error) {
+	query := `
+		SELECT ` + secretVersionColumns + `
+		FROM secret_versions
+		WHERE user_id = $1
+		ORDER BY secret_id, version DESC
+	`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("secretVersionRepo.ListByUserID query: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []*domain.SecretVersion
+	for rows.Next() {
+		v, err := scanSecretVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("secretVersionRepo.ListByUserID scan: %w", err)
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+func (r *secretVersionRepo) GetByVersion(ctx context.Context, secretID string, version int) (*domain.SecretVersion, error) {
+	query := `
+		SELECT ` + secretVersionColumns + `
+		FROM secret_versions
+		WHERE secret_id = $1 AND version = $2
+	`
+	v, err := scanSecretVersion(r.db.QueryRow(ctx, query, secretID, version))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("secretVersionRepo.GetByVersion: %w", err)
+	}
+	return v, nil
+}
+
+func (r *secretVersionRepo) Reseal(ctx context.Context, v *domain.SecretVersion) error {
+	// A version never changes otherwise, so there is nothing to race with.
+	query := `
+		UPDATE secret_versions
+		SET username = $1, encrypted_payload = NULLIF($2, ''), metadata = $3
+		WHERE secret_id = $4 AND version = $5
+	`
+	_, err := r.db.Exec(ctx, query, v.Username, v.EncryptedPayload, v.Metadata, v.SecretID, v.Version)
+	if err != nil {
+		return fmt.Errorf("secretVersionRepo.Reseal: %w", err)
+	}
+	return nil
+}
+
+func (r *secretVersionRepo) Prune(ctx context.Context, userID string, keep int) error {
+	query := `
+		DELETE FROM secret_versions
+		WHERE (secret_id, version) IN (
+			SELECT secret_id, version FROM (
+				SELECT secret_id, version, ROW_NUMBER() OVER (PARTITION BY secret_id ORDER BY version DESC) AS rank
+				FROM secret_versions
+				WHERE user_id = $1
+			) ranked
+			WHERE rank > $2
+		)
+	`
+	_, err := r.db.Exec(ctx, query, userID, keep)
+	if err != nil {
+		return fmt.Errorf("secretVersionRepo.Prune: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type settingsRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewSettingsRepository(db *pgxpool.Pool) domain.SettingsRepository {
+	return &settingsRepo{
+		db: db,
+	}
+}
+
+func (r *settingsRepo) Get(ctx context.Context, userID string) (*domain.UserSettings, error) {
+	query := `SELECT user_id, plaintext_fields, auto_lock_minutes, history_limit, updated_at FROM user_settings WHERE user_id = $1`
+	row := r.db.QueryRow(ctx, query, userID)
+
+	var s domain.UserSettings
+	err := row.Scan(&s.UserID, &s.PlaintextFields, &s.AutoLockMinutes, &s.HistoryLimit, &s.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Nothing saved yet: fall back to the defaults.
+			return &domain.UserSettings{
+				UserID:          userID,
+				PlaintextFields: append([]string(nil), domain.DefaultPlaintextFields...),
+				AutoLockMinutes: domain.DefaultAutoLockMinutes,
+				HistoryLimit:    domain.DefaultHistoryLimit,
+			}, nil
+		}
+		return nil, fmt.Errorf("settingsRepo.Get: %w", err)
+	}
+	return &s, nil
+}
+
+func (r *settingsRepo) Upsert(ctx context.Context, settings *domain.UserSettings) error {
+	query := `
+		INSERT INTO user_settings (user_id, plaintext_fields, auto_lock_minutes, history_limit)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET plaintext_fields = EXCLUDED.plaintext_fields, auto_lock_minutes = EXCLUDED.auto_lock_minutes,
+			history_limit = EXCLUDED.history_limit, updated_at = NOW()
+		RETURNING updated_at
+	`
+	row := r.db.QueryRow(ctx, query, settings.UserID, settings.PlaintextFields, settings.AutoLockMinutes, settings.HistoryLimit)
+	if err := row.Scan(&settings.UpdatedAt); err != nil {
+		return fmt.Errorf("settingsRepo.Upsert: %w", err)
+	}
+	return nil
+}
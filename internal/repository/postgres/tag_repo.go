@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type tagRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewTagRepository(db *pgxpool.Pool) domain.TagRepository {
+	return &tagRepo{
+		db: db,
+	}
+}
+
+// pruneTagsQuery deletes the user's tags that no secret carries any more.
+const pruneTagsQuery = `DELETE FROM tags t WHERE t.user_id = $1 AND NOT EXISTS (SELECT 1 FROM secret_tags st WHERE st.tag_id = t.id)`
+
+func (r *tagRepo) ListByUserID(ctx context.Context, userID string) ([]*domain.Tag, error) {
+	query := `
+		SELECT t.name, COUNT(s.id)
+		FROM tags t
+		JOIN secret_tags st ON st.tag_id = t.id
+		LEFT JOIN secrets s ON s.id = st.secret_id AND s.deleted_at IS NULL
+		WHERE t.user_id = $1
+		GROUP BY t.name
+		ORDER BY t.name
+	`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("tagRepo.ListByUserID query: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []*domain.Tag
+	for rows.Next() {
+		var t domain.Tag
+		if err := rows.Scan(&t.Name, &t.Secrets); err != nil {
+			return nil, fmt.Errorf("tagRepo.ListByUserID scan: %w", err)
+		}
+		tags = append(tags, &t)
+	}
+	return tags, rows.Err()
+}
+
+func (r *tagRepo) AddToSecret(ctx context.Context, userID string, secretID string, names []string) error {
+	// The INSERT's RETURNING only yields tags it created, so existing ones are
+	// looked up separately.
+	query := `
+		WITH created AS (
+			INSERT INTO tags (user_id, name)
+			SELECT $1, unnest($3::text[])
+			ON CONFLICT (user_id, name) DO NOTHING
+			RETURNING id
+		), wanted AS (
+			SELECT id FROM created
+			UNION
+			SELECT id FROM tags WHERE user_id = $1 AND name = ANY($3::text[])
+		)
+		INSERT INTO secret_tags (secret_id, tag_id)
+		SELECT $2, id FROM wanted
+		ON CONFLICT DO NOTHING
+	`
+	if _, err := r.db.Exec(ctx, query, userID, secretID, names); err != nil {
+		return fmt.Errorf("tagRepo.AddToSecret: %w", err)
+	}
+	return nil
+}
+
+func (r *tagRepo) RemoveFromSecret(ctx context.Context, userID string, secretID string, name string) (bool, error) {
+	var removed bool
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			DELETE FROM secret_tags st USING tags t
+			WHERE st.tag_id = t.id AND t.user_id = $1 AND st.secret_id = $2 AND t.name = $3
+		`, userID, secretID, name)
+		if err != nil {
+			return err
+		}
+		removed = tag.RowsAffected() > 0
+		_, err = tx.Exec(ctx, pruneTagsQuery, userID)
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("tagRepo.RemoveFromSecret: %w", err)
+	}
+	return removed, nil
+}
+
+func (r *tagRepo) Rename(ctx context.Context, userID string, from string, to string) (bool, error) {
+	found := true
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		var fromID string
+		err := tx.QueryRow(ctx, `SELECT id FROM tags WHERE user_id = $1 AND name = $2 FOR UPDATE`, userID, from).Scan(&fromID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			found = false
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var toID string
+		err = tx.QueryRow(ctx, `SELECT id FROM tags WHERE user_id = $1 AND name = $2 FOR UPDATE`, userID, to).Scan(&toID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			_, err = tx.Exec(ctx, `UPDATE tags SET name = $2 WHERE id = $1`, fromID, to)
+			return err
+		}
+		if err != nil || toID == fromID {
+			return err
+		}
+
+		// Merge: move every secret over, then drop the old tag and its links.
+		_, err = tx.Exec(ctx, `
+			INSERT INTO secret_tags (secret_id, tag_id)
+			SELECT secret_id, $2 FROM secret_tags WHERE tag_id = $1
+			ON CONFLICT DO NOTHING
+		`, fromID, toID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `DELETE FROM tags WHERE id = $1`, fromID)
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("tagRepo.Rename: %w", err)
+	}
+	return found, nil
+}
+
+func (r *tagRepo) Delete(ctx context.Context, userID string, name string) (bool, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM tags WHERE user_id = $1 AND name = $2`, userID, name)
+	if err != nil {
+		return false, fmt.Errorf("tagRepo.Delete: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type userKeyRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewUserKeyRepository(db *pgxpool.Pool) domain.UserKeyRepository {
+	return &userKeyRepo{
+		db: db,
+	}
+}
+
+func (r *userKeyRepo) GetByUserID(ctx context.Context, userID string) (*domain.UserKey, error) {
+	query := `SELECT user_id, wrapped_key, created_at, updated_at FROM user_keys WHERE user_id = $1`
+	row := r.db.QueryRow(ctx, query, userID)
+
+	var key domain.UserKey
+	err := row.Scan(&key.UserID, &key.WrappedKey, &key.CreatedAt, &key.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("userKeyRepo.GetByUserID: %w", err)
+	}
+	return &key, nil
+}
+
+func (r *userKeyRepo) Create(ctx context.Context, key *domain.UserKey) error {
+	// Two requests may race to create the first key for a user; the first insert wins.
+	query := `
+		INSERT INTO user_keys (user_id, wrapped_key)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO NOTHING
+	`
+	_, err := r.db.Exec(ctx, query, key.UserID, key.WrappedKey)
+	if err != nil {
+		return fmt.Errorf("userKeyRepo.Create: %w", err)
+	}
+	return nil
+}
+
+func (r *userKeyRepo) Count(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM user_keys`).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("userKeyRepo.Count: %w", err)
+	}
+	return count, nil
+}
+
+func (r *userKeyRepo) ListBatch(ctx context.Context, afterUserID string, limit int) ([]*domain.UserKey, error) {
+	query := `
+		SELECT user_id, wrapped_key, created_at, updated_at
+		FROM user_keys
+		WHERE user_id > $1
+		ORDER BY user_id
+		LIMIT $2
+	`
+	if afterUserID == "" {
+		afterUserID = nilUUID
+	}
+	rows, err := r.db.Query(ctx, query, afterUserID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("userKeyRepo.ListBatch query: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*domain.UserKey
+	for rows.Next() {
+		var key domain.UserKey
+		if err := rows.Scan(&key.UserID, &key.WrappedKey, &key.CreatedAt, &key.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("userKeyRepo.ListBatch scan: %w", err)
+		}
+		keys = append(keys, &key)
+	}
+	return keys, rows.Err()
+}
+
+func (r *userKeyRepo) ReplaceWrappedKey(ctx context.Context, userID, oldValue, newValue string) (bool, error) {
+	query := `
+		UPDATE user_keys
+		SET wrapped_key = $1, updated_at = NOW()
+		WHERE user_id = $2 AND wrapped_key = $3
+	`
+	tag, err := r.db.Exec(ctx, query, newValue, userID, oldValue)
+	if err != nil {
+		return false, fmt.Errorf("userKeyRepo.ReplaceWrappedKey: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
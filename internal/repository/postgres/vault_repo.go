@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type vaultRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewVaultRepository(db *pgxpool.Pool) domain.VaultRepository {
+	return &vaultRepo{
+		db: db,
+	}
+}
+
+func (r *vaultRepo) GetByUserID(ctx context.Context, userID string) (*domain.Vault, error) {
+	query := `
+		SELECT kdf_params, COALESCE(master_verifier, ''), COALESCE(vault_key_id, ''), COALESCE(wrapped_vault_key, ''),
+			COALESCE(unlock_pin_hash, ''), vault_updated_at
+		FROM users
+		WHERE id = $1
+	`
+	v := domain.Vault{UserID: userID}
+	err := r.db.QueryRow(ctx, query, userID).Scan(&v.KDF, &v.Verifier, &v.KeyID, &v.WrappedKey, &v.PINHash, &v.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &v, nil
+		}
+		return nil, fmt.Errorf("vaultRepo.GetByUserID: %w", err)
+	}
+	v.ZeroKnowledge = v.Verifier != ""
+	v.HasPIN = v.PINHash != ""
+	return &v, nil
+}
+
+func (r *vaultRepo) Save(ctx context.Context, vault *domain.Vault) error {
+	query := `
+		UPDATE users
+		SET kdf_params = $1, master_verifier = NULLIF($2, ''), vault_key_id = NULLIF($3, ''), wrapped_vault_key = NULLIF($4, ''),
+			unlock_pin_hash = NULLIF($5, ''), vault_updated_at = NOW(), updated_at = NOW()
+		WHERE id = $6
+		RETURNING vault_updated_at
+	`
+	row := r.db.QueryRow(ctx, query, vault.KDF, vault.Verifier, vault.KeyID, vault.WrappedKey, vault.PINHash, vault.UserID)
+	if err := row.Scan(&vault.UpdatedAt); err != nil {
+		return fmt.Errorf("vaultRepo.Save: %w", err)
+	}
+	vault.ZeroKnowledge = vault.Verifier != ""
+	vault.HasPIN = vault.PINHash != ""
+	return nil
+}
@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/pkg/crypto"
+)
+
+// attachmentFiles encrypts attachment contents into a blob store. Every file
+// gets a key of its own, wrapped by the user's data key like a password, so
+// the blobs never need re-encrypting when master keys rotate.
+type attachmentFiles struct {
+	repo  domain.AttachmentRepository
+	blobs domain.BlobStore
+	keys  *keyManager
+	// Limits in bytes; 0 means none
+	maxSize int64
+	quota   int64
+}
+
+func attachmentContext(attachmentID, userID string) []byte {
+	return []byte("attachment:" + attachmentID + ":user:" + userID)
+}
+
+// cleanAttachmentName keeps the base name of an uploaded file, as browsers
+// may send a full path.
+func cleanAttachmentName(name string) (string, error) {
+	name = strings.TrimSpace(path.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == "/" || utf8.RuneCountInString(name) > domain.MaxAttachmentNameLength {
+		return "", fmt.Errorf("%w: the name must be 1 to %d characters", domain.ErrInvalidAttachment, domain.MaxAttachmentNameLength)
+	}
+	if strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return "", fmt.Errorf("%w: the name contains control characters", domain.ErrInvalidAttachment)
+	}
+	return name, nil
+}
+
+// store encrypts content into the blob store and records the attachment,
+// assigning it an ID unless it has one.
+func (f *attachmentFiles) store(ctx context.Context, attachment *domain.Attachment, content io.Reader) error {
+	name, err := cleanAttachmentName(attachment.Name)
+	if err != nil {
+		return err
+	}
+	attachment.Name = name
+	// The type is the browser's guess; anything unusable is replaced
+	if _, _, err := mime.ParseMediaType(attachment.ContentType); err != nil || len(attachment.ContentType) > 255 {
+		attachment.ContentType = "application/octet-stream"
+	}
+	if attachment.Size < 0 || (f.maxSize > 0 && attachment.Size > f.maxSize) {
+		return domain.ErrAttachmentTooLarge
+	}
+	// Fail early; the repository checks again when recording the file
+	if f.quota > 0 {
+		used, err := f.repo.Usage(ctx, attachment.UserID)
+		if err != nil {
+			return err
+		}
+		if used+attachment.Size > f.quota {
+			return domain.ErrQuotaExceeded
+		}
+	}
+
+	if attachment.ID == "" {
+		attachment.ID = uuid.NewString()
+	}
+	fileKey, err := crypto.GenerateDataKey()
+	if err != nil {
+		return fmt.Errorf("failed to generate file key: %w", err)
+	}
+	aad := attachmentContext(attachment.ID, attachment.UserID)
+	attachment.WrappedKey, err = f.keys.Encrypt(ctx, attachment.UserID, base64.StdEncoding.EncodeToString(fileKey), aad)
+	if err != nil {
+		return fmt.Errorf("failed to wrap file key: %w", err)
+	}
+
+	// The file is encrypted as it streams to the store
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(encryptAttachment(pw, fileKey, aad, content, attachment.Size))
+	}()
+	err = f.blobs.Put(ctx, attachment.BlobKey(), pr, attachment.Size+crypto.StreamOverhead(attachment.Size))
+	pr.Close()
+	if err != nil {
+		return fmt.Errorf("failed to store attachment: %w", err)
+	}
+
+	stored, err := f.repo.Create(ctx, attachment, f.quota)
+	if err == nil && !stored {
+		err = domain.ErrQuotaExceeded
+	}
+	if err != nil {
+		if delErr := f.blobs.Delete(ctx, attachment.BlobKey()); delErr != nil {
+			return errors.Join(err, delErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func encryptAttachment(w io.Writer, key, aad []byte, content io.Reader, size int64) error {
+	stream, err := crypto.NewStreamWriter(w, key, aad)
+	if err != nil {
+		return err
+	}
+	written, err := io.Copy(stream, io.LimitReader(content, size+1))
+	if err != nil {
+		return err
+	}
+	if written != size {
+		return fmt.Errorf("%w: expected %d bytes, got %d", domain.ErrInvalidAttachment, size, written)
+	}
+	return stream.Close()
+}
+
+// attachmentReader closes the blob under a decrypting reader.
+type attachmentReader struct {
+	io.Reader
+	io.Closer
+}
+
+// open returns a reader of the attachment's decrypted contents.
+func (f *attachmentFiles) open(ctx context.Context, attachment *domain.Attachment) (io.ReadCloser, error) {
+	aad := attachmentContext(attachment.ID, attachment.UserID)
+	encoded, err := f.keys.Decrypt(ctx, attachment.UserID, attachment.WrappedKey, aad)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap file key: %w", err)
+	}
+	fileKey, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap file key: %w", err)
+	}
+
+	blob, err := f.blobs.Get(ctx, attachment.BlobKey())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	plain, err := crypto.NewStreamReader(blob, fileKey, aad)
+	if err != nil {
+		blob.Close()
+		return nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	return attachmentReader{Reader: plain, Closer: blob}, nil
+}
+
+// remove deletes an attachment, the blob first so that nothing is left
+// behind if it fails.
+func (f *attachmentFiles) remove(ctx context.Context, attachment *domain.Attachment) error {
+	if err := f.blobs.Delete(ctx, attachment.BlobKey()); err != nil {
+		return err
+	}
+	return f.repo.Delete(ctx, attachment.ID)
+}
@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"time"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/pkg/crypto"
+)
+
+// orphanBatchSize is how many attachments of purged secrets are deleted at a
+// time.
+const orphanBatchSize = 100
+
+type attachmentUsecase struct {
+	repo       domain.AttachmentRepository
+	secretRepo domain.SecretRepository
+	vaultRepo  domain.VaultRepository
+	files      *attachmentFiles
+	interval   time.Duration
+}
+
+// NewAttachmentUsecase stores files of up to maxSize bytes each and quota
+// bytes per user (0 means no limit), and checks for the attachments of purged
+// secrets every interval.
+func NewAttachmentUsecase(repo domain.AttachmentRepository, secretRepo domain.SecretRepository, keyRepo domain.UserKeyRepository, vaultRepo domain.VaultRepository, blobs domain.BlobStore, keyProvider crypto.KeyProvider, maxSize, quota int64, interval time.Duration) domain.AttachmentUsecase {
+	return &attachmentUsecase{
+		repo:       repo,
+		secretRepo: secretRepo,
+		vaultRepo:  vaultRepo,
+		files: &attachmentFiles{
+			repo:    repo,
+			blobs:   blobs,
+			keys:    newKeyManager(keyRepo, crypto.NewKeyring(keyProvider)),
+			maxSize: maxSize,
+			quota:   quota,
+		},
+		interval: interval,
+	}
+}
+
+// checkSecret makes sure the secret exists, is not in the trash and belongs
+// to the user. Anything else looks like a missing secret.
+func (u *attachmentUsecase) checkSecret(ctx context.Context, secretID string, userID string) error {
+	secret, err := u.secretRepo.GetByID(ctx, secretID)
+	if err != nil {
+		return err
+	}
+	if secret == nil || secret.UserID != userID || secret.DeletedAt != nil {
+		return domain.ErrAttachmentNotFound
+	}
+	return nil
+}
+
+// ownedAttachment loads an attachment of one of the user's secrets.
+func (u *attachmentUsecase) ownedAttachment(ctx context.Context, id string, secretID string, userID string) (*domain.Attachment, error) {
+	if err := u.checkSecret(ctx, secretID, userID); err != nil {
+		return nil, err
+	}
+	attachment, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if attachment == nil || attachment.SecretID != secretID || attachment.UserID != userID {
+		return nil, domain.ErrAttachmentNotFound
+	}
+	return attachment, nil
+}
+
+func (u *attachmentUsecase) AddAttachment(ctx context.Context, attachment *domain.Attachment, content io.Reader) error {
+	if err := u.checkSecret(ctx, attachment.SecretID, attachment.UserID); err != nil {
+		return err
+	}
+
+	// Like passwords, files must be encrypted by the browser in
+	// zero-knowledge mode.
+	vault, err := u.vaultRepo.GetByUserID(ctx, attachment.UserID)
+	if err != nil {
+		return err
+	}
+	if vault.ZeroKnowledge != attachment.ClientEncrypted {
+		if vault.ZeroKnowledge {
+			return errors.Join(domain.ErrInvalidAttachment, errors.New("zero-knowledge mode requires a file encrypted by the browser"))
+		}
+		return errors.Join(domain.ErrInvalidAttachment, errors.New("zero-knowledge mode is not enabled"))
+	}
+
+	attachment.ID = ""
+	return u.files.store(ctx, attachment, content)
+}
+
+func (u *attachmentUsecase) ListAttachments(ctx context.Context, secretID string, userID string) ([]*domain.Attachment, error) {
+	if err := u.checkSecret(ctx, secretID, userID); err != nil {
+		return nil, err
+	}
+	attachments, err := u.repo.ListBySecret(ctx, secretID)
+	if err != nil {
+		return nil, err
+	}
+	if attachments == nil {
+		attachments = []*domain.Attachment{}
+	}
+	return attachments, nil
+}
+
+func (u *attachmentUsecase) OpenAttachment(ctx context.Context, id string, secretID string, userID string) (*domain.Attachment, io.ReadCloser, error) {
+	attachment, err := u.ownedAttachment(ctx, id, secretID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := u.files.open(ctx, attachment)
+	if err != nil {
+		return nil, nil, err
+	}
+	return attachment, content, nil
+}
+
+func (u *attachmentUsecase) DeleteAttachment(ctx context.Context, id string, secretID string, userID string) error {
+	attachment, err := u.ownedAttachment(ctx, id, secretID, userID)
+	if err != nil {
+		return err
+	}
+	return u.files.remove(ctx, attachment)
+}
+
+func (u *attachmentUsecase) Usage(ctx context.Context, userID string) (*domain.AttachmentUsage, error) {
+	used, err := u.repo.Usage(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.AttachmentUsage{
+		Used:    used,
+		Quota:   u.files.quota,
+		MaxSize: u.files.maxSize,
+	}, nil
+}
+
+func (u *attachmentUsecase) Run(ctx context.Context) error {
+	interval := u.interval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := u.deleteOrphans(ctx); err != nil {
+			log.Printf("attachment sweep: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// deleteOrphans deletes the attachments left behind by purged secrets.
+func (u *attachmentUsecase) deleteOrphans(ctx context.Context) error {
+	deleted := 0
+	defer func() {
+		if deleted > 0 {
+			log.Printf("attachment sweep: deleted %d attachments of purged secrets", deleted)
+		}
+	}()
+	for {
+		orphans, err := u.repo.ListOrphans(ctx, orphanBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, attachment := range orphans {
+			if err := u.files.remove(ctx, attachment); err != nil {
+				return err
+			}
+			deleted++
+		}
+		if len(orphans) < orphanBatchSize {
+			return nil
+		}
+	}
+}
@@ -1,25 +1,51 @@
 package usecase
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"time"
 
-	"github.com/herdiagusthio/password-manager/config"
+	"github.com/google/uuid"
 	"github.com/herdiagusthio/password-manager/internal/domain"
 	"github.com/herdiagusthio/password-manager/pkg/crypto"
 )
 
 type backupUsecase struct {
-	secretRepo domain.SecretRepository
-	cfg        *config.Config
+	secretRepo   domain.SecretRepository
+	versionRepo  domain.SecretVersionRepository
+	tagRepo      domain.TagRepository
+	settingsRepo domain.SettingsRepository
+	vaultRepo    domain.VaultRepository
+	keys         *keyManager
+	sealer       *secretSealer
+	keyring      *crypto.Keyring
+	files        *attachmentFiles
 }
 
-func NewBackupUsecase(secretRepo domain.SecretRepository, cfg *config.Config) domain.BackupUsecase {
+// NewBackupUsecase includes attachments in backups, restoring them within the
+// same size limits as uploads.
+func NewBackupUsecase(secretRepo domain.SecretRepository, versionRepo domain.SecretVersionRepository, tagRepo domain.TagRepository, keyRepo domain.UserKeyRepository, settingsRepo domain.SettingsRepository, vaultRepo domain.VaultRepository, attachmentRepo domain.AttachmentRepository, blobs domain.BlobStore, keyProvider crypto.KeyProvider, maxAttachmentSize, attachmentQuota int64) domain.BackupUsecase {
+	keyring := crypto.NewKeyring(keyProvider)
+	keys := newKeyManager(keyRepo, keyring)
 	return &backupUsecase{
-		secretRepo: secretRepo,
-		cfg:        cfg,
+		secretRepo:   secretRepo,
+		versionRepo:  versionRepo,
+		tagRepo:      tagRepo,
+		settingsRepo: settingsRepo,
+		vaultRepo:    vaultRepo,
+		keys:         keys,
+		sealer:       newSecretSealer(keys, settingsRepo),
+		keyring:      keyring,
+		files: &attachmentFiles{
+			repo:    attachmentRepo,
+			blobs:   blobs,
+			keys:    keys,
+			maxSize: maxAttachmentSize,
+			quota:   attachmentQuota,
+		},
 	}
 }
 
@@ -30,21 +56,54 @@ func (u *backupUsecase) ExportSecrets(ctx context.Context, userID string) ([]byt
 		return nil, fmt.Errorf("failed to list secrets: %w", err)
 	}
 
-	// 2. Prepare Backup struct
+	// 2. Decrypt passwords and sealed fields with the user's data key. The data key never leaves
+	// the server, so the backup carries plaintext that is sealed as a whole below.
+	// Client-encrypted passwords stay opaque: only the server layer is removed.
+	for _, s := range secrets {
+		password, err := u.keys.Decrypt(ctx, userID, s.EncryptedPassword, secretContext(s.ID, userID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt secret %s: %w", s.ID, err)
+		}
+		s.Password = password
+		if err := u.sealer.Open(ctx, s); err != nil {
+			return nil, fmt.Errorf("failed to decrypt secret %s: %w", s.ID, err)
+		}
+	}
+
+	// 3. Prepare Backup struct. In zero-knowledge mode the wrapped vault key goes
+	// along, so the backup can be restored into a fresh account.
+	attachments, err := u.exportAttachments(ctx, userID, secrets)
+	if err != nil {
+		return nil, err
+	}
 	backup := domain.Backup{
-		Version:   "1.0",
-		CreatedAt: time.Now().Format(time.RFC3339),
-		Secrets:   secrets,
+		Version:     "1.0",
+		CreatedAt:   time.Now().Format(time.RFC3339),
+		Secrets:     secrets,
+		Attachments: attachments,
+	}
+	vault, err := u.vaultRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if vault.ZeroKnowledge {
+		backup.Vault = &domain.BackupVault{
+			KDF:        vault.KDF,
+			KeyID:      vault.KeyID,
+			WrappedKey: vault.WrappedKey,
+			Verifier:   vault.Verifier,
+		}
 	}
 
-	// 3. Serialize to JSON
+	// 4. Serialize to JSON
 	jsonData, err := json.Marshal(backup)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal backup: %w", err)
 	}
 
-	// 4. Encrypt the entire JSON blob with Master Key
-	encryptedString, err := crypto.Encrypt(string(jsonData), u.cfg.EncryptionKey)
+	// 5. Encrypt the entire JSON blob with the current Master Key.
+	// The key ID in the header lets the file be restored after a rotation.
+	encryptedString, err := u.keyring.Encrypt(ctx, jsonData, backupContext)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encrypt backup: %w", err)
 	}
@@ -53,18 +112,22 @@ func (u *backupUsecase) ExportSecrets(ctx context.Context, userID string) ([]byt
 }
 
 func (u *backupUsecase) ImportSecrets(ctx context.Context, userID string, backupData []byte) error {
-	// 1. Decrypt
-	decryptedJSON, err := crypto.Decrypt(string(backupData), u.cfg.EncryptionKey)
+	// 1. Decrypt with whichever Master Key the backup was written with
+	decryptedJSON, err := u.keyring.Decrypt(ctx, string(backupData), backupContext)
 	if err != nil {
 		return fmt.Errorf("failed to decrypt backup: %w", err)
 	}
 
 	// 2. Unmarshal
 	var backup domain.Backup
-	if err := json.Unmarshal([]byte(decryptedJSON), &backup); err != nil {
+	if err := json.Unmarshal(decryptedJSON, &backup); err != nil {
 		return fmt.Errorf("failed to unmarshal backup json: %w", err)
 	}
 
+	if err := u.restoreVault(ctx, userID, backup.Vault); err != nil {
+		return err
+	}
+
 	// 3. Restore
 	// Strategy: Iterate and create/update.
 	// We will overwrite existing secrets with same Title? Or just add new ones?
@@ -74,23 +137,203 @@ func (u *backupUsecase) ImportSecrets(ctx context.Context, userID string, backup
 	for _, s := range backup.Secrets {
 		// Enforce UserID to be the current user (prevent restoring secrets to wrong user if backup file is shared/hacked)
 		s.UserID = userID
-		
+		if s.ID == "" {
+			s.ID = uuid.NewString()
+		}
+
 		// Check if exists
 		existing, err := u.secretRepo.GetByID(ctx, s.ID)
 		if err != nil {
 			return fmt.Errorf("error checking secret existence: %w", err)
 		}
+		if existing != nil && existing.UserID != userID {
+			return fmt.Errorf("secret %s belongs to another user", s.ID)
+		}
+		// As with UpdateSecret, the type of a stored secret cannot change
+		if existing != nil {
+			if s.Type == "" {
+				s.Type = existing.Type
+			}
+			if s.Type != existing.Type {
+				return fmt.Errorf("invalid secret %s: %w: the type of a secret cannot be changed", s.ID, domain.ErrInvalidSecret)
+			}
+		}
+		if err := checkSecretType(s); err != nil {
+			return fmt.Errorf("invalid secret %s: %w", s.ID, err)
+		}
+
+		// Re-encrypt the password with the current user's data key, bound to this row.
+		// Backups without a password keep the stored one, like UpdateSecret does.
+		if s.Password == "" && existing != nil {
+			s.EncryptedPassword = existing.EncryptedPassword
+			s.ClientEncrypted = existing.ClientEncrypted
+			s.StrengthScore = existing.StrengthScore
+		} else {
+			if err := scorePassword(s); err != nil {
+				return fmt.Errorf("invalid secret %s: %w", s.ID, err)
+			}
+			encrypted, err := u.keys.Encrypt(ctx, userID, s.Password, secretContext(s.ID, userID))
+			if err != nil {
+				return fmt.Errorf("failed to encrypt secret %s: %w", s.ID, err)
+			}
+			s.EncryptedPassword = encrypted
+		}
+		s.Password = ""
+
+		// Seal the fields the user keeps private, according to their current settings.
+		if err := u.sealer.Seal(ctx, s); err != nil {
+			return fmt.Errorf("failed to encrypt secret %s: %w", s.ID, err)
+		}
 
 		if existing != nil {
+			// Importing a backup deliberately overwrites whatever is stored,
+			// keeping it in history like any other update.
+			if err := recordVersion(ctx, u.versionRepo, u.settingsRepo, domain.NewSecretVersion(existing)); err != nil {
+				return fmt.Errorf("failed to update secret %s: %w", s.ID, err)
+			}
+			s.Version = existing.Version
 			if err := u.secretRepo.Update(ctx, s); err != nil {
 				return fmt.Errorf("failed to update secret %s: %w", s.ID, err)
 			}
+			if err := u.secretRepo.SetFavorite(ctx, s.ID, s.Favorite); err != nil {
+				return fmt.Errorf("failed to update secret %s: %w", s.ID, err)
+			}
+			// Left in the trash, the restored state would soon be purged
+			if existing.DeletedAt != nil {
+				if _, err := u.secretRepo.Restore(ctx, s.ID); err != nil {
+					return fmt.Errorf("failed to restore secret %s from the trash: %w", s.ID, err)
+				}
+			}
 		} else {
+			// Folders are not part of backups; restored secrets start unfiled.
+			s.FolderID = nil
 			if err := u.secretRepo.Create(ctx, s); err != nil {
 				return fmt.Errorf("failed to create secret %s: %w", s.ID, err)
 			}
 		}
+
+		// Tags in the backup are added to those the secret already has.
+		tags := make([]string, 0, len(s.Tags))
+		for _, name := range s.Tags {
+			if tag, err := normalizeTag(name); err == nil {
+				tags = append(tags, tag)
+			}
+		}
+		if len(tags) > 0 {
+			if err := u.tagRepo.AddToSecret(ctx, userID, s.ID, tags); err != nil {
+				return fmt.Errorf("failed to tag secret %s: %w", s.ID, err)
+			}
+		}
+	}
+
+	if err := u.importAttachments(ctx, userID, backup); err != nil {
+		return err
 	}
 
 	return nil
 }
+
+// exportAttachments decrypts the attachments of the exported secrets.
+func (u *backupUsecase) exportAttachments(ctx context.Context, userID string, secrets []*domain.Secret) ([]*domain.BackupAttachment, error) {
+	attachments, err := u.files.repo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+	exported := make(map[string]bool, len(secrets))
+	for _, s := range secrets {
+		exported[s.ID] = true
+	}
+
+	var backupAttachments []*domain.BackupAttachment
+	for _, a := range attachments {
+		if !exported[a.SecretID] {
+			continue
+		}
+		content, err := u.files.open(ctx, a)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt attachment %s: %w", a.ID, err)
+		}
+		data, err := io.ReadAll(content)
+		content.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt attachment %s: %w", a.ID, err)
+		}
+		backupAttachments = append(backupAttachments, &domain.BackupAttachment{
+			ID:              a.ID,
+			SecretID:        a.SecretID,
+			Name:            a.Name,
+			ContentType:     a.ContentType,
+			ClientEncrypted: a.ClientEncrypted,
+			Data:            data,
+		})
+	}
+	return backupAttachments, nil
+}
+
+// importAttachments restores the attachments of the secrets just restored.
+// Attachments that are already stored are left alone, so importing the same
+// backup twice does not duplicate them.
+func (u *backupUsecase) importAttachments(ctx context.Context, userID string, backup domain.Backup) error {
+	restored := make(map[string]bool, len(backup.Secrets))
+	for _, s := range backup.Secrets {
+		restored[s.ID] = true
+	}
+
+	for _, a := range backup.Attachments {
+		if !restored[a.SecretID] {
+			continue
+		}
+		attachment := &domain.Attachment{
+			ID:              a.ID,
+			SecretID:        a.SecretID,
+			UserID:          userID,
+			Name:            a.Name,
+			ContentType:     a.ContentType,
+			Size:            int64(len(a.Data)),
+			ClientEncrypted: a.ClientEncrypted,
+		}
+		if _, err := uuid.Parse(attachment.ID); err != nil {
+			attachment.ID = ""
+		} else {
+			existing, err := u.files.repo.GetByID(ctx, attachment.ID)
+			if err != nil {
+				return fmt.Errorf("error checking attachment existence: %w", err)
+			}
+			if existing != nil {
+				if existing.UserID == userID {
+					continue
+				}
+				attachment.ID = ""
+			}
+		}
+		if err := u.files.store(ctx, attachment, bytes.NewReader(a.Data)); err != nil {
+			return fmt.Errorf("failed to restore attachment %s: %w", a.Name, err)
+		}
+	}
+	return nil
+}
+
+// restoreVault makes sure client-encrypted secrets in a backup stay readable.
+// An account without zero knowledge adopts the backup's vault key; an account
+// with it must already use the same vault key (possibly re-wrapped since).
+func (u *backupUsecase) restoreVault(ctx context.Context, userID string, backupVault *domain.BackupVault) error {
+	if backupVault == nil {
+		return nil
+	}
+	vault, err := u.vaultRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if vault.ZeroKnowledge {
+		if vault.KeyID != backupVault.KeyID {
+			return fmt.Errorf("backup was encrypted with a different vault key")
+		}
+		return nil
+	}
+
+	vault.KDF = backupVault.KDF
+	vault.KeyID = backupVault.KeyID
+	vault.WrappedKey = backupVault.WrappedKey
+	vault.Verifier = backupVault.Verifier
+	return u.vaultRepo.Save(ctx, vault)
+}
@@ -0,0 +1,110 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/internal/mocks"
+	"github.com/herdiagusthio/password-manager/internal/usecase"
+	"github.com/herdiagusthio/password-manager/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// newBackup seals secrets into a backup file as ExportSecrets does.
+func newBackup(t *testing.T, keys crypto.KeyProvider, secrets ...*domain.Secret) []byte {
+	data, err := json.Marshal(domain.Backup{Version: "1.0", Secrets: secrets})
+	require.NoError(t, err)
+	sealed, err := crypto.NewKeyring(keys).Encrypt(context.Background(), data, []byte("backup"))
+	require.NoError(t, err)
+	return []byte(sealed)
+}
+
+func newBackupUsecase(t *testing.T, ctrl *gomock.Controller, secretRepo domain.SecretRepository, versionRepo domain.SecretVersionRepository, keys crypto.KeyProvider, dataKey []byte) domain.BackupUsecase {
+	return usecase.NewBackupUsecase(secretRepo, versionRepo, mocks.NewMockTagRepository(ctrl), newKeyRepo(t, ctrl, keys, dataKey), newSettingsRepo(ctrl), newVaultRepo(ctrl, false), mocks.NewMockAttachmentRepository(ctrl), nil, keys, 0, 0)
+}
+
+func TestBackupUsecase_ImportSecrets_KeepsHistory(t *testing.T) {
+	mockKey := "12345678901234567890123456789012"
+	dataKey := []byte("abcdefghijklmnopqrstuvwxyz012345")
+	keys := newKeyProvider(t, mockKey)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	existing := &domain.Secret{ID: "sec-1", UserID: "user-1", Type: domain.SecretTypeLogin, Title: "Old Mail", EncryptedPassword: "enc", Version: 3}
+	secretRepo := mocks.NewMockSecretRepository(ctrl)
+	secretRepo.EXPECT().GetByID(gomock.Any(), "sec-1").Return(existing, nil)
+	secretRepo.EXPECT().GetByID(gomock.Any(), "sec-2").Return(nil, nil)
+	secretRepo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, s *domain.Secret) error {
+		assert.Equal(t, "Mail", s.Title)
+		assert.Equal(t, 3, s.Version)
+		return nil
+	})
+	secretRepo.EXPECT().SetFavorite(gomock.Any(), "sec-1", false).Return(nil)
+	secretRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+
+	// The overwritten state goes to history; a new secret has none.
+	versions := map[int]*domain.SecretVersion{}
+	uc := newBackupUsecase(t, ctrl, secretRepo, newVersionRepo(ctrl, versions), keys, dataKey)
+	err := uc.ImportSecrets(context.Background(), "user-1", newBackup(t, keys,
+		&domain.Secret{ID: "sec-1", Type: domain.SecretTypeLogin, Title: "Mail", Password: "correct horse battery staple"},
+		&domain.Secret{ID: "sec-2", Type: domain.SecretTypeLogin, Title: "Bank", Password: "correct horse battery staple"},
+	))
+	require.NoError(t, err)
+
+	require.Len(t, versions, 1)
+	require.Contains(t, versions, 3)
+	assert.Equal(t, "sec-1", versions[3].SecretID)
+	assert.Equal(t, "Old Mail", versions[3].Title)
+	assert.Equal(t, "enc", versions[3].EncryptedPassword)
+}
+
+func TestBackupUsecase_ImportSecrets_RestoresTrashed(t *testing.T) {
+	mockKey := "12345678901234567890123456789012"
+	dataKey := []byte("abcdefghijklmnopqrstuvwxyz012345")
+	keys := newKeyProvider(t, mockKey)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	deletedAt := time.Now()
+	trashed := &domain.Secret{ID: "sec-1", UserID: "user-1", Type: domain.SecretTypeLogin, Title: "Mail", EncryptedPassword: "enc", Version: 2, DeletedAt: &deletedAt}
+	secretRepo := mocks.NewMockSecretRepository(ctrl)
+	secretRepo.EXPECT().GetByID(gomock.Any(), "sec-1").Return(trashed, nil)
+	gomock.InOrder(
+		secretRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil),
+		secretRepo.EXPECT().SetFavorite(gomock.Any(), "sec-1", false).Return(nil),
+		secretRepo.EXPECT().Restore(gomock.Any(), "sec-1").Return(true, nil),
+	)
+
+	uc := newBackupUsecase(t, ctrl, secretRepo, newVersionRepo(ctrl, map[int]*domain.SecretVersion{}), keys, dataKey)
+	err := uc.ImportSecrets(context.Background(), "user-1", newBackup(t, keys,
+		&domain.Secret{ID: "sec-1", Type: domain.SecretTypeLogin, Title: "Mail", Password: "correct horse battery staple"},
+	))
+	require.NoError(t, err)
+}
+
+func TestBackupUsecase_ImportSecrets_KeepsType(t *testing.T) {
+	mockKey := "12345678901234567890123456789012"
+	dataKey := []byte("abcdefghijklmnopqrstuvwxyz012345")
+	keys := newKeyProvider(t, mockKey)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// A card in the backup must not turn the stored login into a card.
+	existing := &domain.Secret{ID: "sec-1", UserID: "user-1", Type: domain.SecretTypeLogin, Title: "Mail", EncryptedPassword: "enc", Version: 2}
+	secretRepo := mocks.NewMockSecretRepository(ctrl)
+	secretRepo.EXPECT().GetByID(gomock.Any(), "sec-1").Return(existing, nil)
+
+	uc := newBackupUsecase(t, ctrl, secretRepo, mocks.NewMockSecretVersionRepository(ctrl), keys, dataKey)
+	err := uc.ImportSecrets(context.Background(), "user-1", newBackup(t, keys,
+		&domain.Secret{ID: "sec-1", Type: domain.SecretTypeCard, Title: "Visa", Password: "1234", Metadata: map[string]interface{}{"number": "4111111111111111", "expiry": "03/31"}},
+	))
+	assert.ErrorIs(t, err, domain.ErrInvalidSecret)
+}
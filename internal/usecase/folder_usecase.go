@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/herdiagusthio/password-manager/internal/domain"
+)
+
+type folderUsecase struct {
+	repo       domain.FolderRepository
+	secretRepo domain.SecretRepository
+}
+
+func NewFolderUsecase(repo domain.FolderRepository, secretRepo domain.SecretRepository) domain.FolderUsecase {
+	return &folderUsecase{
+		repo:       repo,
+		secretRepo: secretRepo,
+	}
+}
+
+// ownedFolder loads one of the user's folders. Folders that do not exist,
+// belong to someone else or have a malformed ID are all ErrFolderNotFound.
+func ownedFolder(ctx context.Context, repo domain.FolderRepository, id string, userID string) (*domain.Folder, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, domain.ErrFolderNotFound
+	}
+	folder, err := repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if folder == nil || folder.UserID != userID {
+		return nil, domain.ErrFolderNotFound
+	}
+	return folder, nil
+}
+
+func normalizeFolderName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > domain.MaxFolderNameLength {
+		return "", domain.ErrInvalidFolderName
+	}
+	return name, nil
+}
+
+// folderTree indexes a user's folders for depth and cycle checks.
+type folderTree struct {
+	byID     map[string]*domain.Folder
+	children map[string][]string
+}
+
+func (u *folderUsecase) tree(ctx context.Context, userID string) (*folderTree, error) {
+	folders, err := u.repo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	t := &folderTree{byID: map[string]*domain.Folder{}, children: map[string][]string{}}
+	for _, f := range folders {
+		t.byID[f.ID] = f
+		if f.ParentID != nil {
+			t.children[*f.ParentID] = append(t.children[*f.ParentID], f.ID)
+		}
+	}
+	return t, nil
+}
+
+// The walks below stop after len(byID) steps, so a cycle left behind by
+// concurrent moves cannot hang them.
+
+// depth is 1 for top-level folders.
+func (t *folderTree) depth(id string) int {
+	d := 1
+	for f := t.byID[id]; f != nil && f.ParentID != nil && d <= len(t.byID); d++ {
+		f = t.byID[*f.ParentID]
+	}
+	return d
+}
+
+// height is 1 for folders without subfolders.
+func (t *folderTree) height(id string) int {
+	h, level := 0, []string{id}
+	for len(level) > 0 && h <= len(t.byID) {
+		var next []string
+		for _, f := range level {
+			next = append(next, t.children[f]...)
+		}
+		level = next
+		h++
+	}
+	return h
+}
+
+// isDescendant reports whether id is ancestor itself or lies below it.
+func (t *folderTree) isDescendant(id, ancestor string) bool {
+	f := t.byID[id]
+	for steps := 0; f != nil && steps <= len(t.byID); steps++ {
+		if f.ID == ancestor {
+			return true
+		}
+		if f.ParentID == nil {
+			return false
+		}
+		f = t.byID[*f.ParentID]
+	}
+	return false
+}
+
+// checkParent validates putting folder (nil while creating) under parentID.
+func (u *folderUsecase) checkParent(ctx context.Context, folder *domain.Folder, parentID string, userID string) error {
+	if _, err := ownedFolder(ctx, u.repo, parentID, userID); err != nil {
+		return err
+	}
+	t, err := u.tree(ctx, userID)
+	if err != nil {
+		return err
+	}
+	height := 1
+	if folder != nil {
+		if t.isDescendant(parentID, folder.ID) {
+			return domain.ErrFolderCycle
+		}
+		height = t.height(folder.ID)
+	}
+	if t.depth(parentID)+height > domain.MaxFolderDepth {
+		return domain.ErrFolderTooDeep
+	}
+	return nil
+}
+
+func (u *folderUsecase) CreateFolder(ctx context.Context, folder *domain.Folder) error {
+	name, err := normalizeFolderName(folder.Name)
+	if err != nil {
+		return err
+	}
+	folder.Name = name
+	if folder.ParentID != nil {
+		if err := u.checkParent(ctx, nil, *folder.ParentID, folder.UserID); err != nil {
+			return err
+		}
+	}
+	return u.repo.Create(ctx, folder)
+}
+
+func (u *folderUsecase) ListFolders(ctx context.Context, userID string) ([]*domain.Folder, error) {
+	folders, err := u.repo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if folders == nil {
+		folders = []*domain.Folder{}
+	}
+	return folders, nil
+}
+
+func (u *folderUsecase) UpdateFolder(ctx context.Context, folder *domain.Folder) error {
+	existing, err := ownedFolder(ctx, u.repo, folder.ID, folder.UserID)
+	if err != nil {
+		return err
+	}
+	name, err := normalizeFolderName(folder.Name)
+	if err != nil {
+		return err
+	}
+	folder.Name = name
+	if folder.ParentID != nil {
+		if err := u.checkParent(ctx, existing, *folder.ParentID, folder.UserID); err != nil {
+			return err
+		}
+	}
+	folder.CreatedAt = existing.CreatedAt
+	return u.repo.Update(ctx, folder)
+}
+
+func (u *folderUsecase) DeleteFolder(ctx context.Context, id string, userID string) error {
+	if _, err := ownedFolder(ctx, u.repo, id, userID); err != nil {
+		return err
+	}
+	return u.repo.Delete(ctx, id)
+}
+
+func (u *folderUsecase) MoveSecrets(ctx context.Context, userID string, secretIDs []string, folderID *string) (int64, error) {
+	if folderID != nil {
+		if _, err := ownedFolder(ctx, u.repo, *folderID, userID); err != nil {
+			return 0, err
+		}
+	}
+	// Malformed IDs cannot match any secret.
+	ids := make([]string, 0, len(secretIDs))
+	for _, id := range secretIDs {
+		if _, err := uuid.Parse(id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	return u.secretRepo.MoveToFolder(ctx, userID, ids, folderID)
+}
@@ -0,0 +1,213 @@
+package usecase_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/internal/mocks"
+	"github.com/herdiagusthio/password-manager/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// Folder IDs must be UUIDs; these spell out the tree they are used in.
+const (
+	folderWork     = "00000000-0000-0000-0000-00000000000a"
+	folderProjects = "00000000-0000-0000-0000-00000000000b"
+	folderClient   = "00000000-0000-0000-0000-00000000000c"
+	folderOther    = "00000000-0000-0000-0000-0000000000ff"
+)
+
+// newFolderRepo returns a folder repository serving user-1's tree
+// Work > Projects > Client, plus a folder of user-2.
+func newFolderRepo(ctrl *gomock.Controller) *mocks.MockFolderRepository {
+	parent := func(id string) *string { return &id }
+	folders := []*domain.Folder{
+		{ID: folderWork, UserID: "user-1", Name: "Work"},
+		{ID: folderProjects, UserID: "user-1", Name: "Projects", ParentID: parent(folderWork)},
+		{ID: folderClient, UserID: "user-1", Name: "Client", ParentID: parent(folderProjects)},
+		{ID: folderOther, UserID: "user-2", Name: "Other"},
+	}
+	repo := mocks.NewMockFolderRepository(ctrl)
+	repo.EXPECT().GetByID(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, id string) (*domain.Folder, error) {
+		for _, f := range folders {
+			if f.ID == id {
+				return f, nil
+			}
+		}
+		return nil, nil
+	}).AnyTimes()
+	repo.EXPECT().ListByUserID(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, userID string) ([]*domain.Folder, error) {
+		var owned []*domain.Folder
+		for _, f := range folders {
+			if f.UserID == userID {
+				owned = append(owned, f)
+			}
+		}
+		return owned, nil
+	}).AnyTimes()
+	return repo
+}
+
+func TestFolderUsecase_CreateFolder(t *testing.T) {
+	parent := func(id string) *string { return &id }
+	tests := []struct {
+		name      string
+		folder    *domain.Folder
+		expectErr error
+	}{
+		{name: "Top Level", folder: &domain.Folder{UserID: "user-1", Name: "  Personal "}},
+		{name: "Nested", folder: &domain.Folder{UserID: "user-1", Name: "Archive", ParentID: parent(folderClient)}},
+		{name: "Empty Name", folder: &domain.Folder{UserID: "user-1", Name: "   "}, expectErr: domain.ErrInvalidFolderName},
+		{name: "Long Name", folder: &domain.Folder{UserID: "user-1", Name: strings.Repeat("x", domain.MaxFolderNameLength+1)}, expectErr: domain.ErrInvalidFolderName},
+		{name: "Unknown Parent", folder: &domain.Folder{UserID: "user-1", Name: "A", ParentID: parent("00000000-0000-0000-0000-000000000099")}, expectErr: domain.ErrFolderNotFound},
+		{name: "Malformed Parent", folder: &domain.Folder{UserID: "user-1", Name: "A", ParentID: parent("not-a-uuid")}, expectErr: domain.ErrFolderNotFound},
+		{name: "Parent Of Another User", folder: &domain.Folder{UserID: "user-1", Name: "A", ParentID: parent(folderOther)}, expectErr: domain.ErrFolderNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := newFolderRepo(ctrl)
+			if tt.expectErr == nil {
+				repo.EXPECT().Create(gomock.Any(), tt.folder).Return(nil)
+			}
+
+			uc := usecase.NewFolderUsecase(repo, mocks.NewMockSecretRepository(ctrl))
+			err := uc.CreateFolder(context.Background(), tt.folder)
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, strings.TrimSpace(tt.folder.Name), tt.folder.Name)
+		})
+	}
+}
+
+func TestFolderUsecase_CreateFolder_TooDeep(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// A chain exactly MaxFolderDepth deep has no room for another level.
+	var folders []*domain.Folder
+	var parentID *string
+	for i := 0; i < domain.MaxFolderDepth; i++ {
+		id := fmt.Sprintf("00000000-0000-0000-0001-%012d", i)
+		folders = append(folders, &domain.Folder{ID: id, UserID: "user-1", Name: id, ParentID: parentID})
+		parentID = &folders[i].ID
+	}
+	repo := mocks.NewMockFolderRepository(ctrl)
+	repo.EXPECT().GetByID(gomock.Any(), *parentID).Return(folders[len(folders)-1], nil)
+	repo.EXPECT().ListByUserID(gomock.Any(), "user-1").Return(folders, nil)
+
+	uc := usecase.NewFolderUsecase(repo, mocks.NewMockSecretRepository(ctrl))
+	err := uc.CreateFolder(context.Background(), &domain.Folder{UserID: "user-1", Name: "Deepest", ParentID: parentID})
+	assert.ErrorIs(t, err, domain.ErrFolderTooDeep)
+}
+
+func TestFolderUsecase_UpdateFolder(t *testing.T) {
+	parent := func(id string) *string { return &id }
+	tests := []struct {
+		name      string
+		folder    *domain.Folder
+		expectErr error
+	}{
+		{name: "Rename", folder: &domain.Folder{ID: folderProjects, UserID: "user-1", Name: "Jobs", ParentID: parent(folderWork)}},
+		{name: "Move To Top Level", folder: &domain.Folder{ID: folderProjects, UserID: "user-1", Name: "Projects"}},
+		{name: "Into Itself", folder: &domain.Folder{ID: folderWork, UserID: "user-1", Name: "Work", ParentID: parent(folderWork)}, expectErr: domain.ErrFolderCycle},
+		{name: "Into Subfolder", folder: &domain.Folder{ID: folderWork, UserID: "user-1", Name: "Work", ParentID: parent(folderClient)}, expectErr: domain.ErrFolderCycle},
+		{name: "Folder Of Another User", folder: &domain.Folder{ID: folderOther, UserID: "user-1", Name: "Mine"}, expectErr: domain.ErrFolderNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := newFolderRepo(ctrl)
+			if tt.expectErr == nil {
+				repo.EXPECT().Update(gomock.Any(), tt.folder).Return(nil)
+			}
+
+			uc := usecase.NewFolderUsecase(repo, mocks.NewMockSecretRepository(ctrl))
+			err := uc.UpdateFolder(context.Background(), tt.folder)
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestFolderUsecase_MoveSecrets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	secretID := "00000000-0000-0000-0000-000000000001"
+	folderID := folderProjects
+	secretRepo := mocks.NewMockSecretRepository(ctrl)
+	// Malformed IDs are dropped before they reach the database.
+	secretRepo.EXPECT().MoveToFolder(gomock.Any(), "user-1", []string{secretID}, &folderID).Return(int64(1), nil)
+	secretRepo.EXPECT().MoveToFolder(gomock.Any(), "user-1", []string{secretID}, (*string)(nil)).Return(int64(1), nil)
+
+	uc := usecase.NewFolderUsecase(newFolderRepo(ctrl), secretRepo)
+	ctx := context.Background()
+
+	moved, err := uc.MoveSecrets(ctx, "user-1", []string{secretID, "bogus"}, &folderID)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, moved)
+
+	moved, err = uc.MoveSecrets(ctx, "user-1", []string{secretID}, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, moved)
+
+	other := folderOther
+	_, err = uc.MoveSecrets(ctx, "user-1", []string{secretID}, &other)
+	assert.ErrorIs(t, err, domain.ErrFolderNotFound)
+
+	moved, err = uc.MoveSecrets(ctx, "user-1", nil, nil)
+	require.NoError(t, err)
+	assert.Zero(t, moved)
+}
+
+func TestSecretUsecase_ListSecrets_Folder(t *testing.T) {
+	tests := []struct {
+		name      string
+		folderID  string
+		expectErr error
+	}{
+		{name: "All", folderID: ""},
+		{name: "Unfiled", folderID: domain.UnfiledFolderID},
+		{name: "Folder", folderID: folderWork},
+		{name: "Folder Of Another User", folderID: folderOther, expectErr: domain.ErrFolderNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockSecretRepository(ctrl)
+			if tt.expectErr == nil {
+				repo.EXPECT().ListPage(gomock.Any(), "user-1", domain.SecretFilter{FolderID: tt.folderID}, gomock.Any()).Return(&domain.SecretPage{}, nil)
+			}
+
+			keys := newKeyProvider(t, "12345678901234567890123456789012")
+			uc := usecase.NewSecretUsecase(repo, mocks.NewMockSecretVersionRepository(ctrl), newFolderRepo(ctrl), mocks.NewMockUserKeyRepository(ctrl), newSettingsRepo(ctrl), newVaultRepo(ctrl, false), keys)
+			_, err := uc.ListSecrets(context.Background(), "user-1", domain.SecretFilter{FolderID: tt.folderID}, domain.PageRequest{})
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
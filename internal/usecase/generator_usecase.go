@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/pkg/password"
+)
+
+type generatorUsecase struct{}
+
+func NewGeneratorUsecase() domain.GeneratorUsecase {
+	return &generatorUsecase{}
+}
+
+func (u *generatorUsecase) DefaultOptions() domain.GeneratorOptions {
+	d := password.DefaultOptions()
+	p := password.DefaultPassphraseOptions()
+	return domain.GeneratorOptions{
+		Mode:          domain.GeneratorModePassword,
+		Length:        d.Length,
+		Uppercase:     d.Uppercase,
+		Lowercase:     d.Lowercase,
+		Numbers:       d.Numbers,
+		Symbols:       d.Symbols,
+		Words:         p.Words,
+		Separator:     p.Separator,
+		Capitalize:    p.Capitalize,
+		IncludeNumber: p.IncludeNumber,
+	}
+}
+
+func (u *generatorUsecase) Generate(ctx context.Context, opts domain.GeneratorOptions) (*domain.GeneratedPassword, error) {
+	var (
+		pw      string
+		entropy float64
+		err     error
+	)
+	switch opts.Mode {
+	case "", domain.GeneratorModePassword:
+		opts.Mode = domain.GeneratorModePassword
+		if opts.Rules != "" {
+			return generateWithRules(opts)
+		}
+		if opts.Length == 0 {
+			opts.Length = password.DefaultLength
+		}
+		o := password.Options{
+			Length:    opts.Length,
+			Uppercase: opts.Uppercase,
+			Lowercase: opts.Lowercase,
+			Numbers:   opts.Numbers,
+			Symbols:   opts.Symbols,
+		}
+		pw, err = password.Generate(o)
+		entropy = o.Entropy()
+	case domain.GeneratorModePassphrase:
+		if opts.Words == 0 {
+			opts.Words = password.DefaultWords
+		}
+		o := password.PassphraseOptions{
+			Words:         opts.Words,
+			Separator:     opts.Separator,
+			Capitalize:    opts.Capitalize,
+			IncludeNumber: opts.IncludeNumber,
+		}
+		pw, err = password.GeneratePassphrase(o)
+		entropy = o.Entropy()
+	default:
+		return nil, fmt.Errorf("%w: unknown mode %q", domain.ErrInvalidGeneratorOptions, opts.Mode)
+	}
+	if err != nil {
+		if isGeneratorOptionError(err) {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidGeneratorOptions, err)
+		}
+		return nil, fmt.Errorf("failed to generate password: %w", err)
+	}
+
+	return &domain.GeneratedPassword{
+		Password: pw,
+		Entropy:  math.Round(entropy*10) / 10,
+		Options:  opts,
+	}, nil
+}
+
+// generateWithRules follows a site's passwordrules instead of the class toggles.
+func generateWithRules(opts domain.GeneratorOptions) (*domain.GeneratedPassword, error) {
+	rules, err := password.ParseRules(opts.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidGeneratorOptions, err)
+	}
+	pw, err := password.GenerateWithRules(rules, opts.Length)
+	if err != nil {
+		if isGeneratorOptionError(err) {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidGeneratorOptions, err)
+		}
+		return nil, fmt.Errorf("failed to generate password: %w", err)
+	}
+	opts.Length = len(pw)
+	return &domain.GeneratedPassword{
+		Password: pw,
+		Entropy:  math.Round(rules.Entropy(len(pw))*10) / 10,
+		Options:  opts,
+	}, nil
+}
+
+func isGeneratorOptionError(err error) bool {
+	return errors.Is(err, password.ErrUnsatisfiableRules) ||
+		errors.Is(err, password.ErrInvalidLength) ||
+		errors.Is(err, password.ErrNoCharacterClass) ||
+		errors.Is(err, password.ErrInvalidWordCount) ||
+		errors.Is(err, password.ErrInvalidSeparator)
+}
+
+func (u *generatorUsecase) EstimateStrength(ctx context.Context, pw string, userInputs []string) *domain.PasswordStrength {
+	return toPasswordStrength(password.EstimateStrength(pw, userInputs...))
+}
+
+func toPasswordStrength(s password.Strength) *domain.PasswordStrength {
+	sequence := make([]domain.StrengthMatch, 0, len(s.Sequence))
+	for _, m := range s.Sequence {
+		sequence = append(sequence, domain.StrengthMatch{Pattern: m.Pattern, Token: m.Token, Guesses: m.Guesses})
+	}
+	return &domain.PasswordStrength{
+		Score:   s.Score,
+		Guesses: s.Guesses,
+		Entropy: math.Round(s.Entropy*10) / 10,
+		CrackTimes: domain.CrackTimes{
+			OnlineThrottled:    domain.CrackTime(s.CrackTimes.OnlineThrottled),
+			OnlineUnthrottled:  domain.CrackTime(s.CrackTimes.OnlineUnthrottled),
+			OfflineSlowHashing: domain.CrackTime(s.CrackTimes.OfflineSlowHashing),
+			OfflineFastHashing: domain.CrackTime(s.CrackTimes.OfflineFastHashing),
+		},
+		Sequence:    sequence,
+		Warning:     s.Feedback.Warning,
+		Suggestions: s.Feedback.Suggestions,
+	}
+}
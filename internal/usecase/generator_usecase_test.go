@@ -0,0 +1,78 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratorUsecase_Generate(t *testing.T) {
+	uc := usecase.NewGeneratorUsecase()
+	strong := uc.DefaultOptions()
+
+	passphrase := strong
+	passphrase.Mode = domain.GeneratorModePassphrase
+
+	tests := []struct {
+		name        string
+		opts        domain.GeneratorOptions
+		wantLength  int
+		wantWords   int
+		wantEntropy float64
+		expectError error
+	}{
+		{name: "Strong Default", opts: strong, wantLength: 16, wantEntropy: 103.4},
+		{name: "Custom Length", opts: domain.GeneratorOptions{Mode: domain.GeneratorModePassword, Length: 32, Lowercase: true, Numbers: true}, wantLength: 32, wantEntropy: 165.4},
+		{name: "Too Short", opts: domain.GeneratorOptions{Length: 4, Lowercase: true}, expectError: domain.ErrInvalidGeneratorOptions},
+		{name: "Too Long", opts: domain.GeneratorOptions{Length: 128, Lowercase: true}, expectError: domain.ErrInvalidGeneratorOptions},
+		{name: "No Classes", opts: domain.GeneratorOptions{Length: 16}, expectError: domain.ErrInvalidGeneratorOptions},
+		{name: "Site Rules", opts: domain.GeneratorOptions{Mode: domain.GeneratorModePassword, Length: 12, Rules: "maxlength: 12; required: digit; allowed: lower"}, wantLength: 12, wantEntropy: 62},
+		{name: "Site Rules Override Classes", opts: domain.GeneratorOptions{Mode: domain.GeneratorModePassword, Length: 6, Symbols: true, Rules: "maxlength: 6; required: digit"}, wantLength: 6, wantEntropy: 19.9},
+		{name: "Invalid Rules", opts: domain.GeneratorOptions{Rules: "required: emoji"}, expectError: domain.ErrInvalidGeneratorOptions},
+		{name: "Unsatisfiable Rules", opts: domain.GeneratorOptions{Length: 30, Rules: "maxlength: 20"}, expectError: domain.ErrInvalidGeneratorOptions},
+		{name: "Passphrase Default", opts: passphrase, wantWords: 6, wantEntropy: 77.5},
+		{name: "Passphrase With Number", opts: domain.GeneratorOptions{Mode: domain.GeneratorModePassphrase, Words: 4, Separator: " ", IncludeNumber: true}, wantWords: 4, wantEntropy: 57},
+		{name: "Passphrase Too Few Words", opts: domain.GeneratorOptions{Mode: domain.GeneratorModePassphrase, Words: 2}, expectError: domain.ErrInvalidGeneratorOptions},
+		{name: "Unknown Mode", opts: domain.GeneratorOptions{Mode: "pin"}, expectError: domain.ErrInvalidGeneratorOptions},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := uc.Generate(context.Background(), tt.opts)
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			if tt.wantWords > 0 {
+				assert.Len(t, strings.Split(res.Password, tt.opts.Separator), tt.wantWords)
+			} else {
+				assert.Len(t, res.Password, tt.wantLength)
+			}
+			assert.Equal(t, tt.wantEntropy, res.Entropy)
+			assert.Equal(t, tt.opts, res.Options)
+		})
+	}
+}
+
+func TestGeneratorUsecase_EstimateStrength(t *testing.T) {
+	uc := usecase.NewGeneratorUsecase()
+
+	weak := uc.EstimateStrength(context.Background(), "Alice1990", []string{"alice"})
+	assert.Less(t, weak.Score, domain.MinStrongScore)
+	assert.NotEmpty(t, weak.Warning)
+	require.NotEmpty(t, weak.Sequence)
+	assert.Equal(t, "dictionary", weak.Sequence[0].Pattern)
+	assert.Equal(t, "Alice", weak.Sequence[0].Token)
+
+	strong := uc.EstimateStrength(context.Background(), "x7$Kp2!qLm9#Vw4z", nil)
+	assert.Equal(t, 4, strong.Score)
+	assert.Equal(t, 53.2, strong.Entropy)
+	assert.Equal(t, "centuries", strong.CrackTimes.OfflineSlowHashing.Display)
+	assert.Empty(t, strong.Warning)
+}
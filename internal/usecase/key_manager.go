@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/pkg/crypto"
+)
+
+// keyManager implements envelope encryption: each user's secrets are encrypted
+// with their own data key, and only the wrapped data key is stored in user_keys.
+type keyManager struct {
+	repo    domain.UserKeyRepository
+	keyring *crypto.Keyring
+}
+
+// Associated data that binds each ciphertext to where it is stored, so a row
+// copied elsewhere (another secret, another user) no longer decrypts.
+func userKeyContext(userID string) []byte {
+	return []byte("user_key:" + userID)
+}
+
+func secretContext(secretID, userID string) []byte {
+	return []byte("secret:" + secretID + ":user:" + userID)
+}
+
+var backupContext = []byte("backup")
+
+func newKeyManager(repo domain.UserKeyRepository, keyring *crypto.Keyring) *keyManager {
+	return &keyManager{
+		repo:    repo,
+		keyring: keyring,
+	}
+}
+
+// DataKey returns the user's unwrapped data key, generating one on first use.
+func (k *keyManager) DataKey(ctx context.Context, userID string) ([]byte, error) {
+	key, err := k.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if key == nil {
+		dataKey, err := crypto.GenerateDataKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate data key: %w", err)
+		}
+		wrapped, err := k.keyring.WrapKey(ctx, dataKey, userKeyContext(userID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to wrap data key: %w", err)
+		}
+		if err := k.repo.Create(ctx, &domain.UserKey{UserID: userID, WrappedKey: wrapped}); err != nil {
+			return nil, err
+		}
+
+		// Re-read so that concurrent first writes all end up using the stored key.
+		key, err = k.repo.GetByUserID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if key == nil {
+			return nil, fmt.Errorf("data key for user %s was not stored", userID)
+		}
+	}
+
+	dataKey, err := k.keyring.UnwrapKey(ctx, key.WrappedKey, userKeyContext(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	return dataKey, nil
+}
+
+// Encrypt encrypts plainText with the user's data key, bound to associatedData.
+func (k *keyManager) Encrypt(ctx context.Context, userID, plainText string, associatedData []byte) (string, error) {
+	dataKey, err := k.DataKey(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return k.keyring.EncryptWith([]byte(plainText), associatedData, crypto.DataKeyID, dataKey)
+}
+
+// Decrypt decrypts cipherText with the user's data key. Rows written before
+// per-user keys existed were encrypted with the master key directly and carry
+// no version header, so the master keys are tried as a fallback for those.
+func (k *keyManager) Decrypt(ctx context.Context, userID, cipherText string, associatedData []byte) (string, error) {
+	dataKey, err := k.DataKey(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	plainText, err := k.keyring.DecryptWith(ctx, cipherText, associatedData, crypto.DataKeyID, dataKey)
+	if err != nil {
+		return "", err
+	}
+	return string(plainText), nil
+}
+
+// IsCurrent reports whether cipherText is already sealed with a data key in
+// the current, context-bound format and cipher, i.e. whether the rotation job
+// can skip it.
+func (k *keyManager) IsCurrent(cipherText string) bool {
+	return k.keyring.IsCurrentWith(cipherText, crypto.DataKeyID)
+}
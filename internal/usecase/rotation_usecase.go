@@ -0,0 +1,230 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/pkg/crypto"
+)
+
+const rotationBatchSize = 100
+
+type rotationUsecase struct {
+	secretRepo domain.SecretRepository
+	keyRepo    domain.UserKeyRepository
+	keys       *keyManager
+	sealer     *secretSealer
+	keyring    *crypto.Keyring
+
+	mu       sync.Mutex
+	progress domain.RotationProgress
+}
+
+func NewRotationUsecase(secretRepo domain.SecretRepository, keyRepo domain.UserKeyRepository, settingsRepo domain.SettingsRepository, keyProvider crypto.KeyProvider) domain.RotationUsecase {
+	keyring := crypto.NewKeyring(keyProvider)
+	keys := newKeyManager(keyRepo, keyring)
+	return &rotationUsecase{
+		secretRepo: secretRepo,
+		keyRepo:    keyRepo,
+		keys:       keys,
+		sealer:     newSecretSealer(keys, settingsRepo),
+		keyring:    keyring,
+		progress:   domain.RotationProgress{CurrentKeyID: keyring.CurrentID()},
+	}
+}
+
+func (u *rotationUsecase) Progress() domain.RotationProgress {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.progress
+}
+
+func (u *rotationUsecase) update(fn func(p *domain.RotationProgress)) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	fn(&u.progress)
+}
+
+func (u *rotationUsecase) fail(err error) {
+	u.update(func(p *domain.RotationProgress) {
+		p.Failures++
+		p.LastError = err.Error()
+	})
+	log.Printf("key rotation: %v", err)
+}
+
+func (u *rotationUsecase) Run(ctx context.Context) error {
+	u.mu.Lock()
+	if u.progress.Running {
+		u.mu.Unlock()
+		return fmt.Errorf("key rotation is already running")
+	}
+	now := time.Now()
+	u.progress = domain.RotationProgress{
+		Running:      true,
+		CurrentKeyID: u.keyring.CurrentID(),
+		StartedAt:    &now,
+	}
+	u.mu.Unlock()
+
+	err := u.run(ctx)
+
+	finished := time.Now()
+	u.update(func(p *domain.RotationProgress) {
+		p.Running = false
+		p.FinishedAt = &finished
+		if err != nil {
+			p.LastError = err.Error()
+		}
+	})
+
+	p := u.Progress()
+	log.Printf("key rotation finished: %d/%d data keys re-wrapped, %d/%d secrets re-encrypted, %d failures",
+		p.KeysRewrapped, p.KeysTotal, p.SecretsReencrypted, p.SecretsTotal, p.Failures)
+	return err
+}
+
+func (u *rotationUsecase) run(ctx context.Context) error {
+	keysTotal, err := u.keyRepo.Count(ctx)
+	if err != nil {
+		return err
+	}
+	secretsTotal, err := u.secretRepo.Count(ctx)
+	if err != nil {
+		return err
+	}
+	u.update(func(p *domain.RotationProgress) {
+		p.KeysTotal = keysTotal
+		p.SecretsTotal = secretsTotal
+	})
+
+	// 1. Re-wrap data keys so that every one is sealed by the current master key.
+	after := ""
+	for {
+		batch, err := u.keyRepo.ListBatch(ctx, after, rotationBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		for _, key := range batch {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			rewrapped, err := u.rewrapKey(ctx, key)
+			if err != nil {
+				u.fail(fmt.Errorf("data key of user %s: %w", key.UserID, err))
+			}
+			u.update(func(p *domain.RotationProgress) {
+				p.KeysScanned++
+				if rewrapped {
+					p.KeysRewrapped++
+				}
+			})
+		}
+		after = batch[len(batch)-1].UserID
+		p := u.Progress()
+		log.Printf("key rotation: scanned %d/%d data keys", p.KeysScanned, p.KeysTotal)
+	}
+
+	// 2. Re-encrypt secrets still sealed in a legacy format or not yet bound
+	// to their row with associated data, and seal fields written in plaintext
+	// before their owner chose to encrypt them.
+	after = ""
+	for {
+		batch, err := u.secretRepo.ListBatch(ctx, after, rotationBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		for _, secret := range batch {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			reencrypted, err := u.reencryptSecret(ctx, secret)
+			if err != nil {
+				u.fail(fmt.Errorf("secret %s: %w", secret.ID, err))
+			}
+			u.update(func(p *domain.RotationProgress) {
+				p.SecretsScanned++
+				if reencrypted {
+					p.SecretsReencrypted++
+				}
+			})
+		}
+		after = batch[len(batch)-1].ID
+		p := u.Progress()
+		log.Printf("key rotation: scanned %d/%d secrets", p.SecretsScanned, p.SecretsTotal)
+	}
+
+	return nil
+}
+
+func (u *rotationUsecase) rewrapKey(ctx context.Context, key *domain.UserKey) (bool, error) {
+	if u.keyring.IsCurrent(key.WrappedKey) {
+		return false, nil
+	}
+	dataKey, err := u.keyring.UnwrapKey(ctx, key.WrappedKey, userKeyContext(key.UserID))
+	if err != nil {
+		return false, err
+	}
+	wrapped, err := u.keyring.WrapKey(ctx, dataKey, userKeyContext(key.UserID))
+	if err != nil {
+		return false, err
+	}
+	return u.keyRepo.ReplaceWrappedKey(ctx, key.UserID, key.WrappedKey, wrapped)
+}
+
+func (u *rotationUsecase) reencryptSecret(ctx context.Context, secret *domain.Secret) (bool, error) {
+	resealed, err := u.resealFields(ctx, secret)
+	if err != nil {
+		return false, err
+	}
+	if u.keys.IsCurrent(secret.EncryptedPassword) {
+		return resealed, nil
+	}
+	aad := secretContext(secret.ID, secret.UserID)
+	password, err := u.keys.Decrypt(ctx, secret.UserID, secret.EncryptedPassword, aad)
+	if err != nil {
+		return false, err
+	}
+	encrypted, err := u.keys.Encrypt(ctx, secret.UserID, password, aad)
+	if err != nil {
+		return false, err
+	}
+	// If the user edited the secret meanwhile, their write already used the new format.
+	replaced, err := u.secretRepo.ReplaceEncryptedPassword(ctx, secret.ID, secret.EncryptedPassword, encrypted)
+	return resealed || replaced, err
+}
+
+// resealFields moves plaintext username and metadata into the encrypted
+// payload, and re-encrypts a payload that is not in the current format.
+func (u *rotationUsecase) resealFields(ctx context.Context, secret *domain.Secret) (bool, error) {
+	plain, err := u.sealer.plaintextFields(ctx, secret.UserID)
+	if err != nil {
+		return false, err
+	}
+	stalePayload := secret.EncryptedPayload != "" && !u.keys.IsCurrent(secret.EncryptedPayload)
+	if !stalePayload && !u.sealer.NeedsReseal(secret, plain) {
+		return false, nil
+	}
+
+	// Work on a copy so the password fields of secret stay as listed.
+	sealed := *secret
+	if err := u.sealer.Open(ctx, &sealed); err != nil {
+		return false, err
+	}
+	if err := u.sealer.sealWith(ctx, &sealed, plain); err != nil {
+		return false, err
+	}
+	// If the user edited the secret meanwhile, their write already sealed it
+	// with the current key.
+	return u.secretRepo.Reseal(ctx, &sealed, secret.EncryptedPayload)
+}
@@ -0,0 +1,98 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/internal/mocks"
+	"github.com/herdiagusthio/password-manager/internal/usecase"
+	"github.com/herdiagusthio/password-manager/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRotationUsecase_Run(t *testing.T) {
+	oldKey := []byte("12345678901234567890123456789012")
+	newKey := []byte("abcdefghijklmnopqrstuvwxyzABCDEF")
+	dataKey := []byte("abcdefghijklmnopqrstuvwxyz012345")
+
+	oldKeys, err := crypto.NewStaticProvider("1", map[string][]byte{"1": oldKey})
+	require.NoError(t, err)
+	keys, err := crypto.NewStaticProvider("2", map[string][]byte{"1": oldKey, "2": newKey})
+	require.NoError(t, err)
+	keyring := crypto.NewKeyring(keys)
+
+	staleWrapped, err := crypto.NewKeyring(oldKeys).WrapKey(context.Background(), dataKey, []byte("user_key:user-1"))
+	require.NoError(t, err)
+	legacyPassword, err := crypto.Encrypt("from before data keys", string(oldKey))
+	require.NoError(t, err)
+	unboundPassword, err := crypto.Encrypt("data key, no header", string(dataKey))
+	require.NoError(t, err)
+	currentPassword, err := crypto.EncryptWithKey([]byte("already current"), crypto.DataKeyID, dataKey, []byte("secret:sec-3:user:user-1"))
+	require.NoError(t, err)
+	plaintextNotes, err := crypto.EncryptWithKey([]byte("notes written before sealing"), crypto.DataKeyID, dataKey, []byte("secret:sec-4:user:user-1"))
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	wrapped := staleWrapped
+	keyRepo := mocks.NewMockUserKeyRepository(ctrl)
+	keyRepo.EXPECT().Count(gomock.Any()).Return(1, nil)
+	keyRepo.EXPECT().ListBatch(gomock.Any(), "", gomock.Any()).Return([]*domain.UserKey{{UserID: "user-1", WrappedKey: staleWrapped}}, nil)
+	keyRepo.EXPECT().ListBatch(gomock.Any(), "user-1", gomock.Any()).Return(nil, nil)
+	keyRepo.EXPECT().ReplaceWrappedKey(gomock.Any(), "user-1", staleWrapped, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, userID, oldValue, newValue string) (bool, error) {
+			assert.True(t, keyring.IsCurrent(newValue))
+			wrapped = newValue
+			return true, nil
+		})
+	keyRepo.EXPECT().GetByUserID(gomock.Any(), "user-1").DoAndReturn(func(ctx context.Context, userID string) (*domain.UserKey, error) {
+		return &domain.UserKey{UserID: userID, WrappedKey: wrapped}, nil
+	}).AnyTimes()
+
+	secretRepo := mocks.NewMockSecretRepository(ctrl)
+	secretRepo.EXPECT().Count(gomock.Any()).Return(4, nil)
+	secretRepo.EXPECT().ListBatch(gomock.Any(), "", gomock.Any()).Return([]*domain.Secret{
+		{ID: "sec-1", UserID: "user-1", EncryptedPassword: legacyPassword},
+		{ID: "sec-2", UserID: "user-1", EncryptedPassword: unboundPassword},
+		{ID: "sec-3", UserID: "user-1", EncryptedPassword: currentPassword},
+		{ID: "sec-4", UserID: "user-1", EncryptedPassword: plaintextNotes, Metadata: map[string]interface{}{"notes": "in the clear"}},
+	}, nil)
+	secretRepo.EXPECT().ListBatch(gomock.Any(), "sec-4", gomock.Any()).Return(nil, nil)
+	secretRepo.EXPECT().ReplaceEncryptedPassword(gomock.Any(), "sec-1", legacyPassword, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, id, oldValue, newValue string) (bool, error) {
+			plain, err := keyring.DecryptWith(ctx, newValue, []byte("secret:sec-1:user:user-1"), crypto.DataKeyID, dataKey)
+			require.NoError(t, err)
+			assert.Equal(t, "from before data keys", string(plain))
+			return true, nil
+		})
+	secretRepo.EXPECT().ReplaceEncryptedPassword(gomock.Any(), "sec-2", unboundPassword, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, id, oldValue, newValue string) (bool, error) {
+			assert.True(t, crypto.IsBound(newValue))
+			return true, nil
+		})
+
+	secretRepo.EXPECT().Reseal(gomock.Any(), gomock.Any(), "").DoAndReturn(func(ctx context.Context, s *domain.Secret, oldPayload string) (bool, error) {
+		assert.Equal(t, "sec-4", s.ID)
+		assert.Empty(t, s.Metadata)
+		assert.True(t, crypto.IsBound(s.EncryptedPayload))
+		assert.Equal(t, plaintextNotes, s.EncryptedPassword)
+		return true, nil
+	})
+
+	uc := usecase.NewRotationUsecase(secretRepo, keyRepo, newSettingsRepo(ctrl), keys)
+	require.NoError(t, uc.Run(context.Background()))
+
+	progress := uc.Progress()
+	assert.False(t, progress.Running)
+	assert.Equal(t, "2", progress.CurrentKeyID)
+	assert.Equal(t, 1, progress.KeysRewrapped)
+	assert.Equal(t, 4, progress.SecretsScanned)
+	assert.Equal(t, 3, progress.SecretsReencrypted)
+	assert.Zero(t, progress.Failures)
+	assert.Equal(t, domain.RotationStatus{Finished: true}, progress.Status())
+	assert.NotNil(t, progress.FinishedAt)
+}
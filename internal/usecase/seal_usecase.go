@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"bytes"
+	"log"
+	"sync"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/pkg/crypto"
+	"github.com/herdiagusthio/password-manager/pkg/seal"
+)
+
+type sealUsecase struct {
+	mu       sync.Mutex
+	keys     *crypto.StaticProvider
+	file     *seal.File
+	shares   [][]byte
+	unsealed chan struct{}
+}
+
+// NewSealUsecase returns a usecase that loads keys with the master keys in
+// file once enough shares are provided. A nil file means the keys came from
+// another provider and nothing is sealed.
+func NewSealUsecase(keys *crypto.StaticProvider, file *seal.File) domain.SealUsecase {
+	uc := &sealUsecase{
+		keys:     keys,
+		file:     file,
+		unsealed: make(chan struct{}),
+	}
+	if file == nil || !keys.Sealed() {
+		close(uc.unsealed)
+	}
+	return uc
+}
+
+func (u *sealUsecase) Status() domain.SealStatus {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.status()
+}
+
+func (u *sealUsecase) status() domain.SealStatus {
+	if u.file == nil {
+		return domain.SealStatus{}
+	}
+	return domain.SealStatus{
+		Sealed:    u.keys.Sealed(),
+		Threshold: u.file.Threshold,
+		Shares:    u.file.Shares,
+		Progress:  len(u.shares),
+	}
+}
+
+func (u *sealUsecase) Unseal(share string) (domain.SealStatus, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.file == nil || !u.keys.Sealed() {
+		return u.status(), nil
+	}
+
+	raw, err := seal.DecodeShare(share)
+	if err != nil {
+		return u.status(), domain.ErrMalformedUnsealShare
+	}
+	for _, s := range u.shares {
+		if bytes.Equal(s, raw) {
+			return u.status(), nil
+		}
+	}
+	u.shares = append(u.shares, raw)
+	if len(u.shares) < u.file.Threshold {
+		return u.status(), nil
+	}
+
+	// Shares are only held until this attempt, successful or not.
+	keys, err := u.file.Open(u.shares)
+	u.shares = nil
+	if err != nil {
+		log.Printf("Unseal failed: %v", err)
+		return u.status(), domain.ErrInvalidUnsealShares
+	}
+	if err := u.keys.Load(keys.CurrentID, keys.Keys); err != nil {
+		return u.status(), err
+	}
+
+	log.Printf("Keyring unsealed (current key %s)", keys.CurrentID)
+	close(u.unsealed)
+	return u.status(), nil
+}
+
+func (u *sealUsecase) Unsealed() <-chan struct{} {
+	return u.unsealed
+}
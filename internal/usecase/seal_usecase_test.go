@@ -0,0 +1,61 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/internal/usecase"
+	"github.com/herdiagusthio/password-manager/pkg/crypto"
+	"github.com/herdiagusthio/password-manager/pkg/seal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealUsecase_Unseal(t *testing.T) {
+	masterKey := []byte("12345678901234567890123456789012")
+	file, shares, err := seal.Init(&seal.Keys{CurrentID: "1", Keys: map[string][]byte{"1": masterKey}}, 3, 2)
+	require.NoError(t, err)
+	_, otherShares, err := seal.Init(&seal.Keys{CurrentID: "1", Keys: map[string][]byte{"1": masterKey}}, 3, 2)
+	require.NoError(t, err)
+
+	keys := crypto.NewSealedProvider()
+	uc := usecase.NewSealUsecase(keys, file)
+	assert.Equal(t, domain.SealStatus{Sealed: true, Threshold: 2, Shares: 3}, uc.Status())
+
+	_, err = uc.Unseal("not a share")
+	assert.ErrorIs(t, err, domain.ErrMalformedUnsealShare)
+
+	// A share from another seal combines into the wrong key and resets progress.
+	status, err := uc.Unseal(shares[0])
+	require.NoError(t, err)
+	assert.Equal(t, 1, status.Progress)
+	status, err = uc.Unseal(otherShares[1])
+	assert.ErrorIs(t, err, domain.ErrInvalidUnsealShares)
+	assert.True(t, status.Sealed)
+	assert.Equal(t, 0, status.Progress)
+
+	status, err = uc.Unseal(shares[2])
+	require.NoError(t, err)
+	status, err = uc.Unseal(shares[2])
+	require.NoError(t, err)
+	assert.Equal(t, 1, status.Progress, "duplicate share is ignored")
+
+	select {
+	case <-uc.Unsealed():
+		t.Fatal("unsealed too early")
+	default:
+	}
+
+	status, err = uc.Unseal(shares[1])
+	require.NoError(t, err)
+	assert.False(t, status.Sealed)
+	assert.False(t, keys.Sealed())
+	assert.Equal(t, "1", keys.CurrentKeyID())
+	<-uc.Unsealed()
+}
+
+func TestSealUsecase_NotSealed(t *testing.T) {
+	uc := usecase.NewSealUsecase(nil, nil)
+	assert.False(t, uc.Status().Sealed)
+	<-uc.Unsealed()
+}
@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+)
+
+// sealedPayload is the encrypted part of a secret: the username and any
+// metadata the owner has not chosen to keep in plaintext.
+type sealedPayload struct {
+	Username string                 `json:"username,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+func (p *sealedPayload) empty() bool {
+	return p.Username == "" && len(p.Metadata) == 0
+}
+
+func payloadContext(secretID, userID string) []byte {
+	return []byte("secret_payload:" + secretID + ":user:" + userID)
+}
+
+// secretSealer splits a secret into the plaintext fields used for listing and
+// searching and an encrypted payload holding everything else. Fields its type
+// marks sensitive are always sealed.
+type secretSealer struct {
+	keys     *keyManager
+	settings domain.SettingsRepository
+}
+
+func newSecretSealer(keys *keyManager, settings domain.SettingsRepository) *secretSealer {
+	return &secretSealer{
+		keys:     keys,
+		settings: settings,
+	}
+}
+
+// plaintextFields returns the owner's plaintext field choice as a set.
+func (s *secretSealer) plaintextFields(ctx context.Context, userID string) (map[string]bool, error) {
+	settings, err := s.settings.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return fieldSet(settings.PlaintextFields), nil
+}
+
+func fieldSet(fields []string) map[string]bool {
+	set := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		set[f] = true
+	}
+	return set
+}
+
+// Seal encrypts the fields the owner keeps private into secret.EncryptedPayload.
+// The secret must be fully decrypted (see Open) and have its ID assigned.
+func (s *secretSealer) Seal(ctx context.Context, secret *domain.Secret) error {
+	plain, err := s.plaintextFields(ctx, secret.UserID)
+	if err != nil {
+		return err
+	}
+	return s.sealWith(ctx, secret, plain)
+}
+
+func (s *secretSealer) sealWith(ctx context.Context, secret *domain.Secret, plain map[string]bool) error {
+	var payload sealedPayload
+	if !plain["username"] {
+		payload.Username = secret.Username
+		secret.Username = ""
+	}
+
+	var public map[string]interface{}
+	for key, value := range secret.Metadata {
+		if plain[key] && !sensitiveField(secret.Type, key) {
+			if public == nil {
+				public = make(map[string]interface{})
+			}
+			public[key] = value
+			continue
+		}
+		if payload.Metadata == nil {
+			payload.Metadata = make(map[string]interface{})
+		}
+		payload.Metadata[key] = value
+	}
+	secret.Metadata = public
+
+	if payload.empty() {
+		secret.EncryptedPayload = ""
+		return nil
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sealed fields: %w", err)
+	}
+	encrypted, err := s.keys.Encrypt(ctx, secret.UserID, string(data), payloadContext(secret.ID, secret.UserID))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt sealed fields: %w", err)
+	}
+	secret.EncryptedPayload = encrypted
+	return nil
+}
+
+// Open decrypts secret.EncryptedPayload and merges its fields back into the secret.
+func (s *secretSealer) Open(ctx context.Context, secret *domain.Secret) error {
+	if secret.EncryptedPayload == "" {
+		return nil
+	}
+
+	data, err := s.keys.Decrypt(ctx, secret.UserID, secret.EncryptedPayload, payloadContext(secret.ID, secret.UserID))
+	if err != nil {
+		return fmt.Errorf("failed to decrypt sealed fields: %w", err)
+	}
+	var payload sealedPayload
+	if err := json.Unmarshal([]byte(data), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal sealed fields: %w", err)
+	}
+
+	if payload.Username != "" {
+		secret.Username = payload.Username
+	}
+	if len(payload.Metadata) > 0 && secret.Metadata == nil {
+		secret.Metadata = make(map[string]interface{}, len(payload.Metadata))
+	}
+	for key, value := range payload.Metadata {
+		secret.Metadata[key] = value
+	}
+	secret.EncryptedPayload = ""
+	return nil
+}
+
+// NeedsReseal reports whether a stored secret keeps a field in plaintext that
+// the owner wants sealed, e.g. rows written before sealing existed.
+func (s *secretSealer) NeedsReseal(secret *domain.Secret, plain map[string]bool) bool {
+	if secret.Username != "" && !plain["username"] {
+		return true
+	}
+	for key := range secret.Metadata {
+		if !plain[key] || sensitiveField(secret.Type, key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/pkg/totp"
+)
+
+func (u *secretUsecase) GetTOTP(ctx context.Context, id string, userID string) (*domain.TOTPCode, error) {
+	secret, err := u.ownedSecret(ctx, id, userID)
+	if err != nil || secret == nil {
+		return nil, err
+	}
+	// The seed is a sealed field, so the password itself stays encrypted
+	if err := u.sealer.Open(ctx, secret); err != nil {
+		return nil, err
+	}
+	// In zero-knowledge mode the seed is inside the browser's blob
+	uri, _ := secret.Metadata[domain.TOTPKey].(string)
+	if uri == "" {
+		return nil, domain.ErrNoTOTP
+	}
+	key, err := totp.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("stored TOTP seed: %w", err)
+	}
+
+	code, remaining := key.Code(time.Now())
+	if err := u.repo.MarkUsed(ctx, secret.ID); err != nil {
+		return nil, err
+	}
+	return &domain.TOTPCode{
+		Code:      code,
+		Remaining: remaining,
+		Period:    key.Period,
+		Digits:    key.Digits,
+		Algorithm: key.Algorithm,
+	}, nil
+}
+
+func (u *secretUsecase) DecodeTOTPImage(image io.Reader) (*domain.TOTPKeyInfo, error) {
+	text, err := totp.DecodeQR(image)
+	if err != nil {
+		if errors.Is(err, totp.ErrNoQRCode) {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidTOTP, err)
+		}
+		return nil, err
+	}
+	key, err := totp.Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: the QR code does not hold an otpauth://totp/ URI", domain.ErrInvalidTOTP)
+	}
+	return &domain.TOTPKeyInfo{
+		URI:       key.URI(),
+		Issuer:    key.Issuer,
+		Account:   key.Account,
+		Algorithm: key.Algorithm,
+		Digits:    key.Digits,
+		Period:    key.Period,
+	}, nil
+}
@@ -0,0 +1,98 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/internal/mocks"
+	"github.com/herdiagusthio/password-manager/internal/usecase"
+	"github.com/herdiagusthio/password-manager/pkg/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSecretUsecase_GetTOTP(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]interface{}
+		// Expected key parameters; nil means ErrNoTOTP
+		expected *domain.TOTPCode
+	}{
+		{
+			name:     "Bare Seed",
+			metadata: map[string]interface{}{"totp": "GEZD GNBV GY3T QOJQ GEZD GNBV GY3T QOJQ"},
+			expected: &domain.TOTPCode{Period: 30, Digits: 6, Algorithm: totp.SHA1},
+		},
+		{
+			name:     "URI",
+			metadata: map[string]interface{}{"totp": "otpauth://totp/Example:alice?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&algorithm=SHA512&digits=8&period=60"},
+			expected: &domain.TOTPCode{Period: 60, Digits: 8, Algorithm: totp.SHA512},
+		},
+		{
+			name:     "No Seed",
+			metadata: map[string]interface{}{"url": "https://example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			keys := newKeyProvider(t, "12345678901234567890123456789012")
+			repo := mocks.NewMockSecretRepository(ctrl)
+			var stored *domain.Secret
+			repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, s *domain.Secret) error {
+				stored = s
+				return nil
+			})
+			uc := usecase.NewSecretUsecase(repo, mocks.NewMockSecretVersionRepository(ctrl), mocks.NewMockFolderRepository(ctrl), newKeyRepo(t, ctrl, keys, []byte("abcdefghijklmnopqrstuvwxyz012345")), newSettingsRepo(ctrl), newVaultRepo(ctrl, false), keys)
+			require.NoError(t, uc.CreateSecret(context.Background(), &domain.Secret{UserID: "user-1", Title: "Example", Password: "correct horse battery staple", Metadata: tt.metadata}))
+
+			// The seed is sealed even though nothing else is
+			_, plain := stored.Metadata["totp"]
+			assert.False(t, plain)
+
+			repo.EXPECT().GetByID(gomock.Any(), stored.ID).Return(stored, nil)
+			if tt.expected != nil {
+				repo.EXPECT().MarkUsed(gomock.Any(), stored.ID).Return(nil)
+			}
+
+			before := time.Now()
+			code, err := uc.GetTOTP(context.Background(), stored.ID, "user-1")
+			if tt.expected == nil {
+				assert.ErrorIs(t, err, domain.ErrNoTOTP)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected.Period, code.Period)
+			assert.Equal(t, tt.expected.Digits, code.Digits)
+			assert.Equal(t, tt.expected.Algorithm, code.Algorithm)
+			assert.Len(t, code.Code, tt.expected.Digits)
+
+			// The code may have rolled over between the two clock readings
+			key, err := totp.Parse(tt.metadata["totp"].(string))
+			require.NoError(t, err)
+			first, _ := key.Code(before)
+			second, _ := key.Code(time.Now())
+			assert.Contains(t, []string{first, second}, code.Code)
+		})
+	}
+}
+
+func TestSecretUsecase_GetTOTP_WrongOwner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockSecretRepository(ctrl)
+	repo.EXPECT().GetByID(gomock.Any(), "sec-1").Return(&domain.Secret{ID: "sec-1", UserID: "user-2"}, nil)
+
+	keys := newKeyProvider(t, "12345678901234567890123456789012")
+	uc := usecase.NewSecretUsecase(repo, mocks.NewMockSecretVersionRepository(ctrl), mocks.NewMockFolderRepository(ctrl), mocks.NewMockUserKeyRepository(ctrl), newSettingsRepo(ctrl), newVaultRepo(ctrl, false), keys)
+	code, err := uc.GetTOTP(context.Background(), "sec-1", "user-1")
+	assert.Error(t, err)
+	assert.Nil(t, code)
+}
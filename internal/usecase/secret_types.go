@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/pkg/totp"
+	"golang.org/x/crypto/ssh"
+)
+
+// checkSecretType validates a secret against the schema of its type and
+// normalizes its typed fields, e.g. a card number to its digits. A secret
+// without a type is a login.
+func checkSecretType(secret *domain.Secret) error {
+	if secret.Type == "" {
+		secret.Type = domain.SecretTypeLogin
+	}
+	schema, ok := domain.SchemaFor(secret.Type)
+	if !ok {
+		return fmt.Errorf("%w: unknown type %q", domain.ErrInvalidSecret, secret.Type)
+	}
+	if schema.UsernameLabel == "" && secret.Username != "" {
+		return fmt.Errorf("%w: %s secrets have no username", domain.ErrInvalidSecret, secret.Type)
+	}
+	// A client-encrypted password is a blob the browser writes for every type
+	if schema.PasswordLabel == "" && secret.Password != "" && !secret.ClientEncrypted {
+		return fmt.Errorf("%w: %s secrets have no password", domain.ErrInvalidSecret, secret.Type)
+	}
+
+	for key, value := range secret.Metadata {
+		field, known := schema.Field(key)
+		if !known {
+			if schema.AllowCustom {
+				continue
+			}
+			return fmt.Errorf("%w: %s secrets have no field %q", domain.ErrInvalidSecret, secret.Type, key)
+		}
+		str, isString := value.(string)
+		if !isString {
+			return fmt.Errorf("%w: %s must be a string", domain.ErrInvalidSecret, key)
+		}
+		str = strings.TrimSpace(str)
+		if str == "" {
+			delete(secret.Metadata, key)
+			continue
+		}
+		normalized, err := checkFieldFormat(field.Format, str)
+		if err != nil {
+			return fmt.Errorf("%w: %s %v", domain.ErrInvalidSecret, key, err)
+		}
+		secret.Metadata[key] = normalized
+	}
+
+	for _, field := range schema.Fields {
+		if !field.Required {
+			continue
+		}
+		// In zero-knowledge mode sensitive fields travel inside the password blob
+		if field.Sensitive && secret.ClientEncrypted {
+			continue
+		}
+		if _, ok := secret.Metadata[field.Name]; !ok {
+			return fmt.Errorf("%w: %s is required", domain.ErrInvalidSecret, field.Name)
+		}
+	}
+	return nil
+}
+
+// sensitiveField reports whether a metadata key must be sealed whatever the
+// owner keeps in plaintext.
+func sensitiveField(secretType domain.SecretType, key string) bool {
+	schema, ok := domain.SchemaFor(secretType)
+	if !ok {
+		return false
+	}
+	field, ok := schema.Field(key)
+	return ok && field.Sensitive
+}
+
+// scoredType reports whether secrets of a type get a strength score and can
+// be rotated.
+func scoredType(secretType domain.SecretType) bool {
+	schema, ok := domain.SchemaFor(secretType)
+	return ok && schema.Scored
+}
+
+var (
+	phonePattern  = regexp.MustCompile(`^\+?[0-9][0-9 ()./-]*$`)
+	digitsPattern = regexp.MustCompile(`^[0-9]+$`)
+	expiryPattern = regexp.MustCompile(`^(\d{1,2})\s*[/-]\s*(\d{2}|\d{4})$`)
+)
+
+// checkFieldFormat validates a non-empty field value and returns it in its
+// stored form.
+func checkFieldFormat(format, value string) (string, error) {
+	switch format {
+	case domain.FormatURL:
+		// url.Parse accepts nearly anything, "not a url" included
+		u, err := url.ParseRequestURI(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return "", errors.New("is not a valid URL, e.g. https://example.com")
+		}
+	case domain.FormatEmail:
+		addr, err := mail.ParseAddress(value)
+		if err != nil || addr.Name != "" {
+			return "", errors.New("is not a valid email address")
+		}
+	case domain.FormatPhone:
+		if !phonePattern.MatchString(value) {
+			return "", errors.New("is not a valid phone number")
+		}
+	case domain.FormatDate:
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return "", errors.New("must be a date such as 2030-12-31")
+		}
+	case domain.FormatCardNumber:
+		digits := strings.NewReplacer(" ", "", "-", "").Replace(value)
+		if !digitsPattern.MatchString(digits) || len(digits) < 12 || len(digits) > 19 {
+			return "", errors.New("must be 12 to 19 digits")
+		}
+		if !luhnValid(digits) {
+			return "", errors.New("fails the checksum")
+		}
+		return digits, nil
+	case domain.FormatCardExpiry:
+		m := expiryPattern.FindStringSubmatch(value)
+		if m == nil {
+			return "", errors.New("must be MM/YY")
+		}
+		month, _ := strconv.Atoi(m[1])
+		year, _ := strconv.Atoi(m[2])
+		if month < 1 || month > 12 {
+			return "", errors.New("has no such month")
+		}
+		return fmt.Sprintf("%02d/%02d", month, year%100), nil
+	case domain.FormatCVV:
+		if !digitsPattern.MatchString(value) || len(value) < 3 || len(value) > 4 {
+			return "", errors.New("must be 3 or 4 digits")
+		}
+	case domain.FormatSSHPrivateKey:
+		_, err := ssh.ParseRawPrivateKey([]byte(value))
+		var missing *ssh.PassphraseMissingError
+		if err != nil && !errors.As(err, &missing) {
+			return "", errors.New("is not a private key in PEM or OpenSSH format")
+		}
+		// ssh refuses key files without the final newline
+		return value + "\n", nil
+	case domain.FormatSSHPublicKey:
+		if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(value)); err != nil {
+			return "", errors.New("is not a public key in authorized_keys format")
+		}
+	case domain.FormatTOTP:
+		key, err := totp.Parse(value)
+		if err != nil {
+			return "", errors.New("is not a base32 TOTP secret or an otpauth://totp/ URI")
+		}
+		return key.URI(), nil
+	}
+	return value, nil
+}
+
+// luhnValid checks the Luhn checksum of a card number given as digits.
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
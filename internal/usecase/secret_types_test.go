@@ -0,0 +1,179 @@
+package usecase_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/internal/mocks"
+	"github.com/herdiagusthio/password-manager/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSecretUsecase_CreateSecret_Types(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	require.NoError(t, err)
+	privateKey := string(pem.EncodeToMemory(block))
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	publicKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPub)))
+
+	tests := []struct {
+		name      string
+		secret    *domain.Secret
+		expectErr bool
+		// Plaintext metadata left after sealing
+		expectedMetadata map[string]interface{}
+		expectedScore    bool
+	}{
+		{
+			name:             "Login By Default",
+			secret:           &domain.Secret{Title: "Gmail", Password: "correct horse battery staple", Metadata: map[string]interface{}{"url": "https://mail.google.com", "custom": "kept"}},
+			expectedMetadata: map[string]interface{}{"url": "https://mail.google.com"},
+			expectedScore:    true,
+		},
+		{
+			name: "Card",
+			secret: &domain.Secret{Type: domain.SecretTypeCard, Title: "Visa", Password: "1234", Metadata: map[string]interface{}{
+				"cardholder": "Jane Doe", "number": "4111 1111 1111 1111", "expiry": "3/2031", "cvv": "123",
+			}},
+			// The card number and CVV stay sealed even though the owner keeps "number" in plaintext
+			expectedMetadata: map[string]interface{}{"cardholder": "Jane Doe", "expiry": "03/31"},
+		},
+		{
+			name:      "Card Number Fails Checksum",
+			secret:    &domain.Secret{Type: domain.SecretTypeCard, Title: "Visa", Metadata: map[string]interface{}{"number": "4111111111111112", "expiry": "03/31"}},
+			expectErr: true,
+		},
+		{
+			name:      "Card Without Expiry",
+			secret:    &domain.Secret{Type: domain.SecretTypeCard, Title: "Visa", Metadata: map[string]interface{}{"number": "4111111111111111"}},
+			expectErr: true,
+		},
+		{
+			name:      "Card With Unknown Field",
+			secret:    &domain.Secret{Type: domain.SecretTypeCard, Title: "Visa", Metadata: map[string]interface{}{"number": "4111111111111111", "expiry": "03/31", "url": "https://bank.example"}},
+			expectErr: true,
+		},
+		{
+			name:   "Secure Note",
+			secret: &domain.Secret{Type: domain.SecretTypeNote, Title: "Recovery codes", Metadata: map[string]interface{}{"notes": "abcd-efgh"}},
+		},
+		{
+			name:      "Secure Note With Password",
+			secret:    &domain.Secret{Type: domain.SecretTypeNote, Title: "Recovery codes", Password: "oops", Metadata: map[string]interface{}{"notes": "abcd-efgh"}},
+			expectErr: true,
+		},
+		{
+			name: "SSH Key",
+			secret: &domain.Secret{Type: domain.SecretTypeSSHKey, Title: "Deploy key", Username: "deploy", Metadata: map[string]interface{}{
+				"host": "git.example.com", "private_key": privateKey, "public_key": publicKey,
+			}},
+			expectedMetadata: map[string]interface{}{"host": "git.example.com", "public_key": publicKey},
+		},
+		{
+			name:      "SSH Key Not A Key",
+			secret:    &domain.Secret{Type: domain.SecretTypeSSHKey, Title: "Deploy key", Metadata: map[string]interface{}{"private_key": "hunter2"}},
+			expectErr: true,
+		},
+		{
+			name:      "Login With Bad URL",
+			secret:    &domain.Secret{Title: "Gmail", Password: "correct horse battery staple", Metadata: map[string]interface{}{"url": "not a url"}},
+			expectErr: true,
+		},
+		{
+			name:      "Login URL Without Scheme",
+			secret:    &domain.Secret{Title: "Gmail", Password: "correct horse battery staple", Metadata: map[string]interface{}{"url": "mail.google.com"}},
+			expectErr: true,
+		},
+		{
+			name:      "Identity With Bad Email",
+			secret:    &domain.Secret{Type: domain.SecretTypeIdentity, Title: "Me", Metadata: map[string]interface{}{"full_name": "Jane Doe", "email": "jane at example"}},
+			expectErr: true,
+		},
+		{
+			name:      "Unknown Type",
+			secret:    &domain.Secret{Type: "wifi", Title: "Home"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			keys := newKeyProvider(t, "12345678901234567890123456789012")
+			settingsRepo := mocks.NewMockSettingsRepository(ctrl)
+			settingsRepo.EXPECT().Get(gomock.Any(), gomock.Any()).Return(&domain.UserSettings{
+				PlaintextFields: []string{"username", "url", "cardholder", "number", "expiry", "host", "public_key", "private_key"},
+			}, nil).AnyTimes()
+
+			repo := mocks.NewMockSecretRepository(ctrl)
+			var stored *domain.Secret
+			if !tt.expectErr {
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, s *domain.Secret) error {
+					stored = s
+					return nil
+				})
+			}
+
+			uc := usecase.NewSecretUsecase(repo, mocks.NewMockSecretVersionRepository(ctrl), mocks.NewMockFolderRepository(ctrl), newKeyRepo(t, ctrl, keys, []byte("abcdefghijklmnopqrstuvwxyz012345")), settingsRepo, newVaultRepo(ctrl, false), keys)
+			tt.secret.UserID = "user-1"
+			err := uc.CreateSecret(context.Background(), tt.secret)
+			if tt.expectErr {
+				assert.ErrorIs(t, err, domain.ErrInvalidSecret)
+				return
+			}
+			require.NoError(t, err)
+
+			if tt.secret.Type == domain.SecretTypeLogin || tt.secret.Type == "" {
+				assert.Equal(t, domain.SecretTypeLogin, stored.Type)
+			}
+			assert.Equal(t, tt.expectedMetadata, stored.Metadata)
+			assert.Equal(t, tt.expectedScore, stored.StrengthScore != nil)
+		})
+	}
+}
+
+func TestSecretUsecase_TypedSecretRules(t *testing.T) {
+	card := func() *domain.Secret {
+		return &domain.Secret{ID: "sec-1", UserID: "user-1", Type: domain.SecretTypeCard, Title: "Visa", Version: 1,
+			Metadata: map[string]interface{}{"expiry": "03/31"}}
+	}
+
+	t.Run("Type Cannot Change", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repo := mocks.NewMockSecretRepository(ctrl)
+		repo.EXPECT().GetByID(gomock.Any(), "sec-1").Return(card(), nil)
+
+		keys := newKeyProvider(t, "12345678901234567890123456789012")
+		uc := usecase.NewSecretUsecase(repo, mocks.NewMockSecretVersionRepository(ctrl), mocks.NewMockFolderRepository(ctrl), mocks.NewMockUserKeyRepository(ctrl), newSettingsRepo(ctrl), newVaultRepo(ctrl, false), keys)
+		err := uc.UpdateSecret(context.Background(), &domain.Secret{ID: "sec-1", UserID: "user-1", Type: domain.SecretTypeLogin, Title: "Visa", Version: 1})
+		assert.ErrorIs(t, err, domain.ErrInvalidSecret)
+	})
+
+	t.Run("Only Logins Rotate", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repo := mocks.NewMockSecretRepository(ctrl)
+		repo.EXPECT().GetByID(gomock.Any(), "sec-1").Return(card(), nil)
+
+		keys := newKeyProvider(t, "12345678901234567890123456789012")
+		uc := usecase.NewSecretUsecase(repo, mocks.NewMockSecretVersionRepository(ctrl), mocks.NewMockFolderRepository(ctrl), mocks.NewMockUserKeyRepository(ctrl), newSettingsRepo(ctrl), newVaultRepo(ctrl, false), keys)
+		_, err := uc.RotatePassword(context.Background(), "sec-1", "user-1")
+		assert.ErrorIs(t, err, domain.ErrInvalidSecret)
+	})
+}
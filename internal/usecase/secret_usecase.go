@@ -2,30 +2,145 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
+	"sort"
 
-	"github.com/herdiagusthio/password-manager/config"
+	"github.com/google/uuid"
 	"github.com/herdiagusthio/password-manager/internal/domain"
 	"github.com/herdiagusthio/password-manager/pkg/crypto"
+	"github.com/herdiagusthio/password-manager/pkg/mergepatch"
+	"github.com/herdiagusthio/password-manager/pkg/password"
 )
 
 type secretUsecase struct {
-	repo domain.SecretRepository
-	cfg  *config.Config
+	repo         domain.SecretRepository
+	versionRepo  domain.SecretVersionRepository
+	folderRepo   domain.FolderRepository
+	settingsRepo domain.SettingsRepository
+	vaultRepo    domain.VaultRepository
+	keys         *keyManager
+	sealer       *secretSealer
+	generator    domain.GeneratorUsecase
 }
 
-func NewSecretUsecase(repo domain.SecretRepository, cfg *config.Config) domain.SecretUsecase {
+func NewSecretUsecase(repo domain.SecretRepository, versionRepo domain.SecretVersionRepository, folderRepo domain.FolderRepository, keyRepo domain.UserKeyRepository, settingsRepo domain.SettingsRepository, vaultRepo domain.VaultRepository, keyProvider crypto.KeyProvider) domain.SecretUsecase {
+	keys := newKeyManager(keyRepo, crypto.NewKeyring(keyProvider))
 	return &secretUsecase{
-		repo: repo,
-		cfg:  cfg,
+		repo:         repo,
+		versionRepo:  versionRepo,
+		folderRepo:   folderRepo,
+		settingsRepo: settingsRepo,
+		vaultRepo:    vaultRepo,
+		keys:         keys,
+		sealer:       newSecretSealer(keys, settingsRepo),
+		generator:    NewGeneratorUsecase(),
 	}
 }
 
+// checkClientEncryption enforces zero-knowledge mode: once enabled, passwords
+// must arrive already encrypted by the browser. The server still wraps the
+// opaque blob with the data key like any other password.
+func (u *secretUsecase) checkClientEncryption(ctx context.Context, secret *domain.Secret) error {
+	vault, err := u.vaultRepo.GetByUserID(ctx, secret.UserID)
+	if err != nil {
+		return err
+	}
+	if !vault.ZeroKnowledge {
+		if secret.ClientEncrypted {
+			return fmt.Errorf("zero-knowledge mode is not enabled")
+		}
+		return nil
+	}
+	if !secret.ClientEncrypted || !crypto.IsClientBlob(secret.Password) {
+		return fmt.Errorf("zero-knowledge mode requires a password encrypted by the browser")
+	}
+	return nil
+}
+
+// scorePassword stores the strength score of a plaintext password. A
+// client-encrypted password cannot be analysed here, so the score sent by the
+// browser, if any, is kept. Only types with a password to log in with are
+// scored.
+func scorePassword(secret *domain.Secret) error {
+	if !scoredType(secret.Type) {
+		secret.StrengthScore = nil
+		return nil
+	}
+	if secret.ClientEncrypted {
+		if secret.StrengthScore != nil && (*secret.StrengthScore < 0 || *secret.StrengthScore > 4) {
+			return fmt.Errorf("strength_score must be between 0 and 4")
+		}
+		return nil
+	}
+	score := password.EstimateStrength(secret.Password, secret.Title, secret.Username).Score
+	secret.StrengthScore = &score
+	return nil
+}
+
+// checkPasswordRules makes sure a stored passwordrules string parses, so
+// regenerating the password later cannot fail on it.
+func checkPasswordRules(secret *domain.Secret) error {
+	value, ok := secret.Metadata[domain.PasswordRulesKey]
+	if !ok {
+		return nil
+	}
+	rules, isString := value.(string)
+	if !isString {
+		return fmt.Errorf("%s must be a string", domain.PasswordRulesKey)
+	}
+	if _, err := password.ParseRules(rules); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (u *secretUsecase) recordVersion(ctx context.Context, previous *domain.SecretVersion) error {
+	return recordVersion(ctx, u.versionRepo, u.settingsRepo, previous)
+}
+
+// recordVersion moves the stored state of a secret to history before it is
+// overwritten, then trims the history to the owner's limit.
+func recordVersion(ctx context.Context, versionRepo domain.SecretVersionRepository, settingsRepo domain.SettingsRepository, previous *domain.SecretVersion) error {
+	if err := versionRepo.Create(ctx, previous); err != nil {
+		return err
+	}
+	settings, err := settingsRepo.Get(ctx, previous.UserID)
+	if err != nil {
+		return err
+	}
+	return versionRepo.Prune(ctx, previous.UserID, settings.HistoryLimit)
+}
+
 func (u *secretUsecase) CreateSecret(ctx context.Context, secret *domain.Secret) error {
-	// Encrypt the password before saving
-	// Note: We use the system Master Key from config for simplicity in this version.
-	// Production apps might use a per-user key derived from KDF or KMS.
-	encrypted, err := crypto.Encrypt(secret.Password, u.cfg.EncryptionKey)
+	// The ID is assigned up front because the ciphertext is bound to it.
+	if secret.ID == "" {
+		secret.ID = uuid.NewString()
+	}
+
+	if secret.FolderID != nil {
+		if _, err := ownedFolder(ctx, u.folderRepo, *secret.FolderID, secret.UserID); err != nil {
+			return err
+		}
+	}
+	if err := u.checkClientEncryption(ctx, secret); err != nil {
+		return err
+	}
+	if err := checkSecretType(secret); err != nil {
+		return err
+	}
+	if err := scorePassword(secret); err != nil {
+		return err
+	}
+	if err := checkPasswordRules(secret); err != nil {
+		return err
+	}
+
+	// Encrypt the password before saving with the owner's data key.
+	// The data key itself is stored wrapped by the Master Key (envelope encryption).
+	encrypted, err := u.keys.Encrypt(ctx, secret.UserID, secret.Password, secretContext(secret.ID, secret.UserID))
 	if err != nil {
 		return fmt.Errorf("failed to encrypt password: %w", err)
 	}
@@ -34,6 +149,11 @@ func (u *secretUsecase) CreateSecret(ctx context.Context, secret *domain.Secret)
 	// Clear plain password from struct to avoid accidental leak later
 	secret.Password = "" 
 
+	// Seal the username and sensitive metadata the user keeps private
+	if err := u.sealer.Seal(ctx, secret); err != nil {
+		return err
+	}
+
 	return u.repo.Create(ctx, secret)
 }
 
@@ -42,8 +162,8 @@ func (u *secretUsecase) GetSecret(ctx context.Context, id string, userID string)
 	if err != nil {
 		return nil, err
 	}
-	if secret == nil {
-		return nil, nil // Not found
+	if secret == nil || secret.DeletedAt != nil {
+		return nil, nil // Not found, or in the trash
 	}
 
 	// Authorization check
@@ -52,36 +172,101 @@ func (u *secretUsecase) GetSecret(ctx context.Context, id string, userID string)
 	}
 
 	// Decrypt
-	decrypted, err := crypto.Decrypt(secret.EncryptedPassword, u.cfg.EncryptionKey)
+	decrypted, err := u.keys.Decrypt(ctx, secret.UserID, secret.EncryptedPassword, secretContext(secret.ID, secret.UserID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to decrypt password: %w", err)
 	}
 	secret.Password = decrypted
 
+	if err := u.sealer.Open(ctx, secret); err != nil {
+		return nil, err
+	}
+
+	// Revealing the password is what counts as using the secret
+	if err := u.repo.MarkUsed(ctx, secret.ID); err != nil {
+		return nil, err
+	}
+
 	return secret, nil
 }
 
-func (u *secretUsecase) ListSecrets(ctx context.Context, userID string) ([]*domain.Secret, error) {
-	// We list secrets but do NOT return the decrypted passwords in the list view for security/performance
-	return u.repo.ListByUserID(ctx, userID)
+func (u *secretUsecase) ListSecrets(ctx context.Context, userID string, filter domain.SecretFilter, page domain.PageRequest) (*domain.SecretPage, error) {
+	if page.Sort == "" {
+		page.Sort = domain.SortCreated
+	}
+	switch page.Sort {
+	case domain.SortCreated, domain.SortTitle, domain.SortUpdated, domain.SortLastUsed:
+	default:
+		return nil, domain.ErrInvalidSort
+	}
+	if page.Limit <= 0 {
+		page.Limit = domain.DefaultPageSize
+	}
+	if page.Limit > domain.MaxPageSize {
+		page.Limit = domain.MaxPageSize
+	}
+
+	if filter.FolderID != "" && filter.FolderID != domain.UnfiledFolderID {
+		if _, err := ownedFolder(ctx, u.folderRepo, filter.FolderID, userID); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := domain.SchemaFor(filter.Type); !ok {
+		return &domain.SecretPage{}, nil // No secret has it
+	}
+	if filter.Tag != "" {
+		tag, err := normalizeTag(filter.Tag)
+		if err != nil {
+			return &domain.SecretPage{}, nil // No secret can carry it
+		}
+		filter.Tag = tag
+	}
+	// We list secrets but do NOT return the decrypted passwords in the list view for security/performance.
+	// Likewise only the plaintext fields are shown and searched; sealed fields stay encrypted.
+	return u.repo.ListPage(ctx, userID, filter, page)
 }
 
 func (u *secretUsecase) UpdateSecret(ctx context.Context, secret *domain.Secret) error {
+	if secret.Version < 1 {
+		return domain.ErrVersionRequired
+	}
+
 	// Check existance and ownership first
 	existing, err := u.repo.GetByID(ctx, secret.ID)
 	if err != nil {
 		return err
 	}
-	if existing == nil {
+	if existing == nil || existing.DeletedAt != nil {
 		return fmt.Errorf("secret not found")
 	}
 	if existing.UserID != secret.UserID {
 		return fmt.Errorf("unauthorized update")
 	}
+	if existing.Version != secret.Version {
+		return &domain.VersionConflictError{Expected: secret.Version, Current: existing}
+	}
+	if secret.Type == "" {
+		secret.Type = existing.Type
+	}
+	if secret.Type != existing.Type {
+		return fmt.Errorf("%w: the type of a secret cannot be changed", domain.ErrInvalidSecret)
+	}
+	if err := checkSecretType(secret); err != nil {
+		return err
+	}
+	if err := checkPasswordRules(secret); err != nil {
+		return err
+	}
 
 	// If a new password is provided, encrypt it. Otherwise keep existing.
 	if secret.Password != "" {
-		encrypted, err := crypto.Encrypt(secret.Password, u.cfg.EncryptionKey)
+		if err := u.checkClientEncryption(ctx, secret); err != nil {
+			return err
+		}
+		if err := scorePassword(secret); err != nil {
+			return err
+		}
+		encrypted, err := u.keys.Encrypt(ctx, secret.UserID, secret.Password, secretContext(secret.ID, secret.UserID))
 		if err != nil {
 			return fmt.Errorf("failed to encrypt password: %w", err)
 		}
@@ -89,9 +274,133 @@ func (u *secretUsecase) UpdateSecret(ctx context.Context, secret *domain.Secret)
 		secret.Password = ""
 	} else {
 		secret.EncryptedPassword = existing.EncryptedPassword
+		secret.ClientEncrypted = existing.ClientEncrypted
+		secret.StrengthScore = existing.StrengthScore
+	}
+
+	if err := u.sealer.Seal(ctx, secret); err != nil {
+		return err
+	}
+	if err := u.recordVersion(ctx, domain.NewSecretVersion(existing)); err != nil {
+		return err
 	}
 
-	return u.repo.Update(ctx, secret)
+	return u.update(ctx, secret)
+}
+
+func (u *secretUsecase) PatchSecret(ctx context.Context, id string, userID string, version int, patch map[string]interface{}) (*domain.Secret, error) {
+	existing, err := u.ownedSecret(ctx, id, userID)
+	if err != nil || existing == nil {
+		return nil, err
+	}
+	if version != 0 && version != existing.Version {
+		return nil, &domain.VersionConflictError{Expected: version, Current: existing}
+	}
+
+	// Sealed fields are merged into the patch target, so a patch can change
+	// one of them without knowing the others. The password stays encrypted:
+	// it is either replaced or kept.
+	if err := u.sealer.Open(ctx, existing); err != nil {
+		return nil, err
+	}
+	// Nothing to change, so no new version either
+	if len(patch) == 0 {
+		return existing, nil
+	}
+	secret := &domain.Secret{
+		ID:       id,
+		UserID:   userID,
+		Title:    existing.Title,
+		Username: existing.Username,
+		Metadata: existing.Metadata,
+		Version:  existing.Version,
+	}
+	for key, value := range patch {
+		switch key {
+		case "title", "password":
+			str, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s must be a string and cannot be removed", domain.ErrInvalidPatch, key)
+			}
+			if key == "title" {
+				secret.Title = str
+			} else {
+				secret.Password = str
+			}
+		case "username":
+			str, ok := value.(string)
+			if !ok && value != nil {
+				return nil, fmt.Errorf("%w: username must be a string", domain.ErrInvalidPatch)
+			}
+			secret.Username = str
+		case "metadata":
+			merged := mergepatch.Apply(secret.Metadata, value)
+			metadata, ok := merged.(map[string]interface{})
+			if !ok && merged != nil {
+				return nil, fmt.Errorf("%w: metadata must be an object", domain.ErrInvalidPatch)
+			}
+			secret.Metadata = metadata
+		case "client_encrypted":
+			b, ok := value.(bool)
+			if !ok {
+				return nil, fmt.Errorf("%w: client_encrypted must be a boolean", domain.ErrInvalidPatch)
+			}
+			secret.ClientEncrypted = b
+		case "strength_score":
+			if value == nil {
+				secret.StrengthScore = nil
+				continue
+			}
+			n, ok := value.(float64)
+			if !ok || n != float64(int(n)) {
+				return nil, fmt.Errorf("%w: strength_score must be an integer", domain.ErrInvalidPatch)
+			}
+			score := int(n)
+			secret.StrengthScore = &score
+		default:
+			return nil, fmt.Errorf("%w: unknown field %q", domain.ErrInvalidPatch, key)
+		}
+	}
+
+	if err := u.UpdateSecret(ctx, secret); err != nil {
+		return nil, err
+	}
+
+	// Hand the patched secret back with the sealed fields readable.
+	if err := u.sealer.Open(ctx, secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// update saves a secret on top of secret.Version, turning a lost race into a
+// *VersionConflictError with the state that won.
+func (u *secretUsecase) update(ctx context.Context, secret *domain.Secret) error {
+	expected := secret.Version
+	err := u.repo.Update(ctx, secret)
+	if !errors.Is(err, domain.ErrVersionConflict) {
+		return err
+	}
+	current, getErr := u.repo.GetByID(ctx, secret.ID)
+	if getErr != nil {
+		return getErr
+	}
+	if current == nil {
+		return fmt.Errorf("secret not found")
+	}
+	return &domain.VersionConflictError{Expected: expected, Current: current}
+}
+
+func (u *secretUsecase) SetFavorite(ctx context.Context, id string, userID string, favorite bool) (*domain.Secret, error) {
+	secret, err := u.ownedSecret(ctx, id, userID)
+	if err != nil || secret == nil {
+		return nil, err
+	}
+	if err := u.repo.SetFavorite(ctx, id, favorite); err != nil {
+		return nil, err
+	}
+	secret.Favorite = favorite
+	return secret, nil
 }
 
 func (u *secretUsecase) DeleteSecret(ctx context.Context, id string, userID string) error {
@@ -99,12 +408,246 @@ func (u *secretUsecase) DeleteSecret(ctx context.Context, id string, userID stri
 	if err != nil {
 		return err
 	}
-	if existing == nil {
+	if existing == nil || existing.DeletedAt != nil {
 		return nil // Already gone
 	}
 	if existing.UserID != userID {
 		return fmt.Errorf("unauthorized delete")
 	}
 
-	return u.repo.Delete(ctx, id)
+	// Deleted secrets go to the trash; the trash usecase purges them later.
+	return u.repo.Trash(ctx, id)
+}
+
+// rotationOptions returns the generator options saved with a secret: its
+// passwordrules string if any, on top of the options stored under
+// GeneratorOptionsKey, on top of the "Strong" preset. Rules without saved
+// options pick their own length.
+func (u *secretUsecase) rotationOptions(secret *domain.Secret) (domain.GeneratorOptions, error) {
+	opts := u.generator.DefaultOptions()
+	saved, hasSaved := secret.Metadata[domain.GeneratorOptionsKey]
+	if hasSaved {
+		raw, err := json.Marshal(saved)
+		if err == nil {
+			err = json.Unmarshal(raw, &opts)
+		}
+		if err != nil {
+			return opts, fmt.Errorf("%w: %s: %v", domain.ErrInvalidGeneratorOptions, domain.GeneratorOptionsKey, err)
+		}
+	}
+	if rules, ok := secret.Metadata[domain.PasswordRulesKey].(string); ok && rules != "" {
+		opts.Mode = domain.GeneratorModePassword
+		opts.Rules = rules
+		if !hasSaved {
+			opts.Length = 0
+		}
+	}
+	return opts, nil
+}
+
+func (u *secretUsecase) RotatePassword(ctx context.Context, id string, userID string) (*domain.Secret, error) {
+	secret, err := u.ownedSecret(ctx, id, userID)
+	if err != nil || secret == nil {
+		return nil, err
+	}
+	if !scoredType(secret.Type) {
+		return nil, fmt.Errorf("%w: only logins can be rotated", domain.ErrInvalidSecret)
+	}
+
+	// In zero-knowledge mode the server must never see the new password.
+	vault, err := u.vaultRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if vault.ZeroKnowledge {
+		return nil, domain.ErrRotationUnsupported
+	}
+
+	// Snapshot the stored row before Open merges the payload into it.
+	previous := domain.NewSecretVersion(secret)
+
+	if err := u.sealer.Open(ctx, secret); err != nil {
+		return nil, err
+	}
+	opts, err := u.rotationOptions(secret)
+	if err != nil {
+		return nil, err
+	}
+	generated, err := u.generator.Generate(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	secret.Password = generated.Password
+	secret.ClientEncrypted = false
+	if err := scorePassword(secret); err != nil {
+		return nil, err
+	}
+	encrypted, err := u.keys.Encrypt(ctx, secret.UserID, secret.Password, secretContext(secret.ID, secret.UserID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt password: %w", err)
+	}
+	secret.EncryptedPassword = encrypted
+
+	if err := u.recordVersion(ctx, previous); err != nil {
+		return nil, err
+	}
+	if err := u.sealer.Seal(ctx, secret); err != nil {
+		return nil, err
+	}
+	if err := u.update(ctx, secret); err != nil {
+		return nil, err
+	}
+
+	// Hand the new password back once, with the sealed fields readable.
+	if err := u.sealer.Open(ctx, secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// ownedSecret loads a secret and checks that userID owns it. It returns nil if
+// the secret does not exist.
+func (u *secretUsecase) ownedSecret(ctx context.Context, id string, userID string) (*domain.Secret, error) {
+	secret, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.DeletedAt != nil {
+		return nil, nil
+	}
+	if secret.UserID != userID {
+		return nil, fmt.Errorf("unauthorized access to secret")
+	}
+	return secret, nil
+}
+
+// openSecret decrypts the password and sealed fields of a stored secret or
+// snapshot in place.
+func (u *secretUsecase) openSecret(ctx context.Context, secret *domain.Secret) error {
+	decrypted, err := u.keys.Decrypt(ctx, secret.UserID, secret.EncryptedPassword, secretContext(secret.ID, secret.UserID))
+	if err != nil {
+		return fmt.Errorf("failed to decrypt password: %w", err)
+	}
+	secret.Password = decrypted
+	return u.sealer.Open(ctx, secret)
+}
+
+// changedFields lists the fields that differ between two opened states. A
+// client-encrypted password is compared as ciphertext, which is only rewritten
+// when the password is.
+func changedFields(older, newer *domain.Secret) []string {
+	var changes []string
+	if older.Title != newer.Title {
+		changes = append(changes, "title")
+	}
+	if older.Username != newer.Username {
+		changes = append(changes, "username")
+	}
+	if older.Password != newer.Password {
+		changes = append(changes, "password")
+	}
+
+	keys := make([]string, 0, len(older.Metadata)+len(newer.Metadata))
+	for k := range older.Metadata {
+		keys = append(keys, k)
+	}
+	for k := range newer.Metadata {
+		if _, ok := older.Metadata[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !reflect.DeepEqual(older.Metadata[k], newer.Metadata[k]) {
+			changes = append(changes, "metadata."+k)
+		}
+	}
+	return changes
+}
+
+func (u *secretUsecase) ListHistory(ctx context.Context, id string, userID string) ([]*domain.SecretVersion, error) {
+	secret, err := u.ownedSecret(ctx, id, userID)
+	if err != nil || secret == nil {
+		return nil, err
+	}
+	versions, err := u.versionRepo.ListBySecretID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each version is compared with the one that replaced it, starting from
+	// the current state.
+	if err := u.openSecret(ctx, secret); err != nil {
+		return nil, err
+	}
+	newer := secret
+	history := make([]*domain.SecretVersion, 0, len(versions))
+	for _, v := range versions {
+		older := v.Secret()
+		if err := u.openSecret(ctx, older); err != nil {
+			return nil, fmt.Errorf("version %d: %w", v.Version, err)
+		}
+		v.Username = older.Username
+		v.Metadata = older.Metadata
+		v.Changes = changedFields(older, newer)
+		history = append(history, v)
+		newer = older
+	}
+	return history, nil
+}
+
+func (u *secretUsecase) GetVersion(ctx context.Context, id string, userID string, version int) (*domain.SecretVersion, error) {
+	secret, err := u.ownedSecret(ctx, id, userID)
+	if err != nil || secret == nil {
+		return nil, err
+	}
+	v, err := u.versionRepo.GetByVersion(ctx, id, version)
+	if err != nil || v == nil {
+		return nil, err
+	}
+
+	opened := v.Secret()
+	if err := u.openSecret(ctx, opened); err != nil {
+		return nil, err
+	}
+	v.Password = opened.Password
+	v.Username = opened.Username
+	v.Metadata = opened.Metadata
+	return v, nil
+}
+
+func (u *secretUsecase) RestoreVersion(ctx context.Context, id string, userID string, version int) (*domain.Secret, error) {
+	existing, err := u.ownedSecret(ctx, id, userID)
+	if err != nil || existing == nil {
+		return nil, err
+	}
+	v, err := u.versionRepo.GetByVersion(ctx, id, version)
+	if err != nil || v == nil {
+		return nil, err
+	}
+
+	// The password ciphertext is bound to the secret, so it is reused as is.
+	// The other fields are resealed to match the current settings.
+	restored := v.Secret()
+	restored.Type = existing.Type
+	restored.Version = existing.Version
+	restored.CreatedAt = existing.CreatedAt
+	if err := u.sealer.Open(ctx, restored); err != nil {
+		return nil, err
+	}
+	if err := u.sealer.Seal(ctx, restored); err != nil {
+		return nil, err
+	}
+	if err := u.recordVersion(ctx, domain.NewSecretVersion(existing)); err != nil {
+		return nil, err
+	}
+	if err := u.update(ctx, restored); err != nil {
+		return nil, err
+	}
+
+	if err := u.sealer.Open(ctx, restored); err != nil {
+		return nil, err
+	}
+	return restored, nil
 }
@@ -2,21 +2,63 @@ package usecase_test
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
-	"github.com/herdiagusthio/password-manager/config"
 	"github.com/herdiagusthio/password-manager/internal/domain"
 	"github.com/herdiagusthio/password-manager/internal/mocks"
 	"github.com/herdiagusthio/password-manager/internal/usecase"
+	"github.com/herdiagusthio/password-manager/pkg/crypto"
+	"github.com/herdiagusthio/password-manager/pkg/crypto/kmstest"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
 
+// newKeyProvider returns a key provider whose current master key is masterKey.
+func newKeyProvider(t *testing.T, masterKey string) crypto.KeyProvider {
+	keys, err := crypto.NewStaticProvider("1", map[string][]byte{"1": []byte(masterKey)})
+	require.NoError(t, err)
+	return keys
+}
+
+// newKeyRepo returns a key repository that already holds a data key for every user.
+func newKeyRepo(t *testing.T, ctrl *gomock.Controller, keys crypto.KeyProvider, dataKey []byte) *mocks.MockUserKeyRepository {
+	keyring := crypto.NewKeyring(keys)
+	keyRepo := mocks.NewMockUserKeyRepository(ctrl)
+	keyRepo.EXPECT().GetByUserID(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, userID string) (*domain.UserKey, error) {
+		wrapped, err := keyring.WrapKey(ctx, dataKey, []byte("user_key:"+userID))
+		require.NoError(t, err)
+		return &domain.UserKey{UserID: userID, WrappedKey: wrapped}, nil
+	}).AnyTimes()
+	return keyRepo
+}
+
+// newSettingsRepo returns a settings repository that serves the default settings.
+func newSettingsRepo(ctrl *gomock.Controller) *mocks.MockSettingsRepository {
+	settingsRepo := mocks.NewMockSettingsRepository(ctrl)
+	settingsRepo.EXPECT().Get(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, userID string) (*domain.UserSettings, error) {
+		return &domain.UserSettings{UserID: userID, PlaintextFields: domain.DefaultPlaintextFields, HistoryLimit: domain.DefaultHistoryLimit}, nil
+	}).AnyTimes()
+	return settingsRepo
+}
+
+// newVaultRepo returns a vault repository with zero-knowledge mode on or off for every user.
+func newVaultRepo(ctrl *gomock.Controller, zeroKnowledge bool) *mocks.MockVaultRepository {
+	vaultRepo := mocks.NewMockVaultRepository(ctrl)
+	vaultRepo.EXPECT().GetByUserID(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, userID string) (*domain.Vault, error) {
+		return &domain.Vault{UserID: userID, ZeroKnowledge: zeroKnowledge}, nil
+	}).AnyTimes()
+	return vaultRepo
+}
+
 func TestSecretUsecase_CreateSecret(t *testing.T) {
 	// 32-byte key for AES-256
 	mockKey := "12345678901234567890123456789012"
-	cfg := &config.Config{EncryptionKey: mockKey}
+	keys := newKeyProvider(t, mockKey)
 
 	tests := []struct {
 		name          string
@@ -31,11 +73,17 @@ func TestSecretUsecase_CreateSecret(t *testing.T) {
 				Title:    "Gmail",
 				Username: "test@gmail.com",
 				Password: "supersecretpassword",
+				Metadata: map[string]interface{}{"url": "https://mail.google.com", "notes": "recovery codes"},
 			},
 			mockBehavior: func(m *mocks.MockSecretRepository) {
 				m.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, s *domain.Secret) error {
 					assert.NotEqual(t, "supersecretpassword", s.EncryptedPassword) // Should be encrypted
 					assert.Empty(t, s.Password)                                    // Plain password cleared
+					// Default settings keep username and url in plaintext and seal the rest
+					assert.Equal(t, "test@gmail.com", s.Username)
+					assert.Equal(t, map[string]interface{}{"url": "https://mail.google.com"}, s.Metadata)
+					assert.NotEmpty(t, s.EncryptedPayload)
+					assert.NotContains(t, s.EncryptedPayload, "recovery")
 					return nil
 				})
 			},
@@ -62,8 +110,9 @@ func TestSecretUsecase_CreateSecret(t *testing.T) {
 
 			repo := mocks.NewMockSecretRepository(ctrl)
 			tt.mockBehavior(repo)
+			keyRepo := newKeyRepo(t, ctrl, keys, []byte("abcdefghijklmnopqrstuvwxyz012345"))
 
-			uc := usecase.NewSecretUsecase(repo, cfg)
+			uc := usecase.NewSecretUsecase(repo, mocks.NewMockSecretVersionRepository(ctrl), mocks.NewMockFolderRepository(ctrl), keyRepo, newSettingsRepo(ctrl), newVaultRepo(ctrl, false), keys)
 			err := uc.CreateSecret(context.Background(), tt.inputSecret)
 
 			if tt.expectedError {
@@ -77,7 +126,7 @@ func TestSecretUsecase_CreateSecret(t *testing.T) {
 
 func TestSecretUsecase_GetSecret(t *testing.T) {
 	mockKey := "12345678901234567890123456789012"
-	cfg := &config.Config{EncryptionKey: mockKey}
+	keys := newKeyProvider(t, mockKey)
 
 	// Helper to encrypt for setup
 	// In real test we might just use a string we know decrypts or mock Crypto but our UC integrates Crypto lib
@@ -124,6 +173,15 @@ func TestSecretUsecase_GetSecret(t *testing.T) {
             },
             expectedError: true,
         },
+		{
+			name:     "In Trash",
+			secretID: "sec-1",
+			userID:   "user-1",
+			mockBehavior: func(m *mocks.MockSecretRepository) {
+				deletedAt := time.Now()
+				m.EXPECT().GetByID(gomock.Any(), "sec-1").Return(&domain.Secret{ID: "sec-1", UserID: "user-1", DeletedAt: &deletedAt}, nil)
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -133,8 +191,9 @@ func TestSecretUsecase_GetSecret(t *testing.T) {
 
 			repo := mocks.NewMockSecretRepository(ctrl)
 			tt.mockBehavior(repo)
+			keyRepo := newKeyRepo(t, ctrl, keys, []byte("abcdefghijklmnopqrstuvwxyz012345"))
 
-			uc := usecase.NewSecretUsecase(repo, cfg)
+			uc := usecase.NewSecretUsecase(repo, mocks.NewMockSecretVersionRepository(ctrl), mocks.NewMockFolderRepository(ctrl), keyRepo, newSettingsRepo(ctrl), newVaultRepo(ctrl, false), keys)
 			_, err := uc.GetSecret(context.Background(), tt.secretID, tt.userID)
 
 			if tt.expectedError {
@@ -145,3 +204,1015 @@ func TestSecretUsecase_GetSecret(t *testing.T) {
 		})
 	}
 }
+
+func TestSecretUsecase_GetSecret_DataKey(t *testing.T) {
+	mockKey := "12345678901234567890123456789012"
+	dataKey := []byte("abcdefghijklmnopqrstuvwxyz012345")
+	keys := newKeyProvider(t, mockKey)
+
+	aad := []byte("secret:sec-1:user:user-1")
+	withDataKey, err := crypto.EncryptWithKey([]byte("per-user"), crypto.DataKeyID, dataKey, aad)
+	require.NoError(t, err)
+	copiedFromOtherSecret, err := crypto.EncryptWithKey([]byte("moved"), crypto.DataKeyID, dataKey, []byte("secret:sec-2:user:user-1"))
+	require.NoError(t, err)
+	withLegacyDataKey, err := crypto.Encrypt("before headers", string(dataKey))
+	require.NoError(t, err)
+	withMasterKey, err := crypto.Encrypt("legacy", mockKey)
+	require.NoError(t, err)
+	withOtherKey, err := crypto.EncryptWithKey([]byte("someone else"), crypto.DataKeyID, []byte("zyxwvutsrqponmlkjihgfedcba543210"), aad)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name             string
+		encrypted        string
+		expectedPassword string
+		expectedError    bool
+	}{
+		{name: "Data Key", encrypted: withDataKey, expectedPassword: "per-user"},
+		{name: "Data Key Without Header", encrypted: withLegacyDataKey, expectedPassword: "before headers"},
+		{name: "Legacy Master Key", encrypted: withMasterKey, expectedPassword: "legacy"},
+		{name: "Foreign Key", encrypted: withOtherKey, expectedError: true},
+		{name: "Copied From Other Secret", encrypted: copiedFromOtherSecret, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockSecretRepository(ctrl)
+			repo.EXPECT().GetByID(gomock.Any(), "sec-1").Return(&domain.Secret{
+				ID:                "sec-1",
+				UserID:            "user-1",
+				EncryptedPassword: tt.encrypted,
+			}, nil)
+			if !tt.expectedError {
+				repo.EXPECT().MarkUsed(gomock.Any(), "sec-1").Return(nil)
+			}
+
+			uc := usecase.NewSecretUsecase(repo, mocks.NewMockSecretVersionRepository(ctrl), mocks.NewMockFolderRepository(ctrl), newKeyRepo(t, ctrl, keys, dataKey), newSettingsRepo(ctrl), newVaultRepo(ctrl, false), keys)
+			secret, err := uc.GetSecret(context.Background(), "sec-1", "user-1")
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedPassword, secret.Password)
+		})
+	}
+}
+
+func TestSecretUsecase_CreateSecret_GeneratesDataKey(t *testing.T) {
+	mockKey := "12345678901234567890123456789012"
+	keys := newKeyProvider(t, mockKey)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var stored *domain.UserKey
+	keyRepo := mocks.NewMockUserKeyRepository(ctrl)
+	keyRepo.EXPECT().GetByUserID(gomock.Any(), "user-1").DoAndReturn(func(ctx context.Context, userID string) (*domain.UserKey, error) {
+		return stored, nil
+	}).Times(2)
+	keyRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, key *domain.UserKey) error {
+		assert.Equal(t, "user-1", key.UserID)
+		stored = key
+		return nil
+	})
+
+	var encrypted, id string
+	repo := mocks.NewMockSecretRepository(ctrl)
+	repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, s *domain.Secret) error {
+		assert.NotEmpty(t, s.ID) // Assigned before encryption so the ciphertext can be bound to it
+		encrypted, id = s.EncryptedPassword, s.ID
+		return nil
+	})
+
+	uc := usecase.NewSecretUsecase(repo, mocks.NewMockSecretVersionRepository(ctrl), mocks.NewMockFolderRepository(ctrl), keyRepo, newSettingsRepo(ctrl), newVaultRepo(ctrl, false), keys)
+	require.NoError(t, uc.CreateSecret(context.Background(), &domain.Secret{UserID: "user-1", Password: "hunter2"}))
+
+	// The password must not be readable with the master key alone.
+	aad := []byte("secret:" + id + ":user:user-1")
+	keyring := crypto.NewKeyring(keys)
+	_, err := keyring.Decrypt(context.Background(), encrypted, aad)
+	assert.Error(t, err)
+
+	dataKey, err := keyring.UnwrapKey(context.Background(), stored.WrappedKey, []byte("user_key:user-1"))
+	require.NoError(t, err)
+	plain, err := keyring.DecryptWith(context.Background(), encrypted, aad, crypto.DataKeyID, dataKey)
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", string(plain))
+}
+
+func TestSecretUsecase_KMSKeyProvider(t *testing.T) {
+	server, err := kmstest.NewServer("token", "kms-1", map[string][]byte{"kms-1": []byte("12345678901234567890123456789012")})
+	require.NoError(t, err)
+	defer server.Close()
+	keys, err := crypto.NewHTTPProvider(server.URL, "token", "kms-1", server.Client())
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var storedKey *domain.UserKey
+	keyRepo := mocks.NewMockUserKeyRepository(ctrl)
+	keyRepo.EXPECT().GetByUserID(gomock.Any(), "user-1").DoAndReturn(func(ctx context.Context, userID string) (*domain.UserKey, error) {
+		return storedKey, nil
+	}).AnyTimes()
+	keyRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, key *domain.UserKey) error {
+		h, ok := crypto.ParseHeader(key.WrappedKey)
+		require.True(t, ok)
+		assert.Equal(t, "kms-1", h.KeyID)
+		storedKey = key
+		return nil
+	})
+
+	var stored domain.Secret
+	repo := mocks.NewMockSecretRepository(ctrl)
+	repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, s *domain.Secret) error {
+		stored = *s
+		return nil
+	})
+	repo.EXPECT().MarkUsed(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	repo.EXPECT().GetByID(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, id string) (*domain.Secret, error) {
+		s := stored
+		return &s, nil
+	})
+
+	uc := usecase.NewSecretUsecase(repo, mocks.NewMockSecretVersionRepository(ctrl), mocks.NewMockFolderRepository(ctrl), keyRepo, newSettingsRepo(ctrl), newVaultRepo(ctrl, false), keys)
+	require.NoError(t, uc.CreateSecret(context.Background(), &domain.Secret{UserID: "user-1", Password: "hunter2"}))
+
+	secret, err := uc.GetSecret(context.Background(), stored.ID, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", secret.Password)
+}
+
+func TestSecretUsecase_SealedFields(t *testing.T) {
+	mockKey := "12345678901234567890123456789012"
+	dataKey := []byte("abcdefghijklmnopqrstuvwxyz012345")
+	keys := newKeyProvider(t, mockKey)
+
+	tests := []struct {
+		name             string
+		plaintextFields  []string
+		expectedUsername string
+		expectedMetadata map[string]interface{}
+	}{
+		{
+			name:             "Defaults",
+			plaintextFields:  domain.DefaultPlaintextFields,
+			expectedUsername: "alice",
+			expectedMetadata: map[string]interface{}{"url": "https://example.com"},
+		},
+		{
+			name:             "Everything Sealed",
+			plaintextFields:  []string{},
+			expectedUsername: "",
+			expectedMetadata: nil,
+		},
+		{
+			name:             "Notes In Plaintext",
+			plaintextFields:  []string{"notes"},
+			expectedUsername: "",
+			expectedMetadata: map[string]interface{}{"notes": "shared account"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			settingsRepo := mocks.NewMockSettingsRepository(ctrl)
+			settingsRepo.EXPECT().Get(gomock.Any(), "user-1").Return(&domain.UserSettings{UserID: "user-1", PlaintextFields: tt.plaintextFields}, nil)
+
+			var stored domain.Secret
+			repo := mocks.NewMockSecretRepository(ctrl)
+			repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, s *domain.Secret) error {
+				stored = *s
+				return nil
+			})
+			repo.EXPECT().MarkUsed(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			repo.EXPECT().GetByID(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, id string) (*domain.Secret, error) {
+				s := stored
+				return &s, nil
+			})
+
+			uc := usecase.NewSecretUsecase(repo, mocks.NewMockSecretVersionRepository(ctrl), mocks.NewMockFolderRepository(ctrl), newKeyRepo(t, ctrl, keys, dataKey), settingsRepo, newVaultRepo(ctrl, false), keys)
+			require.NoError(t, uc.CreateSecret(context.Background(), &domain.Secret{
+				UserID:   "user-1",
+				Title:    "Example",
+				Username: "alice",
+				Password: "hunter2",
+				Metadata: map[string]interface{}{"url": "https://example.com", "notes": "shared account"},
+			}))
+
+			assert.Equal(t, tt.expectedUsername, stored.Username)
+			assert.Equal(t, tt.expectedMetadata, stored.Metadata)
+
+			// Reading the secret back merges the sealed fields in again.
+			secret, err := uc.GetSecret(context.Background(), stored.ID, "user-1")
+			require.NoError(t, err)
+			assert.Equal(t, "alice", secret.Username)
+			assert.Equal(t, "hunter2", secret.Password)
+			assert.Equal(t, map[string]interface{}{"url": "https://example.com", "notes": "shared account"}, secret.Metadata)
+		})
+	}
+}
+
+func TestSecretUsecase_ZeroKnowledge(t *testing.T) {
+	mockKey := "12345678901234567890123456789012"
+	dataKey := []byte("abcdefghijklmnopqrstuvwxyz012345")
+	keys := newKeyProvider(t, mockKey)
+
+	blob := crypto.ClientBlobPrefix + base64.StdEncoding.EncodeToString([]byte("nonce-bytes-ciphertext-and-tag!!"))
+
+	tests := []struct {
+		name            string
+		zeroKnowledge   bool
+		password        string
+		clientEncrypted bool
+		expectedError   bool
+	}{
+		{name: "Server Encryption", password: "hunter2"},
+		{name: "Client Blob Without Zero Knowledge", password: blob, clientEncrypted: true, expectedError: true},
+		{name: "Plaintext In Zero Knowledge", zeroKnowledge: true, password: "hunter2", expectedError: true},
+		{name: "Malformed Blob", zeroKnowledge: true, password: "zk1:hunter2", clientEncrypted: true, expectedError: true},
+		{name: "Client Blob", zeroKnowledge: true, password: blob, clientEncrypted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var stored domain.Secret
+			repo := mocks.NewMockSecretRepository(ctrl)
+			if !tt.expectedError {
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, s *domain.Secret) error {
+					assert.NotContains(t, s.EncryptedPassword, "zk1:") // The blob is wrapped by the data key as well
+					stored = *s
+					return nil
+				})
+				repo.EXPECT().MarkUsed(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+				repo.EXPECT().GetByID(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, id string) (*domain.Secret, error) {
+					s := stored
+					return &s, nil
+				})
+			}
+
+			uc := usecase.NewSecretUsecase(repo, mocks.NewMockSecretVersionRepository(ctrl), mocks.NewMockFolderRepository(ctrl), newKeyRepo(t, ctrl, keys, dataKey), newSettingsRepo(ctrl), newVaultRepo(ctrl, tt.zeroKnowledge), keys)
+			err := uc.CreateSecret(context.Background(), &domain.Secret{
+				UserID:          "user-1",
+				Title:           "Example",
+				Password:        tt.password,
+				ClientEncrypted: tt.clientEncrypted,
+			})
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			// The owner gets back exactly what the browser sent.
+			secret, err := uc.GetSecret(context.Background(), stored.ID, "user-1")
+			require.NoError(t, err)
+			assert.Equal(t, tt.password, secret.Password)
+			assert.Equal(t, tt.clientEncrypted, secret.ClientEncrypted)
+		})
+	}
+}
+
+func TestSecretUsecase_StrengthScore(t *testing.T) {
+	mockKey := "12345678901234567890123456789012"
+	dataKey := []byte("abcdefghijklmnopqrstuvwxyz012345")
+	keys := newKeyProvider(t, mockKey)
+
+	blob := crypto.ClientBlobPrefix + base64.StdEncoding.EncodeToString([]byte("nonce-bytes-ciphertext-and-tag!!"))
+	score := func(s int) *int { return &s }
+
+	tests := []struct {
+		name          string
+		zeroKnowledge bool
+		secret        domain.Secret
+		expectedScore *int
+		expectedWeak  bool
+		expectedError bool
+	}{
+		{name: "Common Password", secret: domain.Secret{Password: "password"}, expectedScore: score(0), expectedWeak: true},
+		{name: "Contains Username", secret: domain.Secret{Username: "alice", Password: "alice1990"}, expectedScore: score(1), expectedWeak: true},
+		{name: "Strong Password", secret: domain.Secret{Password: "x7$Kp2!qLm9#Vw4z"}, expectedScore: score(4)},
+		{name: "Client Score Ignored For Plaintext", secret: domain.Secret{Password: "password", StrengthScore: score(4)}, expectedScore: score(0), expectedWeak: true},
+		{name: "Client Encrypted Without Score", zeroKnowledge: true, secret: domain.Secret{Password: blob, ClientEncrypted: true}},
+		{name: "Client Encrypted With Score", zeroKnowledge: true, secret: domain.Secret{Password: blob, ClientEncrypted: true, StrengthScore: score(2)}, expectedScore: score(2), expectedWeak: true},
+		{name: "Client Score Out Of Range", zeroKnowledge: true, secret: domain.Secret{Password: blob, ClientEncrypted: true, StrengthScore: score(5)}, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockSecretRepository(ctrl)
+			if !tt.expectedError {
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, s *domain.Secret) error {
+					assert.Equal(t, tt.expectedScore, s.StrengthScore)
+					assert.Equal(t, tt.expectedWeak, s.WeakPassword())
+					return nil
+				})
+			}
+
+			uc := usecase.NewSecretUsecase(repo, mocks.NewMockSecretVersionRepository(ctrl), mocks.NewMockFolderRepository(ctrl), newKeyRepo(t, ctrl, keys, dataKey), newSettingsRepo(ctrl), newVaultRepo(ctrl, tt.zeroKnowledge), keys)
+			secret := tt.secret
+			secret.UserID = "user-1"
+			secret.Title = "Example"
+			err := uc.CreateSecret(context.Background(), &secret)
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSecretUsecase_PasswordRules(t *testing.T) {
+	mockKey := "12345678901234567890123456789012"
+	dataKey := []byte("abcdefghijklmnopqrstuvwxyz012345")
+	keys := newKeyProvider(t, mockKey)
+
+	tests := []struct {
+		name          string
+		metadata      map[string]interface{}
+		expectedError bool
+	}{
+		{name: "No Rules", metadata: map[string]interface{}{"url": "https://example.com"}},
+		{name: "Valid Rules", metadata: map[string]interface{}{domain.PasswordRulesKey: "maxlength: 20; required: upper; allowed: lower, digit"}},
+		{name: "Invalid Rules", metadata: map[string]interface{}{domain.PasswordRulesKey: "required: emoji"}, expectedError: true},
+		{name: "Not A String", metadata: map[string]interface{}{domain.PasswordRulesKey: 20.0}, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockSecretRepository(ctrl)
+			if !tt.expectedError {
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			uc := usecase.NewSecretUsecase(repo, mocks.NewMockSecretVersionRepository(ctrl), mocks.NewMockFolderRepository(ctrl), newKeyRepo(t, ctrl, keys, dataKey), newSettingsRepo(ctrl), newVaultRepo(ctrl, false), keys)
+			err := uc.CreateSecret(context.Background(), &domain.Secret{
+				UserID:   "user-1",
+				Title:    "Example",
+				Password: "hunter2",
+				Metadata: tt.metadata,
+			})
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSecretUsecase_RotatePassword(t *testing.T) {
+	mockKey := "12345678901234567890123456789012"
+	dataKey := []byte("abcdefghijklmnopqrstuvwxyz012345")
+	keys := newKeyProvider(t, mockKey)
+
+	tests := []struct {
+		name          string
+		userID        string
+		metadata      map[string]interface{}
+		zeroKnowledge bool
+		check         func(t *testing.T, pw string)
+		expectedError error
+		expectNil     bool
+	}{
+		{
+			name:   "Strong Preset",
+			userID: "user-1",
+			check: func(t *testing.T, pw string) {
+				assert.Len(t, pw, 16)
+			},
+		},
+		{
+			name:     "Password Rules",
+			userID:   "user-1",
+			metadata: map[string]interface{}{domain.PasswordRulesKey: "minlength: 10; maxlength: 10; required: digit; allowed: lower"},
+			check: func(t *testing.T, pw string) {
+				assert.Regexp(t, `^[a-z0-9]{10}$`, pw)
+				assert.Regexp(t, `[0-9]`, pw)
+			},
+		},
+		{
+			name:     "Saved Generator Options",
+			userID:   "user-1",
+			metadata: map[string]interface{}{domain.GeneratorOptionsKey: map[string]interface{}{"mode": "passphrase", "words": 4.0, "separator": "."}},
+			check: func(t *testing.T, pw string) {
+				assert.Len(t, strings.Split(pw, "."), 4)
+			},
+		},
+		{
+			name:          "Invalid Saved Options",
+			userID:        "user-1",
+			metadata:      map[string]interface{}{domain.GeneratorOptionsKey: map[string]interface{}{"length": 3.0}},
+			expectedError: domain.ErrInvalidGeneratorOptions,
+		},
+		{
+			name:          "Zero Knowledge",
+			userID:        "user-1",
+			zeroKnowledge: true,
+			expectedError: domain.ErrRotationUnsupported,
+		},
+		{
+			name:      "Not Found",
+			userID:    "user-1",
+			expectNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var stored domain.Secret
+			repo := mocks.NewMockSecretRepository(ctrl)
+			repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, s *domain.Secret) error {
+				s.Version = 1
+				stored = *s
+				return nil
+			})
+			repo.EXPECT().MarkUsed(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			repo.EXPECT().GetByID(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, id string) (*domain.Secret, error) {
+				if tt.expectNil {
+					return nil, nil
+				}
+				s := stored
+				return &s, nil
+			}).AnyTimes()
+			versionRepo := mocks.NewMockSecretVersionRepository(ctrl)
+			var previous *domain.SecretVersion
+			if tt.check != nil {
+				versionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, v *domain.SecretVersion) error {
+					previous = v
+					return nil
+				})
+				versionRepo.EXPECT().Prune(gomock.Any(), "user-1", domain.DefaultHistoryLimit).Return(nil)
+				repo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, s *domain.Secret) error {
+					s.Version++
+					stored = *s
+					stored.Password = ""
+					return nil
+				})
+			}
+
+			// Create runs with zero-knowledge off so the fixture holds a plaintext password.
+			vaultRepo := mocks.NewMockVaultRepository(ctrl)
+			vaultRepo.EXPECT().GetByUserID(gomock.Any(), gomock.Any()).Return(&domain.Vault{UserID: "user-1"}, nil)
+			vaultRepo.EXPECT().GetByUserID(gomock.Any(), gomock.Any()).Return(&domain.Vault{UserID: "user-1", ZeroKnowledge: tt.zeroKnowledge}, nil).AnyTimes()
+
+			uc := usecase.NewSecretUsecase(repo, versionRepo, mocks.NewMockFolderRepository(ctrl), newKeyRepo(t, ctrl, keys, dataKey), newSettingsRepo(ctrl), vaultRepo, keys)
+			require.NoError(t, uc.CreateSecret(context.Background(), &domain.Secret{
+				UserID:   "user-1",
+				Title:    "Example",
+				Username: "alice",
+				Password: "hunter2",
+				Metadata: tt.metadata,
+			}))
+			oldEncrypted := stored.EncryptedPassword
+
+			rotated, err := uc.RotatePassword(context.Background(), stored.ID, tt.userID)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			if tt.expectNil {
+				assert.Nil(t, rotated)
+				return
+			}
+
+			tt.check(t, rotated.Password)
+			assert.Equal(t, 2, rotated.Version)
+			assert.Equal(t, "alice", rotated.Username)
+			require.NotNil(t, rotated.StrengthScore)
+
+			// The previous state is kept, still encrypted, under the old version.
+			require.NotNil(t, previous)
+			assert.Equal(t, 1, previous.Version)
+			assert.Equal(t, oldEncrypted, previous.EncryptedPassword)
+
+			got, err := uc.GetSecret(context.Background(), stored.ID, "user-1")
+			require.NoError(t, err)
+			assert.Equal(t, rotated.Password, got.Password)
+		})
+	}
+
+	t.Run("Other User", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repo := mocks.NewMockSecretRepository(ctrl)
+		repo.EXPECT().GetByID(gomock.Any(), "secret-1").Return(&domain.Secret{ID: "secret-1", UserID: "user-2"}, nil)
+
+		uc := usecase.NewSecretUsecase(repo, mocks.NewMockSecretVersionRepository(ctrl), mocks.NewMockFolderRepository(ctrl), newKeyRepo(t, ctrl, keys, dataKey), newSettingsRepo(ctrl), newVaultRepo(ctrl, false), keys)
+		_, err := uc.RotatePassword(context.Background(), "secret-1", "user-1")
+		assert.Error(t, err)
+	})
+}
+
+// newVersionRepo returns a version repository backed by a map, keeping the
+// newest limit versions per secret like the real one.
+func newVersionRepo(ctrl *gomock.Controller, versions map[int]*domain.SecretVersion) *mocks.MockSecretVersionRepository {
+	versionRepo := mocks.NewMockSecretVersionRepository(ctrl)
+	versionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, v *domain.SecretVersion) error {
+		versions[v.Version] = v
+		return nil
+	}).AnyTimes()
+	versionRepo.EXPECT().Prune(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, userID string, keep int) error {
+		for len(versions) > keep {
+			oldest := -1
+			for n := range versions {
+				if oldest < 0 || n < oldest {
+					oldest = n
+				}
+			}
+			delete(versions, oldest)
+		}
+		return nil
+	}).AnyTimes()
+	versionRepo.EXPECT().ListBySecretID(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, secretID string) ([]*domain.SecretVersion, error) {
+		var list []*domain.SecretVersion
+		for n := len(versions) + 10; n >= 0; n-- {
+			if v, ok := versions[n]; ok {
+				c := *v
+				list = append(list, &c)
+			}
+		}
+		return list, nil
+	}).AnyTimes()
+	versionRepo.EXPECT().GetByVersion(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, secretID string, version int) (*domain.SecretVersion, error) {
+		v, ok := versions[version]
+		if !ok {
+			return nil, nil
+		}
+		c := *v
+		return &c, nil
+	}).AnyTimes()
+	return versionRepo
+}
+
+func TestSecretUsecase_History(t *testing.T) {
+	mockKey := "12345678901234567890123456789012"
+	dataKey := []byte("abcdefghijklmnopqrstuvwxyz012345")
+	keys := newKeyProvider(t, mockKey)
+	ctx := context.Background()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var stored domain.Secret
+	repo := mocks.NewMockSecretRepository(ctrl)
+	repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, s *domain.Secret) error {
+		s.Version = 1
+		stored = *s
+		return nil
+	})
+	repo.EXPECT().MarkUsed(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	repo.EXPECT().GetByID(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, id string) (*domain.Secret, error) {
+		s := stored
+		return &s, nil
+	}).AnyTimes()
+	repo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, s *domain.Secret) error {
+		if s.Version != stored.Version {
+			return domain.ErrVersionConflict
+		}
+		s.Version++
+		stored = *s
+		stored.Password = ""
+		return nil
+	}).AnyTimes()
+
+	versions := map[int]*domain.SecretVersion{}
+	uc := usecase.NewSecretUsecase(repo, newVersionRepo(ctrl, versions), mocks.NewMockFolderRepository(ctrl), newKeyRepo(t, ctrl, keys, dataKey), newSettingsRepo(ctrl), newVaultRepo(ctrl, false), keys)
+
+	require.NoError(t, uc.CreateSecret(ctx, &domain.Secret{
+		UserID: "user-1", Title: "Mail", Username: "alice", Password: "first-password",
+		Metadata: map[string]interface{}{"notes": "v1"},
+	}))
+	id := stored.ID
+	// v2 changes the password and sealed notes, v3 only the title.
+	require.NoError(t, uc.UpdateSecret(ctx, &domain.Secret{
+		ID: id, UserID: "user-1", Version: 1, Title: "Mail", Username: "alice", Password: "second-password",
+		Metadata: map[string]interface{}{"notes": "v2"},
+	}))
+	require.NoError(t, uc.UpdateSecret(ctx, &domain.Secret{
+		ID: id, UserID: "user-1", Version: 2, Title: "Webmail", Username: "alice",
+		Metadata: map[string]interface{}{"notes": "v2"},
+	}))
+	assert.Equal(t, 3, stored.Version)
+
+	t.Run("ListHistory", func(t *testing.T) {
+		history, err := uc.ListHistory(ctx, id, "user-1")
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		assert.Equal(t, 2, history[0].Version)
+		assert.Equal(t, []string{"title"}, history[0].Changes)
+		assert.Equal(t, 1, history[1].Version)
+		assert.Equal(t, []string{"password", "metadata.notes"}, history[1].Changes)
+		for _, v := range history {
+			assert.Empty(t, v.Password)
+		}
+		assert.Equal(t, "v1", history[1].Metadata["notes"]) // Sealed fields are opened
+	})
+
+	t.Run("GetVersion", func(t *testing.T) {
+		v, err := uc.GetVersion(ctx, id, "user-1", 1)
+		require.NoError(t, err)
+		require.NotNil(t, v)
+		assert.Equal(t, "first-password", v.Password)
+		assert.Equal(t, "Mail", v.Title)
+
+		missing, err := uc.GetVersion(ctx, id, "user-1", 7)
+		require.NoError(t, err)
+		assert.Nil(t, missing)
+
+		_, err = uc.GetVersion(ctx, id, "user-2", 1)
+		assert.Error(t, err)
+	})
+
+	t.Run("RestoreVersion", func(t *testing.T) {
+		restored, err := uc.RestoreVersion(ctx, id, "user-1", 1)
+		require.NoError(t, err)
+		require.NotNil(t, restored)
+		assert.Equal(t, 4, restored.Version)
+		assert.Equal(t, "Mail", restored.Title)
+		assert.Equal(t, "v1", restored.Metadata["notes"])
+
+		current, err := uc.GetSecret(ctx, id, "user-1")
+		require.NoError(t, err)
+		assert.Equal(t, "first-password", current.Password)
+
+		// The replaced state is in history too.
+		v3, err := uc.GetVersion(ctx, id, "user-1", 3)
+		require.NoError(t, err)
+		assert.Equal(t, "Webmail", v3.Title)
+		assert.Equal(t, "second-password", v3.Password)
+	})
+}
+
+func TestSecretUsecase_UpdateSecret_Version(t *testing.T) {
+	mockKey := "12345678901234567890123456789012"
+	dataKey := []byte("abcdefghijklmnopqrstuvwxyz012345")
+	keys := newKeyProvider(t, mockKey)
+
+	stored := &domain.Secret{ID: "secret-1", UserID: "user-1", Title: "Mail", Version: 3}
+	racing := &domain.Secret{ID: "secret-1", UserID: "user-1", Title: "Webmail", Version: 4}
+
+	tests := []struct {
+		name            string
+		version         int
+		mockBehavior    func(m *mocks.MockSecretRepository)
+		expectedError   error
+		expectedCurrent *domain.Secret
+	}{
+		{
+			name:    "Success",
+			version: 3,
+			mockBehavior: func(m *mocks.MockSecretRepository) {
+				m.EXPECT().GetByID(gomock.Any(), "secret-1").Return(stored, nil)
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, s *domain.Secret) error {
+					assert.Equal(t, 3, s.Version) // Saved on top of the version the caller saw
+					s.Version++
+					return nil
+				})
+			},
+		},
+		{
+			name:          "Missing Version",
+			version:       0,
+			mockBehavior:  func(m *mocks.MockSecretRepository) {},
+			expectedError: domain.ErrVersionRequired,
+		},
+		{
+			name:    "Stale Version",
+			version: 2,
+			mockBehavior: func(m *mocks.MockSecretRepository) {
+				m.EXPECT().GetByID(gomock.Any(), "secret-1").Return(stored, nil)
+			},
+			expectedError:   domain.ErrVersionConflict,
+			expectedCurrent: stored,
+		},
+		{
+			name:    "Lost Race",
+			version: 3,
+			mockBehavior: func(m *mocks.MockSecretRepository) {
+				m.EXPECT().GetByID(gomock.Any(), "secret-1").Return(stored, nil)
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).Return(domain.ErrVersionConflict)
+				m.EXPECT().GetByID(gomock.Any(), "secret-1").Return(racing, nil)
+			},
+			expectedError:   domain.ErrVersionConflict,
+			expectedCurrent: racing,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockSecretRepository(ctrl)
+			tt.mockBehavior(repo)
+
+			uc := usecase.NewSecretUsecase(repo, newVersionRepo(ctrl, map[int]*domain.SecretVersion{}), mocks.NewMockFolderRepository(ctrl), newKeyRepo(t, ctrl, keys, dataKey), newSettingsRepo(ctrl), newVaultRepo(ctrl, false), keys)
+			secret := &domain.Secret{ID: "secret-1", UserID: "user-1", Title: "Mail", Password: "hunter2", Version: tt.version}
+			err := uc.UpdateSecret(context.Background(), secret)
+
+			if tt.expectedError == nil {
+				require.NoError(t, err)
+				assert.Equal(t, 4, secret.Version)
+				return
+			}
+			assert.ErrorIs(t, err, tt.expectedError)
+			if tt.expectedCurrent != nil {
+				var conflict *domain.VersionConflictError
+				require.ErrorAs(t, err, &conflict)
+				assert.Equal(t, tt.version, conflict.Expected)
+				assert.Equal(t, tt.expectedCurrent, conflict.Current)
+			}
+		})
+	}
+}
+
+func TestSecretUsecase_PatchSecret(t *testing.T) {
+	mockKey := "12345678901234567890123456789012"
+	dataKey := []byte("abcdefghijklmnopqrstuvwxyz012345")
+	keys := newKeyProvider(t, mockKey)
+
+	tests := []struct {
+		name          string
+		version       int
+		patch         map[string]interface{}
+		check         func(t *testing.T, s *domain.Secret)
+		unchanged     bool // No new version is written
+		expectedError error
+	}{
+		{
+			name:  "Title Only",
+			patch: map[string]interface{}{"title": "Webmail"},
+			check: func(t *testing.T, s *domain.Secret) {
+				assert.Equal(t, "Webmail", s.Title)
+				assert.Equal(t, "alice", s.Username)
+				assert.Equal(t, "hunter2", s.Password)
+				assert.Equal(t, "recovery codes", s.Metadata["notes"]) // Sealed fields survive
+			},
+		},
+		{
+			name:  "Remove Metadata Key",
+			patch: map[string]interface{}{"metadata": map[string]interface{}{"notes": nil}},
+			check: func(t *testing.T, s *domain.Secret) {
+				assert.NotContains(t, s.Metadata, "notes")
+				assert.Equal(t, "https://mail.example.com", s.Metadata["url"])
+			},
+		},
+		{
+			name: "Nested Metadata",
+			patch: map[string]interface{}{"metadata": map[string]interface{}{
+				domain.GeneratorOptionsKey: map[string]interface{}{"length": 24.0},
+			}},
+			check: func(t *testing.T, s *domain.Secret) {
+				assert.Equal(t, map[string]interface{}{"mode": "password", "length": 24.0}, s.Metadata[domain.GeneratorOptionsKey])
+			},
+		},
+		{
+			name:  "Clear Metadata",
+			patch: map[string]interface{}{"metadata": nil, "username": nil},
+			check: func(t *testing.T, s *domain.Secret) {
+				assert.Empty(t, s.Metadata)
+				assert.Empty(t, s.Username)
+			},
+		},
+		{
+			name:    "New Password At Version",
+			version: 1,
+			patch:   map[string]interface{}{"password": "correct horse battery staple"},
+			check: func(t *testing.T, s *domain.Secret) {
+				assert.Equal(t, "correct horse battery staple", s.Password)
+				assert.Equal(t, "Mail", s.Title)
+			},
+		},
+		{
+			name:      "Empty Patch",
+			patch:     map[string]interface{}{},
+			unchanged: true,
+			check: func(t *testing.T, s *domain.Secret) {
+				assert.Equal(t, "Mail", s.Title)
+				assert.Equal(t, "recovery codes", s.Metadata["notes"])
+			},
+		},
+		{name: "Unknown Field", patch: map[string]interface{}{"owner": "mallory"}, expectedError: domain.ErrInvalidPatch},
+		{name: "Null Title", patch: map[string]interface{}{"title": nil}, expectedError: domain.ErrInvalidPatch},
+		{name: "Metadata Not An Object", patch: map[string]interface{}{"metadata": "x"}, expectedError: domain.ErrInvalidPatch},
+		{name: "Stale Version", version: 7, patch: map[string]interface{}{"title": "x"}, expectedError: domain.ErrVersionConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Like the database, the repository shares no maps with its callers.
+			var stored domain.Secret
+			load := func(s domain.Secret) *domain.Secret {
+				if s.Metadata != nil {
+					metadata := make(map[string]interface{}, len(s.Metadata))
+					for k, v := range s.Metadata {
+						metadata[k] = v
+					}
+					s.Metadata = metadata
+				}
+				return &s
+			}
+			repo := mocks.NewMockSecretRepository(ctrl)
+			repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, s *domain.Secret) error {
+				s.Version = 1
+				stored = *load(*s)
+				return nil
+			})
+			repo.EXPECT().MarkUsed(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			repo.EXPECT().GetByID(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, id string) (*domain.Secret, error) {
+				return load(stored), nil
+			}).AnyTimes()
+			repo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, s *domain.Secret) error {
+				s.Version++
+				stored = *load(*s)
+				stored.Password = ""
+				return nil
+			}).AnyTimes()
+
+			versions := map[int]*domain.SecretVersion{}
+			uc := usecase.NewSecretUsecase(repo, newVersionRepo(ctrl, versions), mocks.NewMockFolderRepository(ctrl), newKeyRepo(t, ctrl, keys, dataKey), newSettingsRepo(ctrl), newVaultRepo(ctrl, false), keys)
+			require.NoError(t, uc.CreateSecret(context.Background(), &domain.Secret{
+				UserID: "user-1", Title: "Mail", Username: "alice", Password: "hunter2",
+				Metadata: map[string]interface{}{
+					"url":                      "https://mail.example.com",
+					"notes":                    "recovery codes",
+					domain.GeneratorOptionsKey: map[string]interface{}{"mode": "password", "length": 16.0},
+				},
+			}))
+
+			patched, err := uc.PatchSecret(context.Background(), stored.ID, "user-1", tt.version, tt.patch)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			if tt.unchanged {
+				assert.Equal(t, 1, patched.Version)
+				assert.Empty(t, versions)
+			} else {
+				assert.Equal(t, 2, patched.Version)
+			}
+
+			got, err := uc.GetSecret(context.Background(), stored.ID, "user-1")
+			require.NoError(t, err)
+			tt.check(t, got)
+			// The response shows the sealed fields, as the patch may have set them
+			assert.Equal(t, got.Username, patched.Username)
+			assert.Equal(t, got.Metadata, patched.Metadata)
+		})
+	}
+
+	t.Run("Not Found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repo := mocks.NewMockSecretRepository(ctrl)
+		repo.EXPECT().GetByID(gomock.Any(), "missing").Return(nil, nil)
+
+		uc := usecase.NewSecretUsecase(repo, mocks.NewMockSecretVersionRepository(ctrl), mocks.NewMockFolderRepository(ctrl), newKeyRepo(t, ctrl, keys, dataKey), newSettingsRepo(ctrl), newVaultRepo(ctrl, false), keys)
+		patched, err := uc.PatchSecret(context.Background(), "missing", "user-1", 0, map[string]interface{}{"title": "x"})
+		require.NoError(t, err)
+		assert.Nil(t, patched)
+	})
+}
+
+func TestSecretUsecase_DeleteSecret(t *testing.T) {
+	deletedAt := time.Now()
+	tests := []struct {
+		name         string
+		userID       string
+		mockBehavior func(m *mocks.MockSecretRepository)
+		expectErr    bool
+	}{
+		{
+			name:   "Moves To Trash",
+			userID: "user-1",
+			mockBehavior: func(m *mocks.MockSecretRepository) {
+				m.EXPECT().GetByID(gomock.Any(), "sec-1").Return(&domain.Secret{ID: "sec-1", UserID: "user-1"}, nil)
+				m.EXPECT().Trash(gomock.Any(), "sec-1").Return(nil)
+			},
+		},
+		{
+			name:   "Already In Trash",
+			userID: "user-1",
+			mockBehavior: func(m *mocks.MockSecretRepository) {
+				m.EXPECT().GetByID(gomock.Any(), "sec-1").Return(&domain.Secret{ID: "sec-1", UserID: "user-1", DeletedAt: &deletedAt}, nil)
+			},
+		},
+		{
+			name:   "Not Found",
+			userID: "user-1",
+			mockBehavior: func(m *mocks.MockSecretRepository) {
+				m.EXPECT().GetByID(gomock.Any(), "sec-1").Return(nil, nil)
+			},
+		},
+		{
+			name:   "Unauthorized",
+			userID: "user-2",
+			mockBehavior: func(m *mocks.MockSecretRepository) {
+				m.EXPECT().GetByID(gomock.Any(), "sec-1").Return(&domain.Secret{ID: "sec-1", UserID: "user-1"}, nil)
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			keys := newKeyProvider(t, "12345678901234567890123456789012")
+			repo := mocks.NewMockSecretRepository(ctrl)
+			tt.mockBehavior(repo)
+
+			uc := usecase.NewSecretUsecase(repo, mocks.NewMockSecretVersionRepository(ctrl), mocks.NewMockFolderRepository(ctrl), mocks.NewMockUserKeyRepository(ctrl), newSettingsRepo(ctrl), newVaultRepo(ctrl, false), keys)
+			err := uc.DeleteSecret(context.Background(), "sec-1", tt.userID)
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSecretUsecase_ListSecrets_Page(t *testing.T) {
+	tests := []struct {
+		name      string
+		filter    domain.SecretFilter
+		page      domain.PageRequest
+		expected  domain.PageRequest
+		expectErr error
+	}{
+		{
+			name:     "Defaults",
+			expected: domain.PageRequest{Sort: domain.SortCreated, Limit: domain.DefaultPageSize},
+		},
+		{
+			name:     "Search By Title",
+			filter:   domain.SecretFilter{Query: "git hub"},
+			page:     domain.PageRequest{Sort: domain.SortTitle, Cursor: "next", Limit: 10},
+			expected: domain.PageRequest{Sort: domain.SortTitle, Cursor: "next", Limit: 10},
+		},
+		{
+			name:     "Limit Capped",
+			page:     domain.PageRequest{Sort: domain.SortLastUsed, Limit: 10000},
+			expected: domain.PageRequest{Sort: domain.SortLastUsed, Limit: domain.MaxPageSize},
+		},
+		{
+			name:      "Unknown Sort",
+			page:      domain.PageRequest{Sort: "password"},
+			expectErr: domain.ErrInvalidSort,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockSecretRepository(ctrl)
+			if tt.expectErr == nil {
+				repo.EXPECT().ListPage(gomock.Any(), "user-1", tt.filter, tt.expected).Return(&domain.SecretPage{Total: 1}, nil)
+			}
+
+			keys := newKeyProvider(t, "12345678901234567890123456789012")
+			uc := usecase.NewSecretUsecase(repo, mocks.NewMockSecretVersionRepository(ctrl), mocks.NewMockFolderRepository(ctrl), mocks.NewMockUserKeyRepository(ctrl), newSettingsRepo(ctrl), newVaultRepo(ctrl, false), keys)
+			page, err := uc.ListSecrets(context.Background(), "user-1", tt.filter, tt.page)
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 1, page.Total)
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/pkg/crypto"
+)
+
+// plaintextFieldPattern matches "username" and metadata keys users may leave unencrypted.
+var plaintextFieldPattern = regexp.MustCompile(`^[a-z0-9_]{1,64}$`)
+
+type settingsUsecase struct {
+	repo        domain.SettingsRepository
+	secretRepo  domain.SecretRepository
+	versionRepo domain.SecretVersionRepository
+	sealer      *secretSealer
+}
+
+func NewSettingsUsecase(repo domain.SettingsRepository, secretRepo domain.SecretRepository, versionRepo domain.SecretVersionRepository, keyRepo domain.UserKeyRepository, keyProvider crypto.KeyProvider) domain.SettingsUsecase {
+	return &settingsUsecase{
+		repo:        repo,
+		secretRepo:  secretRepo,
+		versionRepo: versionRepo,
+		sealer:      newSecretSealer(newKeyManager(keyRepo, crypto.NewKeyring(keyProvider)), repo),
+	}
+}
+
+func (u *settingsUsecase) GetSettings(ctx context.Context, userID string) (*domain.UserSettings, error) {
+	return u.repo.Get(ctx, userID)
+}
+
+func (u *settingsUsecase) UpdateSettings(ctx context.Context, settings *domain.UserSettings) error {
+	fields, err := normalizePlaintextFields(settings.PlaintextFields)
+	if err != nil {
+		return err
+	}
+	settings.PlaintextFields = fields
+	if settings.AutoLockMinutes < 0 || settings.AutoLockMinutes > domain.MaxAutoLockMinutes {
+		return fmt.Errorf("auto_lock_minutes must be 0-%d", domain.MaxAutoLockMinutes)
+	}
+	if settings.HistoryLimit < 1 || settings.HistoryLimit > domain.MaxHistoryLimit {
+		return fmt.Errorf("history_limit must be 1-%d", domain.MaxHistoryLimit)
+	}
+
+	current, err := u.repo.Get(ctx, settings.UserID)
+	if err != nil {
+		return err
+	}
+	if err := u.repo.Upsert(ctx, settings); err != nil {
+		return err
+	}
+	if settings.HistoryLimit < current.HistoryLimit {
+		if err := u.versionRepo.Prune(ctx, settings.UserID, settings.HistoryLimit); err != nil {
+			return err
+		}
+	}
+	if sameFields(current.PlaintextFields, fields) {
+		return nil
+	}
+
+	// Move fields between plaintext and the sealed payload to match the new choice.
+	// Open merges both halves, so rows not yet resealed stay readable if this stops early.
+	secrets, err := u.secretRepo.ListByUserID(ctx, settings.UserID)
+	if err != nil {
+		return err
+	}
+	trashed, err := u.secretRepo.ListTrash(ctx, settings.UserID)
+	if err != nil {
+		return err
+	}
+	secrets = append(secrets, trashed...)
+	plain := fieldSet(fields)
+	for _, s := range secrets {
+		if err := u.reseal(ctx, s, plain); err != nil {
+			return fmt.Errorf("secret %s: %w", s.ID, err)
+		}
+	}
+
+	// History holds the same fields, and is shown like the live secret.
+	versions, err := u.versionRepo.ListByUserID(ctx, settings.UserID)
+	if err != nil {
+		return err
+	}
+	for _, v := range versions {
+		snapshot := v.Secret()
+		if err := u.sealer.Open(ctx, snapshot); err != nil {
+			return fmt.Errorf("secret %s version %d: %w", v.SecretID, v.Version, err)
+		}
+		if err := u.sealer.sealWith(ctx, snapshot, plain); err != nil {
+			return fmt.Errorf("secret %s version %d: %w", v.SecretID, v.Version, err)
+		}
+		v.Username = snapshot.Username
+		v.Metadata = snapshot.Metadata
+		v.EncryptedPayload = snapshot.EncryptedPayload
+		if err := u.versionRepo.Reseal(ctx, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resealAttempts bounds how often a secret edited while it is resealed is
+// read again.
+const resealAttempts = 3
+
+// reseal moves the fields of a stored secret to match plain. If the secret
+// changed since it was read, it starts over from the stored state, as the
+// edit may have been sealed under the old settings.
+func (u *settingsUsecase) reseal(ctx context.Context, s *domain.Secret, plain map[string]bool) error {
+	for attempt := 1; ; attempt++ {
+		oldPayload := s.EncryptedPayload
+		if err := u.sealer.Open(ctx, s); err != nil {
+			return err
+		}
+		if err := u.sealer.sealWith(ctx, s, plain); err != nil {
+			return err
+		}
+		resealed, err := u.secretRepo.Reseal(ctx, s, oldPayload)
+		if err != nil || resealed {
+			return err
+		}
+		if attempt == resealAttempts {
+			return domain.ErrVersionConflict
+		}
+		s, err = u.secretRepo.GetByID(ctx, s.ID)
+		if err != nil || s == nil {
+			return err // A purged secret needs no resealing
+		}
+	}
+}
+
+func normalizePlaintextFields(fields []string) ([]string, error) {
+	seen := make(map[string]bool, len(fields))
+	normalized := make([]string, 0, len(fields))
+	for _, f := range fields {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f == "title" || seen[f] {
+			continue // The title is always plaintext
+		}
+		if f == "password" {
+			return nil, fmt.Errorf("the password is always encrypted")
+		}
+		if !plaintextFieldPattern.MatchString(f) {
+			return nil, fmt.Errorf("invalid field name %q", f)
+		}
+		seen[f] = true
+		normalized = append(normalized, f)
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+func sameFields(a, b []string) bool {
+	setA, setB := fieldSet(a), fieldSet(b)
+	if len(setA) != len(setB) {
+		return false
+	}
+	for f := range setA {
+		if !setB[f] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,195 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/internal/mocks"
+	"github.com/herdiagusthio/password-manager/internal/usecase"
+	"github.com/herdiagusthio/password-manager/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSettingsUsecase_UpdateSettings(t *testing.T) {
+	mockKey := "12345678901234567890123456789012"
+	dataKey := []byte("abcdefghijklmnopqrstuvwxyz012345")
+	keys := newKeyProvider(t, mockKey)
+
+	tests := []struct {
+		name           string
+		fields         []string
+		autoLock       int
+		historyLimit   int // 0 means domain.DefaultHistoryLimit
+		expectedFields []string
+		expectedError  bool
+	}{
+		{name: "Normalized", fields: []string{" URL ", "username", "url", "title"}, expectedFields: []string{"url", "username"}},
+		{name: "Nothing In Plaintext", fields: nil, expectedFields: []string{}},
+		{name: "Password Rejected", fields: []string{"password"}, expectedError: true},
+		{name: "Invalid Field Name", fields: []string{"notes; DROP"}, expectedError: true},
+		{name: "Auto-Lock Too Long", fields: []string{"url"}, autoLock: domain.MaxAutoLockMinutes + 1, expectedError: true},
+		{name: "Negative Auto-Lock", fields: []string{"url"}, autoLock: -1, expectedError: true},
+		{name: "History Limit Too Large", fields: []string{"url"}, historyLimit: domain.MaxHistoryLimit + 1, expectedError: true},
+		{name: "Negative History Limit", fields: []string{"url"}, historyLimit: -1, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			settingsRepo := mocks.NewMockSettingsRepository(ctrl)
+			secretRepo := mocks.NewMockSecretRepository(ctrl)
+			versionRepo := mocks.NewMockSecretVersionRepository(ctrl)
+			if !tt.expectedError {
+				settingsRepo.EXPECT().Get(gomock.Any(), "user-1").Return(&domain.UserSettings{UserID: "user-1", PlaintextFields: []string{"notes"}, HistoryLimit: domain.DefaultHistoryLimit}, nil)
+				settingsRepo.EXPECT().Upsert(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, s *domain.UserSettings) error {
+					assert.Equal(t, tt.expectedFields, s.PlaintextFields)
+					return nil
+				})
+				secretRepo.EXPECT().ListByUserID(gomock.Any(), "user-1").Return(nil, nil)
+				secretRepo.EXPECT().ListTrash(gomock.Any(), "user-1").Return(nil, nil)
+				versionRepo.EXPECT().ListByUserID(gomock.Any(), "user-1").Return(nil, nil)
+			}
+
+			historyLimit := tt.historyLimit
+			if historyLimit == 0 {
+				historyLimit = domain.DefaultHistoryLimit
+			}
+
+			uc := usecase.NewSettingsUsecase(settingsRepo, secretRepo, versionRepo, newKeyRepo(t, ctrl, keys, dataKey), keys)
+			err := uc.UpdateSettings(context.Background(), &domain.UserSettings{UserID: "user-1", PlaintextFields: tt.fields, AutoLockMinutes: tt.autoLock, HistoryLimit: historyLimit})
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSettingsUsecase_UpdateSettings_Reseals(t *testing.T) {
+	mockKey := "12345678901234567890123456789012"
+	dataKey := []byte("abcdefghijklmnopqrstuvwxyz012345")
+	keys := newKeyProvider(t, mockKey)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	settingsRepo := mocks.NewMockSettingsRepository(ctrl)
+	settingsRepo.EXPECT().Get(gomock.Any(), "user-1").Return(&domain.UserSettings{UserID: "user-1", PlaintextFields: domain.DefaultPlaintextFields, HistoryLimit: domain.DefaultHistoryLimit}, nil)
+	settingsRepo.EXPECT().Upsert(gomock.Any(), gomock.Any()).Return(nil)
+
+	// Stored under the defaults: username in plaintext, notes sealed.
+	payload, err := crypto.EncryptWithKey([]byte(`{"metadata":{"notes":"old notes"}}`), crypto.DataKeyID, dataKey, []byte("secret_payload:sec-1:user:user-1"))
+	require.NoError(t, err)
+
+	secretRepo := mocks.NewMockSecretRepository(ctrl)
+	// Trashed secrets are resealed too, so they still match once restored.
+	secretRepo.EXPECT().ListByUserID(gomock.Any(), "user-1").Return(nil, nil)
+	secretRepo.EXPECT().ListTrash(gomock.Any(), "user-1").Return([]*domain.Secret{
+		{ID: "sec-1", UserID: "user-1", Username: "alice", EncryptedPayload: payload},
+	}, nil)
+	secretRepo.EXPECT().Reseal(gomock.Any(), gomock.Any(), payload).DoAndReturn(func(ctx context.Context, s *domain.Secret, oldPayload string) (bool, error) {
+		assert.Empty(t, s.Username)
+		assert.Equal(t, map[string]interface{}{"notes": "old notes"}, s.Metadata)
+		assert.NotEmpty(t, s.EncryptedPayload)
+		assert.NotEqual(t, payload, s.EncryptedPayload)
+		return true, nil
+	})
+
+	// So is history, which would otherwise still show the username.
+	versionRepo := mocks.NewMockSecretVersionRepository(ctrl)
+	versionRepo.EXPECT().ListByUserID(gomock.Any(), "user-1").Return([]*domain.SecretVersion{
+		{SecretID: "sec-1", UserID: "user-1", Version: 1, Username: "old-alice"},
+	}, nil)
+	versionRepo.EXPECT().Reseal(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, v *domain.SecretVersion) error {
+		assert.Equal(t, 1, v.Version)
+		assert.Empty(t, v.Username)
+		sealed, err := crypto.DecryptWithKeys(v.EncryptedPayload, []byte("secret_payload:sec-1:user:user-1"), func(string) ([]byte, error) { return dataKey, nil })
+		require.NoError(t, err)
+		assert.Contains(t, string(sealed), "old-alice")
+		return nil
+	})
+
+	uc := usecase.NewSettingsUsecase(settingsRepo, secretRepo, versionRepo, newKeyRepo(t, ctrl, keys, dataKey), keys)
+	err = uc.UpdateSettings(context.Background(), &domain.UserSettings{UserID: "user-1", PlaintextFields: []string{"notes"}, HistoryLimit: domain.DefaultHistoryLimit})
+	require.NoError(t, err)
+}
+
+func TestSettingsUsecase_UpdateSettings_ResealsEditedSecret(t *testing.T) {
+	mockKey := "12345678901234567890123456789012"
+	dataKey := []byte("abcdefghijklmnopqrstuvwxyz012345")
+	keys := newKeyProvider(t, mockKey)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	settingsRepo := mocks.NewMockSettingsRepository(ctrl)
+	settingsRepo.EXPECT().Get(gomock.Any(), "user-1").Return(&domain.UserSettings{UserID: "user-1", PlaintextFields: domain.DefaultPlaintextFields, HistoryLimit: domain.DefaultHistoryLimit}, nil)
+	settingsRepo.EXPECT().Upsert(gomock.Any(), gomock.Any()).Return(nil)
+
+	// The user renames the account between the listing and the reseal; the
+	// reseal must start over from the edit instead of writing over it.
+	secretRepo := mocks.NewMockSecretRepository(ctrl)
+	secretRepo.EXPECT().ListByUserID(gomock.Any(), "user-1").Return([]*domain.Secret{
+		{ID: "sec-1", UserID: "user-1", Username: "alice", Version: 1},
+	}, nil)
+	secretRepo.EXPECT().ListTrash(gomock.Any(), "user-1").Return(nil, nil)
+	gomock.InOrder(
+		secretRepo.EXPECT().Reseal(gomock.Any(), gomock.Any(), "").Return(false, nil),
+		secretRepo.EXPECT().GetByID(gomock.Any(), "sec-1").Return(&domain.Secret{ID: "sec-1", UserID: "user-1", Username: "bob", Version: 2}, nil),
+		secretRepo.EXPECT().Reseal(gomock.Any(), gomock.Any(), "").DoAndReturn(func(ctx context.Context, s *domain.Secret, oldPayload string) (bool, error) {
+			assert.Equal(t, 2, s.Version)
+			assert.Empty(t, s.Username)
+			payload, err := crypto.DecryptWithKeys(s.EncryptedPayload, []byte("secret_payload:sec-1:user:user-1"), func(string) ([]byte, error) { return dataKey, nil })
+			require.NoError(t, err)
+			assert.Contains(t, string(payload), "bob")
+			return true, nil
+		}),
+	)
+
+	versionRepo := mocks.NewMockSecretVersionRepository(ctrl)
+	versionRepo.EXPECT().ListByUserID(gomock.Any(), "user-1").Return(nil, nil)
+
+	uc := usecase.NewSettingsUsecase(settingsRepo, secretRepo, versionRepo, newKeyRepo(t, ctrl, keys, dataKey), keys)
+	err := uc.UpdateSettings(context.Background(), &domain.UserSettings{UserID: "user-1", PlaintextFields: []string{"notes"}, HistoryLimit: domain.DefaultHistoryLimit})
+	require.NoError(t, err)
+}
+
+func TestSettingsUsecase_UpdateSettings_SameFields(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	settingsRepo := mocks.NewMockSettingsRepository(ctrl)
+	settingsRepo.EXPECT().Get(gomock.Any(), "user-1").Return(&domain.UserSettings{UserID: "user-1", PlaintextFields: []string{"url", "username"}, HistoryLimit: domain.DefaultHistoryLimit}, nil)
+	settingsRepo.EXPECT().Upsert(gomock.Any(), gomock.Any()).Return(nil)
+
+	// Changing only the auto-lock timeout must not touch any secret.
+	secretRepo := mocks.NewMockSecretRepository(ctrl)
+
+	uc := usecase.NewSettingsUsecase(settingsRepo, secretRepo, mocks.NewMockSecretVersionRepository(ctrl), mocks.NewMockUserKeyRepository(ctrl), newKeyProvider(t, "12345678901234567890123456789012"))
+	err := uc.UpdateSettings(context.Background(), &domain.UserSettings{UserID: "user-1", PlaintextFields: []string{"username", "url"}, AutoLockMinutes: 5, HistoryLimit: domain.DefaultHistoryLimit})
+	require.NoError(t, err)
+}
+
+func TestSettingsUsecase_UpdateSettings_PrunesHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	settingsRepo := mocks.NewMockSettingsRepository(ctrl)
+	settingsRepo.EXPECT().Get(gomock.Any(), "user-1").Return(&domain.UserSettings{UserID: "user-1", PlaintextFields: []string{"url"}, HistoryLimit: domain.DefaultHistoryLimit}, nil)
+	settingsRepo.EXPECT().Upsert(gomock.Any(), gomock.Any()).Return(nil)
+
+	// Lowering the limit drops the excess history right away.
+	versionRepo := mocks.NewMockSecretVersionRepository(ctrl)
+	versionRepo.EXPECT().Prune(gomock.Any(), "user-1", 3).Return(nil)
+
+	uc := usecase.NewSettingsUsecase(settingsRepo, mocks.NewMockSecretRepository(ctrl), versionRepo, mocks.NewMockUserKeyRepository(ctrl), newKeyProvider(t, "12345678901234567890123456789012"))
+	err := uc.UpdateSettings(context.Background(), &domain.UserSettings{UserID: "user-1", PlaintextFields: []string{"url"}, HistoryLimit: 3})
+	require.NoError(t, err)
+}
@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+)
+
+var tagPattern = regexp.MustCompile(`^[\p{L}\p{N}][\p{L}\p{N} _.:-]*$`)
+
+// normalizeTag trims and lowercases a tag name, so "Prod-DB" and "prod-db"
+// are the same tag.
+func normalizeTag(name string) (string, error) {
+	name = strings.ToLower(strings.Join(strings.Fields(name), " "))
+	if utf8.RuneCountInString(name) > domain.MaxTagLength || !tagPattern.MatchString(name) {
+		return "", domain.ErrInvalidTag
+	}
+	return name, nil
+}
+
+type tagUsecase struct {
+	repo       domain.TagRepository
+	secretRepo domain.SecretRepository
+}
+
+func NewTagUsecase(repo domain.TagRepository, secretRepo domain.SecretRepository) domain.TagUsecase {
+	return &tagUsecase{
+		repo:       repo,
+		secretRepo: secretRepo,
+	}
+}
+
+func (u *tagUsecase) ListTags(ctx context.Context, userID string) ([]*domain.Tag, error) {
+	tags, err := u.repo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if tags == nil {
+		tags = []*domain.Tag{}
+	}
+	return tags, nil
+}
+
+// ownedSecret loads one of the user's secrets outside the trash; nil if it
+// does not exist.
+func (u *tagUsecase) ownedSecret(ctx context.Context, id string, userID string) (*domain.Secret, error) {
+	secret, err := u.secretRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.DeletedAt != nil {
+		return nil, nil
+	}
+	if secret.UserID != userID {
+		return nil, fmt.Errorf("unauthorized access to secret")
+	}
+	return secret, nil
+}
+
+// tagsOf reloads a secret's tags after a change.
+func (u *tagUsecase) tagsOf(ctx context.Context, id string) ([]string, error) {
+	secret, err := u.secretRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Tags == nil {
+		return []string{}, nil
+	}
+	return secret.Tags, nil
+}
+
+func (u *tagUsecase) AddTags(ctx context.Context, userID string, secretID string, names []string) ([]string, error) {
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		tag, err := normalizeTag(name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", err, name)
+		}
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+
+	secret, err := u.ownedSecret(ctx, secretID, userID)
+	if err != nil || secret == nil {
+		return nil, err
+	}
+	if len(normalized) > 0 {
+		if err := u.repo.AddToSecret(ctx, userID, secretID, normalized); err != nil {
+			return nil, err
+		}
+	}
+	return u.tagsOf(ctx, secretID)
+}
+
+func (u *tagUsecase) RemoveTag(ctx context.Context, userID string, secretID string, name string) ([]string, error) {
+	tag, err := normalizeTag(name)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := u.ownedSecret(ctx, secretID, userID)
+	if err != nil || secret == nil {
+		return nil, err
+	}
+	// Removing a tag the secret does not have is not an error.
+	if _, err := u.repo.RemoveFromSecret(ctx, userID, secretID, tag); err != nil {
+		return nil, err
+	}
+	return u.tagsOf(ctx, secretID)
+}
+
+func (u *tagUsecase) RenameTag(ctx context.Context, userID string, from string, to string) error {
+	from, err := normalizeTag(from)
+	if err != nil {
+		return domain.ErrTagNotFound
+	}
+	to, err = normalizeTag(to)
+	if err != nil {
+		return err
+	}
+	found, err := u.repo.Rename(ctx, userID, from, to)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return domain.ErrTagNotFound
+	}
+	return nil
+}
+
+func (u *tagUsecase) DeleteTag(ctx context.Context, userID string, name string) error {
+	tag, err := normalizeTag(name)
+	if err != nil {
+		return domain.ErrTagNotFound
+	}
+	found, err := u.repo.Delete(ctx, userID, tag)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return domain.ErrTagNotFound
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/internal/mocks"
+	"github.com/herdiagusthio/password-manager/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTagUsecase_AddTags(t *testing.T) {
+	deletedAt := time.Now()
+	tests := []struct {
+		name         string
+		userID       string
+		tags         []string
+		mockBehavior func(secrets *mocks.MockSecretRepository, tags *mocks.MockTagRepository)
+		expectTags   []string
+		expectErr    error
+		expectAnyErr bool
+	}{
+		{
+			name:   "Normalized",
+			userID: "user-1",
+			tags:   []string{" On-Call ", "prod-db", "Team  Blue"},
+			mockBehavior: func(secrets *mocks.MockSecretRepository, tags *mocks.MockTagRepository) {
+				secrets.EXPECT().GetByID(gomock.Any(), "sec-1").Return(&domain.Secret{ID: "sec-1", UserID: "user-1"}, nil)
+				tags.EXPECT().AddToSecret(gomock.Any(), "user-1", "sec-1", []string{"on-call", "prod-db", "team blue"}).Return(nil)
+				secrets.EXPECT().GetByID(gomock.Any(), "sec-1").Return(&domain.Secret{ID: "sec-1", UserID: "user-1", Tags: []string{"on-call", "prod-db", "team blue"}}, nil)
+			},
+			expectTags: []string{"on-call", "prod-db", "team blue"},
+		},
+		{
+			name:      "Invalid Tag",
+			userID:    "user-1",
+			tags:      []string{"ok", "a/b"},
+			expectErr: domain.ErrInvalidTag,
+		},
+		{
+			name:      "Empty Tag",
+			userID:    "user-1",
+			tags:      []string{"  "},
+			expectErr: domain.ErrInvalidTag,
+		},
+		{
+			name:   "In Trash",
+			userID: "user-1",
+			tags:   []string{"prod-db"},
+			mockBehavior: func(secrets *mocks.MockSecretRepository, tags *mocks.MockTagRepository) {
+				secrets.EXPECT().GetByID(gomock.Any(), "sec-1").Return(&domain.Secret{ID: "sec-1", UserID: "user-1", DeletedAt: &deletedAt}, nil)
+			},
+		},
+		{
+			name:   "Unauthorized",
+			userID: "user-2",
+			tags:   []string{"prod-db"},
+			mockBehavior: func(secrets *mocks.MockSecretRepository, tags *mocks.MockTagRepository) {
+				secrets.EXPECT().GetByID(gomock.Any(), "sec-1").Return(&domain.Secret{ID: "sec-1", UserID: "user-1"}, nil)
+			},
+			expectAnyErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			secretRepo := mocks.NewMockSecretRepository(ctrl)
+			tagRepo := mocks.NewMockTagRepository(ctrl)
+			if tt.mockBehavior != nil {
+				tt.mockBehavior(secretRepo, tagRepo)
+			}
+
+			uc := usecase.NewTagUsecase(tagRepo, secretRepo)
+			tags, err := uc.AddTags(context.Background(), tt.userID, "sec-1", tt.tags)
+			switch {
+			case tt.expectErr != nil:
+				assert.ErrorIs(t, err, tt.expectErr)
+			case tt.expectAnyErr:
+				assert.Error(t, err)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectTags, tags)
+			}
+		})
+	}
+}
+
+func TestTagUsecase_RenameTag(t *testing.T) {
+	tests := []struct {
+		name         string
+		from, to     string
+		mockBehavior func(m *mocks.MockTagRepository)
+		expectErr    error
+	}{
+		{
+			name: "Rename",
+			from: "Prod",
+			to:   "prod-db",
+			mockBehavior: func(m *mocks.MockTagRepository) {
+				m.EXPECT().Rename(gomock.Any(), "user-1", "prod", "prod-db").Return(true, nil)
+			},
+		},
+		{
+			name: "Unknown",
+			from: "prod",
+			to:   "prod-db",
+			mockBehavior: func(m *mocks.MockTagRepository) {
+				m.EXPECT().Rename(gomock.Any(), "user-1", "prod", "prod-db").Return(false, nil)
+			},
+			expectErr: domain.ErrTagNotFound,
+		},
+		{name: "Invalid New Name", from: "prod", to: "", expectErr: domain.ErrInvalidTag},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			tagRepo := mocks.NewMockTagRepository(ctrl)
+			if tt.mockBehavior != nil {
+				tt.mockBehavior(tagRepo)
+			}
+
+			uc := usecase.NewTagUsecase(tagRepo, mocks.NewMockSecretRepository(ctrl))
+			err := uc.RenameTag(context.Background(), "user-1", tt.from, tt.to)
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSecretUsecase_ListSecrets_TagAndFavorite(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockSecretRepository(ctrl)
+	repo.EXPECT().ListPage(gomock.Any(), "user-1", domain.SecretFilter{Tag: "prod-db", Favorite: true}, gomock.Any()).Return(&domain.SecretPage{}, nil)
+
+	keys := newKeyProvider(t, "12345678901234567890123456789012")
+	uc := usecase.NewSecretUsecase(repo, mocks.NewMockSecretVersionRepository(ctrl), mocks.NewMockFolderRepository(ctrl), mocks.NewMockUserKeyRepository(ctrl), newSettingsRepo(ctrl), newVaultRepo(ctrl, false), keys)
+
+	// Tags are matched case-insensitively.
+	_, err := uc.ListSecrets(context.Background(), "user-1", domain.SecretFilter{Tag: "Prod-DB", Favorite: true}, domain.PageRequest{})
+	require.NoError(t, err)
+
+	// A tag no secret can carry finds nothing without asking the database.
+	page, err := uc.ListSecrets(context.Background(), "user-1", domain.SecretFilter{Tag: "a/b"}, domain.PageRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, page.Secrets)
+}
+
+func TestSecretUsecase_SetFavorite(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockSecretRepository(ctrl)
+	repo.EXPECT().GetByID(gomock.Any(), "sec-1").Return(&domain.Secret{ID: "sec-1", UserID: "user-1", Version: 3}, nil)
+	repo.EXPECT().SetFavorite(gomock.Any(), "sec-1", true).Return(nil)
+	repo.EXPECT().GetByID(gomock.Any(), "sec-2").Return(nil, nil)
+
+	keys := newKeyProvider(t, "12345678901234567890123456789012")
+	uc := usecase.NewSecretUsecase(repo, mocks.NewMockSecretVersionRepository(ctrl), mocks.NewMockFolderRepository(ctrl), mocks.NewMockUserKeyRepository(ctrl), newSettingsRepo(ctrl), newVaultRepo(ctrl, false), keys)
+
+	secret, err := uc.SetFavorite(context.Background(), "sec-1", "user-1", true)
+	require.NoError(t, err)
+	assert.True(t, secret.Favorite)
+	assert.Equal(t, 3, secret.Version) // Not an edit
+
+	secret, err = uc.SetFavorite(context.Background(), "sec-2", "user-1", true)
+	require.NoError(t, err)
+	assert.Nil(t, secret)
+}
@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+)
+
+type trashUsecase struct {
+	secretRepo domain.SecretRepository
+	retention  time.Duration
+	interval   time.Duration
+}
+
+// NewTrashUsecase purges trashed secrets once they are older than retention,
+// checking every interval. A zero retention keeps them until the user purges them.
+func NewTrashUsecase(secretRepo domain.SecretRepository, retention, interval time.Duration) domain.TrashUsecase {
+	return &trashUsecase{
+		secretRepo: secretRepo,
+		retention:  retention,
+		interval:   interval,
+	}
+}
+
+func (u *trashUsecase) ListTrash(ctx context.Context, userID string) ([]*domain.Secret, error) {
+	secrets, err := u.secretRepo.ListTrash(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if secrets == nil {
+		secrets = []*domain.Secret{}
+	}
+	return secrets, nil
+}
+
+// trashedSecret loads a secret of the user that is in the trash. It returns
+// nil if the secret does not exist.
+func (u *trashUsecase) trashedSecret(ctx context.Context, id string, userID string) (*domain.Secret, error) {
+	secret, err := u.secretRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil {
+		return nil, nil
+	}
+	if secret.UserID != userID {
+		return nil, fmt.Errorf("unauthorized access to secret")
+	}
+	if secret.DeletedAt == nil {
+		return nil, domain.ErrNotInTrash
+	}
+	return secret, nil
+}
+
+func (u *trashUsecase) RestoreSecret(ctx context.Context, id string, userID string) (*domain.Secret, error) {
+	secret, err := u.trashedSecret(ctx, id, userID)
+	if err != nil || secret == nil {
+		return nil, err
+	}
+	restored, err := u.secretRepo.Restore(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		// Restored or purged concurrently.
+		return nil, domain.ErrNotInTrash
+	}
+	secret.DeletedAt = nil
+	return secret, nil
+}
+
+func (u *trashUsecase) PurgeSecret(ctx context.Context, id string, userID string) error {
+	secret, err := u.trashedSecret(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if secret == nil {
+		return domain.ErrNotInTrash
+	}
+	// The history goes with it (ON DELETE CASCADE).
+	purged, err := u.secretRepo.Purge(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !purged {
+		// Restored or purged concurrently.
+		return domain.ErrNotInTrash
+	}
+	return nil
+}
+
+func (u *trashUsecase) EmptyTrash(ctx context.Context, userID string) (int64, error) {
+	return u.secretRepo.EmptyTrash(ctx, userID)
+}
+
+func (u *trashUsecase) Retention() time.Duration {
+	return u.retention
+}
+
+func (u *trashUsecase) Run(ctx context.Context) error {
+	if u.retention <= 0 {
+		return nil
+	}
+	ticker := time.NewTicker(u.interval)
+	defer ticker.Stop()
+	for {
+		if err := u.purgeExpired(ctx); err != nil {
+			log.Printf("trash purge: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (u *trashUsecase) purgeExpired(ctx context.Context) error {
+	purged, err := u.secretRepo.PurgeTrash(ctx, time.Now().Add(-u.retention))
+	if err != nil {
+		return err
+	}
+	if purged > 0 {
+		log.Printf("trash purge: deleted %d secrets trashed more than %s ago", purged, u.retention)
+	}
+	return nil
+}
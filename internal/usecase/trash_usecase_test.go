@@ -0,0 +1,181 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/internal/mocks"
+	"github.com/herdiagusthio/password-manager/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTrashUsecase_RestoreSecret(t *testing.T) {
+	deletedAt := time.Now()
+	tests := []struct {
+		name         string
+		userID       string
+		mockBehavior func(m *mocks.MockSecretRepository)
+		expectNil    bool
+		expectErr    error
+		expectAnyErr bool
+	}{
+		{
+			name:   "Restored",
+			userID: "user-1",
+			mockBehavior: func(m *mocks.MockSecretRepository) {
+				m.EXPECT().GetByID(gomock.Any(), "sec-1").Return(&domain.Secret{ID: "sec-1", UserID: "user-1", DeletedAt: &deletedAt}, nil)
+				m.EXPECT().Restore(gomock.Any(), "sec-1").Return(true, nil)
+			},
+		},
+		{
+			name:   "Not Found",
+			userID: "user-1",
+			mockBehavior: func(m *mocks.MockSecretRepository) {
+				m.EXPECT().GetByID(gomock.Any(), "sec-1").Return(nil, nil)
+			},
+			expectNil: true,
+		},
+		{
+			name:   "Not In Trash",
+			userID: "user-1",
+			mockBehavior: func(m *mocks.MockSecretRepository) {
+				m.EXPECT().GetByID(gomock.Any(), "sec-1").Return(&domain.Secret{ID: "sec-1", UserID: "user-1"}, nil)
+			},
+			expectErr: domain.ErrNotInTrash,
+		},
+		{
+			name:   "Restored Concurrently",
+			userID: "user-1",
+			mockBehavior: func(m *mocks.MockSecretRepository) {
+				m.EXPECT().GetByID(gomock.Any(), "sec-1").Return(&domain.Secret{ID: "sec-1", UserID: "user-1", DeletedAt: &deletedAt}, nil)
+				m.EXPECT().Restore(gomock.Any(), "sec-1").Return(false, nil)
+			},
+			expectErr: domain.ErrNotInTrash,
+		},
+		{
+			name:   "Unauthorized",
+			userID: "user-2",
+			mockBehavior: func(m *mocks.MockSecretRepository) {
+				m.EXPECT().GetByID(gomock.Any(), "sec-1").Return(&domain.Secret{ID: "sec-1", UserID: "user-1", DeletedAt: &deletedAt}, nil)
+			},
+			expectAnyErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockSecretRepository(ctrl)
+			tt.mockBehavior(repo)
+
+			uc := usecase.NewTrashUsecase(repo, 30*24*time.Hour, time.Hour)
+			secret, err := uc.RestoreSecret(context.Background(), "sec-1", tt.userID)
+			switch {
+			case tt.expectErr != nil:
+				assert.ErrorIs(t, err, tt.expectErr)
+			case tt.expectAnyErr:
+				assert.Error(t, err)
+			case tt.expectNil:
+				assert.NoError(t, err)
+				assert.Nil(t, secret)
+			default:
+				require.NoError(t, err)
+				assert.Nil(t, secret.DeletedAt)
+			}
+		})
+	}
+}
+
+func TestTrashUsecase_PurgeSecret(t *testing.T) {
+	deletedAt := time.Now()
+	tests := []struct {
+		name         string
+		mockBehavior func(m *mocks.MockSecretRepository)
+		expectErr    error
+	}{
+		{
+			name: "Purged",
+			mockBehavior: func(m *mocks.MockSecretRepository) {
+				m.EXPECT().GetByID(gomock.Any(), "sec-1").Return(&domain.Secret{ID: "sec-1", UserID: "user-1", DeletedAt: &deletedAt}, nil)
+				m.EXPECT().Purge(gomock.Any(), "sec-1").Return(true, nil)
+			},
+		},
+		{
+			name: "Restored Concurrently",
+			mockBehavior: func(m *mocks.MockSecretRepository) {
+				m.EXPECT().GetByID(gomock.Any(), "sec-1").Return(&domain.Secret{ID: "sec-1", UserID: "user-1", DeletedAt: &deletedAt}, nil)
+				m.EXPECT().Purge(gomock.Any(), "sec-1").Return(false, nil)
+			},
+			expectErr: domain.ErrNotInTrash,
+		},
+		{
+			name: "Not In Trash",
+			mockBehavior: func(m *mocks.MockSecretRepository) {
+				// Live secrets must be deleted through the trash first.
+				m.EXPECT().GetByID(gomock.Any(), "sec-1").Return(&domain.Secret{ID: "sec-1", UserID: "user-1"}, nil)
+			},
+			expectErr: domain.ErrNotInTrash,
+		},
+		{
+			name: "Not Found",
+			mockBehavior: func(m *mocks.MockSecretRepository) {
+				m.EXPECT().GetByID(gomock.Any(), "sec-1").Return(nil, nil)
+			},
+			expectErr: domain.ErrNotInTrash,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockSecretRepository(ctrl)
+			tt.mockBehavior(repo)
+
+			uc := usecase.NewTrashUsecase(repo, 30*24*time.Hour, time.Hour)
+			err := uc.PurgeSecret(context.Background(), "sec-1", "user-1")
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTrashUsecase_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	retention := 30 * 24 * time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The first purge runs at startup, with the cutoff one retention period ago.
+	repo := mocks.NewMockSecretRepository(ctrl)
+	repo.EXPECT().PurgeTrash(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, before time.Time) (int64, error) {
+		assert.WithinDuration(t, time.Now().Add(-retention), before, time.Minute)
+		cancel()
+		return 2, nil
+	})
+
+	uc := usecase.NewTrashUsecase(repo, retention, time.Hour)
+	err := uc.Run(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestTrashUsecase_Run_Disabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// No retention: nothing is ever purged automatically.
+	uc := usecase.NewTrashUsecase(mocks.NewMockSecretRepository(ctrl), 0, time.Hour)
+	assert.NoError(t, uc.Run(context.Background()))
+}
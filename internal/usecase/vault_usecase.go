@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/pkg/crypto"
+)
+
+// Argon2id bounds for the parameters chosen by the browser. The minimums follow
+// the OWASP recommendation; the maximums keep unlocking feasible on phones.
+const (
+	kdfAlgorithm      = "argon2id"
+	minKDFMemory      = 19 * 1024 // KiB
+	maxKDFMemory      = 1024 * 1024
+	minKDFIterations  = 2
+	maxKDFIterations  = 64
+	maxKDFParallelism = 16
+	minKDFSaltSize    = 16
+	authKeySize       = 32
+	maxVaultKeyIDSize = 64
+	minPINLength      = 6
+	maxPINLength      = 64
+)
+
+type vaultUsecase struct {
+	repo         domain.VaultRepository
+	settingsRepo domain.SettingsRepository
+}
+
+func NewVaultUsecase(repo domain.VaultRepository, settingsRepo domain.SettingsRepository) domain.VaultUsecase {
+	return &vaultUsecase{
+		repo:         repo,
+		settingsRepo: settingsRepo,
+	}
+}
+
+func (u *vaultUsecase) GetVault(ctx context.Context, userID string) (*domain.Vault, error) {
+	return u.repo.GetByUserID(ctx, userID)
+}
+
+func (u *vaultUsecase) EnableZeroKnowledge(ctx context.Context, userID string, setup *domain.VaultSetup) (*domain.Vault, error) {
+	vault, err := u.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if vault.ZeroKnowledge {
+		return nil, fmt.Errorf("zero-knowledge mode is already enabled")
+	}
+	if setup.KeyID == "" || len(setup.KeyID) > maxVaultKeyIDSize {
+		return nil, fmt.Errorf("key_id must be 1-%d characters", maxVaultKeyIDSize)
+	}
+	if err := u.apply(vault, setup); err != nil {
+		return nil, err
+	}
+	vault.KeyID = setup.KeyID
+
+	if err := u.repo.Save(ctx, vault); err != nil {
+		return nil, err
+	}
+	return vault, nil
+}
+
+func (u *vaultUsecase) ChangeMasterPassword(ctx context.Context, userID, currentAuthKey string, setup *domain.VaultSetup) (*domain.Vault, error) {
+	vault, err := u.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyAuthKey(vault, currentAuthKey); err != nil {
+		return nil, err
+	}
+	// The vault key stays the same, so existing secrets and backups remain readable.
+	if setup.KeyID != "" && setup.KeyID != vault.KeyID {
+		return nil, fmt.Errorf("changing the master password must not replace the vault key")
+	}
+	if err := u.apply(vault, setup); err != nil {
+		return nil, err
+	}
+
+	if err := u.repo.Save(ctx, vault); err != nil {
+		return nil, err
+	}
+	return vault, nil
+}
+
+func (u *vaultUsecase) VerifyMasterPassword(ctx context.Context, userID, authKey string) error {
+	vault, err := u.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return verifyAuthKey(vault, authKey)
+}
+
+func (u *vaultUsecase) GetLockPolicy(ctx context.Context, userID string) (*domain.LockPolicy, error) {
+	vault, err := u.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := u.settingsRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &domain.LockPolicy{
+		Methods:  []string{},
+		AutoLock: time.Duration(settings.AutoLockMinutes) * time.Minute,
+	}
+	if vault.ZeroKnowledge {
+		policy.Methods = append(policy.Methods, domain.UnlockMethodMasterPassword)
+	}
+	if vault.HasPIN {
+		policy.Methods = append(policy.Methods, domain.UnlockMethodPIN)
+	}
+	return policy, nil
+}
+
+func (u *vaultUsecase) Unlock(ctx context.Context, userID string, req *domain.UnlockRequest) error {
+	vault, err := u.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case req.AuthKey != "":
+		return verifyAuthKey(vault, req.AuthKey)
+	case req.PIN != "":
+		if !vault.HasPIN {
+			return fmt.Errorf("no unlock PIN is set")
+		}
+		ok, err := crypto.VerifyPassword(req.PIN, vault.PINHash)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return domain.ErrInvalidPIN
+		}
+		return nil
+	default:
+		return fmt.Errorf("a PIN or master password is required")
+	}
+}
+
+func (u *vaultUsecase) SetPIN(ctx context.Context, userID, pin string) error {
+	if len(pin) < minPINLength || len(pin) > maxPINLength {
+		return fmt.Errorf("PIN must be %d-%d characters", minPINLength, maxPINLength)
+	}
+	vault, err := u.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	hash, err := crypto.HashPassword(pin)
+	if err != nil {
+		return fmt.Errorf("failed to hash PIN: %w", err)
+	}
+	vault.PINHash = hash
+	return u.repo.Save(ctx, vault)
+}
+
+func (u *vaultUsecase) RemovePIN(ctx context.Context, userID string) error {
+	vault, err := u.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !vault.HasPIN {
+		return nil
+	}
+	vault.PINHash = ""
+	return u.repo.Save(ctx, vault)
+}
+
+// apply validates setup and copies the KDF parameters, verifier and wrapped key into vault.
+func (u *vaultUsecase) apply(vault *domain.Vault, setup *domain.VaultSetup) error {
+	if err := validateKDFParams(&setup.KDF); err != nil {
+		return err
+	}
+	verifier, err := hashAuthKey(setup.AuthKey)
+	if err != nil {
+		return err
+	}
+	if !crypto.IsClientBlob(setup.WrappedKey) {
+		return fmt.Errorf("wrapped_key must be encrypted by the browser")
+	}
+
+	kdf := setup.KDF
+	vault.KDF = &kdf
+	vault.Verifier = verifier
+	vault.WrappedKey = setup.WrappedKey
+	return nil
+}
+
+func validateKDFParams(p *domain.KDFParams) error {
+	if p.Algorithm != kdfAlgorithm {
+		return fmt.Errorf("unsupported KDF %q, expected %s", p.Algorithm, kdfAlgorithm)
+	}
+	if p.Memory < minKDFMemory || p.Memory > maxKDFMemory {
+		return fmt.Errorf("KDF memory must be %d-%d KiB", minKDFMemory, maxKDFMemory)
+	}
+	if p.Iterations < minKDFIterations || p.Iterations > maxKDFIterations {
+		return fmt.Errorf("KDF iterations must be %d-%d", minKDFIterations, maxKDFIterations)
+	}
+	if p.Parallelism < 1 || p.Parallelism > maxKDFParallelism {
+		return fmt.Errorf("KDF parallelism must be 1-%d", maxKDFParallelism)
+	}
+	salt, err := base64.StdEncoding.DecodeString(p.Salt)
+	if err != nil || len(salt) < minKDFSaltSize {
+		return fmt.Errorf("KDF salt must be at least %d bytes, base64 encoded", minKDFSaltSize)
+	}
+	return nil
+}
+
+// hashAuthKey returns the verifier stored for an authentication key. The key
+// is the output of Argon2id, so a single SHA-256 is enough to store it safely.
+func hashAuthKey(authKey string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(authKey)
+	if err != nil || len(raw) != authKeySize {
+		return "", fmt.Errorf("auth_key must be %d bytes, base64 encoded", authKeySize)
+	}
+	sum := sha256.Sum256(raw)
+	return base64.StdEncoding.EncodeToString(sum[:]), nil
+}
+
+func verifyAuthKey(vault *domain.Vault, authKey string) error {
+	if !vault.ZeroKnowledge {
+		return fmt.Errorf("zero-knowledge mode is not enabled")
+	}
+	verifier, err := hashAuthKey(authKey)
+	if err != nil {
+		return domain.ErrInvalidMasterPassword
+	}
+	if subtle.ConstantTimeCompare([]byte(verifier), []byte(vault.Verifier)) != 1 {
+		return domain.ErrInvalidMasterPassword
+	}
+	return nil
+}
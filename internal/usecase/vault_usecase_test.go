@@ -0,0 +1,271 @@
+package usecase_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/internal/mocks"
+	"github.com/herdiagusthio/password-manager/internal/usecase"
+	"github.com/herdiagusthio/password-manager/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	authKey     = base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	otherKey    = base64.StdEncoding.EncodeToString([]byte("fedcba9876543210fedcba9876543210"))
+	wrappedKey  = crypto.ClientBlobPrefix + base64.StdEncoding.EncodeToString([]byte("nonce-bytes-wrapped-vault-key-and-tag"))
+	validParams = domain.KDFParams{
+		Algorithm:   "argon2id",
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 4,
+		Salt:        base64.StdEncoding.EncodeToString([]byte("sixteen byte salt")),
+	}
+)
+
+func verifierOf(key string) string {
+	raw, _ := base64.StdEncoding.DecodeString(key)
+	sum := sha256.Sum256(raw)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func TestVaultUsecase_EnableZeroKnowledge(t *testing.T) {
+	weak := validParams
+	weak.Memory = 1024
+	wrongAlg := validParams
+	wrongAlg.Algorithm = "pbkdf2"
+	shortSalt := validParams
+	shortSalt.Salt = base64.StdEncoding.EncodeToString([]byte("salt"))
+
+	tests := []struct {
+		name          string
+		enabled       bool
+		setup         domain.VaultSetup
+		expectedError bool
+	}{
+		{name: "Success", setup: domain.VaultSetup{KDF: validParams, AuthKey: authKey, KeyID: "vk-1", WrappedKey: wrappedKey}},
+		{name: "Already Enabled", enabled: true, setup: domain.VaultSetup{KDF: validParams, AuthKey: authKey, KeyID: "vk-1", WrappedKey: wrappedKey}, expectedError: true},
+		{name: "Weak KDF", setup: domain.VaultSetup{KDF: weak, AuthKey: authKey, KeyID: "vk-1", WrappedKey: wrappedKey}, expectedError: true},
+		{name: "Wrong Algorithm", setup: domain.VaultSetup{KDF: wrongAlg, AuthKey: authKey, KeyID: "vk-1", WrappedKey: wrappedKey}, expectedError: true},
+		{name: "Short Salt", setup: domain.VaultSetup{KDF: shortSalt, AuthKey: authKey, KeyID: "vk-1", WrappedKey: wrappedKey}, expectedError: true},
+		{name: "Short Auth Key", setup: domain.VaultSetup{KDF: validParams, AuthKey: "c2hvcnQ=", KeyID: "vk-1", WrappedKey: wrappedKey}, expectedError: true},
+		{name: "Plaintext Vault Key", setup: domain.VaultSetup{KDF: validParams, AuthKey: authKey, KeyID: "vk-1", WrappedKey: "raw key"}, expectedError: true},
+		{name: "Missing Key ID", setup: domain.VaultSetup{KDF: validParams, AuthKey: authKey, WrappedKey: wrappedKey}, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockVaultRepository(ctrl)
+			repo.EXPECT().GetByUserID(gomock.Any(), "user-1").Return(&domain.Vault{UserID: "user-1", ZeroKnowledge: tt.enabled}, nil)
+			if !tt.expectedError {
+				repo.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, v *domain.Vault) error {
+					assert.Equal(t, verifierOf(authKey), v.Verifier) // Only a hash of the auth key is stored
+					assert.Equal(t, "vk-1", v.KeyID)
+					assert.Equal(t, wrappedKey, v.WrappedKey)
+					return nil
+				})
+			}
+
+			uc := usecase.NewVaultUsecase(repo, newSettingsRepo(ctrl))
+			_, err := uc.EnableZeroKnowledge(context.Background(), "user-1", &tt.setup)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestVaultUsecase_ChangeMasterPassword(t *testing.T) {
+	rewrapped := crypto.ClientBlobPrefix + base64.StdEncoding.EncodeToString([]byte("nonce-bytes-rewrapped-vault-key-tag"))
+
+	tests := []struct {
+		name          string
+		currentKey    string
+		keyID         string
+		expectedError error
+	}{
+		{name: "Success", currentKey: authKey},
+		{name: "Same Key ID", currentKey: authKey, keyID: "vk-1"},
+		{name: "Wrong Master Password", currentKey: otherKey, expectedError: domain.ErrInvalidMasterPassword},
+		{name: "New Vault Key", currentKey: authKey, keyID: "vk-2", expectedError: assert.AnError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockVaultRepository(ctrl)
+			repo.EXPECT().GetByUserID(gomock.Any(), "user-1").Return(&domain.Vault{
+				UserID:        "user-1",
+				ZeroKnowledge: true,
+				KeyID:         "vk-1",
+				WrappedKey:    wrappedKey,
+				Verifier:      verifierOf(authKey),
+			}, nil)
+			if tt.expectedError == nil {
+				repo.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, v *domain.Vault) error {
+					assert.Equal(t, verifierOf(otherKey), v.Verifier)
+					assert.Equal(t, "vk-1", v.KeyID)
+					assert.Equal(t, rewrapped, v.WrappedKey)
+					return nil
+				})
+			}
+
+			uc := usecase.NewVaultUsecase(repo, newSettingsRepo(ctrl))
+			_, err := uc.ChangeMasterPassword(context.Background(), "user-1", tt.currentKey, &domain.VaultSetup{
+				KDF: validParams, AuthKey: otherKey, KeyID: tt.keyID, WrappedKey: rewrapped,
+			})
+
+			switch {
+			case tt.expectedError == nil:
+				require.NoError(t, err)
+			case tt.expectedError == assert.AnError:
+				assert.Error(t, err)
+			default:
+				assert.ErrorIs(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestVaultUsecase_VerifyMasterPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mocks.NewMockVaultRepository(ctrl)
+	repo.EXPECT().GetByUserID(gomock.Any(), "user-1").Return(&domain.Vault{
+		UserID:        "user-1",
+		ZeroKnowledge: true,
+		Verifier:      verifierOf(authKey),
+	}, nil).Times(3)
+
+	uc := usecase.NewVaultUsecase(repo, newSettingsRepo(ctrl))
+	assert.NoError(t, uc.VerifyMasterPassword(context.Background(), "user-1", authKey))
+	assert.ErrorIs(t, uc.VerifyMasterPassword(context.Background(), "user-1", otherKey), domain.ErrInvalidMasterPassword)
+	assert.ErrorIs(t, uc.VerifyMasterPassword(context.Background(), "user-1", "not base64"), domain.ErrInvalidMasterPassword)
+}
+
+func TestVaultUsecase_GetLockPolicy(t *testing.T) {
+	tests := []struct {
+		name            string
+		vault           domain.Vault
+		autoLockMinutes int
+		expectedMethods []string
+	}{
+		{name: "Nothing To Unlock With", vault: domain.Vault{}, autoLockMinutes: 15, expectedMethods: []string{}},
+		{name: "PIN", vault: domain.Vault{HasPIN: true}, autoLockMinutes: 5, expectedMethods: []string{domain.UnlockMethodPIN}},
+		{name: "Master Password And PIN", vault: domain.Vault{ZeroKnowledge: true, HasPIN: true}, expectedMethods: []string{domain.UnlockMethodMasterPassword, domain.UnlockMethodPIN}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockVaultRepository(ctrl)
+			repo.EXPECT().GetByUserID(gomock.Any(), "user-1").Return(&tt.vault, nil)
+			settingsRepo := mocks.NewMockSettingsRepository(ctrl)
+			settingsRepo.EXPECT().Get(gomock.Any(), "user-1").Return(&domain.UserSettings{UserID: "user-1", AutoLockMinutes: tt.autoLockMinutes}, nil)
+
+			uc := usecase.NewVaultUsecase(repo, settingsRepo)
+			policy, err := uc.GetLockPolicy(context.Background(), "user-1")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedMethods, policy.Methods)
+			assert.Equal(t, len(tt.expectedMethods) > 0, policy.Lockable())
+			assert.Equal(t, time.Duration(tt.autoLockMinutes)*time.Minute, policy.AutoLock)
+		})
+	}
+}
+
+func TestVaultUsecase_Unlock(t *testing.T) {
+	pinHash, err := crypto.HashPassword("135790")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		vault         domain.Vault
+		req           domain.UnlockRequest
+		expectedError error
+	}{
+		{name: "PIN", vault: domain.Vault{HasPIN: true, PINHash: pinHash}, req: domain.UnlockRequest{PIN: "135790"}},
+		{name: "Wrong PIN", vault: domain.Vault{HasPIN: true, PINHash: pinHash}, req: domain.UnlockRequest{PIN: "000000"}, expectedError: domain.ErrInvalidPIN},
+		{name: "No PIN Set", vault: domain.Vault{}, req: domain.UnlockRequest{PIN: "135790"}, expectedError: assert.AnError},
+		{name: "Master Password", vault: domain.Vault{ZeroKnowledge: true, Verifier: verifierOf(authKey)}, req: domain.UnlockRequest{AuthKey: authKey}},
+		{name: "Wrong Master Password", vault: domain.Vault{ZeroKnowledge: true, Verifier: verifierOf(authKey)}, req: domain.UnlockRequest{AuthKey: otherKey}, expectedError: domain.ErrInvalidMasterPassword},
+		{name: "No Credentials", vault: domain.Vault{HasPIN: true, PINHash: pinHash}, expectedError: assert.AnError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockVaultRepository(ctrl)
+			repo.EXPECT().GetByUserID(gomock.Any(), "user-1").Return(&tt.vault, nil)
+
+			uc := usecase.NewVaultUsecase(repo, newSettingsRepo(ctrl))
+			err := uc.Unlock(context.Background(), "user-1", &tt.req)
+
+			switch {
+			case tt.expectedError == nil:
+				assert.NoError(t, err)
+			case tt.expectedError == assert.AnError:
+				assert.Error(t, err)
+			default:
+				assert.ErrorIs(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestVaultUsecase_SetPIN(t *testing.T) {
+	tests := []struct {
+		name          string
+		pin           string
+		expectedError bool
+	}{
+		{name: "Success", pin: "135790"},
+		{name: "Too Short", pin: "1234", expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockVaultRepository(ctrl)
+			if !tt.expectedError {
+				repo.EXPECT().GetByUserID(gomock.Any(), "user-1").Return(&domain.Vault{UserID: "user-1"}, nil)
+				repo.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, v *domain.Vault) error {
+					assert.NotContains(t, v.PINHash, tt.pin)
+					ok, err := crypto.VerifyPassword(tt.pin, v.PINHash)
+					require.NoError(t, err)
+					assert.True(t, ok)
+					return nil
+				})
+			}
+
+			uc := usecase.NewVaultUsecase(repo, newSettingsRepo(ctrl))
+			err := uc.SetPIN(context.Background(), "user-1", tt.pin)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+-- Per-user data encryption keys, wrapped by the master key (envelope encryption).
+CREATE TABLE IF NOT EXISTS user_keys (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    wrapped_key TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
@@ -0,0 +1,11 @@
+-- Username, notes and other sensitive metadata are sealed into one encrypted blob.
+-- The plaintext username/metadata columns keep only the fields each user leaves
+-- unencrypted for listing and searching.
+ALTER TABLE secrets ADD COLUMN IF NOT EXISTS encrypted_payload TEXT;
+
+CREATE TABLE IF NOT EXISTS user_settings (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    plaintext_fields TEXT[] NOT NULL DEFAULT '{username,url}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
@@ -0,0 +1,11 @@
+-- Zero-knowledge mode: the browser derives keys from a master password with
+-- Argon2id. The server keeps only the KDF parameters, a verifier and the vault
+-- key wrapped by the browser.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS kdf_params JSONB;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS master_verifier TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS vault_key_id TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS wrapped_vault_key TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS vault_updated_at TIMESTAMP WITH TIME ZONE;
+
+-- Passwords encrypted in the browser; the server cannot read them.
+ALTER TABLE secrets ADD COLUMN IF NOT EXISTS client_encrypted BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,4 @@
+-- Vault unlock after login: an optional PIN (Argon2id hash) and an idle
+-- timeout after which the vault locks again.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS unlock_pin_hash TEXT;
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS auto_lock_minutes INT NOT NULL DEFAULT 15;
@@ -0,0 +1,3 @@
+-- Strength score (0-4) computed when a password is saved, so weak entries can
+-- be flagged without decrypting anything. NULL when unknown.
+ALTER TABLE secrets ADD COLUMN IF NOT EXISTS strength_score SMALLINT CHECK (strength_score BETWEEN 0 AND 4);
@@ -0,0 +1,19 @@
+-- Encrypted snapshots of secrets as they were before a change, e.g. the
+-- previous password after a rotation.
+CREATE TABLE IF NOT EXISTS secret_versions (
+    secret_id UUID NOT NULL REFERENCES secrets(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    username VARCHAR(255) NOT NULL,
+    encrypted_password TEXT NOT NULL,
+    encrypted_payload TEXT,
+    client_encrypted BOOLEAN NOT NULL DEFAULT FALSE,
+    strength_score SMALLINT,
+    metadata JSONB,
+    updated_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (secret_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_secret_versions_user_id ON secret_versions(user_id);
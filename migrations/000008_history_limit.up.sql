@@ -0,0 +1,2 @@
+-- How many previous versions of each secret a user keeps.
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS history_limit INT NOT NULL DEFAULT 20;
@@ -0,0 +1,4 @@
+-- Secrets used to be created with version 0; versions now start at 1 and
+-- updates must name the version they were based on.
+UPDATE secrets SET version = 1 WHERE version IS NULL OR version < 1;
+ALTER TABLE secrets ALTER COLUMN version SET NOT NULL;
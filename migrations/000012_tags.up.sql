@@ -0,0 +1,18 @@
+-- Tags are per-user labels shared by many secrets; names are stored lowercase.
+CREATE TABLE IF NOT EXISTS tags (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS secret_tags (
+    secret_id UUID NOT NULL REFERENCES secrets(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (secret_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_secret_tags_tag_id ON secret_tags(tag_id);
+
+ALTER TABLE secrets ADD COLUMN IF NOT EXISTS favorite BOOLEAN NOT NULL DEFAULT FALSE;
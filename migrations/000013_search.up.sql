@@ -0,0 +1,16 @@
+-- Secret search matches substrings of the plaintext fields, backed by a
+-- trigram index on the same expression the repository queries.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_secrets_search ON secrets USING GIN (
+    (title || ' ' || username || ' ' || COALESCE(metadata->>'url', '') || ' ' || COALESCE(metadata->>'notes', '')) gin_trgm_ops
+);
+
+-- Set whenever a password is revealed, for sorting by last use.
+ALTER TABLE secrets ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMP WITH TIME ZONE;
+
+-- Keyset pagination for each sort order.
+CREATE INDEX IF NOT EXISTS idx_secrets_user_created ON secrets(user_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_secrets_user_title ON secrets(user_id, lower(title), id);
+CREATE INDEX IF NOT EXISTS idx_secrets_user_updated ON secrets(user_id, updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_secrets_user_last_used ON secrets(user_id, COALESCE(last_used_at, 'epoch'), id);
@@ -0,0 +1,5 @@
+-- Secrets have a type (login, card, note, ssh_key, api_key, identity) whose
+-- schema decides which metadata fields they take. Existing secrets are logins.
+ALTER TABLE secrets ADD COLUMN IF NOT EXISTS type VARCHAR(20) NOT NULL DEFAULT 'login';
+
+CREATE INDEX IF NOT EXISTS idx_secrets_user_type ON secrets(user_id, type);
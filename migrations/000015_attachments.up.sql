@@ -0,0 +1,19 @@
+-- Files attached to secrets. Their contents are encrypted into a blob store;
+-- only metadata and the wrapped file key are kept here. Purging a secret
+-- detaches its files (secret_id becomes NULL) so that a background job can
+-- delete the blobs before the rows.
+CREATE TABLE IF NOT EXISTS attachments (
+    id UUID PRIMARY KEY,
+    secret_id UUID REFERENCES secrets(id) ON DELETE SET NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    wrapped_key TEXT NOT NULL,
+    client_encrypted BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_secret_id ON attachments(secret_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_user_id ON attachments(user_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_orphans ON attachments(created_at) WHERE secret_id IS NULL;
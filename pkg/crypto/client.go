@@ -0,0 +1,25 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// ClientBlobPrefix marks values encrypted in the browser in zero-knowledge
+// mode, using the Web Crypto API with a key the server never sees:
+//
+//	"zk1:" base64(nonce (12) | AES-256-GCM ciphertext and tag)
+//
+// The server cannot decrypt these; it only checks that they are well formed.
+const ClientBlobPrefix = "zk1:"
+
+const clientBlobOverhead = 12 + 16 // GCM nonce and tag
+
+// IsClientBlob reports whether s looks like a value encrypted in the browser.
+func IsClientBlob(s string) bool {
+	if !strings.HasPrefix(s, ClientBlobPrefix) {
+		return false
+	}
+	data, err := base64.StdEncoding.DecodeString(s[len(ClientBlobPrefix):])
+	return err == nil && len(data) >= clientBlobOverhead
+}
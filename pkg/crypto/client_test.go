@@ -0,0 +1,31 @@
+package crypto_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/herdiagusthio/password-manager/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsClientBlob(t *testing.T) {
+	sealed := base64.StdEncoding.EncodeToString(make([]byte, 12+16+8))
+
+	tests := []struct {
+		name     string
+		value    string
+		expected bool
+	}{
+		{name: "Valid", value: crypto.ClientBlobPrefix + sealed, expected: true},
+		{name: "Plaintext", value: "hunter2", expected: false},
+		{name: "Missing Prefix", value: sealed, expected: false},
+		{name: "Not Base64", value: crypto.ClientBlobPrefix + "not base64!", expected: false},
+		{name: "Too Short", value: crypto.ClientBlobPrefix + base64.StdEncoding.EncodeToString([]byte("short")), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, crypto.IsClientBlob(tt.value))
+		})
+	}
+}
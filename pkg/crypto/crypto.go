@@ -6,69 +6,160 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"errors"
+	"fmt"
 	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
 )
 
+// DataKeySize is the size in bytes of keys produced by GenerateDataKey (AES-256).
+const DataKeySize = 32
+
 // Encrypt encrypts plainText using AES-GCM with the provided key.
 // The key must be 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256.
+// The output has no version header; prefer EncryptWithKey or a Keyring for
+// anything that must survive a key rotation.
 func Encrypt(plainText, key string) (string, error) {
-	if len(key) != 16 && len(key) != 24 && len(key) != 32 {
-		return "", errors.New("crypto: invalid key length (must be 16, 24, or 32 bytes)")
+	cipherText, err := sealAEAD([]byte(key), []byte(plainText), nil)
+	if err != nil {
+		return "", err
 	}
+	return base64.StdEncoding.EncodeToString(cipherText), nil
+}
 
-	block, err := aes.NewCipher([]byte(key))
-	if err != nil {
+// Decrypt decrypts cipherText (base64 encoded) using AES-GCM with the provided key.
+func Decrypt(cipherText, key string) (string, error) {
+	if err := checkKey([]byte(key)); err != nil {
 		return "", err
 	}
 
-	gcm, err := cipher.NewGCM(block)
+	data, err := base64.StdEncoding.DecodeString(cipherText)
 	if err != nil {
 		return "", err
 	}
 
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+	plainText, err := openAEAD([]byte(key), data, nil)
+	if err != nil {
 		return "", err
 	}
 
-	// Seal encrypts and authenticates plainText, appending the result to nonce.
-	// The nonce is prepended to the ciphertext to be used for decryption.
-	cipherText := gcm.Seal(nonce, nonce, []byte(plainText), nil)
+	return string(plainText), nil
+}
 
-	return base64.StdEncoding.EncodeToString(cipherText), nil
+// GenerateDataKey returns a new random key for encrypting a single user's vault.
+func GenerateDataKey() ([]byte, error) {
+	key := make([]byte, DataKeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	return key, nil
 }
 
-// Decrypt decrypts cipherText (base64 encoded) using AES-GCM with the provided key.
-func Decrypt(cipherText, key string) (string, error) {
-	if len(key) != 16 && len(key) != 24 && len(key) != 32 {
-		return "", errors.New("crypto: invalid key length (must be 16, 24, or 32 bytes)")
+// WrapKey encrypts dataKey with the key-encryption key kek so it can be stored at rest.
+// The result is base64 encoded, like the output of Encrypt.
+func WrapKey(dataKey []byte, kek string) (string, error) {
+	if err := checkKey(dataKey); err != nil {
+		return "", errors.New("crypto: invalid data key length (must be 16, 24, or 32 bytes)")
 	}
 
-	data, err := base64.StdEncoding.DecodeString(cipherText)
+	wrapped, err := sealAEAD([]byte(kek), dataKey, nil)
 	if err != nil {
 		return "", err
 	}
+	return base64.StdEncoding.EncodeToString(wrapped), nil
+}
+
+// UnwrapKey reverses WrapKey, returning the plain data key.
+func UnwrapKey(wrapped string, kek string) ([]byte, error) {
+	if err := checkKey([]byte(kek)); err != nil {
+		return nil, err
+	}
 
-	block, err := aes.NewCipher([]byte(key))
+	data, err := base64.StdEncoding.DecodeString(wrapped)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	gcm, err := cipher.NewGCM(block)
+	dataKey, err := openAEAD([]byte(kek), data, nil)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if err := checkKey(dataKey); err != nil {
+		return nil, errors.New("crypto: unwrapped data key has invalid length")
 	}
+	return dataKey, nil
+}
 
-	nonceSize := gcm.NonceSize()
-	if len(data) < nonceSize {
-		return "", errors.New("crypto: ciphertext too short")
+func checkKey(key []byte) error {
+	if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+		return errors.New("crypto: invalid key length (must be 16, 24, or 32 bytes)")
 	}
+	return nil
+}
 
-	nonce, ciphertext := data[:nonceSize], data[nonceSize:]
-	plainText, err := gcm.Open(nil, nonce, ciphertext, nil)
+// newAEAD returns the cipher for alg. AES-GCM accepts 16, 24 or 32 byte keys;
+// XChaCha20-Poly1305 needs 32.
+func newAEAD(alg Algorithm, key []byte) (cipher.AEAD, error) {
+	switch alg {
+	case AlgAES256GCM:
+		if err := checkKey(key); err != nil {
+			return nil, err
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case AlgXChaCha20Poly1305:
+		if len(key) != chacha20poly1305.KeySize {
+			return nil, errors.New("crypto: invalid key length for xchacha20-poly1305 (must be 32 bytes)")
+		}
+		return chacha20poly1305.NewX(key)
+	default:
+		return nil, fmt.Errorf("crypto: unsupported algorithm %s", alg)
+	}
+}
+
+// sealWith encrypts plainText with alg and returns nonce || ciphertext.
+// additionalData is authenticated but not encrypted.
+func sealWith(alg Algorithm, key, plainText, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(alg, key)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return string(plainText), nil
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	// Seal encrypts and authenticates plainText, appending the result to nonce.
+	// The nonce is prepended to the ciphertext to be used for decryption.
+	return aead.Seal(nonce, nonce, plainText, additionalData), nil
+}
+
+// openWith reverses sealWith.
+func openWith(alg Algorithm, key, data, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(alg, key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errors.New("crypto: ciphertext too short")
+	}
+
+	nonce, ciphertext := data[:nonceSize], data[nonceSize:]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
+
+// sealAEAD encrypts plainText with AES-GCM, the cipher of the legacy formats.
+func sealAEAD(key, plainText, additionalData []byte) ([]byte, error) {
+	return sealWith(AlgAES256GCM, key, plainText, additionalData)
+}
+
+// openAEAD reverses sealAEAD.
+func openAEAD(key, data, additionalData []byte) ([]byte, error) {
+	return openWith(AlgAES256GCM, key, data, additionalData)
 }
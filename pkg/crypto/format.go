@@ -0,0 +1,221 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// Versioned ciphertexts start with a small header that names the key and
+// algorithm used, so data written under an old key stays readable after a
+// rotation:
+//
+//	magic (1) | version (1) | algorithm (1) | key ID length (1) | key ID | nonce | ciphertext
+//
+// The header is authenticated as additional data, so it cannot be altered
+// without failing decryption. From FormatV2 on, the caller's associated data
+// (e.g. the ID of the row holding the ciphertext) is authenticated too, which
+// binds the ciphertext to that context.
+const (
+	headerMagic byte = 0xA7
+
+	// FormatV1 ciphertexts authenticate only their header.
+	FormatV1 byte = 1
+	// FormatV2 ciphertexts also authenticate the caller's associated data.
+	FormatV2 byte = 2
+
+	// DataKeyID is the key ID recorded for data sealed with a user's data key.
+	DataKeyID = "dek"
+)
+
+// Algorithm identifies the AEAD cipher used for a ciphertext.
+type Algorithm byte
+
+const (
+	// AlgAES256GCM uses a random 96-bit nonce, which limits how many messages
+	// one key can safely encrypt.
+	AlgAES256GCM Algorithm = 1
+	// AlgXChaCha20Poly1305 uses a random 192-bit nonce, so collisions are not
+	// a concern no matter how much data a key encrypts.
+	AlgXChaCha20Poly1305 Algorithm = 2
+	// AlgProvider marks data wrapped by a KeyProvider that does not release
+	// its keys; the body is opaque and only the provider can unwrap it.
+	AlgProvider Algorithm = 3
+
+	// DefaultAlgorithm is used for new writes unless configured otherwise.
+	DefaultAlgorithm = AlgXChaCha20Poly1305
+)
+
+func (a Algorithm) String() string {
+	switch a {
+	case AlgAES256GCM:
+		return "aes-256-gcm"
+	case AlgXChaCha20Poly1305:
+		return "xchacha20-poly1305"
+	case AlgProvider:
+		return "provider"
+	default:
+		return fmt.Sprintf("unknown(%d)", byte(a))
+	}
+}
+
+// ParseAlgorithm returns the cipher named name, as printed by String.
+func ParseAlgorithm(name string) (Algorithm, error) {
+	for _, alg := range []Algorithm{AlgAES256GCM, AlgXChaCha20Poly1305} {
+		if name == alg.String() {
+			return alg, nil
+		}
+	}
+	return 0, fmt.Errorf("crypto: unknown algorithm %q (want aes-256-gcm or xchacha20-poly1305)", name)
+}
+
+var (
+	ErrUnknownKeyID = errors.New("crypto: unknown key id")
+	// ErrUnbound is returned in strict mode for ciphertexts that were written
+	// without associated data and could have been copied from another context.
+	ErrUnbound  = errors.New("crypto: ciphertext is not bound to its context")
+	errNoHeader = errors.New("crypto: ciphertext has no version header")
+)
+
+// Header describes a versioned ciphertext.
+type Header struct {
+	Version   byte
+	Algorithm Algorithm
+	KeyID     string
+}
+
+// Bound reports whether the ciphertext authenticates associated data.
+func (h Header) Bound() bool {
+	return h.Version >= FormatV2
+}
+
+func (h Header) marshal() []byte {
+	b := make([]byte, 0, 4+len(h.KeyID))
+	b = append(b, headerMagic, h.Version, byte(h.Algorithm), byte(len(h.KeyID)))
+	return append(b, h.KeyID...)
+}
+
+// parseHeader splits data into its header and the remaining nonce || ciphertext.
+func parseHeader(data []byte) (Header, []byte, error) {
+	if len(data) < 4 || data[0] != headerMagic {
+		return Header{}, nil, errNoHeader
+	}
+	h := Header{Version: data[1], Algorithm: Algorithm(data[2])}
+	if h.Version != FormatV1 && h.Version != FormatV2 {
+		return Header{}, nil, fmt.Errorf("crypto: unsupported format version %d", h.Version)
+	}
+	idLen := int(data[3])
+	if len(data) < 4+idLen {
+		return Header{}, nil, errors.New("crypto: truncated header")
+	}
+	h.KeyID = string(data[4 : 4+idLen])
+	return h, data[4+idLen:], nil
+}
+
+// ParseHeader returns the header of a base64 encoded ciphertext. ok is false
+// for legacy ciphertexts produced by Encrypt, which carry no header.
+func ParseHeader(cipherText string) (h Header, ok bool) {
+	data, err := base64.StdEncoding.DecodeString(cipherText)
+	if err != nil {
+		return Header{}, false
+	}
+	h, _, err = parseHeader(data)
+	return h, err == nil
+}
+
+// IsBound reports whether cipherText authenticates associated data.
+func IsBound(cipherText string) bool {
+	h, ok := ParseHeader(cipherText)
+	return ok && h.Bound()
+}
+
+func additionalData(header, associatedData []byte) []byte {
+	aad := make([]byte, 0, len(header)+len(associatedData))
+	aad = append(aad, header...)
+	return append(aad, associatedData...)
+}
+
+// EncryptWithKey encrypts plainText with key using DefaultAlgorithm and tags
+// the result with keyID. associatedData is not stored; the same value must be
+// passed to decrypt.
+func EncryptWithKey(plainText []byte, keyID string, key []byte, associatedData []byte) (string, error) {
+	return encryptWithKey(DefaultAlgorithm, plainText, keyID, key, associatedData)
+}
+
+func encryptWithKey(alg Algorithm, plainText []byte, keyID string, key []byte, associatedData []byte) (string, error) {
+	if len(keyID) == 0 || len(keyID) > 255 {
+		return "", errors.New("crypto: key id must be 1-255 bytes")
+	}
+
+	h := Header{Version: FormatV2, Algorithm: alg, KeyID: keyID}
+	header := h.marshal()
+	sealed, err := sealWith(alg, key, plainText, additionalData(header, associatedData))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(append(header, sealed...)), nil
+}
+
+// DecryptWithKeys decrypts a ciphertext produced by EncryptWithKey, using lookup
+// to find the key named in its header. Legacy ciphertexts without a header are
+// tried against each of legacyKeys in turn. Ciphertexts written before
+// associated data was supported are accepted; callers that require binding
+// should check IsBound.
+func DecryptWithKeys(cipherText string, associatedData []byte, lookup KeyLookup, legacyKeys ...[]byte) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(cipherText)
+	if err != nil {
+		return nil, err
+	}
+
+	h, body, err := parseHeader(data)
+	if err == nil {
+		header := data[:len(data)-len(body)]
+		aad := header
+		if h.Bound() {
+			aad = additionalData(header, associatedData)
+		}
+		plainText, versionedErr := openVersioned(h, body, aad, lookup)
+		if versionedErr == nil {
+			return plainText, nil
+		}
+		// A legacy nonce can start with the magic byte by chance, so fall
+		// through to the legacy keys before giving up.
+		if plainText, legacyErr := openLegacy(data, legacyKeys); legacyErr == nil {
+			return plainText, nil
+		}
+		return nil, versionedErr
+	}
+
+	return openLegacy(data, legacyKeys)
+}
+
+// KeyLookup returns the key registered under keyID.
+type KeyLookup func(keyID string) ([]byte, error)
+
+func openVersioned(h Header, body, aad []byte, lookup KeyLookup) ([]byte, error) {
+	if h.Algorithm != AlgAES256GCM && h.Algorithm != AlgXChaCha20Poly1305 {
+		return nil, fmt.Errorf("crypto: unsupported algorithm %s", h.Algorithm)
+	}
+	key, err := lookup(h.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	return openWith(h.Algorithm, key, body, aad)
+}
+
+func openLegacy(data []byte, keys [][]byte) ([]byte, error) {
+	if len(keys) == 0 {
+		return nil, errNoHeader
+	}
+	var err error
+	for _, key := range keys {
+		if err = checkKey(key); err != nil {
+			continue
+		}
+		var plainText []byte
+		if plainText, err = openAEAD(key, data, nil); err == nil {
+			return plainText, nil
+		}
+	}
+	return nil, err
+}
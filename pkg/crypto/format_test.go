@@ -0,0 +1,73 @@
+package crypto
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encryptV1 produces a ciphertext in the FormatV1 layout, which authenticates
+// only its header and predates associated data.
+func encryptV1(t *testing.T, plainText []byte, keyID string, key []byte) string {
+	t.Helper()
+	header := Header{Version: FormatV1, Algorithm: AlgAES256GCM, KeyID: keyID}.marshal()
+	sealed, err := sealAEAD(key, plainText, header)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(append(header, sealed...))
+}
+
+func TestAssociatedData(t *testing.T) {
+	key := []byte("12345678901234567890123456789012")
+	provider, err := NewStaticProvider("1", map[string][]byte{"1": key})
+	require.NoError(t, err)
+	keyring := NewKeyring(provider)
+
+	bound, err := keyring.Encrypt(context.Background(), []byte("secret"), []byte("row-1"))
+	require.NoError(t, err)
+	v1 := encryptV1(t, []byte("old row"), "1", key)
+	legacy, err := Encrypt("older row", string(key))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		cipherText     string
+		associatedData string
+		strict         bool
+		expected       string
+		expectError    bool
+		errorIs        error
+	}{
+		{name: "Matching Context", cipherText: bound, associatedData: "row-1", expected: "secret"},
+		{name: "Other Context", cipherText: bound, associatedData: "row-2", expectError: true},
+		{name: "V1 Ignores Context", cipherText: v1, associatedData: "row-2", expected: "old row"},
+		{name: "Legacy Ignores Context", cipherText: legacy, associatedData: "row-2", expected: "older row"},
+		{name: "Strict Accepts Bound", cipherText: bound, associatedData: "row-1", strict: true, expected: "secret"},
+		{name: "Strict Rejects V1", cipherText: v1, associatedData: "row-1", strict: true, expectError: true, errorIs: ErrUnbound},
+		{name: "Strict Rejects Legacy", cipherText: legacy, associatedData: "row-1", strict: true, expectError: true, errorIs: ErrUnbound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyring.RequireBinding(tt.strict)
+			defer keyring.RequireBinding(false)
+
+			plain, err := keyring.Decrypt(context.Background(), tt.cipherText, []byte(tt.associatedData))
+			if tt.expectError {
+				assert.Error(t, err)
+				if tt.errorIs != nil {
+					assert.ErrorIs(t, err, tt.errorIs)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(plain))
+		})
+	}
+
+	assert.True(t, keyring.IsCurrent(bound))
+	assert.False(t, keyring.IsCurrent(v1))
+	assert.False(t, IsBound(legacy))
+}
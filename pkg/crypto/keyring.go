@@ -0,0 +1,242 @@
+package crypto
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"sync"
+)
+
+// Keyring encrypts data under the master keys of a KeyProvider, in the
+// versioned ciphertext format. New data is always encrypted with the
+// provider's current key; older keys are only needed so that data written
+// before a rotation can still be decrypted.
+type Keyring struct {
+	provider KeyProvider
+	local    bool // provider holds its keys in memory
+
+	mu           sync.RWMutex
+	alg          Algorithm
+	requireBound bool
+}
+
+// NewKeyring returns a keyring backed by provider, configured with the
+// options attached by WithOptions, if any.
+func NewKeyring(provider KeyProvider) *Keyring {
+	var opts KeyringOptions
+	if c, ok := provider.(configuredProvider); ok {
+		provider, opts = c.KeyProvider, c.opts
+	}
+	_, local := provider.(localProvider)
+
+	k := &Keyring{provider: provider, local: local, alg: DefaultAlgorithm, requireBound: opts.RequireBinding}
+	if opts.Algorithm != 0 {
+		k.alg = opts.Algorithm
+	}
+	return k
+}
+
+// RequireBinding makes decryption reject ciphertexts that were written without
+// associated data. Enable it once every stored ciphertext has been re-encrypted.
+func (k *Keyring) RequireBinding(require bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.requireBound = require
+}
+
+// SetAlgorithm selects the cipher for new writes. Existing data stays
+// readable whatever it was written with.
+func (k *Keyring) SetAlgorithm(alg Algorithm) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.alg = alg
+}
+
+// Algorithm returns the cipher used for new writes with keys held locally.
+func (k *Keyring) Algorithm() Algorithm {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.alg
+}
+
+// CurrentID returns the ID of the key used for new encryptions, or "" while sealed.
+func (k *Keyring) CurrentID() string {
+	return k.provider.CurrentKeyID()
+}
+
+func (k *Keyring) legacyKeys() [][]byte {
+	if p, ok := k.provider.(localProvider); ok {
+		return p.legacyKeys()
+	}
+	return nil
+}
+
+// masterAlgorithm is the algorithm recorded for new data under master keys.
+func (k *Keyring) masterAlgorithm() Algorithm {
+	if !k.local {
+		return AlgProvider
+	}
+	return k.Algorithm()
+}
+
+// Encrypt encrypts plainText with the current key, bound to associatedData.
+func (k *Keyring) Encrypt(ctx context.Context, plainText, associatedData []byte) (string, error) {
+	keyID := k.provider.CurrentKeyID()
+	if keyID == "" {
+		return "", ErrSealed
+	}
+
+	alg := k.masterAlgorithm()
+	header := Header{Version: FormatV2, Algorithm: alg, KeyID: keyID}.marshal()
+	aad := additionalData(header, associatedData)
+
+	var sealed []byte
+	if k.local {
+		key, err := k.provider.GetKey(ctx, keyID)
+		if err != nil {
+			return "", err
+		}
+		sealed, err = sealWith(alg, key, plainText, aad)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		var err error
+		sealed, err = k.provider.Wrap(ctx, keyID, plainText, aad)
+		if err != nil {
+			return "", err
+		}
+	}
+	return base64.StdEncoding.EncodeToString(append(header, sealed...)), nil
+}
+
+// EncryptWith encrypts plainText with an extra key that is not held by the
+// provider, such as a user's data key registered under DataKeyID.
+func (k *Keyring) EncryptWith(plainText, associatedData []byte, keyID string, key []byte) (string, error) {
+	return encryptWithKey(k.Algorithm(), plainText, keyID, key, associatedData)
+}
+
+// Decrypt decrypts data written with any key of the provider, including
+// legacy ciphertexts produced by Encrypt before version headers existed.
+func (k *Keyring) Decrypt(ctx context.Context, cipherText string, associatedData []byte) ([]byte, error) {
+	return k.decrypt(ctx, cipherText, associatedData, "", nil)
+}
+
+// DecryptWith decrypts data sealed with an extra key that is not held by the
+// provider, such as a user's data key registered under DataKeyID. Legacy
+// ciphertexts are tried against the extra key first, then the master keys.
+func (k *Keyring) DecryptWith(ctx context.Context, cipherText string, associatedData []byte, keyID string, key []byte) ([]byte, error) {
+	return k.decrypt(ctx, cipherText, associatedData, keyID, key)
+}
+
+func (k *Keyring) decrypt(ctx context.Context, cipherText string, associatedData []byte, extraID string, extraKey []byte) ([]byte, error) {
+	if err := k.checkBound(cipherText); err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(cipherText)
+	if err != nil {
+		return nil, err
+	}
+
+	legacyKeys := k.legacyKeys()
+	if extraKey != nil {
+		legacyKeys = append([][]byte{extraKey}, legacyKeys...)
+	}
+
+	h, body, err := parseHeader(data)
+	if err != nil {
+		return openLegacy(data, legacyKeys)
+	}
+
+	header := data[:len(data)-len(body)]
+	aad := header
+	if h.Bound() {
+		aad = additionalData(header, associatedData)
+	}
+
+	var plainText []byte
+	var versionedErr error
+	if extraKey != nil && h.KeyID == extraID {
+		plainText, versionedErr = openVersioned(h, body, aad, func(string) ([]byte, error) { return extraKey, nil })
+	} else {
+		plainText, versionedErr = k.unwrap(ctx, h, body, aad)
+	}
+	if versionedErr == nil {
+		return plainText, nil
+	}
+	// A legacy nonce can start with the magic byte by chance, so fall
+	// through to the legacy keys before giving up.
+	if plainText, legacyErr := openLegacy(data, legacyKeys); legacyErr == nil {
+		return plainText, nil
+	}
+	return nil, versionedErr
+}
+
+func (k *Keyring) unwrap(ctx context.Context, h Header, body, aad []byte) ([]byte, error) {
+	if h.Algorithm == AlgProvider {
+		return k.provider.Unwrap(ctx, h.KeyID, body, aad)
+	}
+	if k.local {
+		return openVersioned(h, body, aad, func(id string) ([]byte, error) {
+			return k.provider.GetKey(ctx, id)
+		})
+	}
+
+	// Written locally, before the keys moved to a remote provider. Opening it
+	// needs the raw key; providers that keep their keys may still have
+	// wrapped it themselves in an earlier version.
+	key, err := k.provider.GetKey(ctx, h.KeyID)
+	if errors.Is(err, ErrKeyNotExportable) {
+		return k.provider.Unwrap(ctx, h.KeyID, body, aad)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return openVersioned(h, body, aad, func(string) ([]byte, error) { return key, nil })
+}
+
+func (k *Keyring) checkBound(cipherText string) error {
+	k.mu.RLock()
+	requireBound := k.requireBound
+	k.mu.RUnlock()
+	if requireBound && !IsBound(cipherText) {
+		return ErrUnbound
+	}
+	return nil
+}
+
+// WrapKey encrypts a data key with the current master key, bound to
+// associatedData (typically the owner's ID).
+func (k *Keyring) WrapKey(ctx context.Context, dataKey, associatedData []byte) (string, error) {
+	if err := checkKey(dataKey); err != nil {
+		return "", errors.New("crypto: invalid data key length (must be 16, 24, or 32 bytes)")
+	}
+	return k.Encrypt(ctx, dataKey, associatedData)
+}
+
+// UnwrapKey reverses WrapKey. Keys wrapped by a retired master key, or by
+// WrapKey before version headers existed, are also accepted.
+func (k *Keyring) UnwrapKey(ctx context.Context, wrapped string, associatedData []byte) ([]byte, error) {
+	dataKey, err := k.Decrypt(ctx, wrapped, associatedData)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkKey(dataKey); err != nil {
+		return nil, errors.New("crypto: unwrapped data key has invalid length")
+	}
+	return dataKey, nil
+}
+
+// IsCurrent reports whether cipherText was written with the current key and
+// cipher in the current, context-bound format.
+func (k *Keyring) IsCurrent(cipherText string) bool {
+	h, ok := ParseHeader(cipherText)
+	return ok && h.Bound() && h.KeyID == k.CurrentID() && h.Algorithm == k.masterAlgorithm()
+}
+
+// IsCurrentWith is IsCurrent for data sealed with an extra key registered
+// under keyID, as by EncryptWith.
+func (k *Keyring) IsCurrentWith(cipherText, keyID string) bool {
+	h, ok := ParseHeader(cipherText)
+	return ok && h.Bound() && h.KeyID == keyID && h.Algorithm == k.Algorithm()
+}
@@ -0,0 +1,196 @@
+package crypto_test
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/herdiagusthio/password-manager/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	oldKey = []byte("11111111111111111111111111111111")
+	newKey = []byte("22222222222222222222222222222222")
+	ctx    = context.Background()
+)
+
+func newKeyring(t *testing.T, currentID string, keys map[string][]byte) *crypto.Keyring {
+	t.Helper()
+	provider, err := crypto.NewStaticProvider(currentID, keys)
+	require.NoError(t, err)
+	return crypto.NewKeyring(provider)
+}
+
+func TestKeyring_DecryptAcrossRotation(t *testing.T) {
+	before := newKeyring(t, "v1", map[string][]byte{"v1": oldKey})
+	after := newKeyring(t, "v2", map[string][]byte{"v1": oldKey, "v2": newKey})
+
+	writtenBefore, err := before.Encrypt(ctx, []byte("old data"), nil)
+	require.NoError(t, err)
+	legacy, err := crypto.Encrypt("pre-header data", string(oldKey))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		cipherText string
+		expected   string
+		current    bool
+	}{
+		{name: "Old Key By ID", cipherText: writtenBefore, expected: "old data"},
+		{name: "Legacy Without Header", cipherText: legacy, expected: "pre-header data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plain, err := after.Decrypt(ctx, tt.cipherText, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(plain))
+			assert.False(t, after.IsCurrent(tt.cipherText))
+		})
+	}
+
+	t.Run("New Writes Use Current Key", func(t *testing.T) {
+		cipherText, err := after.Encrypt(ctx, []byte("new data"), nil)
+		require.NoError(t, err)
+
+		h, ok := crypto.ParseHeader(cipherText)
+		require.True(t, ok)
+		assert.Equal(t, "v2", h.KeyID)
+		assert.Equal(t, crypto.DefaultAlgorithm, h.Algorithm)
+		assert.True(t, after.IsCurrent(cipherText))
+
+		_, err = before.Decrypt(ctx, cipherText, nil)
+		assert.ErrorIs(t, err, crypto.ErrUnknownKeyID)
+	})
+}
+
+func TestKeyring_HeaderIsAuthenticated(t *testing.T) {
+	keyring := newKeyring(t, "a", map[string][]byte{"a": oldKey, "b": oldKey})
+
+	cipherText, err := keyring.Encrypt(ctx, []byte("data"), nil)
+	require.NoError(t, err)
+
+	// Relabel the ciphertext with another key ID that maps to the same key bytes.
+	data, err := base64.StdEncoding.DecodeString(cipherText)
+	require.NoError(t, err)
+	data[4] = 'b'
+	_, err = keyring.Decrypt(ctx, base64.StdEncoding.EncodeToString(data), nil)
+	assert.Error(t, err)
+}
+
+func TestKeyring_WrapKey(t *testing.T) {
+	keyring := newKeyring(t, "v1", map[string][]byte{"v1": oldKey})
+
+	dataKey, err := crypto.GenerateDataKey()
+	require.NoError(t, err)
+
+	wrapped, err := keyring.WrapKey(ctx, dataKey, []byte("user-1"))
+	require.NoError(t, err)
+	unwrapped, err := keyring.UnwrapKey(ctx, wrapped, []byte("user-1"))
+	require.NoError(t, err)
+	assert.Equal(t, dataKey, unwrapped)
+
+	// A wrapped key copied to another user's row no longer unwraps.
+	_, err = keyring.UnwrapKey(ctx, wrapped, []byte("user-2"))
+	assert.Error(t, err)
+
+	// Keys wrapped before version headers existed are still accepted.
+	legacyWrapped, err := crypto.WrapKey(dataKey, string(oldKey))
+	require.NoError(t, err)
+	unwrapped, err = keyring.UnwrapKey(ctx, legacyWrapped, []byte("user-1"))
+	require.NoError(t, err)
+	assert.Equal(t, dataKey, unwrapped)
+}
+
+func TestNewStaticProvider_Invalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		currentID string
+		keys      map[string][]byte
+	}{
+		{name: "Missing Current", currentID: "v2", keys: map[string][]byte{"v1": oldKey}},
+		{name: "Short Key", currentID: "v1", keys: map[string][]byte{"v1": []byte("short")}},
+		{name: "Reserved ID", currentID: "v1", keys: map[string][]byte{"v1": oldKey, crypto.DataKeyID: newKey}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := crypto.NewStaticProvider(tt.currentID, tt.keys)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestKeyring_Sealed(t *testing.T) {
+	provider := crypto.NewSealedProvider()
+	keyring := crypto.NewKeyring(provider)
+	assert.True(t, provider.Sealed())
+	assert.Equal(t, "", keyring.CurrentID())
+
+	_, err := keyring.Encrypt(ctx, []byte("data"), nil)
+	assert.ErrorIs(t, err, crypto.ErrSealed)
+	_, err = keyring.WrapKey(ctx, newKey, []byte("user_key:user-1"))
+	assert.ErrorIs(t, err, crypto.ErrSealed)
+
+	assert.Error(t, provider.Load("1", map[string][]byte{"1": []byte("short")}))
+	assert.True(t, provider.Sealed())
+
+	require.NoError(t, provider.Load("1", map[string][]byte{"1": oldKey}))
+	assert.False(t, provider.Sealed())
+	ciphertext, err := keyring.Encrypt(ctx, []byte("data"), nil)
+	require.NoError(t, err)
+	plaintext, err := keyring.Decrypt(ctx, ciphertext, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), plaintext)
+}
+
+func TestKeyring_Algorithms(t *testing.T) {
+	provider, err := crypto.NewStaticProvider("1", map[string][]byte{"1": oldKey})
+	require.NoError(t, err)
+	aes := crypto.NewKeyring(crypto.WithOptions(provider, crypto.KeyringOptions{Algorithm: crypto.AlgAES256GCM}))
+	xchacha := crypto.NewKeyring(provider)
+
+	tests := []struct {
+		name      string
+		writer    *crypto.Keyring
+		reader    *crypto.Keyring
+		algorithm crypto.Algorithm
+		nonceSize int
+	}{
+		{name: "XChaCha20 Default", writer: xchacha, reader: aes, algorithm: crypto.AlgXChaCha20Poly1305, nonceSize: 24},
+		{name: "AES-GCM Configured", writer: aes, reader: xchacha, algorithm: crypto.AlgAES256GCM, nonceSize: 12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cipherText, err := tt.writer.Encrypt(ctx, []byte("data"), []byte("row-1"))
+			require.NoError(t, err)
+			h, ok := crypto.ParseHeader(cipherText)
+			require.True(t, ok)
+			assert.Equal(t, tt.algorithm, h.Algorithm)
+
+			raw, err := base64.StdEncoding.DecodeString(cipherText)
+			require.NoError(t, err)
+			assert.Len(t, raw, 4+len("1")+tt.nonceSize+len("data")+16)
+
+			// Either keyring reads both, but only the writer considers it current.
+			plain, err := tt.reader.Decrypt(ctx, cipherText, []byte("row-1"))
+			require.NoError(t, err)
+			assert.Equal(t, "data", string(plain))
+			assert.True(t, tt.writer.IsCurrent(cipherText))
+			assert.False(t, tt.reader.IsCurrent(cipherText))
+
+			dataKey, err := crypto.GenerateDataKey()
+			require.NoError(t, err)
+			sealed, err := tt.writer.EncryptWith([]byte("password"), []byte("row-1"), crypto.DataKeyID, dataKey)
+			require.NoError(t, err)
+			plain, err = tt.reader.DecryptWith(ctx, sealed, []byte("row-1"), crypto.DataKeyID, dataKey)
+			require.NoError(t, err)
+			assert.Equal(t, "password", string(plain))
+			assert.True(t, tt.writer.IsCurrentWith(sealed, crypto.DataKeyID))
+			assert.False(t, tt.reader.IsCurrentWith(sealed, crypto.DataKeyID))
+		})
+	}
+}
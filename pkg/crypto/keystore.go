@@ -0,0 +1,142 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// A keystore is a local file holding master keys encrypted with a key derived
+// from a passphrase. It stands in for a KMS on single-host deployments.
+const (
+	keystoreVersion = 1
+	keystoreKeyID   = "keystore"
+)
+
+var (
+	keystoreContext = []byte("keystore")
+	// ErrWrongPassphrase is returned when a keystore cannot be decrypted.
+	ErrWrongPassphrase = errors.New("crypto: wrong keystore passphrase")
+)
+
+type keystoreFile struct {
+	Version int         `json:"version"`
+	KDF     keystoreKDF `json:"kdf"`
+	Keys    string      `json:"keys"` // keystoreKeys, encrypted with the derived key
+}
+
+type keystoreKDF struct {
+	Memory      uint32 `json:"memory"`
+	Iterations  uint32 `json:"iterations"`
+	Parallelism uint8  `json:"parallelism"`
+	Salt        []byte `json:"salt"`
+}
+
+type keystoreKeys struct {
+	CurrentID string            `json:"current_id"`
+	Keys      map[string][]byte `json:"keys"`
+}
+
+func (k keystoreKDF) derive(passphrase string) []byte {
+	return argon2.IDKey([]byte(passphrase), k.Salt, k.Iterations, k.Memory, k.Parallelism, 32)
+}
+
+// FileProvider serves master keys read from a keystore file.
+type FileProvider struct {
+	*StaticProvider
+}
+
+// NewFileProvider opens the keystore at path with passphrase.
+func NewFileProvider(path, passphrase string) (*FileProvider, error) {
+	currentID, keys, err := ReadKeystore(path, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	static, err := NewStaticProvider(currentID, keys)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: keystore %s: %w", path, err)
+	}
+	return &FileProvider{StaticProvider: static}, nil
+}
+
+// ReadKeystore decrypts the keystore at path.
+func ReadKeystore(path, passphrase string) (currentID string, keys map[string][]byte, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, err
+	}
+	var f keystoreFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return "", nil, fmt.Errorf("crypto: keystore %s: %w", path, err)
+	}
+	if f.Version != keystoreVersion {
+		return "", nil, fmt.Errorf("crypto: keystore %s: unsupported version %d", path, f.Version)
+	}
+
+	derived := f.KDF.derive(passphrase)
+	plain, err := DecryptWithKeys(f.Keys, keystoreContext, func(id string) ([]byte, error) {
+		if id != keystoreKeyID {
+			return nil, ErrUnknownKeyID
+		}
+		return derived, nil
+	})
+	if err != nil {
+		return "", nil, ErrWrongPassphrase
+	}
+	var content keystoreKeys
+	if err := json.Unmarshal(plain, &content); err != nil {
+		return "", nil, fmt.Errorf("crypto: keystore %s: %w", path, err)
+	}
+	return content.CurrentID, content.Keys, nil
+}
+
+// WriteKeystore encrypts keys with passphrase and writes them to path,
+// readable by the owner only. An existing file is replaced atomically.
+func WriteKeystore(path, passphrase, currentID string, keys map[string][]byte) error {
+	if passphrase == "" {
+		return errors.New("crypto: keystore passphrase is empty")
+	}
+	if _, err := newKeySet(currentID, keys); err != nil {
+		return err
+	}
+
+	kdf := keystoreKDF{Memory: hashMemory, Iterations: hashIterations, Parallelism: hashParallelism, Salt: make([]byte, hashSaltSize)}
+	if _, err := rand.Read(kdf.Salt); err != nil {
+		return err
+	}
+	plain, err := json.Marshal(keystoreKeys{CurrentID: currentID, Keys: keys})
+	if err != nil {
+		return err
+	}
+	sealed, err := EncryptWithKey(plain, keystoreKeyID, kdf.derive(passphrase), keystoreContext)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(keystoreFile{Version: keystoreVersion, KDF: kdf, Keys: sealed}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".keystore-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,49 @@
+package crypto_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/herdiagusthio/password-manager/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	keys := map[string][]byte{"1": oldKey, "2": newKey}
+	require.NoError(t, crypto.WriteKeystore(path, "correct horse", "2", keys))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), string(newKey))
+
+	provider, err := crypto.NewFileProvider(path, "correct horse")
+	require.NoError(t, err)
+	assert.Equal(t, "2", provider.CurrentKeyID())
+	key, err := provider.GetKey(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, oldKey, key)
+
+	// Data written under the environment keys stays readable from the keystore.
+	env := newKeyring(t, "2", keys)
+	cipherText, err := env.Encrypt(ctx, []byte("data"), []byte("row-1"))
+	require.NoError(t, err)
+	plain, err := crypto.NewKeyring(provider).Decrypt(ctx, cipherText, []byte("row-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "data", string(plain))
+
+	_, err = crypto.NewFileProvider(path, "wrong")
+	assert.ErrorIs(t, err, crypto.ErrWrongPassphrase)
+}
+
+func TestWriteKeystore_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	assert.Error(t, crypto.WriteKeystore(path, "", "1", map[string][]byte{"1": oldKey}))
+	assert.Error(t, crypto.WriteKeystore(path, "pass", "2", map[string][]byte{"1": oldKey}))
+	assert.Error(t, crypto.WriteKeystore(path, "pass", "1", map[string][]byte{"1": []byte("short")}))
+}
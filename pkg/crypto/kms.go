@@ -0,0 +1,141 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// HTTPProvider delegates key operations to a KMS-like HTTP service:
+//
+//	GET  {base}/v1/keys/{id}         -> {"key": base64}
+//	POST {base}/v1/keys/{id}/wrap    {"plaintext", "aad"} -> {"ciphertext"}
+//	POST {base}/v1/keys/{id}/unwrap  {"ciphertext", "aad"} -> {"plaintext"}
+//
+// Byte fields are base64 encoded (encoding/json's []byte form), and requests
+// carry the token as a bearer credential. Keys normally never leave the
+// service, in which case GetKey reports ErrKeyNotExportable.
+type HTTPProvider struct {
+	baseURL   string
+	token     string
+	currentID string
+	client    *http.Client
+}
+
+// NewHTTPProvider returns a provider for the service at baseURL, wrapping new
+// data under currentID. A nil client uses a client with a 10 second timeout.
+func NewHTTPProvider(baseURL, token, currentID string, client *http.Client) (*HTTPProvider, error) {
+	if _, err := url.ParseRequestURI(baseURL); err != nil {
+		return nil, fmt.Errorf("crypto: kms url: %w", err)
+	}
+	if currentID == "" {
+		return nil, errors.New("crypto: kms key id is empty")
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &HTTPProvider{baseURL: baseURL, token: token, currentID: currentID, client: client}, nil
+}
+
+type kmsKeyResponse struct {
+	Key []byte `json:"key"`
+}
+
+type kmsWrapRequest struct {
+	Plaintext []byte `json:"plaintext"`
+	AAD       []byte `json:"aad,omitempty"`
+}
+
+type kmsWrapResponse struct {
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+type kmsUnwrapRequest struct {
+	Ciphertext []byte `json:"ciphertext"`
+	AAD        []byte `json:"aad,omitempty"`
+}
+
+type kmsUnwrapResponse struct {
+	Plaintext []byte `json:"plaintext"`
+}
+
+// CurrentKeyID returns the configured key ID.
+func (p *HTTPProvider) CurrentKeyID() string {
+	return p.currentID
+}
+
+// GetKey asks the service to export a key.
+func (p *HTTPProvider) GetKey(ctx context.Context, keyID string) ([]byte, error) {
+	var resp kmsKeyResponse
+	if err := p.do(ctx, http.MethodGet, keyID, "", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Key, nil
+}
+
+// Wrap encrypts plainText inside the service.
+func (p *HTTPProvider) Wrap(ctx context.Context, keyID string, plainText, associatedData []byte) ([]byte, error) {
+	var resp kmsWrapResponse
+	if err := p.do(ctx, http.MethodPost, keyID, "/wrap", kmsWrapRequest{Plaintext: plainText, AAD: associatedData}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Ciphertext, nil
+}
+
+// Unwrap decrypts wrapped inside the service.
+func (p *HTTPProvider) Unwrap(ctx context.Context, keyID string, wrapped, associatedData []byte) ([]byte, error) {
+	var resp kmsUnwrapResponse
+	if err := p.do(ctx, http.MethodPost, keyID, "/unwrap", kmsUnwrapRequest{Ciphertext: wrapped, AAD: associatedData}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Plaintext, nil
+}
+
+func (p *HTTPProvider) do(ctx context.Context, method, keyID, action string, body, out any) error {
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+"/v1/keys/"+url.PathEscape(keyID)+action, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("crypto: kms: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return json.NewDecoder(resp.Body).Decode(out)
+	case http.StatusNotFound:
+		return fmt.Errorf("%w %q", ErrUnknownKeyID, keyID)
+	case http.StatusForbidden:
+		if action == "" {
+			return ErrKeyNotExportable
+		}
+	}
+
+	var e struct {
+		Error string `json:"error"`
+	}
+	json.NewDecoder(resp.Body).Decode(&e)
+	return fmt.Errorf("crypto: kms: %s %s: %d %s", method, action, resp.StatusCode, e.Error)
+}
@@ -0,0 +1,86 @@
+package crypto_test
+
+import (
+	"testing"
+
+	"github.com/herdiagusthio/password-manager/pkg/crypto"
+	"github.com/herdiagusthio/password-manager/pkg/crypto/kmstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPProvider(t *testing.T) {
+	server, err := kmstest.NewServer("token", "2", map[string][]byte{"1": oldKey, "2": newKey})
+	require.NoError(t, err)
+	defer server.Close()
+
+	provider, err := crypto.NewHTTPProvider(server.URL, "token", "2", server.Client())
+	require.NoError(t, err)
+	keyring := crypto.NewKeyring(provider)
+
+	dataKey, err := crypto.GenerateDataKey()
+	require.NoError(t, err)
+	wrapped, err := keyring.WrapKey(ctx, dataKey, []byte("user_key:user-1"))
+	require.NoError(t, err)
+	assert.True(t, keyring.IsCurrent(wrapped))
+	h, _ := crypto.ParseHeader(wrapped)
+	assert.Equal(t, crypto.AlgProvider, h.Algorithm)
+
+	unwrapped, err := keyring.UnwrapKey(ctx, wrapped, []byte("user_key:user-1"))
+	require.NoError(t, err)
+	assert.Equal(t, dataKey, unwrapped)
+
+	_, err = keyring.UnwrapKey(ctx, wrapped, []byte("user_key:user-2"))
+	assert.Error(t, err, "associated data is checked by the service")
+
+	t.Run("Written Locally", func(t *testing.T) {
+		// Written while key 1 was held locally, before it moved to the KMS.
+		before := newKeyring(t, "1", map[string][]byte{"1": oldKey})
+		cipherText, err := before.Encrypt(ctx, []byte("backup"), []byte("backup"))
+		require.NoError(t, err)
+
+		_, err = keyring.Decrypt(ctx, cipherText, []byte("backup"))
+		assert.Error(t, err, "the KMS cannot open the local format")
+
+		server.Exportable = true
+		defer func() { server.Exportable = false }()
+		plain, err := keyring.Decrypt(ctx, cipherText, []byte("backup"))
+		require.NoError(t, err)
+		assert.Equal(t, "backup", string(plain))
+	})
+
+	t.Run("Unknown Key", func(t *testing.T) {
+		_, err := provider.Wrap(ctx, "9", []byte("data"), nil)
+		assert.ErrorIs(t, err, crypto.ErrUnknownKeyID)
+	})
+
+	t.Run("Export", func(t *testing.T) {
+		_, err := provider.GetKey(ctx, "1")
+		assert.ErrorIs(t, err, crypto.ErrKeyNotExportable)
+
+		server.Exportable = true
+		defer func() { server.Exportable = false }()
+		key, err := provider.GetKey(ctx, "1")
+		require.NoError(t, err)
+		assert.Equal(t, oldKey, key)
+	})
+
+	t.Run("Bad Token", func(t *testing.T) {
+		other, err := crypto.NewHTTPProvider(server.URL, "wrong", "2", server.Client())
+		require.NoError(t, err)
+		_, err = other.Wrap(ctx, "2", []byte("data"), nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestWithOptions_RequireBinding(t *testing.T) {
+	provider, err := crypto.NewStaticProvider("1", map[string][]byte{"1": oldKey})
+	require.NoError(t, err)
+	legacy, err := crypto.Encrypt("unbound", string(oldKey))
+	require.NoError(t, err)
+
+	_, err = crypto.NewKeyring(provider).Decrypt(ctx, legacy, nil)
+	assert.NoError(t, err)
+	_, err = crypto.NewKeyring(crypto.WithOptions(provider, crypto.KeyringOptions{RequireBinding: true})).Decrypt(ctx, legacy, nil)
+	assert.ErrorIs(t, err, crypto.ErrUnbound)
+}
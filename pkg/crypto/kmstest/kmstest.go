@@ -0,0 +1,117 @@
+// Package kmstest runs a local stand-in for the KMS-like HTTP service used by
+// crypto.HTTPProvider, for tests and local development.
+package kmstest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/herdiagusthio/password-manager/pkg/crypto"
+)
+
+// Server is a fake KMS holding keys in memory.
+type Server struct {
+	*httptest.Server
+	// Token is the bearer token clients must send.
+	Token string
+	// Exportable allows GET /v1/keys/{id} to return raw keys.
+	Exportable bool
+
+	keys *crypto.StaticProvider
+}
+
+// NewServer starts a fake KMS serving keys. Close it when done.
+func NewServer(token, currentID string, keys map[string][]byte) (*Server, error) {
+	provider, err := crypto.NewStaticProvider(currentID, keys)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{Token: token, keys: provider}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/keys/{id}", s.getKey)
+	mux.HandleFunc("POST /v1/keys/{id}/wrap", s.wrap)
+	mux.HandleFunc("POST /v1/keys/{id}/unwrap", s.unwrap)
+	s.Server = httptest.NewServer(s.authenticate(mux))
+	return s, nil
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+s.Token {
+			writeError(w, http.StatusUnauthorized, errors.New("invalid token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) getKey(w http.ResponseWriter, r *http.Request) {
+	if !s.Exportable {
+		writeError(w, http.StatusForbidden, crypto.ErrKeyNotExportable)
+		return
+	}
+	key, err := s.keys.GetKey(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, map[string][]byte{"key": key})
+}
+
+func (s *Server) wrap(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Plaintext []byte `json:"plaintext"`
+		AAD       []byte `json:"aad"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	id := r.PathValue("id")
+	if _, err := s.keys.GetKey(r.Context(), id); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	wrapped, err := s.keys.Wrap(r.Context(), id, req.Plaintext, req.AAD)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, map[string][]byte{"ciphertext": wrapped})
+}
+
+func (s *Server) unwrap(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Ciphertext []byte `json:"ciphertext"`
+		AAD        []byte `json:"aad"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	id := r.PathValue("id")
+	if _, err := s.keys.GetKey(r.Context(), id); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	plain, err := s.keys.Unwrap(r.Context(), id, req.Ciphertext, req.AAD)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, map[string][]byte{"plaintext": plain})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,64 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MasterKeySize is the size in bytes required of configured master keys.
+const MasterKeySize = 32
+
+// minDistinctBytes is far below what 32 random bytes produce (about 30), but
+// catches placeholders such as all zeros or a short repeated pattern.
+const minDistinctBytes = 16
+
+// ErrWeakKey is returned for keys that are the right size but clearly not random.
+var ErrWeakKey = errors.New("crypto: key is not random (too few distinct bytes)")
+
+// ParseMasterKey decodes a master key given as 64 hex characters or as
+// base64, and checks that it is 256 bits and plausibly random.
+func ParseMasterKey(encoded string) ([]byte, error) {
+	encoded = strings.TrimSpace(encoded)
+	if encoded == "" {
+		return nil, errors.New("crypto: key is empty")
+	}
+
+	key, err := decodeKey(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != MasterKeySize {
+		return nil, fmt.Errorf("crypto: key is %d bits, must be %d", len(key)*8, MasterKeySize*8)
+	}
+	if distinctBytes(key) < minDistinctBytes {
+		return nil, ErrWeakKey
+	}
+	return key, nil
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	if key, err := hex.DecodeString(encoded); err == nil {
+		return key, nil
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if key, err := enc.DecodeString(encoded); err == nil {
+			return key, nil
+		}
+	}
+	return nil, errors.New("crypto: key must be hex or base64 encoded")
+}
+
+func distinctBytes(key []byte) int {
+	var seen [256]bool
+	n := 0
+	for _, b := range key {
+		if !seen[b] {
+			seen[b] = true
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,49 @@
+package crypto_test
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/herdiagusthio/password-manager/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMasterKey(t *testing.T) {
+	key, err := crypto.GenerateDataKey()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		encoded     string
+		expectError bool
+		errorIs     error
+	}{
+		{name: "Hex", encoded: hex.EncodeToString(key)},
+		{name: "Hex With Whitespace", encoded: " " + hex.EncodeToString(key) + "\n"},
+		{name: "Base64", encoded: base64.StdEncoding.EncodeToString(key)},
+		{name: "Base64 URL Unpadded", encoded: base64.RawURLEncoding.EncodeToString(key)},
+		{name: "Empty", encoded: "", expectError: true},
+		{name: "Raw String", encoded: "your_32_byte_hex_key_here_000000", expectError: true},
+		{name: "128 Bit", encoded: hex.EncodeToString(key[:16]), expectError: true},
+		{name: "All Zero", encoded: strings.Repeat("00", 32), expectError: true, errorIs: crypto.ErrWeakKey},
+		{name: "Repeated Pattern", encoded: strings.Repeat("0123456789abcdef", 4), expectError: true, errorIs: crypto.ErrWeakKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := crypto.ParseMasterKey(tt.encoded)
+			if tt.expectError {
+				assert.Error(t, err)
+				if tt.errorIs != nil {
+					assert.ErrorIs(t, err, tt.errorIs)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, key, parsed)
+		})
+	}
+}
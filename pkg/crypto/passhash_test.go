@@ -0,0 +1,45 @@
+package crypto_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/herdiagusthio/password-manager/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashPassword(t *testing.T) {
+	hash, err := crypto.HashPassword("246810")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=65536,t=3,p=2$"))
+
+	other, err := crypto.HashPassword("246810")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other) // Salted
+
+	tests := []struct {
+		name        string
+		password    string
+		hash        string
+		expected    bool
+		expectError bool
+	}{
+		{name: "Match", password: "246810", hash: hash, expected: true},
+		{name: "Mismatch", password: "246811", hash: hash, expected: false},
+		{name: "Not A Hash", password: "246810", hash: "246810", expectError: true},
+		{name: "Other Algorithm", password: "246810", hash: "$2a$10$abcdefghijklmnopqrstuv", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := crypto.VerifyPassword(tt.password, tt.hash)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, ok)
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package crypto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// KeyProvider supplies the master keys that protect users' data keys and
+// backups. Keys are addressed by ID so that data written under a retired key
+// can still be unwrapped after a rotation.
+type KeyProvider interface {
+	// CurrentKeyID returns the ID of the key used for new data.
+	CurrentKeyID() string
+	// GetKey returns the raw key registered under keyID. Providers that never
+	// release key material return ErrKeyNotExportable.
+	GetKey(ctx context.Context, keyID string) ([]byte, error)
+	// Wrap encrypts plainText with the key registered under keyID and
+	// authenticates associatedData, which is not stored.
+	Wrap(ctx context.Context, keyID string, plainText, associatedData []byte) ([]byte, error)
+	// Unwrap reverses Wrap.
+	Unwrap(ctx context.Context, keyID string, wrapped, associatedData []byte) ([]byte, error)
+}
+
+var (
+	// ErrSealed is returned by a provider whose master keys have not been loaded yet.
+	ErrSealed = errors.New("crypto: keyring is sealed")
+	// ErrKeyNotExportable is returned by GetKey when a provider keeps its keys.
+	ErrKeyNotExportable = errors.New("crypto: key is not exportable")
+)
+
+// localProvider is implemented by providers that hold their keys in memory.
+// Keyrings encrypt with such keys directly, in the configured algorithm, and
+// can try every raw key on data written before version headers existed.
+type localProvider interface {
+	legacyKeys() [][]byte
+}
+
+// StaticProvider holds master keys in memory. It backs the ENCRYPTION_KEY
+// environment variables, the keystore file and the sealed keys file.
+//
+// A provider can start out sealed, without keys, and have them loaded later
+// (see Load), so it is safe for concurrent use.
+type StaticProvider struct {
+	mu  sync.RWMutex
+	set *keySet // nil while sealed
+}
+
+// keySet is an immutable set of master keys.
+type keySet struct {
+	currentID string
+	keys      map[string][]byte
+}
+
+// NewStaticProvider holds keys in memory, using currentID for new data.
+func NewStaticProvider(currentID string, keys map[string][]byte) (*StaticProvider, error) {
+	set, err := newKeySet(currentID, keys)
+	if err != nil {
+		return nil, err
+	}
+	return &StaticProvider{set: set}, nil
+}
+
+// NewSealedProvider returns a provider without keys. Every operation fails
+// with ErrSealed until Load is called.
+func NewSealedProvider() *StaticProvider {
+	return &StaticProvider{}
+}
+
+func newKeySet(currentID string, keys map[string][]byte) (*keySet, error) {
+	if _, ok := keys[currentID]; !ok {
+		return nil, fmt.Errorf("crypto: current key %q is not in the keyring", currentID)
+	}
+
+	s := &keySet{currentID: currentID, keys: make(map[string][]byte, len(keys))}
+	for id, key := range keys {
+		if id == DataKeyID {
+			return nil, fmt.Errorf("crypto: key id %q is reserved", id)
+		}
+		if len(id) == 0 || len(id) > 255 {
+			return nil, errors.New("crypto: key id must be 1-255 bytes")
+		}
+		if err := checkKey(key); err != nil {
+			return nil, fmt.Errorf("crypto: key %q: %w", id, err)
+		}
+		s.keys[id] = key
+	}
+	return s, nil
+}
+
+// Load replaces the keys of the provider, unsealing it if it was sealed.
+func (p *StaticProvider) Load(currentID string, keys map[string][]byte) error {
+	set, err := newKeySet(currentID, keys)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.set = set
+	return nil
+}
+
+// Sealed reports whether the provider has no keys loaded.
+func (p *StaticProvider) Sealed() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.set == nil
+}
+
+func (p *StaticProvider) keys() (*keySet, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.set == nil {
+		return nil, ErrSealed
+	}
+	return p.set, nil
+}
+
+// CurrentKeyID returns the ID of the key used for new data, or "" while sealed.
+func (p *StaticProvider) CurrentKeyID() string {
+	s, err := p.keys()
+	if err != nil {
+		return ""
+	}
+	return s.currentID
+}
+
+// GetKey returns the key registered under keyID.
+func (p *StaticProvider) GetKey(_ context.Context, keyID string) ([]byte, error) {
+	s, err := p.keys()
+	if err != nil {
+		return nil, err
+	}
+	return s.key(keyID)
+}
+
+// Wrap encrypts plainText with AES-256-GCM and returns nonce || ciphertext.
+func (p *StaticProvider) Wrap(ctx context.Context, keyID string, plainText, associatedData []byte) ([]byte, error) {
+	key, err := p.GetKey(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	return sealAEAD(key, plainText, associatedData)
+}
+
+// Unwrap reverses Wrap.
+func (p *StaticProvider) Unwrap(ctx context.Context, keyID string, wrapped, associatedData []byte) ([]byte, error) {
+	key, err := p.GetKey(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	return openAEAD(key, wrapped, associatedData)
+}
+
+func (p *StaticProvider) legacyKeys() [][]byte {
+	s, err := p.keys()
+	if err != nil {
+		return nil
+	}
+	return s.legacyKeys()
+}
+
+func (s *keySet) key(id string) ([]byte, error) {
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKeyID, id)
+	}
+	return key, nil
+}
+
+// legacyKeys returns every key, current first, for decrypting data that
+// predates version headers.
+func (s *keySet) legacyKeys() [][]byte {
+	ids := make([]string, 0, len(s.keys))
+	for id := range s.keys {
+		if id != s.currentID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	keys := [][]byte{s.keys[s.currentID]}
+	for _, id := range ids {
+		keys = append(keys, s.keys[id])
+	}
+	return keys
+}
+
+// KeyringOptions configure every Keyring built on a provider.
+type KeyringOptions struct {
+	// Algorithm encrypts new data; zero means DefaultAlgorithm. Providers
+	// that keep their keys choose their own cipher.
+	Algorithm Algorithm
+	// RequireBinding rejects ciphertexts written without associated data.
+	// Enable it once every stored ciphertext has been re-encrypted.
+	RequireBinding bool
+}
+
+// configuredProvider carries the options for keyrings built on a provider.
+type configuredProvider struct {
+	KeyProvider
+	opts KeyringOptions
+}
+
+// WithOptions returns p carrying opts, which NewKeyring applies.
+func WithOptions(p KeyProvider, opts KeyringOptions) KeyProvider {
+	if c, ok := p.(configuredProvider); ok {
+		p = c.KeyProvider
+	}
+	return configuredProvider{KeyProvider: p, opts: opts}
+}
@@ -0,0 +1,242 @@
+package crypto
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Streams encrypt data too large to hold in memory, such as file attachments,
+// in chunks (the STREAM construction). Each chunk is sealed on its own, so a
+// reader can release plaintext as it goes:
+//
+//	magic (1) | version (1) | algorithm (1) | nonce prefix | chunk | chunk | ...
+//
+// Every chunk holds StreamChunkSize bytes of plaintext, except the last, which
+// may be shorter or empty. Chunk nonces are the random prefix followed by the
+// chunk's 32-bit counter and a flag that is set only on the last chunk, so
+// reordered, dropped or truncated chunks fail to decrypt. The header is
+// authenticated with every chunk, along with the caller's associated data.
+//
+// The random prefix leaves too little room for random nonces under a reused
+// key: encrypt each stream with its own key, e.g. one from GenerateDataKey.
+const (
+	streamMagic   byte = 0xA8
+	streamVersion byte = 1
+
+	// StreamChunkSize is the plaintext size of every chunk but the last.
+	StreamChunkSize = 64 * 1024
+
+	streamAlgorithm = AlgAES256GCM
+	streamCounter   = 4 // counter bytes in a chunk nonce
+)
+
+// ErrStreamCorrupt is returned by stream readers for chunks that do not
+// authenticate, including when the stream ends before its last chunk.
+var ErrStreamCorrupt = errors.New("crypto: encrypted stream is corrupt or truncated")
+
+// StreamOverhead returns how many bytes a stream of size plaintext bytes
+// grows by when encrypted, so the ciphertext size is known up front.
+func StreamOverhead(size int64) int64 {
+	aead, _ := newAEAD(streamAlgorithm, make([]byte, DataKeySize))
+	chunks := (size + StreamChunkSize - 1) / StreamChunkSize
+	if chunks == 0 {
+		chunks = 1 // an empty stream still has its (empty) last chunk
+	}
+	return int64(streamHeaderSize(aead)) + chunks*int64(aead.Overhead())
+}
+
+func streamHeaderSize(aead cipher.AEAD) int {
+	return 3 + aead.NonceSize() - streamCounter - 1
+}
+
+// streamNonce builds the nonce of chunk counter into nonce, which already
+// starts with the stream's prefix.
+func streamNonce(nonce []byte, counter uint32, last bool) {
+	prefix := len(nonce) - streamCounter - 1
+	binary.BigEndian.PutUint32(nonce[prefix:], counter)
+	nonce[len(nonce)-1] = 0
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+}
+
+type streamWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	aad     []byte
+	nonce   []byte
+	counter uint32
+	buf     []byte
+	closed  bool
+}
+
+// NewStreamWriter returns a writer that encrypts everything written to it
+// into w with key, bound to associatedData. Close must be called to write the
+// last chunk; it does not close w.
+func NewStreamWriter(w io.Writer, key, associatedData []byte) (io.WriteCloser, error) {
+	aead, err := newAEAD(streamAlgorithm, key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, streamHeaderSize(aead))
+	header[0], header[1], header[2] = streamMagic, streamVersion, byte(streamAlgorithm)
+	if _, err := io.ReadFull(rand.Reader, header[3:]); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	copy(nonce, header[3:])
+	return &streamWriter{
+		w:     w,
+		aead:  aead,
+		aad:   append(header, associatedData...),
+		nonce: nonce,
+		buf:   make([]byte, 0, StreamChunkSize+aead.Overhead()),
+	}, nil
+}
+
+func (s *streamWriter) Write(p []byte) (int, error) {
+	if s.closed {
+		return 0, errors.New("crypto: write to closed stream")
+	}
+	written := 0
+	for len(p) > 0 {
+		// A full chunk is only sealed once more data follows, since the last
+		// chunk has to be flagged as such
+		if len(s.buf) == StreamChunkSize {
+			if err := s.flush(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(s.buf[len(s.buf):StreamChunkSize], p)
+		s.buf = s.buf[:len(s.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+func (s *streamWriter) flush(last bool) error {
+	if s.counter == ^uint32(0) {
+		return errors.New("crypto: stream is too long")
+	}
+	streamNonce(s.nonce, s.counter, last)
+	sealed := s.aead.Seal(s.buf[:0], s.nonce, s.buf, s.aad)
+	if _, err := s.w.Write(sealed); err != nil {
+		return err
+	}
+	s.counter++
+	s.buf = s.buf[:0]
+	return nil
+}
+
+// Close seals the last chunk.
+func (s *streamWriter) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.flush(true)
+}
+
+type streamReader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	aad     []byte
+	nonce   []byte
+	counter uint32
+	sealed  []byte
+	plain   []byte // decrypted but not yet read
+	done    bool
+}
+
+// NewStreamReader returns a reader that decrypts a stream written by
+// NewStreamWriter with the same key and associated data. Reads fail as soon
+// as a chunk does not authenticate; data returned before that is genuine.
+func NewStreamReader(r io.Reader, key, associatedData []byte) (io.Reader, error) {
+	aead, err := newAEAD(streamAlgorithm, key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, streamHeaderSize(aead))
+	if _, err := io.ReadFull(r, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrStreamCorrupt
+		}
+		return nil, err
+	}
+	if header[0] != streamMagic || header[1] != streamVersion {
+		return nil, errors.New("crypto: not an encrypted stream")
+	}
+	if Algorithm(header[2]) != streamAlgorithm {
+		return nil, fmt.Errorf("crypto: unsupported stream algorithm %s", Algorithm(header[2]))
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	copy(nonce, header[3:])
+	return &streamReader{
+		r:      bufio.NewReaderSize(r, StreamChunkSize+aead.Overhead()),
+		aead:   aead,
+		aad:    append(header, associatedData...),
+		nonce:  nonce,
+		sealed: make([]byte, StreamChunkSize+aead.Overhead()),
+	}, nil
+}
+
+func (s *streamReader) Read(p []byte) (int, error) {
+	for len(s.plain) == 0 {
+		if s.done {
+			return 0, io.EOF
+		}
+		if err := s.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, s.plain)
+	s.plain = s.plain[n:]
+	return n, nil
+}
+
+// next decrypts the following chunk into s.plain.
+func (s *streamReader) next() error {
+	n, err := io.ReadFull(s.r, s.sealed)
+	switch {
+	case errors.Is(err, io.EOF):
+		return ErrStreamCorrupt
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		// Only the last chunk may be short
+	case err != nil:
+		return err
+	}
+	last := n < len(s.sealed)
+	if !last {
+		// A full chunk is the last one if nothing follows it
+		if _, err := s.r.Peek(1); errors.Is(err, io.EOF) {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	streamNonce(s.nonce, s.counter, last)
+	plain, err := s.aead.Open(s.sealed[:0], s.nonce, s.sealed[:n], s.aad)
+	if err != nil {
+		// A stream cut at a chunk boundary fails here too: its new last
+		// chunk was not sealed as the last one
+		return ErrStreamCorrupt
+	}
+	s.counter++
+	s.plain = plain
+	s.done = last
+	return nil
+}
@@ -0,0 +1,141 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encryptStream(t *testing.T, key, plainText, associatedData []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewStreamWriter(&buf, key, associatedData)
+	require.NoError(t, err)
+	// Odd-sized writes exercise chunks filled across calls
+	for rest := plainText; len(rest) > 0; {
+		n := min(len(rest), 1000)
+		_, err := w.Write(rest[:n])
+		require.NoError(t, err)
+		rest = rest[n:]
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func decryptStream(key, cipherText, associatedData []byte) ([]byte, error) {
+	r, err := NewStreamReader(bytes.NewReader(cipherText), key, associatedData)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestStream(t *testing.T) {
+	key, err := GenerateDataKey()
+	require.NoError(t, err)
+
+	sizes := map[string]int{
+		"Empty":            0,
+		"Short":            100,
+		"One Chunk":        StreamChunkSize,
+		"Chunk And A Byte": StreamChunkSize + 1,
+		"Several Chunks":   3*StreamChunkSize + 12345,
+	}
+	for name, size := range sizes {
+		t.Run(name, func(t *testing.T) {
+			plainText := make([]byte, size)
+			_, err := rand.Read(plainText)
+			require.NoError(t, err)
+
+			cipherText := encryptStream(t, key, plainText, []byte("file-1"))
+			assert.EqualValues(t, int64(size)+StreamOverhead(int64(size)), len(cipherText))
+
+			decrypted, err := decryptStream(key, cipherText, []byte("file-1"))
+			require.NoError(t, err)
+			assert.Equal(t, plainText, decrypted)
+		})
+	}
+}
+
+func TestStream_Tampering(t *testing.T) {
+	key, err := GenerateDataKey()
+	require.NoError(t, err)
+	plainText := make([]byte, 2*StreamChunkSize+500)
+	_, err = rand.Read(plainText)
+	require.NoError(t, err)
+	cipherText := encryptStream(t, key, plainText, []byte("file-1"))
+
+	// An empty stream is the header and one tag
+	const tag = 16
+	header := int(StreamOverhead(0)) - tag
+	sealedChunk := StreamChunkSize + tag
+
+	tests := []struct {
+		name           string
+		cipherText     func() []byte
+		associatedData string
+	}{
+		{
+			name:           "Wrong Associated Data",
+			cipherText:     func() []byte { return cipherText },
+			associatedData: "file-2",
+		},
+		{
+			name: "Flipped Bit",
+			cipherText: func() []byte {
+				c := bytes.Clone(cipherText)
+				c[header+10] ^= 1
+				return c
+			},
+		},
+		{
+			name: "Cut At Chunk Boundary",
+			cipherText: func() []byte {
+				return cipherText[:header+sealedChunk]
+			},
+		},
+		{
+			name: "Cut Mid Chunk",
+			cipherText: func() []byte {
+				return cipherText[:len(cipherText)-10]
+			},
+		},
+		{
+			name: "Chunks Swapped",
+			cipherText: func() []byte {
+				c := bytes.Clone(cipherText[:header])
+				c = append(c, cipherText[header+sealedChunk:header+2*sealedChunk]...)
+				c = append(c, cipherText[header:header+sealedChunk]...)
+				return append(c, cipherText[header+2*sealedChunk:]...)
+			},
+		},
+		{
+			name: "Header Only",
+			cipherText: func() []byte {
+				return cipherText[:header]
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			associatedData := tt.associatedData
+			if associatedData == "" {
+				associatedData = "file-1"
+			}
+			_, err := decryptStream(key, tt.cipherText(), []byte(associatedData))
+			assert.ErrorIs(t, err, ErrStreamCorrupt)
+		})
+	}
+
+	t.Run("Wrong Key", func(t *testing.T) {
+		other, err := GenerateDataKey()
+		require.NoError(t, err)
+		_, err = decryptStream(other, cipherText, []byte("file-1"))
+		assert.ErrorIs(t, err, ErrStreamCorrupt)
+	})
+}
@@ -0,0 +1,28 @@
+// Package mergepatch applies JSON Merge Patch documents (RFC 7396) to values
+// decoded by encoding/json.
+//
+// A patch object is merged key by key: null removes the key, an object is
+// merged recursively and anything else, arrays included, replaces the target
+// value. A patch that is not an object replaces the whole target.
+package mergepatch
+
+// Apply returns target with patch merged into it. Maps in target are updated
+// in place; pass a copy to keep the original.
+func Apply(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok || targetObject == nil {
+		targetObject = map[string]interface{}{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = Apply(targetObject[key], value)
+	}
+	return targetObject
+}
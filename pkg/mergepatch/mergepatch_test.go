@@ -0,0 +1,51 @@
+package mergepatch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestApply runs the examples from RFC 7396, Appendix A.
+func TestApply(t *testing.T) {
+	tests := []struct {
+		target string
+		patch  string
+		result string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target+" + "+tt.patch, func(t *testing.T) {
+			var target, patch, expected interface{}
+			require.NoError(t, json.Unmarshal([]byte(tt.target), &target))
+			require.NoError(t, json.Unmarshal([]byte(tt.patch), &patch))
+			require.NoError(t, json.Unmarshal([]byte(tt.result), &expected))
+
+			assert.Equal(t, expected, Apply(target, patch))
+		})
+	}
+}
+
+func TestApply_NilMap(t *testing.T) {
+	var target map[string]interface{}
+	result := Apply(target, map[string]interface{}{"a": "b"})
+	assert.Equal(t, map[string]interface{}{"a": "b"}, result)
+}
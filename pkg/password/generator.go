@@ -2,44 +2,156 @@ package password
 
 import (
 	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
 	"math/big"
 )
 
 const (
-	letterBytes  = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
-	numberBytes  = "0123456789"
-	symbolBytes  = "!@#$%^&*()_+-=[]{}|;:,.<>?"
-	defaultLength = 16
+	lowerBytes  = "abcdefghijklmnopqrstuvwxyz"
+	upperBytes  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	numberBytes = "0123456789"
+	symbolBytes = "!@#$%^&*()_+-=[]{}|;:,.<>?"
+
+	// MinLength and MaxLength bound the generated password length (REQ-GEN-01).
+	MinLength = 8
+	MaxLength = 64
+	// DefaultLength is the length of the "Strong" preset (REQ-GEN-03).
+	DefaultLength = 16
+)
+
+var (
+	// ErrInvalidLength is returned for lengths outside MinLength..MaxLength.
+	ErrInvalidLength = fmt.Errorf("length must be between %d and %d", MinLength, MaxLength)
+	// ErrNoCharacterClass is returned when every character class is turned off.
+	ErrNoCharacterClass = errors.New("at least one character class must be selected")
 )
 
+// Options selects the length and character classes of a generated password.
+// Every selected class appears at least once in the result.
 type Options struct {
-	Length       int
-	IncludeOr    bool // if true, ensure at least one of chosen types
-	IncludeNums  bool
-	IncludeSyms  bool
+	Length    int
+	Uppercase bool
+	Lowercase bool
+	Numbers   bool
+	Symbols   bool
 }
 
-func Generate(opts Options) (string, error) {
-	if opts.Length <= 0 {
-		opts.Length = defaultLength
+// DefaultOptions is the "Strong" preset: 16 characters mixing all classes.
+func DefaultOptions() Options {
+	return Options{
+		Length:    DefaultLength,
+		Uppercase: true,
+		Lowercase: true,
+		Numbers:   true,
+		Symbols:   true,
+	}
+}
+
+// Validate checks the length bounds and that at least one class is selected.
+func (o Options) Validate() error {
+	if o.Length < MinLength || o.Length > MaxLength {
+		return ErrInvalidLength
+	}
+	if len(o.classes()) == 0 {
+		return ErrNoCharacterClass
+	}
+	return nil
+}
+
+// Entropy returns the entropy in bits of a password generated with o. It
+// treats every character as drawn from all selected classes, which slightly
+// overstates it because each class is guaranteed to appear.
+func (o Options) Entropy() float64 {
+	size := 0
+	for _, class := range o.classes() {
+		size += len(class)
+	}
+	if size == 0 {
+		return 0
 	}
+	return float64(o.Length) * math.Log2(float64(size))
+}
 
-	charSet := letterBytes
-	if opts.IncludeNums {
-		charSet += numberBytes
+func (o Options) classes() []string {
+	var classes []string
+	if o.Uppercase {
+		classes = append(classes, upperBytes)
+	}
+	if o.Lowercase {
+		classes = append(classes, lowerBytes)
 	}
-	if opts.IncludeSyms {
-		charSet += symbolBytes
+	if o.Numbers {
+		classes = append(classes, numberBytes)
+	}
+	if o.Symbols {
+		classes = append(classes, symbolBytes)
+	}
+	return classes
+}
+
+// Generate returns a random password for opts. A zero Length means
+// DefaultLength. One character is drawn from each selected class, the rest
+// from their union, and the result is shuffled so the guaranteed characters
+// do not sit at fixed positions.
+func Generate(opts Options) (string, error) {
+	if opts.Length == 0 {
+		opts.Length = DefaultLength
+	}
+	if err := opts.Validate(); err != nil {
+		return "", err
+	}
+
+	classes := opts.classes()
+	charSet := ""
+	for _, class := range classes {
+		charSet += class
 	}
 
 	b := make([]byte, opts.Length)
 	for i := range b {
-		num, err := rand.Int(rand.Reader, big.NewInt(int64(len(charSet))))
+		set := charSet
+		if i < len(classes) {
+			set = classes[i]
+		}
+		c, err := pick(set)
 		if err != nil {
 			return "", err
 		}
-		b[i] = charSet[num.Int64()]
+		b[i] = c
 	}
 
+	if err := shuffle(b); err != nil {
+		return "", err
+	}
 	return string(b), nil
 }
+
+func pick(set string) (byte, error) {
+	n, err := randInt(len(set))
+	if err != nil {
+		return 0, err
+	}
+	return set[n], nil
+}
+
+// shuffle is a Fisher-Yates shuffle driven by crypto/rand.
+func shuffle[T any](b []T) error {
+	for i := len(b) - 1; i > 0; i-- {
+		j, err := randInt(i + 1)
+		if err != nil {
+			return err
+		}
+		b[i], b[j] = b[j], b[i]
+	}
+	return nil
+}
+
+func randInt(n int) (int, error) {
+	num, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(num.Int64()), nil
+}
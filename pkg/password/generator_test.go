@@ -0,0 +1,66 @@
+package password_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/herdiagusthio/password-manager/pkg/password"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        password.Options
+		wantLength  int
+		expectError error
+	}{
+		{name: "Strong Default", opts: password.DefaultOptions(), wantLength: 16},
+		{name: "Zero Length Uses Default", opts: password.Options{Lowercase: true}, wantLength: 16},
+		{name: "Minimum Length All Classes", opts: password.Options{Length: 8, Uppercase: true, Lowercase: true, Numbers: true, Symbols: true}, wantLength: 8},
+		{name: "Maximum Length", opts: password.Options{Length: 64, Lowercase: true, Numbers: true}, wantLength: 64},
+		{name: "Numbers Only", opts: password.Options{Length: 12, Numbers: true}, wantLength: 12},
+		{name: "Too Short", opts: password.Options{Length: 7, Lowercase: true}, expectError: password.ErrInvalidLength},
+		{name: "Too Long", opts: password.Options{Length: 65, Lowercase: true}, expectError: password.ErrInvalidLength},
+		{name: "Negative Length", opts: password.Options{Length: -1, Lowercase: true}, expectError: password.ErrInvalidLength},
+		{name: "No Classes", opts: password.Options{Length: 16}, expectError: password.ErrNoCharacterClass},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Generate repeatedly: with uniform sampling a class would be
+			// missing from a short password now and then.
+			for i := 0; i < 200; i++ {
+				pw, err := password.Generate(tt.opts)
+				if tt.expectError != nil {
+					assert.ErrorIs(t, err, tt.expectError)
+					return
+				}
+				require.NoError(t, err)
+				require.Len(t, pw, tt.wantLength)
+				assertClasses(t, tt.opts, pw)
+			}
+		})
+	}
+}
+
+func assertClasses(t *testing.T, opts password.Options, pw string) {
+	t.Helper()
+	classes := []struct {
+		enabled bool
+		chars   string
+	}{
+		{opts.Uppercase, "ABCDEFGHIJKLMNOPQRSTUVWXYZ"},
+		{opts.Lowercase, "abcdefghijklmnopqrstuvwxyz"},
+		{opts.Numbers, "0123456789"},
+		{opts.Symbols, "!@#$%^&*()_+-=[]{}|;:,.<>?"},
+	}
+	for _, class := range classes {
+		if class.enabled {
+			assert.True(t, strings.ContainsAny(pw, class.chars), "%q is missing one of %q", pw, class.chars)
+		} else {
+			assert.False(t, strings.ContainsAny(pw, class.chars), "%q contains a deselected class %q", pw, class.chars)
+		}
+	}
+}
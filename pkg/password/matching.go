@@ -0,0 +1,748 @@
+package password
+
+import (
+	_ "embed"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Patterns recognised by the strength estimator.
+const (
+	PatternDictionary = "dictionary"
+	PatternSpatial    = "spatial"
+	PatternRepeat     = "repeat"
+	PatternSequence   = "sequence"
+	PatternDate       = "date"
+	PatternBruteforce = "bruteforce"
+)
+
+// Dictionaries searched by the strength estimator.
+const (
+	DictionaryPasswords  = "passwords"
+	DictionaryWords      = "words"
+	DictionaryUserInputs = "user_inputs"
+)
+
+// commonPasswords lists frequently leaked passwords, most common first.
+//
+//go:embed common_passwords.txt
+var commonPasswords string
+
+// Match is one pattern found in a password. I and J are inclusive rune
+// offsets; the remaining fields are set according to Pattern.
+type Match struct {
+	Pattern string
+	I, J    int
+	Token   string
+	Guesses float64
+
+	// Dictionary matches.
+	Dictionary  string
+	MatchedWord string
+	Rank        int
+	Reversed    bool
+	L33t        bool
+	Sub         map[rune]rune
+
+	// Spatial matches.
+	Turns        int
+	ShiftedCount int
+
+	// Repeat matches.
+	BaseToken   string
+	RepeatCount int
+
+	// Sequence matches.
+	Ascending bool
+
+	// Date matches. Month and Day are zero for a lone year.
+	Year, Month, Day int
+	Separator        string
+}
+
+type rankedDictionary struct {
+	name   string
+	ranks  map[string]int
+	maxLen int
+}
+
+func newRankedDictionary(name string, words []string) rankedDictionary {
+	d := rankedDictionary{name: name, ranks: make(map[string]int, len(words))}
+	for i, w := range words {
+		w = strings.ToLower(w)
+		if _, ok := d.ranks[w]; ok {
+			continue
+		}
+		d.ranks[w] = i + 1
+		d.maxLen = max(d.maxLen, len([]rune(w)))
+	}
+	return d
+}
+
+var builtinDictionaries = sync.OnceValue(func() []rankedDictionary {
+	passwords := newRankedDictionary(DictionaryPasswords, strings.Fields(commonPasswords))
+
+	// The EFF list is not ordered by frequency, so every word gets the
+	// average rank.
+	words := newRankedDictionary(DictionaryWords, wordlist())
+	for w := range words.ranks {
+		words.ranks[w] = len(words.ranks) / 2
+	}
+	return []rankedDictionary{passwords, words}
+})
+
+// userInputSplit separates user inputs such as "alice@example.com" into words.
+var userInputSplit = regexp.MustCompile(`[^\pL\pN]+`)
+
+// matcher finds every pattern in a password.
+type matcher struct {
+	dictionaries []rankedDictionary
+	refYear      int
+}
+
+func newMatcher(userInputs []string, refYear int) *matcher {
+	var inputs []string
+	for _, input := range userInputs {
+		input = strings.ToLower(strings.TrimSpace(input))
+		if input == "" {
+			continue
+		}
+		inputs = append(inputs, input)
+		for _, part := range userInputSplit.Split(input, -1) {
+			if len([]rune(part)) >= 3 && part != input {
+				inputs = append(inputs, part)
+			}
+		}
+	}
+
+	dicts := builtinDictionaries()
+	if len(inputs) > 0 {
+		dicts = append(append([]rankedDictionary{}, dicts...), newRankedDictionary(DictionaryUserInputs, inputs))
+	}
+	return &matcher{dictionaries: dicts, refYear: refYear}
+}
+
+// omnimatch returns the matches of every pattern, with their guesses.
+func (m *matcher) omnimatch(pw []rune) []*Match {
+	var matches []*Match
+	matches = append(matches, m.dictionaryMatches(pw)...)
+	matches = append(matches, m.reverseDictionaryMatches(pw)...)
+	matches = append(matches, m.l33tMatches(pw)...)
+	matches = append(matches, spatialMatches(pw)...)
+	matches = append(matches, m.repeatMatches(pw)...)
+	matches = append(matches, sequenceMatches(pw)...)
+	matches = append(matches, m.dateMatches(pw)...)
+
+	// A pattern inside a longer password is at least a few guesses, so
+	// single characters never beat brute force.
+	for _, match := range matches {
+		if match.I == 0 && match.J == len(pw)-1 {
+			continue
+		}
+		minGuesses := 50.0
+		if match.I == match.J {
+			minGuesses = 10
+		}
+		match.Guesses = math.Max(match.Guesses, minGuesses)
+	}
+	return matches
+}
+
+func (m *matcher) dictionaryMatches(pw []rune) []*Match {
+	lower := lowerRunes(pw)
+	var matches []*Match
+	for _, dict := range m.dictionaries {
+		for i := range lower {
+			for j := i + 2; j < len(lower) && j-i < dict.maxLen; j++ {
+				word := string(lower[i : j+1])
+				rank, ok := dict.ranks[word]
+				if !ok {
+					continue
+				}
+				token := pw[i : j+1]
+				matches = append(matches, &Match{
+					Pattern:     PatternDictionary,
+					I:           i,
+					J:           j,
+					Token:       string(token),
+					Guesses:     float64(rank) * uppercaseVariations(token),
+					Dictionary:  dict.name,
+					MatchedWord: word,
+					Rank:        rank,
+				})
+			}
+		}
+	}
+	return matches
+}
+
+func (m *matcher) reverseDictionaryMatches(pw []rune) []*Match {
+	n := len(pw)
+	reversed := make([]rune, n)
+	for i, r := range pw {
+		reversed[n-1-i] = r
+	}
+
+	matches := m.dictionaryMatches(reversed)
+	for _, match := range matches {
+		match.I, match.J = n-1-match.J, n-1-match.I
+		match.Token = string(pw[match.I : match.J+1])
+		match.Reversed = true
+		match.Guesses *= 2
+	}
+	return matches
+}
+
+// l33tTable maps substituted characters to the letters they stand for.
+var l33tTable = map[rune][]rune{
+	'4': {'a'}, '@': {'a'},
+	'8': {'b'},
+	'(': {'c'}, '{': {'c'}, '[': {'c'}, '<': {'c'},
+	'3': {'e'},
+	'6': {'g'}, '9': {'g'},
+	'1': {'i', 'l'}, '!': {'i'}, '|': {'i', 'l'},
+	'7': {'l', 't'},
+	'0': {'o'},
+	'$': {'s'}, '5': {'s'},
+	'+': {'t'},
+	'%': {'x'},
+	'2': {'z'},
+}
+
+// maxL33tSubs caps how many substitution tables are tried per password.
+const maxL33tSubs = 64
+
+func (m *matcher) l33tMatches(pw []rune) []*Match {
+	lower := lowerRunes(pw)
+	var present []rune
+	seen := map[rune]bool{}
+	for _, r := range lower {
+		if _, ok := l33tTable[r]; ok && !seen[r] {
+			seen[r] = true
+			present = append(present, r)
+		}
+	}
+	if len(present) == 0 {
+		return nil
+	}
+	sort.Slice(present, func(a, b int) bool { return present[a] < present[b] })
+
+	type key struct {
+		i, j int
+		word string
+	}
+	best := map[key]*Match{}
+	var order []key
+	for _, sub := range l33tSubs(present) {
+		translated := make([]rune, len(lower))
+		for i, r := range lower {
+			if letter, ok := sub[r]; ok {
+				r = letter
+			}
+			translated[i] = r
+		}
+
+		for _, match := range m.dictionaryMatches(translated) {
+			used := map[rune]rune{}
+			for _, r := range lower[match.I : match.J+1] {
+				if letter, ok := sub[r]; ok {
+					used[r] = letter
+				}
+			}
+			if len(used) == 0 {
+				continue
+			}
+
+			token := pw[match.I : match.J+1]
+			match.Token = string(token)
+			match.L33t = true
+			match.Sub = used
+			match.Guesses = float64(match.Rank) * uppercaseVariations(token) * l33tVariations(token, used)
+
+			k := key{match.I, match.J, match.MatchedWord}
+			if prev, ok := best[k]; !ok {
+				order = append(order, k)
+				best[k] = match
+			} else if match.Guesses < prev.Guesses {
+				best[k] = match
+			}
+		}
+	}
+
+	matches := make([]*Match, 0, len(order))
+	for _, k := range order {
+		matches = append(matches, best[k])
+	}
+	return matches
+}
+
+// l33tSubs returns every way of reading the present l33t characters, up to
+// maxL33tSubs of them.
+func l33tSubs(present []rune) []map[rune]rune {
+	subs := []map[rune]rune{{}}
+	for _, r := range present {
+		var next []map[rune]rune
+		for _, sub := range subs {
+			for _, letter := range l33tTable[r] {
+				if len(next) == maxL33tSubs {
+					break
+				}
+				extended := make(map[rune]rune, len(sub)+1)
+				for k, v := range sub {
+					extended[k] = v
+				}
+				extended[r] = letter
+				next = append(next, extended)
+			}
+		}
+		subs = next
+	}
+	return subs
+}
+
+// keyPosition places a key on a staggered qwerty layout.
+type keyPosition struct {
+	row     int
+	x       float64
+	shifted bool
+}
+
+var qwerty = sync.OnceValue(func() map[rune]keyPosition {
+	rows := []struct {
+		offset           float64
+		unshifted, shift string
+	}{
+		{0, "`1234567890-=", "~!@#$%^&*()_+"},
+		{1.5, "qwertyuiop[]\\", "QWERTYUIOP{}|"},
+		{1.75, "asdfghjkl;'", "ASDFGHJKL:\""},
+		{2.25, "zxcvbnm,./", "ZXCVBNM<>?"},
+	}
+	keys := map[rune]keyPosition{}
+	for row, r := range rows {
+		shifted := []rune(r.shift)
+		for col, c := range []rune(r.unshifted) {
+			x := r.offset + float64(col)
+			keys[c] = keyPosition{row: row, x: x}
+			keys[shifted[col]] = keyPosition{row: row, x: x, shifted: true}
+		}
+	}
+	return keys
+})
+
+// keyDirection reports the direction from key a to an adjacent key b:
+// left/right on the same row, or one of the two keys above or below.
+func keyDirection(a, b keyPosition) (int, bool) {
+	dr := b.row - a.row
+	dx := b.x - a.x
+	right := 0
+	if dx > 0 {
+		right = 1
+	}
+	switch {
+	case dr == 0 && math.Abs(dx) == 1:
+		return right, true
+	case dr == -1 && math.Abs(dx) < 1:
+		return 2 + right, true
+	case dr == 1 && math.Abs(dx) < 1:
+		return 4 + right, true
+	}
+	return 0, false
+}
+
+// keyboardStats returns the number of unshifted keys and their average
+// number of neighbours, used to count keyboard walks.
+var keyboardStats = sync.OnceValues(func() (float64, float64) {
+	keys := qwerty()
+	var starts, neighbours float64
+	for _, a := range keys {
+		if a.shifted {
+			continue
+		}
+		starts++
+		for _, b := range keys {
+			if _, ok := keyDirection(a, b); ok && !b.shifted {
+				neighbours++
+			}
+		}
+	}
+	return starts, neighbours / starts
+})
+
+func spatialMatches(pw []rune) []*Match {
+	keys := qwerty()
+	var matches []*Match
+	for i := 0; i < len(pw)-2; {
+		j := i + 1
+		turns := 0
+		lastDirection := -1
+		for ; j < len(pw); j++ {
+			a, okA := keys[pw[j-1]]
+			b, okB := keys[pw[j]]
+			if !okA || !okB {
+				break
+			}
+			direction, ok := keyDirection(a, b)
+			if !ok {
+				break
+			}
+			if direction != lastDirection {
+				turns++
+				lastDirection = direction
+			}
+		}
+
+		if j-i >= 3 {
+			shifted := 0
+			for _, r := range pw[i:j] {
+				if keys[r].shifted {
+					shifted++
+				}
+			}
+			matches = append(matches, &Match{
+				Pattern:      PatternSpatial,
+				I:            i,
+				J:            j - 1,
+				Token:        string(pw[i:j]),
+				Guesses:      spatialGuesses(j-i, turns, shifted),
+				Turns:        turns,
+				ShiftedCount: shifted,
+			})
+		}
+		i = j
+	}
+	return matches
+}
+
+func spatialGuesses(length, turns, shifted int) float64 {
+	starts, degree := keyboardStats()
+	var guesses float64
+	for i := 2; i <= length; i++ {
+		for j := 1; j <= min(turns, i-1); j++ {
+			guesses += binomial(i-1, j-1) * starts * math.Pow(degree, float64(j))
+		}
+	}
+	return guesses * caseVariations(shifted, length-shifted)
+}
+
+func (m *matcher) repeatMatches(pw []rune) []*Match {
+	var matches []*Match
+	for i := 0; i < len(pw); {
+		bestBase, bestCount := 0, 0
+		for base := 1; i+2*base <= len(pw); base++ {
+			count := 1
+			for i+(count+1)*base <= len(pw) && equalRunes(pw[i:i+base], pw[i+count*base:i+(count+1)*base]) {
+				count++
+			}
+			if count < 2 || (base == 1 && count < 3) {
+				continue
+			}
+			if count*base > bestBase*bestCount {
+				bestBase, bestCount = base, count
+			}
+		}
+		if bestCount == 0 {
+			i++
+			continue
+		}
+
+		span := bestBase * bestCount
+		base := pw[i : i+bestBase]
+		baseGuesses, _ := mostGuessable(base, m.omnimatch(base))
+		matches = append(matches, &Match{
+			Pattern:     PatternRepeat,
+			I:           i,
+			J:           i + span - 1,
+			Token:       string(pw[i : i+span]),
+			Guesses:     baseGuesses * float64(bestCount),
+			BaseToken:   string(base),
+			RepeatCount: bestCount,
+		})
+		i += span
+	}
+	return matches
+}
+
+// maxSequenceDelta is the largest step between characters of a sequence.
+const maxSequenceDelta = 5
+
+func sequenceMatches(pw []rune) []*Match {
+	var matches []*Match
+	for i := 0; i < len(pw)-2; {
+		delta := pw[i+1] - pw[i]
+		if delta == 0 || delta > maxSequenceDelta || delta < -maxSequenceDelta || !sameClass(pw[i], pw[i+1]) {
+			i++
+			continue
+		}
+		j := i + 1
+		for j+1 < len(pw) && pw[j+1]-pw[j] == delta && sameClass(pw[j], pw[j+1]) {
+			j++
+		}
+		if j-i < 2 {
+			i++
+			continue
+		}
+
+		token := pw[i : j+1]
+		base := 26.0
+		switch {
+		case strings.ContainsRune("aAzZ019", token[0]):
+			base = 4
+		case unicode.IsDigit(token[0]):
+			base = 10
+		}
+		if delta < 0 {
+			base *= 2
+		}
+		matches = append(matches, &Match{
+			Pattern:   PatternSequence,
+			I:         i,
+			J:         j,
+			Token:     string(token),
+			Guesses:   base * float64(len(token)),
+			Ascending: delta > 0,
+		})
+		i = j
+	}
+	return matches
+}
+
+func sameClass(a, b rune) bool {
+	switch {
+	case a >= 'a' && a <= 'z':
+		return b >= 'a' && b <= 'z'
+	case a >= 'A' && a <= 'Z':
+		return b >= 'A' && b <= 'Z'
+	case a >= '0' && a <= '9':
+		return b >= '0' && b <= '9'
+	}
+	return false
+}
+
+const (
+	// minYearSpace keeps years close to the reference year from looking
+	// unrealistically cheap.
+	minYearSpace = 20
+	minYear      = 1000
+	maxYear      = 2050
+)
+
+// dateSplits lists where a run of digits of a given length may be cut into
+// three date parts.
+var dateSplits = map[int][][2]int{
+	4: {{1, 2}, {2, 3}},
+	5: {{1, 3}, {2, 3}},
+	6: {{1, 2}, {2, 4}, {4, 5}},
+	7: {{1, 3}, {2, 3}, {4, 5}, {4, 6}},
+	8: {{2, 4}, {4, 6}},
+}
+
+var separatedDate = regexp.MustCompile(`^(\d{1,4})([\s/\\_.-])(\d{1,2})([\s/\\_.-])(\d{1,4})$`)
+
+func (m *matcher) dateMatches(pw []rune) []*Match {
+	var matches []*Match
+	for i := range pw {
+		for j := i + 3; j < len(pw) && j-i < 10; j++ {
+			token := string(pw[i : j+1])
+			length := j - i + 1
+
+			if isDigits(token) {
+				if length == 4 {
+					if year, _ := strconv.Atoi(token); year >= 1900 && year <= maxYear {
+						matches = append(matches, &Match{
+							Pattern: PatternDate,
+							I:       i,
+							J:       j,
+							Token:   token,
+							Guesses: m.yearSpace(year),
+							Year:    year,
+						})
+					}
+				}
+
+				var best *Match
+				for _, split := range dateSplits[length] {
+					parts := [3]string{token[:split[0]], token[split[0]:split[1]], token[split[1]:]}
+					if match, ok := m.date(parts, ""); ok && (best == nil || m.yearSpace(match.Year) < m.yearSpace(best.Year)) {
+						best = match
+					}
+				}
+				if best != nil {
+					best.I, best.J, best.Token = i, j, token
+					matches = append(matches, best)
+				}
+				continue
+			}
+
+			groups := separatedDate.FindStringSubmatch(token)
+			if groups == nil || groups[2] != groups[4] {
+				continue
+			}
+			if match, ok := m.date([3]string{groups[1], groups[3], groups[5]}, groups[2]); ok {
+				match.I, match.J, match.Token = i, j, token
+				matches = append(matches, match)
+			}
+		}
+	}
+	return matches
+}
+
+// date reads three parts as a date with the year first or last, preferring
+// the reading closest to the reference year.
+func (m *matcher) date(parts [3]string, separator string) (*Match, bool) {
+	var best *Match
+	candidates := [][3]string{
+		{parts[0], parts[1], parts[2]},
+		{parts[2], parts[0], parts[1]},
+	}
+	for _, c := range candidates {
+		year, ok := parseYear(c[0])
+		if !ok {
+			continue
+		}
+		for _, order := range [][2]string{{c[1], c[2]}, {c[2], c[1]}} {
+			if len(order[0]) > 2 || len(order[1]) > 2 {
+				continue
+			}
+			month, _ := strconv.Atoi(order[0])
+			day, _ := strconv.Atoi(order[1])
+			if month < 1 || month > 12 || day < 1 || day > 31 {
+				continue
+			}
+			if best == nil || m.yearSpace(year) < m.yearSpace(best.Year) {
+				best = &Match{Pattern: PatternDate, Year: year, Month: month, Day: day, Separator: separator}
+			}
+		}
+	}
+	if best == nil {
+		return nil, false
+	}
+
+	best.Guesses = m.yearSpace(best.Year) * 365
+	if separator != "" {
+		best.Guesses *= 4
+	}
+	return best, true
+}
+
+func parseYear(s string) (int, bool) {
+	year, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, false
+	}
+	switch len(s) {
+	case 2:
+		if year > 50 {
+			return 1900 + year, true
+		}
+		return 2000 + year, true
+	case 4:
+		return year, year >= minYear && year <= maxYear
+	}
+	return 0, false
+}
+
+func (m *matcher) yearSpace(year int) float64 {
+	return math.Max(math.Abs(float64(year-m.refYear)), minYearSpace)
+}
+
+// uppercaseVariations counts the capitalizations an attacker would try: one
+// of first letter, last letter or all caps is cheap, mixed case is not.
+func uppercaseVariations(token []rune) float64 {
+	var upper, lower int
+	for _, r := range token {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		}
+	}
+	if upper == 0 {
+		return 1
+	}
+	if lower == 0 || (upper == 1 && (unicode.IsUpper(token[0]) || unicode.IsUpper(token[len(token)-1]))) {
+		return 2
+	}
+	return caseVariations(upper, lower)
+}
+
+// l33tVariations counts the ways the substitutions could have been applied
+// to the letters of the token.
+func l33tVariations(token []rune, sub map[rune]rune) float64 {
+	lower := lowerRunes(token)
+	variations := 1.0
+	for subbed, letter := range sub {
+		var s, u int
+		for _, r := range lower {
+			switch r {
+			case subbed:
+				s++
+			case letter:
+				u++
+			}
+		}
+		variations *= caseVariations(s, u)
+	}
+	return variations
+}
+
+// caseVariations is the number of ways to pick up to min(a, b) of the a+b
+// characters, or 2 when one side is empty.
+func caseVariations(a, b int) float64 {
+	if a == 0 && b == 0 {
+		return 1
+	}
+	if a == 0 || b == 0 {
+		return 2
+	}
+	var variations float64
+	for i := 1; i <= min(a, b); i++ {
+		variations += binomial(a+b, i)
+	}
+	return variations
+}
+
+func binomial(n, k int) float64 {
+	if k < 0 || k > n {
+		return 0
+	}
+	result := 1.0
+	for i := 1; i <= k; i++ {
+		result = result * float64(n-k+i) / float64(i)
+	}
+	return result
+}
+
+func lowerRunes(pw []rune) []rune {
+	lower := make([]rune, len(pw))
+	for i, r := range pw {
+		lower[i] = unicode.ToLower(r)
+	}
+	return lower
+}
+
+func equalRunes(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,130 @@
+package password
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// effLargeWordlist is the EFF large wordlist for five-dice diceware
+// (https://www.eff.org/dice), one "rolls<TAB>word" entry per line.
+//
+//go:embed eff_large_wordlist.txt
+var effLargeWordlist string
+
+var wordlist = sync.OnceValue(func() []string {
+	lines := strings.Split(strings.TrimSpace(effLargeWordlist), "\n")
+	words := make([]string, 0, len(lines))
+	for _, line := range lines {
+		_, word, _ := strings.Cut(line, "\t")
+		words = append(words, word)
+	}
+	return words
+})
+
+const (
+	// MinWords and MaxWords bound the number of words in a passphrase.
+	MinWords = 3
+	MaxWords = 20
+	// DefaultWords gives about 77 bits of entropy with the EFF list.
+	DefaultWords = 6
+	// DefaultSeparator joins the words of a passphrase.
+	DefaultSeparator = "-"
+	// MaxSeparatorLength caps the separator, which adds no entropy.
+	MaxSeparatorLength = 5
+)
+
+var (
+	// ErrInvalidWordCount is returned for word counts outside MinWords..MaxWords.
+	ErrInvalidWordCount = fmt.Errorf("words must be between %d and %d", MinWords, MaxWords)
+	// ErrInvalidSeparator is returned for separators longer than MaxSeparatorLength.
+	ErrInvalidSeparator = fmt.Errorf("separator must be at most %d characters", MaxSeparatorLength)
+	errEmptyWordlist    = errors.New("wordlist is empty")
+)
+
+// PassphraseOptions selects the shape of a diceware passphrase.
+type PassphraseOptions struct {
+	Words     int
+	Separator string
+	// Capitalize upper-cases the first letter of every word.
+	Capitalize bool
+	// IncludeNumber appends a random digit to one randomly chosen word.
+	IncludeNumber bool
+}
+
+// DefaultPassphraseOptions is six lowercase words joined by "-".
+func DefaultPassphraseOptions() PassphraseOptions {
+	return PassphraseOptions{
+		Words:     DefaultWords,
+		Separator: DefaultSeparator,
+	}
+}
+
+// Validate checks the word count and separator length.
+func (o PassphraseOptions) Validate() error {
+	if o.Words < MinWords || o.Words > MaxWords {
+		return ErrInvalidWordCount
+	}
+	if utf8.RuneCountInString(o.Separator) > MaxSeparatorLength {
+		return ErrInvalidSeparator
+	}
+	return nil
+}
+
+// Entropy returns the entropy in bits of a passphrase generated with o,
+// assuming the attacker knows the wordlist and the options. Separator and
+// capitalization are fixed choices and add nothing; the digit adds the choice
+// of digit and of the word it is attached to.
+func (o PassphraseOptions) Entropy() float64 {
+	bits := float64(o.Words) * math.Log2(float64(len(wordlist())))
+	if o.IncludeNumber {
+		bits += math.Log2(float64(len(numberBytes))) + math.Log2(float64(o.Words))
+	}
+	return bits
+}
+
+// GeneratePassphrase returns a passphrase of words drawn uniformly from the
+// embedded EFF wordlist. A zero Words means DefaultWords.
+func GeneratePassphrase(opts PassphraseOptions) (string, error) {
+	if opts.Words == 0 {
+		opts.Words = DefaultWords
+	}
+	if err := opts.Validate(); err != nil {
+		return "", err
+	}
+
+	list := wordlist()
+	if len(list) == 0 {
+		return "", errEmptyWordlist
+	}
+
+	words := make([]string, opts.Words)
+	for i := range words {
+		n, err := randInt(len(list))
+		if err != nil {
+			return "", err
+		}
+		words[i] = list[n]
+		if opts.Capitalize {
+			words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+		}
+	}
+
+	if opts.IncludeNumber {
+		i, err := randInt(len(words))
+		if err != nil {
+			return "", err
+		}
+		digit, err := pick(numberBytes)
+		if err != nil {
+			return "", err
+		}
+		words[i] += string(digit)
+	}
+
+	return strings.Join(words, opts.Separator), nil
+}
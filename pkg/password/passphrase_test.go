@@ -0,0 +1,69 @@
+package password_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/herdiagusthio/password-manager/pkg/password"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratePassphrase(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        password.PassphraseOptions
+		wantWords   int
+		expectError error
+	}{
+		{name: "Default", opts: password.DefaultPassphraseOptions(), wantWords: 6},
+		{name: "Zero Words Uses Default", opts: password.PassphraseOptions{Separator: " "}, wantWords: 6},
+		{name: "Capitalized With Number", opts: password.PassphraseOptions{Words: 4, Separator: ".", Capitalize: true, IncludeNumber: true}, wantWords: 4},
+		{name: "Maximum Words", opts: password.PassphraseOptions{Words: 20, Separator: "_"}, wantWords: 20},
+		{name: "Too Few Words", opts: password.PassphraseOptions{Words: 2, Separator: "-"}, expectError: password.ErrInvalidWordCount},
+		{name: "Too Many Words", opts: password.PassphraseOptions{Words: 21, Separator: "-"}, expectError: password.ErrInvalidWordCount},
+		{name: "Long Separator", opts: password.PassphraseOptions{Words: 6, Separator: "------"}, expectError: password.ErrInvalidSeparator},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			phrase, err := password.GeneratePassphrase(tt.opts)
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+
+			words := strings.Split(phrase, tt.opts.Separator)
+			require.Len(t, words, tt.wantWords)
+
+			digits := 0
+			for _, word := range words {
+				require.NotEmpty(t, word)
+				assert.Equal(t, tt.opts.Capitalize, unicode.IsUpper(rune(word[0])), word)
+				for _, r := range word {
+					if unicode.IsDigit(r) {
+						digits++
+					}
+				}
+			}
+			if tt.opts.IncludeNumber {
+				assert.Equal(t, 1, digits, phrase)
+			} else {
+				assert.Zero(t, digits, phrase)
+			}
+		})
+	}
+}
+
+func TestEntropy(t *testing.T) {
+	// 7776 = 6^5 words, so each word is worth 5*log2(6) bits.
+	wordBits := 5 * math.Log2(6)
+	assert.InDelta(t, 6*wordBits, password.DefaultPassphraseOptions().Entropy(), 0.001)
+	assert.InDelta(t, 4*wordBits+math.Log2(10)+2, password.PassphraseOptions{Words: 4, IncludeNumber: true}.Entropy(), 0.001)
+
+	assert.InDelta(t, 16*math.Log2(88), password.DefaultOptions().Entropy(), 0.001)
+	assert.InDelta(t, 10*math.Log2(10), password.Options{Length: 10, Numbers: true}.Entropy(), 0.001)
+}
@@ -0,0 +1,316 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Character classes of the passwordrules syntax
+// (https://github.com/whatwg/html/issues/3518, Apple's "Password Rules").
+const (
+	specialBytes        = "-~!@#$%^&*_+=`|(){}[:;\"'<>,.? ]"
+	asciiPrintableBytes = " !\"#$%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~"
+)
+
+var ruleClasses = map[string]string{
+	"upper":           upperBytes,
+	"lower":           lowerBytes,
+	"digit":           numberBytes,
+	"special":         specialBytes,
+	"ascii-printable": asciiPrintableBytes,
+	// Generated passwords stay within ASCII even when a site accepts any
+	// Unicode character.
+	"unicode": asciiPrintableBytes,
+}
+
+var (
+	// ErrInvalidRules is returned for rule strings that do not parse.
+	ErrInvalidRules = errors.New("invalid password rules")
+	// ErrUnsatisfiableRules is returned when no password can meet the rules.
+	ErrUnsatisfiableRules = errors.New("password rules cannot be satisfied")
+)
+
+// maxRuleAttempts bounds the retries spent meeting max-consecutive.
+const maxRuleAttempts = 1000
+
+// Rules is a parsed passwordrules string such as
+// "minlength: 8; maxlength: 20; required: upper; required: digit; allowed: lower, [-_]".
+type Rules struct {
+	// Required holds one character set per "required" rule; a password must
+	// contain at least one character from each.
+	Required []string
+	// Allowed is the union of the "allowed" rules.
+	Allowed string
+	// MaxConsecutive limits runs of the same character; 0 means no limit.
+	MaxConsecutive int
+	// MinLength and MaxLength are 0 when the rules do not set them.
+	MinLength int
+	MaxLength int
+}
+
+// ParseRules parses a passwordrules string. Property and class names are
+// case-insensitive; when a length rule repeats, the strictest value wins.
+func ParseRules(s string) (*Rules, error) {
+	r := &Rules{}
+	allowed := ""
+	for _, rule := range splitRules(s) {
+		name, value, ok := strings.Cut(rule, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q is not of the form name: value", ErrInvalidRules, rule)
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+
+		switch name {
+		case "required", "allowed":
+			set, err := parseClasses(value)
+			if err != nil {
+				return nil, err
+			}
+			if name == "required" {
+				r.Required = append(r.Required, set)
+			} else {
+				allowed += set
+			}
+		case "max-consecutive", "minlength", "maxlength":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: %s must be a positive integer", ErrInvalidRules, name)
+			}
+			switch name {
+			case "max-consecutive":
+				if r.MaxConsecutive == 0 || n < r.MaxConsecutive {
+					r.MaxConsecutive = n
+				}
+			case "minlength":
+				r.MinLength = max(r.MinLength, n)
+			case "maxlength":
+				if r.MaxLength == 0 || n < r.MaxLength {
+					r.MaxLength = n
+				}
+			}
+		default:
+			return nil, fmt.Errorf("%w: unknown property %q", ErrInvalidRules, name)
+		}
+	}
+	r.Allowed = normalizeSet(allowed)
+	return r, nil
+}
+
+// splitRules splits on ";" outside custom character sets.
+func splitRules(s string) []string {
+	var rules []string
+	inSet := false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			inSet = true
+		case ']':
+			// "]]" puts a "]" at the end of the set.
+			if i+1 < len(s) && s[i+1] == ']' {
+				i++
+			}
+			inSet = false
+		case ';':
+			if !inSet {
+				rules = append(rules, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	rules = append(rules, s[start:])
+
+	var trimmed []string
+	for _, rule := range rules {
+		if rule = strings.TrimSpace(rule); rule != "" {
+			trimmed = append(trimmed, rule)
+		}
+	}
+	return trimmed
+}
+
+// parseClasses reads a comma-separated list of class names and custom sets
+// like [-().&@?'#,/"+]. In a custom set "-" may only come first and "]" only
+// last.
+func parseClasses(value string) (string, error) {
+	set := ""
+	for value != "" {
+		if value[0] == '[' {
+			end := strings.IndexByte(value[1:], ']')
+			if end < 0 {
+				return "", fmt.Errorf("%w: unterminated custom set in %q", ErrInvalidRules, value)
+			}
+			end++
+			if end+1 < len(value) && value[end+1] == ']' {
+				end++
+			}
+			custom := value[1:end]
+			for i := 0; i < len(custom); i++ {
+				c := custom[i]
+				if c < 0x20 || c > 0x7e {
+					return "", fmt.Errorf("%w: custom sets may only hold printable ASCII", ErrInvalidRules)
+				}
+				if c == '-' && i > 0 {
+					return "", fmt.Errorf("%w: \"-\" must come first in a custom set", ErrInvalidRules)
+				}
+			}
+			set += custom
+			value = strings.TrimSpace(value[end+1:])
+		} else {
+			name, rest, _ := strings.Cut(value, ",")
+			name = strings.ToLower(strings.TrimSpace(name))
+			class, ok := ruleClasses[name]
+			if !ok {
+				return "", fmt.Errorf("%w: unknown character class %q", ErrInvalidRules, name)
+			}
+			set += class
+			value = "," + rest
+			if rest == "" {
+				value = ""
+			}
+		}
+
+		if value == "" {
+			break
+		}
+		if value[0] != ',' {
+			return "", fmt.Errorf("%w: expected \",\" before %q", ErrInvalidRules, value)
+		}
+		value = strings.TrimSpace(value[1:])
+	}
+	if set == "" {
+		return "", fmt.Errorf("%w: empty character class", ErrInvalidRules)
+	}
+	return normalizeSet(set), nil
+}
+
+// normalizeSet sorts and de-duplicates the characters of set.
+func normalizeSet(set string) string {
+	b := []byte(set)
+	sort.Slice(b, func(i, j int) bool { return b[i] < b[j] })
+	out := b[:0]
+	for i, c := range b {
+		if i == 0 || c != b[i-1] {
+			out = append(out, c)
+		}
+	}
+	return string(out)
+}
+
+// Characters returns every character a password may use: the required and
+// allowed sets, or all printable ASCII when the rules name neither.
+func (r *Rules) Characters() string {
+	if len(r.Required) == 0 && r.Allowed == "" {
+		return asciiPrintableBytes
+	}
+	return normalizeSet(r.Allowed + strings.Join(r.Required, ""))
+}
+
+// LengthRange returns the lengths a generated password may have: the rules'
+// bounds, or MinLength..MaxLength where they are silent. A site limit below
+// MinLength wins over it.
+func (r *Rules) LengthRange() (int, int) {
+	hi := MaxLength
+	if r.MaxLength > 0 {
+		hi = min(r.MaxLength, MaxLength)
+	}
+	lo := min(MinLength, hi)
+	if r.MinLength > 0 {
+		lo = r.MinLength
+	}
+	return lo, hi
+}
+
+// Entropy returns the entropy in bits of a password of the given length
+// drawn from the rules' characters.
+func (r *Rules) Entropy(length int) float64 {
+	return float64(length) * math.Log2(float64(len(r.Characters())))
+}
+
+// GenerateWithRules returns a random password that satisfies r. A zero
+// length picks DefaultLength, clamped to the rules' length range.
+func GenerateWithRules(r *Rules, length int) (string, error) {
+	lo, hi := r.LengthRange()
+	if lo > hi {
+		return "", fmt.Errorf("%w: minlength %d exceeds maxlength %d", ErrUnsatisfiableRules, lo, hi)
+	}
+	if length == 0 {
+		length = min(max(DefaultLength, lo), hi)
+	}
+	if length < lo || length > hi {
+		return "", fmt.Errorf("%w: length must be between %d and %d", ErrUnsatisfiableRules, lo, hi)
+	}
+	if len(r.Required) > length {
+		return "", fmt.Errorf("%w: %d required classes do not fit in %d characters", ErrUnsatisfiableRules, len(r.Required), length)
+	}
+
+	chars := r.Characters()
+	for attempt := 0; attempt < maxRuleAttempts; attempt++ {
+		pw, ok, err := r.generate(chars, length)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return pw, nil
+		}
+	}
+	return "", fmt.Errorf("%w: cannot keep runs to %d characters", ErrUnsatisfiableRules, r.MaxConsecutive)
+}
+
+// generate fills the password left to right, reserving random positions for
+// the required classes and skipping any character that would make a run
+// longer than MaxConsecutive. It reports false if a position ran out of
+// candidates.
+func (r *Rules) generate(chars string, length int) (string, bool, error) {
+	positions := make([]int, length)
+	for i := range positions {
+		positions[i] = i
+	}
+	if err := shuffle(positions); err != nil {
+		return "", false, err
+	}
+	sets := make([]string, length)
+	for i := range sets {
+		sets[i] = chars
+	}
+	for k, set := range r.Required {
+		sets[positions[k]] = set
+	}
+
+	b := make([]byte, 0, length)
+	for _, set := range sets {
+		if r.MaxConsecutive > 0 && len(b) >= r.MaxConsecutive {
+			last := b[len(b)-1]
+			if maxRun(b[len(b)-r.MaxConsecutive:]) == r.MaxConsecutive {
+				set = strings.ReplaceAll(set, string(last), "")
+			}
+		}
+		if set == "" {
+			return "", false, nil
+		}
+		c, err := pick(set)
+		if err != nil {
+			return "", false, err
+		}
+		b = append(b, c)
+	}
+	return string(b), true, nil
+}
+
+func maxRun(b []byte) int {
+	longest, run := 0, 0
+	for i := range b {
+		if i > 0 && b[i] == b[i-1] {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+	}
+	return longest
+}
@@ -0,0 +1,124 @@
+package password_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/herdiagusthio/password-manager/pkg/password"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRules(t *testing.T) {
+	tests := []struct {
+		name        string
+		rules       string
+		expected    *password.Rules
+		expectError bool
+	}{
+		{
+			name:  "Apple Example",
+			rules: "minlength: 8; maxlength: 20; required: upper; required: digit; allowed: lower, [-_];",
+			expected: &password.Rules{
+				Required:  []string{"ABCDEFGHIJKLMNOPQRSTUVWXYZ", "0123456789"},
+				Allowed:   "-_abcdefghijklmnopqrstuvwxyz",
+				MinLength: 8,
+				MaxLength: 20,
+			},
+		},
+		{
+			name:     "Case Insensitive With Whitespace",
+			rules:    "  Required : Lower , Digit ;MAX-CONSECUTIVE:2",
+			expected: &password.Rules{Required: []string{"0123456789abcdefghijklmnopqrstuvwxyz"}, MaxConsecutive: 2},
+		},
+		{
+			name:     "Custom Set With Semicolon And Bracket",
+			rules:    "required: [-;a]]; allowed: digit",
+			expected: &password.Rules{Required: []string{"-;]a"}, Allowed: "0123456789"},
+		},
+		{
+			name:     "Strictest Length Wins",
+			rules:    "minlength: 6; minlength: 10; maxlength: 30; maxlength: 12",
+			expected: &password.Rules{MinLength: 10, MaxLength: 12},
+		},
+		{name: "Empty", rules: "", expected: &password.Rules{}},
+		{name: "Unknown Property", rules: "required: upper; colour: blue", expectError: true},
+		{name: "Unknown Class", rules: "required: emoji", expectError: true},
+		{name: "Missing Colon", rules: "required upper", expectError: true},
+		{name: "Unterminated Set", rules: "allowed: [abc", expectError: true},
+		{name: "Dash Not First", rules: "allowed: [a-z]", expectError: true},
+		{name: "Non ASCII Set", rules: "allowed: [é]", expectError: true},
+		{name: "Bad Number", rules: "maxlength: ten", expectError: true},
+		{name: "Zero Length", rules: "minlength: 0", expectError: true},
+		{name: "Empty Class", rules: "required: ", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := password.ParseRules(tt.rules)
+			if tt.expectError {
+				assert.ErrorIs(t, err, password.ErrInvalidRules)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rules)
+		})
+	}
+}
+
+func TestGenerateWithRules(t *testing.T) {
+	tests := []struct {
+		name        string
+		rules       string
+		length      int
+		wantLength  int
+		expectError error
+	}{
+		{name: "Default Length", rules: "required: upper; required: digit; allowed: lower", wantLength: 16},
+		{name: "Clamped To Maxlength", rules: "maxlength: 12; required: lower", wantLength: 12},
+		{name: "Clamped To Minlength", rules: "minlength: 24; allowed: lower", wantLength: 24},
+		{name: "Site Below Minimum", rules: "maxlength: 6; required: digit", wantLength: 6},
+		{name: "Explicit Length", rules: "required: [-_]; allowed: lower", length: 30, wantLength: 30},
+		{name: "No Classes Uses ASCII Printable", rules: "minlength: 10", wantLength: 16},
+		{name: "Max Consecutive", rules: "allowed: [ab]; max-consecutive: 1", length: 20, wantLength: 20},
+		{name: "Length Outside Rules", rules: "maxlength: 12; allowed: lower", length: 20, expectError: password.ErrUnsatisfiableRules},
+		{name: "Minlength Above Maxlength", rules: "minlength: 20; maxlength: 10", expectError: password.ErrUnsatisfiableRules},
+		{name: "Too Many Required", rules: "maxlength: 2; required: upper; required: lower; required: digit", expectError: password.ErrUnsatisfiableRules},
+		{name: "Runs Impossible", rules: "allowed: [a]; max-consecutive: 1", expectError: password.ErrUnsatisfiableRules},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := password.ParseRules(tt.rules)
+			require.NoError(t, err)
+
+			for i := 0; i < 50; i++ {
+				pw, err := password.GenerateWithRules(rules, tt.length)
+				if tt.expectError != nil {
+					assert.ErrorIs(t, err, tt.expectError)
+					return
+				}
+				require.NoError(t, err)
+				assert.Len(t, pw, tt.wantLength)
+				assertSatisfies(t, rules, pw)
+			}
+		})
+	}
+}
+
+func assertSatisfies(t *testing.T, rules *password.Rules, pw string) {
+	t.Helper()
+	chars := rules.Characters()
+	for _, c := range pw {
+		require.True(t, strings.ContainsRune(chars, c), "%q uses %q outside %q", pw, c, chars)
+	}
+	for _, required := range rules.Required {
+		assert.True(t, strings.ContainsAny(pw, required), "%q lacks one of %q", pw, required)
+	}
+	if rules.MaxConsecutive > 0 {
+		for i := rules.MaxConsecutive; i < len(pw); i++ {
+			run := pw[i-rules.MaxConsecutive : i+1]
+			assert.NotEqual(t, strings.Repeat(pw[i:i+1], len(run)), run, "%q has a run over %d", pw, rules.MaxConsecutive)
+		}
+	}
+}
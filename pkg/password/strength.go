@@ -0,0 +1,352 @@
+package password
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// MaxStrengthInput caps how many characters are analysed; anything
+	// longer is already beyond practical guessing.
+	MaxStrengthInput = 100
+
+	// minGuessesBeforeGrowingSequence penalises splitting a password into
+	// many cheap patterns, so that brute force wins for random strings.
+	minGuessesBeforeGrowingSequence = 10000
+	bruteforceCardinality           = 10
+)
+
+// Guessing rates, in guesses per second, for the crack-time scenarios.
+const (
+	onlineThrottledRate   = 100.0 / 3600
+	onlineUnthrottledRate = 10
+	offlineSlowHashRate   = 1e4
+	offlineFastHashRate   = 1e10
+)
+
+// Strength is a zxcvbn-style estimate of how hard a password is to guess.
+type Strength struct {
+	// Score runs from 0 (too guessable) to 4 (very unguessable).
+	Score int
+	// Guesses is the estimated number of guesses needed and Entropy its
+	// base-2 logarithm.
+	Guesses    float64
+	Entropy    float64
+	CrackTimes CrackTimes
+	// Sequence is the cheapest way found to build the password from patterns.
+	Sequence []*Match
+	Feedback Feedback
+}
+
+// CrackTimes estimates the time to guess a password in four attack scenarios.
+type CrackTimes struct {
+	// OnlineThrottled is an online attack limited to 100 guesses per hour.
+	OnlineThrottled CrackTime
+	// OnlineUnthrottled is an online attack at 10 guesses per second.
+	OnlineUnthrottled CrackTime
+	// OfflineSlowHashing is an offline attack on a slow hash such as Argon2.
+	OfflineSlowHashing CrackTime
+	// OfflineFastHashing is an offline attack on a fast hash with many GPUs.
+	OfflineFastHashing CrackTime
+}
+
+// CrackTime is a duration in seconds and a bucket for display, such as
+// "3 hours" or "centuries".
+type CrackTime struct {
+	Seconds float64
+	Display string
+}
+
+// Feedback explains a weak score. It is empty for scores of 3 and above.
+type Feedback struct {
+	Warning     string
+	Suggestions []string
+}
+
+// EstimateStrength scores pw by looking for dictionary words (with l33t
+// substitutions, capitalization and reversal), keyboard walks, repeats,
+// sequences and dates, and finding the cheapest combination of them.
+// userInputs such as the title or username are treated as a dictionary too.
+func EstimateStrength(pw string, userInputs ...string) Strength {
+	runes := []rune(pw)
+	if len(runes) > MaxStrengthInput {
+		runes = runes[:MaxStrengthInput]
+	}
+
+	m := newMatcher(userInputs, time.Now().Year())
+	guesses, sequence := mostGuessable(runes, m.omnimatch(runes))
+	score := guessesToScore(guesses)
+	return Strength{
+		Score:   score,
+		Guesses: guesses,
+		Entropy: math.Log2(guesses),
+		CrackTimes: CrackTimes{
+			OnlineThrottled:    crackTime(guesses, onlineThrottledRate),
+			OnlineUnthrottled:  crackTime(guesses, onlineUnthrottledRate),
+			OfflineSlowHashing: crackTime(guesses, offlineSlowHashRate),
+			OfflineFastHashing: crackTime(guesses, offlineFastHashRate),
+		},
+		Sequence: sequence,
+		Feedback: feedback(score, sequence),
+	}
+}
+
+type optimalEntry struct {
+	match   *Match
+	product float64
+	guesses float64
+}
+
+// mostGuessable finds the sequence of non-overlapping matches covering pw
+// that minimises l! * product(guesses) + D^(l-1), where l is the number of
+// matches. Gaps are filled with brute-force matches.
+func mostGuessable(pw []rune, matches []*Match) (float64, []*Match) {
+	n := len(pw)
+	if n == 0 {
+		return 1, nil
+	}
+
+	byEnd := make([][]*Match, n)
+	for _, match := range matches {
+		byEnd[match.J] = append(byEnd[match.J], match)
+	}
+	for _, ms := range byEnd {
+		sort.SliceStable(ms, func(a, b int) bool { return ms[a].I < ms[b].I })
+	}
+
+	// optimal[k][l] is the best sequence of l matches covering pw[:k+1].
+	optimal := make([]map[int]optimalEntry, n)
+	for k := range optimal {
+		optimal[k] = map[int]optimalEntry{}
+	}
+
+	update := func(match *Match, l int) {
+		k := match.J
+		product := match.Guesses
+		if l > 1 {
+			product *= optimal[match.I-1][l-1].product
+		}
+		guesses := math.Gamma(float64(l+1))*product + math.Pow(minGuessesBeforeGrowingSequence, float64(l-1))
+		for cl, e := range optimal[k] {
+			if cl <= l && e.guesses <= guesses {
+				return
+			}
+		}
+		optimal[k][l] = optimalEntry{match: match, product: product, guesses: guesses}
+	}
+
+	bruteforce := func(i, j int) *Match {
+		length := j - i + 1
+		minGuesses := 51.0
+		if length == 1 {
+			minGuesses = 11
+		}
+		return &Match{
+			Pattern: PatternBruteforce,
+			I:       i,
+			J:       j,
+			Token:   string(pw[i : j+1]),
+			Guesses: math.Max(math.Pow(bruteforceCardinality, float64(length)), minGuesses),
+		}
+	}
+
+	for k := 0; k < n; k++ {
+		for _, match := range byEnd[k] {
+			if match.I == 0 {
+				update(match, 1)
+				continue
+			}
+			for _, l := range sortedLengths(optimal[match.I-1]) {
+				update(match, l+1)
+			}
+		}
+
+		update(bruteforce(0, k), 1)
+		for i := 1; i <= k; i++ {
+			bm := bruteforce(i, k)
+			for _, l := range sortedLengths(optimal[i-1]) {
+				// Two brute-force matches in a row are always worse than one.
+				if optimal[i-1][l].match.Pattern == PatternBruteforce {
+					continue
+				}
+				update(bm, l+1)
+			}
+		}
+	}
+
+	bestL := 0
+	guesses := math.Inf(1)
+	for _, l := range sortedLengths(optimal[n-1]) {
+		if e := optimal[n-1][l]; e.guesses < guesses {
+			bestL, guesses = l, e.guesses
+		}
+	}
+
+	sequence := make([]*Match, bestL)
+	for k, l := n-1, bestL; k >= 0; l-- {
+		match := optimal[k][l].match
+		sequence[l-1] = match
+		k = match.I - 1
+	}
+	return guesses, sequence
+}
+
+func sortedLengths(entries map[int]optimalEntry) []int {
+	lengths := make([]int, 0, len(entries))
+	for l := range entries {
+		lengths = append(lengths, l)
+	}
+	sort.Ints(lengths)
+	return lengths
+}
+
+// guessesToScore buckets guesses like zxcvbn; the small delta keeps exact
+// threshold values in the lower bucket.
+func guessesToScore(guesses float64) int {
+	const delta = 5
+	switch {
+	case guesses < 1e3+delta:
+		return 0
+	case guesses < 1e6+delta:
+		return 1
+	case guesses < 1e8+delta:
+		return 2
+	case guesses < 1e10+delta:
+		return 3
+	}
+	return 4
+}
+
+func crackTime(guesses, rate float64) CrackTime {
+	seconds := guesses / rate
+	return CrackTime{Seconds: seconds, Display: displayTime(seconds)}
+}
+
+func displayTime(seconds float64) string {
+	const (
+		minute  = 60
+		hour    = minute * 60
+		day     = hour * 24
+		month   = day * 31
+		year    = month * 12
+		century = year * 100
+	)
+	units := []struct {
+		size float64
+		name string
+	}{
+		{year, "year"},
+		{month, "month"},
+		{day, "day"},
+		{hour, "hour"},
+		{minute, "minute"},
+		{1, "second"},
+	}
+
+	switch {
+	case seconds < 1:
+		return "less than a second"
+	case seconds >= century:
+		return "centuries"
+	}
+	for _, u := range units {
+		if seconds < u.size {
+			continue
+		}
+		n := int(math.Round(seconds / u.size))
+		if n == 1 {
+			return "1 " + u.name
+		}
+		return strconv.Itoa(n) + " " + u.name + "s"
+	}
+	return "less than a second"
+}
+
+func feedback(score int, sequence []*Match) Feedback {
+	if len(sequence) == 0 {
+		return Feedback{Suggestions: []string{
+			"Use a few words, avoid common phrases",
+			"No need for symbols, digits, or uppercase letters",
+		}}
+	}
+	if score > 2 {
+		return Feedback{}
+	}
+
+	longest := sequence[0]
+	for _, match := range sequence[1:] {
+		if len(match.Token) > len(longest.Token) {
+			longest = match
+		}
+	}
+
+	f := matchFeedback(longest, len(sequence) == 1)
+	f.Suggestions = append([]string{"Add another word or two. Uncommon words are better."}, f.Suggestions...)
+	return f
+}
+
+func matchFeedback(match *Match, soleMatch bool) Feedback {
+	switch match.Pattern {
+	case PatternDictionary:
+		return dictionaryFeedback(match, soleMatch)
+	case PatternSpatial:
+		warning := "Short keyboard patterns are easy to guess"
+		if match.Turns == 1 {
+			warning = "Straight rows of keys are easy to guess"
+		}
+		return Feedback{Warning: warning, Suggestions: []string{"Use a longer keyboard pattern with more turns"}}
+	case PatternRepeat:
+		warning := `Repeats like "abcabcabc" are only slightly harder to guess than "abc"`
+		if len([]rune(match.BaseToken)) == 1 {
+			warning = `Repeats like "aaa" are easy to guess`
+		}
+		return Feedback{Warning: warning, Suggestions: []string{"Avoid repeated words and characters"}}
+	case PatternSequence:
+		return Feedback{Warning: "Sequences like abc or 6543 are easy to guess", Suggestions: []string{"Avoid sequences"}}
+	case PatternDate:
+		return Feedback{Warning: "Dates are often easy to guess", Suggestions: []string{"Avoid dates and years that are associated with you"}}
+	}
+	return Feedback{}
+}
+
+func dictionaryFeedback(match *Match, soleMatch bool) Feedback {
+	var f Feedback
+	switch match.Dictionary {
+	case DictionaryPasswords:
+		switch {
+		case soleMatch && !match.L33t && !match.Reversed && match.Rank <= 10:
+			f.Warning = "This is a top-10 common password"
+		case soleMatch && !match.L33t && !match.Reversed && match.Rank <= 100:
+			f.Warning = "This is a top-100 common password"
+		case soleMatch && !match.L33t && !match.Reversed:
+			f.Warning = "This is a very common password"
+		default:
+			f.Warning = "This is similar to a commonly used password"
+		}
+	case DictionaryWords:
+		if soleMatch {
+			f.Warning = "A word by itself is easy to guess"
+		}
+	case DictionaryUserInputs:
+		f.Warning = "Avoid using the title or username in the password"
+	}
+
+	token := []rune(match.Token)
+	switch {
+	case strings.ToUpper(match.Token) == match.Token && strings.ToLower(match.Token) != match.Token:
+		f.Suggestions = append(f.Suggestions, "All-uppercase is almost as easy to guess as all-lowercase")
+	case len(token) > 0 && unicode.IsUpper(token[0]):
+		f.Suggestions = append(f.Suggestions, "Capitalization doesn't help very much")
+	}
+	if match.Reversed && len(token) >= 4 {
+		f.Suggestions = append(f.Suggestions, "Reversed words aren't much harder to guess")
+	}
+	if match.L33t {
+		f.Suggestions = append(f.Suggestions, "Predictable substitutions like '@' instead of 'a' don't help very much")
+	}
+	return f
+}
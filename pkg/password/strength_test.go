@@ -0,0 +1,158 @@
+package password_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/herdiagusthio/password-manager/pkg/password"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateStrength(t *testing.T) {
+	tests := []struct {
+		name        string
+		password    string
+		userInputs  []string
+		maxScore    int
+		minScore    int
+		wantPattern string
+		wantToken   string
+		check       func(t *testing.T, m *password.Match)
+	}{
+		{
+			name: "Common Password", password: "password", maxScore: 0,
+			wantPattern: password.PatternDictionary, wantToken: "password",
+			check: func(t *testing.T, m *password.Match) {
+				assert.Equal(t, password.DictionaryPasswords, m.Dictionary)
+				assert.Equal(t, 2, m.Rank)
+			},
+		},
+		{
+			name: "L33t Substitution", password: "P@ssw0rd", maxScore: 0,
+			wantPattern: password.PatternDictionary, wantToken: "P@ssw0rd",
+			check: func(t *testing.T, m *password.Match) {
+				assert.True(t, m.L33t)
+				assert.Equal(t, "password", m.MatchedWord)
+				assert.Equal(t, map[rune]rune{'@': 'a', '0': 'o'}, m.Sub)
+			},
+		},
+		{
+			name: "Reversed Word", password: "drowssap", maxScore: 0,
+			wantPattern: password.PatternDictionary, wantToken: "drowssap",
+			check: func(t *testing.T, m *password.Match) { assert.True(t, m.Reversed) },
+		},
+		{
+			name: "Keyboard Walk", password: "zxcvbnm,./", maxScore: 1,
+			wantPattern: password.PatternSpatial, wantToken: "zxcvbnm,./",
+			check: func(t *testing.T, m *password.Match) { assert.Equal(t, 1, m.Turns) },
+		},
+		{
+			name: "Keyboard Walk With Turns", password: "1qaz@WSX", maxScore: 2,
+			wantPattern: password.PatternSpatial,
+		},
+		{
+			name: "Repeated Character", password: "aaaaaaaaaa", maxScore: 0,
+			wantPattern: password.PatternRepeat, wantToken: "aaaaaaaaaa",
+			check: func(t *testing.T, m *password.Match) {
+				assert.Equal(t, "a", m.BaseToken)
+				assert.Equal(t, 10, m.RepeatCount)
+			},
+		},
+		{
+			name: "Repeated Block", password: "xkq7xkq7xkq7", maxScore: 2,
+			wantPattern: password.PatternRepeat, wantToken: "xkq7xkq7xkq7",
+			check: func(t *testing.T, m *password.Match) { assert.Equal(t, "xkq7", m.BaseToken) },
+		},
+		{
+			name: "Sequence", password: "lmnopqrs", maxScore: 0,
+			wantPattern: password.PatternSequence, wantToken: "lmnopqrs",
+			check: func(t *testing.T, m *password.Match) { assert.True(t, m.Ascending) },
+		},
+		{
+			name: "Date With Separators", password: "12/25/1990", maxScore: 1,
+			wantPattern: password.PatternDate, wantToken: "12/25/1990",
+			check: func(t *testing.T, m *password.Match) {
+				assert.Equal(t, [3]int{1990, 12, 25}, [3]int{m.Year, m.Month, m.Day})
+				assert.Equal(t, "/", m.Separator)
+			},
+		},
+		{
+			name: "Date Without Separators", password: "19900101", maxScore: 1,
+			wantPattern: password.PatternDate, wantToken: "19900101",
+			check: func(t *testing.T, m *password.Match) {
+				assert.Equal(t, [3]int{1990, 1, 1}, [3]int{m.Year, m.Month, m.Day})
+			},
+		},
+		{
+			name: "User Input", password: "alice2024", userInputs: []string{"alice@example.com"}, maxScore: 1,
+			wantPattern: password.PatternDictionary, wantToken: "alice",
+			check: func(t *testing.T, m *password.Match) {
+				assert.Equal(t, password.DictionaryUserInputs, m.Dictionary)
+			},
+		},
+		{name: "Random String", password: "x7$Kp2!qLm9#Vw4z", minScore: 4, maxScore: 4},
+		{name: "Long Passphrase", password: "correct-horse-battery-staple", minScore: 4, maxScore: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := password.EstimateStrength(tt.password, tt.userInputs...)
+			assert.GreaterOrEqual(t, s.Score, tt.minScore)
+			assert.LessOrEqual(t, s.Score, tt.maxScore)
+			assert.Greater(t, s.Guesses, 0.0)
+
+			covered := ""
+			for _, m := range s.Sequence {
+				covered += m.Token
+			}
+			assert.Equal(t, tt.password, covered, "sequence must cover the password")
+
+			if tt.wantPattern == "" {
+				return
+			}
+			var found *password.Match
+			for _, m := range s.Sequence {
+				if m.Pattern == tt.wantPattern && (tt.wantToken == "" || m.Token == tt.wantToken) {
+					found = m
+				}
+			}
+			require.NotNil(t, found, "no %s match for %q in %+v", tt.wantPattern, tt.wantToken, s.Sequence)
+			if tt.check != nil {
+				tt.check(t, found)
+			}
+		})
+	}
+}
+
+func TestEstimateStrength_Feedback(t *testing.T) {
+	weak := password.EstimateStrength("password")
+	assert.Equal(t, "This is a top-10 common password", weak.Feedback.Warning)
+	assert.NotEmpty(t, weak.Feedback.Suggestions)
+	assert.Equal(t, "less than a second", weak.CrackTimes.OfflineFastHashing.Display)
+
+	strong := password.EstimateStrength("x7$Kp2!qLm9#Vw4z")
+	assert.Empty(t, strong.Feedback.Warning)
+	assert.Empty(t, strong.Feedback.Suggestions)
+	assert.Equal(t, "centuries", strong.CrackTimes.OnlineThrottled.Display)
+	assert.InDelta(t, 16*3.3219, strong.Entropy, 0.01)
+
+	empty := password.EstimateStrength("")
+	assert.Equal(t, 0, empty.Score)
+	assert.Zero(t, empty.Entropy)
+
+	long := password.EstimateStrength(strings.Repeat("x7$Kp2!q", 40))
+	assert.Equal(t, 4, long.Score)
+}
+
+func TestEstimateStrength_GeneratedPasswordsAreStrong(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		pw, err := password.Generate(password.DefaultOptions())
+		require.NoError(t, err)
+		assert.Equal(t, 4, password.EstimateStrength(pw).Score, pw)
+
+		phrase, err := password.GeneratePassphrase(password.DefaultPassphraseOptions())
+		require.NoError(t, err)
+		assert.Equal(t, 4, password.EstimateStrength(phrase).Score, phrase)
+	}
+}
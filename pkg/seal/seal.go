@@ -0,0 +1,214 @@
+// Package seal stores the master keyring on disk encrypted with an unseal key
+// that exists only as Shamir shares held by different operators. The server
+// starts sealed and can decrypt nothing until enough shares are provided.
+package seal
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/herdiagusthio/password-manager/pkg/crypto"
+	"github.com/herdiagusthio/password-manager/pkg/shamir"
+)
+
+const (
+	fileVersion   = 1
+	unsealKeySize = 32
+	unsealKeyID   = "unseal"
+	// ShareSize is the decoded size of a share: the key plus its x coordinate.
+	ShareSize = unsealKeySize + 1
+)
+
+var sealContext = []byte("sealed_keyring")
+
+// ErrInvalidShares is returned when the combined shares do not decrypt the keyring.
+var ErrInvalidShares = errors.New("seal: shares do not match the sealed keyring")
+
+// File is the on-disk form of a sealed keyring.
+type File struct {
+	Version   int       `json:"version"`
+	Shares    int       `json:"shares"`
+	Threshold int       `json:"threshold"`
+	Keyring   string    `json:"keyring"` // Keys, encrypted with the unseal key
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Keys is the plaintext content of a sealed keyring.
+type Keys struct {
+	CurrentID string            `json:"current_id"`
+	Keys      map[string][]byte `json:"keys"`
+}
+
+// Validate checks that the keys form a valid keyring.
+func (k *Keys) Validate() error {
+	_, err := crypto.NewStaticProvider(k.CurrentID, k.Keys)
+	return err
+}
+
+// Init seals keys under a new unseal key split into n shares, threshold of
+// which are needed to unseal. The shares are returned base64 encoded.
+func Init(keys *Keys, n, threshold int) (*File, []string, error) {
+	if err := keys.Validate(); err != nil {
+		return nil, nil, err
+	}
+	unsealKey := make([]byte, unsealKeySize)
+	if _, err := rand.Read(unsealKey); err != nil {
+		return nil, nil, err
+	}
+	parts, err := shamir.Split(unsealKey, n, threshold)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f := &File{Version: fileVersion, Shares: n, Threshold: threshold, CreatedAt: time.Now().UTC()}
+	if err := f.encrypt(unsealKey, keys); err != nil {
+		return nil, nil, err
+	}
+
+	shares := make([]string, len(parts))
+	for i, p := range parts {
+		shares[i] = base64.StdEncoding.EncodeToString(p)
+	}
+	return f, shares, nil
+}
+
+func (f *File) encrypt(unsealKey []byte, keys *Keys) error {
+	data, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+	sealed, err := crypto.EncryptWithKey(data, unsealKeyID, unsealKey, sealContext)
+	if err != nil {
+		return err
+	}
+	f.Keyring = sealed
+	return nil
+}
+
+// DecodeShare parses a base64 share.
+func DecodeShare(share string) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(share)
+	if err != nil || len(raw) != ShareSize {
+		return nil, errors.New("seal: malformed share")
+	}
+	return raw, nil
+}
+
+func (f *File) unsealKey(shares [][]byte) ([]byte, error) {
+	if len(shares) < f.Threshold {
+		return nil, fmt.Errorf("seal: %d of %d shares provided", len(shares), f.Threshold)
+	}
+	return shamir.Combine(shares)
+}
+
+// Open combines decoded shares and decrypts the keyring.
+func (f *File) Open(shares [][]byte) (*Keys, error) {
+	unsealKey, err := f.unsealKey(shares)
+	if err != nil {
+		return nil, err
+	}
+	return f.open(unsealKey)
+}
+
+func (f *File) open(unsealKey []byte) (*Keys, error) {
+	lookup := func(id string) ([]byte, error) {
+		if id != unsealKeyID {
+			return nil, crypto.ErrUnknownKeyID
+		}
+		return unsealKey, nil
+	}
+	data, err := crypto.DecryptWithKeys(f.Keyring, sealContext, lookup)
+	if err != nil {
+		return nil, ErrInvalidShares
+	}
+	var keys Keys
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("seal: invalid keyring: %w", err)
+	}
+	return &keys, nil
+}
+
+// Rekey opens the file with the current shares and seals the same keys under
+// a new unseal key with a new share layout. Old shares stop working.
+func (f *File) Rekey(shares [][]byte, n, threshold int) (*File, []string, error) {
+	keys, err := f.Open(shares)
+	if err != nil {
+		return nil, nil, err
+	}
+	return Init(keys, n, threshold)
+}
+
+// Update opens the file, lets fn change the keys (e.g. to add a new master
+// key), and seals them again under the same unseal key, so shares stay valid.
+func (f *File) Update(shares [][]byte, fn func(keys *Keys) error) (*File, error) {
+	unsealKey, err := f.unsealKey(shares)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := f.open(unsealKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := fn(keys); err != nil {
+		return nil, err
+	}
+	if err := keys.Validate(); err != nil {
+		return nil, err
+	}
+
+	updated := *f
+	if err := updated.encrypt(unsealKey, keys); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// Load reads a sealed keyring file.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("seal: %s: %w", path, err)
+	}
+	if f.Version != fileVersion {
+		return nil, fmt.Errorf("seal: %s: unsupported version %d", path, f.Version)
+	}
+	if f.Threshold < 2 || f.Threshold > f.Shares {
+		return nil, fmt.Errorf("seal: %s: invalid threshold %d of %d", path, f.Threshold, f.Shares)
+	}
+	return &f, nil
+}
+
+// Save writes the file atomically, readable by the owner only.
+func (f *File) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".sealed-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,95 @@
+package seal_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/herdiagusthio/password-manager/pkg/seal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decode(t *testing.T, shares ...string) [][]byte {
+	decoded := make([][]byte, len(shares))
+	for i, s := range shares {
+		raw, err := seal.DecodeShare(s)
+		require.NoError(t, err)
+		decoded[i] = raw
+	}
+	return decoded
+}
+
+func testKeys() *seal.Keys {
+	return &seal.Keys{
+		CurrentID: "2",
+		Keys: map[string][]byte{
+			"1": []byte("11111111111111111111111111111111"),
+			"2": []byte("22222222222222222222222222222222"),
+		},
+	}
+}
+
+func TestInitAndOpen(t *testing.T) {
+	f, shares, err := seal.Init(testKeys(), 5, 3)
+	require.NoError(t, err)
+	require.Len(t, shares, 5)
+
+	path := filepath.Join(t.TempDir(), "sealed.json")
+	require.NoError(t, f.Save(path))
+	loaded, err := seal.Load(path)
+	require.NoError(t, err)
+
+	keys, err := loaded.Open(decode(t, shares[4], shares[0], shares[2]))
+	require.NoError(t, err)
+	assert.Equal(t, testKeys(), keys)
+
+	_, err = loaded.Open(decode(t, shares[0], shares[1]))
+	assert.Error(t, err, "below threshold")
+
+	other, otherShares, err := seal.Init(testKeys(), 5, 3)
+	require.NoError(t, err)
+	require.NotNil(t, other)
+	_, err = loaded.Open(decode(t, shares[0], shares[1], otherShares[2]))
+	assert.ErrorIs(t, err, seal.ErrInvalidShares)
+}
+
+func TestInit_InvalidKeys(t *testing.T) {
+	_, _, err := seal.Init(&seal.Keys{CurrentID: "1", Keys: map[string][]byte{"1": []byte("short")}}, 3, 2)
+	assert.Error(t, err)
+	_, _, err = seal.Init(testKeys(), 3, 4)
+	assert.Error(t, err)
+}
+
+func TestRekey(t *testing.T) {
+	f, shares, err := seal.Init(testKeys(), 3, 2)
+	require.NoError(t, err)
+
+	rekeyed, newShares, err := f.Rekey(decode(t, shares[0], shares[1]), 5, 4)
+	require.NoError(t, err)
+	assert.Equal(t, 4, rekeyed.Threshold)
+
+	keys, err := rekeyed.Open(decode(t, newShares[0], newShares[1], newShares[2], newShares[3]))
+	require.NoError(t, err)
+	assert.Equal(t, testKeys(), keys)
+
+	_, err = rekeyed.Open(decode(t, shares[0], shares[1], shares[2]))
+	assert.Error(t, err, "old shares must stop working")
+}
+
+func TestUpdate(t *testing.T) {
+	f, shares, err := seal.Init(testKeys(), 3, 2)
+	require.NoError(t, err)
+
+	updated, err := f.Update(decode(t, shares[0], shares[2]), func(keys *seal.Keys) error {
+		keys.Keys["3"] = []byte("33333333333333333333333333333333")
+		keys.CurrentID = "3"
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The same shares unseal the updated file.
+	keys, err := updated.Open(decode(t, shares[1], shares[2]))
+	require.NoError(t, err)
+	assert.Equal(t, "3", keys.CurrentID)
+	assert.Len(t, keys.Keys, 3)
+}
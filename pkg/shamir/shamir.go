@@ -0,0 +1,175 @@
+// Package shamir implements Shamir's secret sharing over GF(2^8).
+//
+// Each byte of the secret is the constant term of a random polynomial of
+// degree threshold-1; a share holds the polynomial values at one non-zero x
+// coordinate, which is appended as the share's last byte. Any threshold shares
+// recover the secret by Lagrange interpolation at x = 0, fewer reveal nothing.
+package shamir
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// MaxShares is the number of distinct non-zero x coordinates in GF(2^8).
+const MaxShares = 255
+
+var (
+	ErrTooFewShares     = errors.New("shamir: at least two shares are required")
+	ErrShareLength      = errors.New("shamir: shares have different or invalid lengths")
+	ErrDuplicateShare   = errors.New("shamir: duplicate share")
+	ErrInvalidThreshold = errors.New("shamir: threshold must be between 2 and the number of shares")
+)
+
+// Split divides secret into n shares, any threshold of which recover it.
+func Split(secret []byte, n, threshold int) ([][]byte, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("shamir: secret must not be empty")
+	}
+	if n < 2 || n > MaxShares {
+		return nil, fmt.Errorf("shamir: number of shares must be 2-%d", MaxShares)
+	}
+	if threshold < 2 || threshold > n {
+		return nil, ErrInvalidThreshold
+	}
+
+	// Random, distinct x coordinates so that shares do not reveal their position.
+	xs, err := randomCoordinates(n)
+	if err != nil {
+		return nil, err
+	}
+
+	shares := make([][]byte, n)
+	for i := range shares {
+		shares[i] = make([]byte, len(secret)+1)
+		shares[i][len(secret)] = xs[i]
+	}
+
+	coeffs := make([]byte, threshold)
+	for b, s := range secret {
+		coeffs[0] = s
+		if _, err := rand.Read(coeffs[1:]); err != nil {
+			return nil, err
+		}
+		for i, x := range xs {
+			shares[i][b] = evaluate(coeffs, x)
+		}
+	}
+	return shares, nil
+}
+
+// Combine recovers the secret from threshold or more shares. Combining fewer
+// shares than the threshold yields garbage, not an error, so callers must
+// authenticate the result.
+func Combine(shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, ErrTooFewShares
+	}
+	size := len(shares[0])
+	if size < 2 {
+		return nil, ErrShareLength
+	}
+	xs := make([]byte, len(shares))
+	seen := make(map[byte]bool, len(shares))
+	for i, share := range shares {
+		if len(share) != size {
+			return nil, ErrShareLength
+		}
+		x := share[size-1]
+		if x == 0 {
+			return nil, errors.New("shamir: invalid share coordinate")
+		}
+		if seen[x] {
+			return nil, ErrDuplicateShare
+		}
+		seen[x] = true
+		xs[i] = x
+	}
+
+	secret := make([]byte, size-1)
+	ys := make([]byte, len(shares))
+	for b := range secret {
+		for i, share := range shares {
+			ys[i] = share[b]
+		}
+		secret[b] = interpolateAtZero(xs, ys)
+	}
+	return secret, nil
+}
+
+func randomCoordinates(n int) ([]byte, error) {
+	// Shuffle 1..255 and take the first n.
+	all := make([]byte, MaxShares)
+	for i := range all {
+		all[i] = byte(i + 1)
+	}
+	random := make([]byte, MaxShares)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	for i := len(all) - 1; i > 0; i-- {
+		j := int(random[i]) % (i + 1)
+		all[i], all[j] = all[j], all[i]
+	}
+	return all[:n], nil
+}
+
+// evaluate computes the polynomial with the given coefficients at x (Horner).
+func evaluate(coeffs []byte, x byte) byte {
+	var y byte
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		y = add(mul(y, x), coeffs[i])
+	}
+	return y
+}
+
+func interpolateAtZero(xs, ys []byte) byte {
+	var result byte
+	for i := range xs {
+		// Lagrange basis polynomial i at 0: prod(x_j / (x_j - x_i)), j != i.
+		basis := byte(1)
+		for j := range xs {
+			if i == j {
+				continue
+			}
+			basis = mul(basis, div(xs[j], add(xs[j], xs[i])))
+		}
+		result = add(result, mul(ys[i], basis))
+	}
+	return result
+}
+
+// GF(2^8) arithmetic with the AES polynomial x^8 + x^4 + x^3 + x + 1.
+
+func add(a, b byte) byte {
+	return a ^ b
+}
+
+// mul multiplies without data-dependent branches, since shares are secret.
+func mul(a, b byte) byte {
+	var p byte
+	for i := 0; i < 8; i++ {
+		p ^= a & -(b & 1)
+		carry := a >> 7
+		a = a<<1 ^ 0x1b&-carry
+		b >>= 1
+	}
+	return p
+}
+
+// inverse returns a^-1 as a^254, since a^255 = 1 for every non-zero a.
+func inverse(a byte) byte {
+	result := byte(1)
+	for i := 0; i < 254; i++ {
+		result = mul(result, a)
+	}
+	return result
+}
+
+func div(a, b byte) byte {
+	if b == 0 {
+		panic("shamir: division by zero")
+	}
+	return mul(a, inverse(b))
+}
@@ -0,0 +1,73 @@
+package shamir
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitCombine(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	tests := []struct {
+		name      string
+		n         int
+		threshold int
+		use       []int // Indexes of the shares to combine
+		recovered bool
+	}{
+		{name: "Threshold Shares", n: 5, threshold: 3, use: []int{0, 2, 4}, recovered: true},
+		{name: "All Shares", n: 5, threshold: 3, use: []int{0, 1, 2, 3, 4}, recovered: true},
+		{name: "Other Subset", n: 5, threshold: 3, use: []int{4, 1, 3}, recovered: true},
+		{name: "Too Few Shares", n: 5, threshold: 3, use: []int{1, 3}, recovered: false},
+		{name: "Two Of Two", n: 2, threshold: 2, use: []int{0, 1}, recovered: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares, err := Split(secret, tt.n, tt.threshold)
+			require.NoError(t, err)
+			require.Len(t, shares, tt.n)
+
+			subset := make([][]byte, 0, len(tt.use))
+			for _, i := range tt.use {
+				subset = append(subset, shares[i])
+			}
+			got, err := Combine(subset)
+			require.NoError(t, err)
+			assert.Equal(t, tt.recovered, bytes.Equal(secret, got))
+		})
+	}
+}
+
+func TestSplit_InvalidParameters(t *testing.T) {
+	_, err := Split([]byte("secret"), 3, 4)
+	assert.ErrorIs(t, err, ErrInvalidThreshold)
+	_, err = Split([]byte("secret"), 3, 1)
+	assert.ErrorIs(t, err, ErrInvalidThreshold)
+	_, err = Split([]byte("secret"), 256, 2)
+	assert.Error(t, err)
+	_, err = Split(nil, 3, 2)
+	assert.Error(t, err)
+}
+
+func TestCombine_InvalidShares(t *testing.T) {
+	shares, err := Split([]byte("secret"), 3, 2)
+	require.NoError(t, err)
+
+	_, err = Combine(shares[:1])
+	assert.ErrorIs(t, err, ErrTooFewShares)
+	_, err = Combine([][]byte{shares[0], shares[0]})
+	assert.ErrorIs(t, err, ErrDuplicateShare)
+	_, err = Combine([][]byte{shares[0], shares[1][:3]})
+	assert.ErrorIs(t, err, ErrShareLength)
+}
+
+func TestFieldArithmetic(t *testing.T) {
+	for a := 1; a < 256; a++ {
+		assert.Equal(t, byte(1), mul(byte(a), inverse(byte(a))), "a=%d", a)
+	}
+	assert.Equal(t, byte(0xc1), mul(0x57, 0x83)) // FIPS-197 example
+}
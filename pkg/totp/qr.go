@@ -0,0 +1,53 @@
+package totp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // register GIF decoding
+	_ "image/jpeg" // register JPEG decoding
+	_ "image/png"  // register PNG decoding
+	"io"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
+)
+
+// MaxImageSide caps the width and height of QR code images, so a small file
+// cannot decode into a huge bitmap.
+const MaxImageSide = 4096
+
+// ErrNoQRCode is returned when an image holds no readable QR code.
+var ErrNoQRCode = errors.New("totp: no QR code found in the image")
+
+// DecodeQR reads a PNG, JPEG or GIF image and returns the text of the QR code
+// in it, e.g. the otpauth:// URI a site shows when enabling two-factor login.
+func DecodeQR(r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("totp: read image: %w", err)
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrNoQRCode, err)
+	}
+	if config.Width > MaxImageSide || config.Height > MaxImageSide {
+		return "", fmt.Errorf("%w: the image is larger than %dx%d", ErrNoQRCode, MaxImageSide, MaxImageSide)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrNoQRCode, err)
+	}
+
+	bitmap, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrNoQRCode, err)
+	}
+	hints := map[gozxing.DecodeHintType]interface{}{gozxing.DecodeHintType_TRY_HARDER: true}
+	result, err := qrcode.NewQRCodeReader().Decode(bitmap, hints)
+	if err != nil {
+		return "", ErrNoQRCode
+	}
+	return result.GetText(), nil
+}
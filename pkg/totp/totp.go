@@ -0,0 +1,175 @@
+// Package totp computes time-based one-time passwords (RFC 6238) from a
+// base32 seed or an otpauth:// URI, as used by authenticator apps.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Algorithms supported for the HMAC.
+const (
+	SHA1   = "SHA1"
+	SHA256 = "SHA256"
+	SHA512 = "SHA512"
+)
+
+const (
+	DefaultDigits = 6
+	DefaultPeriod = 30
+	// MaxPeriod caps the period at one day.
+	MaxPeriod = 24 * 60 * 60
+	// minSecretSize is 64 bits, below the 128 bits RFC 4226 asks for: many
+	// issuers still hand out 80-bit seeds, and those must be accepted.
+	minSecretSize = 8
+)
+
+// ErrInvalidKey is returned for seeds and URIs that cannot generate codes.
+var ErrInvalidKey = errors.New("totp: invalid key")
+
+// Key is a TOTP shared secret with its parameters.
+type Key struct {
+	Secret    []byte
+	Algorithm string
+	Digits    int
+	Period    int
+	// Issuer and Account label the key in authenticator apps.
+	Issuer  string
+	Account string
+}
+
+// Parse reads an otpauth://totp/ URI, or a bare base32 seed with the default
+// parameters (SHA1, 6 digits, 30 seconds).
+func Parse(value string) (*Key, error) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(strings.ToLower(value), "otpauth:") {
+		secret, err := decodeSecret(value)
+		if err != nil {
+			return nil, err
+		}
+		return &Key{Secret: secret, Algorithm: SHA1, Digits: DefaultDigits, Period: DefaultPeriod}, nil
+	}
+
+	u, err := url.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	if !strings.EqualFold(u.Host, "totp") {
+		return nil, fmt.Errorf("%w: only otpauth://totp/ URIs are supported", ErrInvalidKey)
+	}
+	query := u.Query()
+	secret, err := decodeSecret(query.Get("secret"))
+	if err != nil {
+		return nil, err
+	}
+	key := &Key{Secret: secret, Algorithm: SHA1, Digits: DefaultDigits, Period: DefaultPeriod}
+
+	if alg := query.Get("algorithm"); alg != "" {
+		key.Algorithm = strings.ToUpper(alg)
+		if key.Algorithm != SHA1 && key.Algorithm != SHA256 && key.Algorithm != SHA512 {
+			return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidKey, alg)
+		}
+	}
+	if digits := query.Get("digits"); digits != "" {
+		key.Digits, err = strconv.Atoi(digits)
+		if err != nil || key.Digits < 6 || key.Digits > 10 {
+			return nil, fmt.Errorf("%w: digits must be 6 to 10", ErrInvalidKey)
+		}
+	}
+	if period := query.Get("period"); period != "" {
+		key.Period, err = strconv.Atoi(period)
+		if err != nil || key.Period < 1 || key.Period > MaxPeriod {
+			return nil, fmt.Errorf("%w: period must be 1 to %d seconds", ErrInvalidKey, MaxPeriod)
+		}
+	}
+
+	// The label is "Issuer:account" or just "account"; the issuer parameter wins.
+	label := strings.TrimPrefix(u.Path, "/")
+	if issuer, account, found := strings.Cut(label, ":"); found {
+		key.Issuer = strings.TrimSpace(issuer)
+		key.Account = strings.TrimSpace(account)
+	} else {
+		key.Account = strings.TrimSpace(label)
+	}
+	if issuer := query.Get("issuer"); issuer != "" {
+		key.Issuer = issuer
+	}
+	return key, nil
+}
+
+// decodeSecret decodes a base32 seed, ignoring case, spaces and padding.
+func decodeSecret(value string) ([]byte, error) {
+	value = strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(value))
+	value = strings.TrimRight(value, "=")
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(value)
+	if err != nil || len(secret) == 0 {
+		return nil, fmt.Errorf("%w: the secret must be base32", ErrInvalidKey)
+	}
+	if len(secret) < minSecretSize {
+		return nil, fmt.Errorf("%w: the secret is too short", ErrInvalidKey)
+	}
+	return secret, nil
+}
+
+// URI returns the key as an otpauth://totp/ URI.
+func (k *Key) URI() string {
+	query := url.Values{}
+	query.Set("secret", base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(k.Secret))
+	query.Set("algorithm", k.Algorithm)
+	query.Set("digits", strconv.Itoa(k.Digits))
+	query.Set("period", strconv.Itoa(k.Period))
+	label := k.Account
+	if k.Issuer != "" {
+		query.Set("issuer", k.Issuer)
+		label = k.Issuer + ":" + k.Account
+	}
+	u := url.URL{Scheme: "otpauth", Host: "totp", Path: "/" + label, RawQuery: query.Encode()}
+	return u.String()
+}
+
+// Code returns the code valid at t and the seconds it stays valid.
+func (k *Key) Code(t time.Time) (string, int) {
+	unix := t.Unix()
+	counter := uint64(unix / int64(k.Period))
+	remaining := k.Period - int(unix%int64(k.Period))
+	return k.hotp(counter), remaining
+}
+
+// hotp is RFC 4226's HMAC-based one-time password for a counter.
+func (k *Key) hotp(counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac := hmac.New(k.hash(), k.Secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := uint64(binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff)
+	mod := uint64(1)
+	for i := 0; i < k.Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", k.Digits, value%mod)
+}
+
+func (k *Key) hash() func() hash.Hash {
+	switch k.Algorithm {
+	case SHA256:
+		return sha256.New
+	case SHA512:
+		return sha512.New
+	default:
+		return sha1.New
+	}
+}
@@ -0,0 +1,119 @@
+package totp
+
+import (
+	"bytes"
+	"encoding/base32"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCode runs the test vectors from RFC 6238, Appendix B.
+func TestCode(t *testing.T) {
+	seeds := map[string]string{
+		SHA1:   "12345678901234567890",
+		SHA256: "12345678901234567890123456789012",
+		SHA512: "1234567890123456789012345678901234567890123456789012345678901234",
+	}
+	tests := []struct {
+		unix      int64
+		algorithm string
+		code      string
+	}{
+		{59, SHA1, "94287082"},
+		{59, SHA256, "46119246"},
+		{59, SHA512, "90693936"},
+		{1111111109, SHA1, "07081804"},
+		{1111111109, SHA256, "68084774"},
+		{1111111109, SHA512, "25091201"},
+		{1234567890, SHA1, "89005924"},
+		{1234567890, SHA256, "91819424"},
+		{1234567890, SHA512, "93441116"},
+		{2000000000, SHA1, "69279037"},
+		{2000000000, SHA256, "90698825"},
+		{2000000000, SHA512, "38618901"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.algorithm+"@"+time.Unix(tt.unix, 0).UTC().Format(time.RFC3339), func(t *testing.T) {
+			key := &Key{Secret: []byte(seeds[tt.algorithm]), Algorithm: tt.algorithm, Digits: 8, Period: 30}
+			code, remaining := key.Code(time.Unix(tt.unix, 0))
+			assert.Equal(t, tt.code, code)
+			assert.Equal(t, 30-int(tt.unix%30), remaining)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	seed := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	t.Run("Bare Seed", func(t *testing.T) {
+		// Authenticator setup pages show seeds lowercased in groups of four
+		spaced := strings.ToLower(seed[:4] + " " + seed[4:8] + " " + seed[8:])
+		key, err := Parse(spaced)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("12345678901234567890"), key.Secret)
+		assert.Equal(t, SHA1, key.Algorithm)
+		assert.Equal(t, DefaultDigits, key.Digits)
+		assert.Equal(t, DefaultPeriod, key.Period)
+	})
+
+	t.Run("URI", func(t *testing.T) {
+		key, err := Parse("otpauth://totp/Example:alice@example.com?secret=" + strings.TrimRight(seed, "=") + "&algorithm=sha256&digits=8&period=60&issuer=Example%20Inc")
+		require.NoError(t, err)
+		assert.Equal(t, SHA256, key.Algorithm)
+		assert.Equal(t, 8, key.Digits)
+		assert.Equal(t, 60, key.Period)
+		assert.Equal(t, "Example Inc", key.Issuer)
+		assert.Equal(t, "alice@example.com", key.Account)
+
+		// The canonical URI parses back to the same key
+		again, err := Parse(key.URI())
+		require.NoError(t, err)
+		assert.Equal(t, key, again)
+	})
+
+	t.Run("80-Bit Seed", func(t *testing.T) {
+		// Shorter than RFC 4226 allows, but common
+		key, err := Parse("JBSWY3DPEHPK3PXP")
+		require.NoError(t, err)
+		assert.Len(t, key.Secret, 10)
+	})
+
+	invalid := map[string]string{
+		"Not Base32":     "not a seed!",
+		"Too Short":      "JBSWY3DP",
+		"HOTP":           "otpauth://hotp/Example?secret=" + seed + "&counter=1",
+		"Bad Algorithm":  "otpauth://totp/Example?secret=" + seed + "&algorithm=MD5",
+		"Bad Digits":     "otpauth://totp/Example?secret=" + seed + "&digits=4",
+		"Bad Period":     "otpauth://totp/Example?secret=" + seed + "&period=0",
+		"Missing Secret": "otpauth://totp/Example",
+	}
+	for name, value := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(value)
+			assert.ErrorIs(t, err, ErrInvalidKey)
+		})
+	}
+}
+
+func TestDecodeQR(t *testing.T) {
+	uri := "otpauth://totp/Example:alice@example.com?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&issuer=Example"
+	matrix, err := qrcode.NewQRCodeWriter().Encode(uri, gozxing.BarcodeFormat_QR_CODE, 300, 300, nil)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, matrix))
+
+	text, err := DecodeQR(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, uri, text)
+
+	_, err = DecodeQR(strings.NewReader("not an image"))
+	assert.ErrorIs(t, err, ErrNoQRCode)
+}
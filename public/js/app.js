@@ -1,7 +1,97 @@
+// secretTypes holds the schema of each secret type, from /api/secret-types.
+let secretTypes = [];
+
+async function loadSecretTypes() {
+    const response = await fetch('/api/secret-types');
+    if (response.ok) secretTypes = await response.json();
+}
+
+function secretSchema(type) {
+    return secretTypes.find(t => t.type === (type || 'login'));
+}
+
+function fieldInputType(field) {
+    switch (field.format) {
+        case 'email': return 'email';
+        case 'phone': return 'tel';
+        case 'date': return 'date';
+        case 'url': return 'url';
+        default: return 'text';
+    }
+}
+
+// renderTypeFields shows the inputs of the type chosen in the secret form,
+// filled from values.
+function renderTypeFields(values = {}) {
+    const type = document.getElementById('secretType').value;
+    const schema = secretSchema(type);
+    if (!schema) return;
+
+    // Logins keep their own inputs for the URL and password rules
+    const isLogin = type === 'login';
+    document.querySelectorAll('.login-field').forEach(el => el.classList.toggle('hidden', !isLogin));
+    document.getElementById('usernameField').classList.toggle('hidden', !schema.username_label);
+    document.getElementById('usernameLabel').innerText = schema.username_label || 'Username';
+    document.getElementById('passwordField').classList.toggle('hidden', !schema.password_label);
+    document.getElementById('passwordLabel').innerText = schema.password_label || 'Password';
+    document.getElementById('password').required = isLogin;
+    document.getElementById('generateButton').classList.toggle('hidden', !schema.scored);
+    checkStrength();
+
+    const container = document.getElementById('typeFields');
+    container.replaceChildren();
+    if (isLogin) return;
+    for (const field of schema.fields) {
+        const wrapper = document.createElement('div');
+        const label = document.createElement('label');
+        label.className = 'block text-sm font-medium text-gray-700';
+        label.textContent = field.label;
+        if (field.sensitive) {
+            const lock = document.createElement('i');
+            lock.className = 'fa-solid fa-lock ml-1 text-gray-400';
+            lock.title = 'Always encrypted';
+            label.appendChild(lock);
+        }
+
+        const input = document.createElement(field.multiline ? 'textarea' : 'input');
+        if (field.multiline) {
+            input.rows = 3;
+        } else {
+            input.type = fieldInputType(field);
+        }
+        input.dataset.field = field.name;
+        input.required = field.required;
+        input.value = values[field.name] || '';
+        input.className = 'mt-1 block w-full rounded-md border-gray-300 shadow-sm focus:border-blue-500 focus:ring-blue-500 sm:text-sm border p-2';
+        if (field.format.startsWith('ssh_')) input.classList.add('font-mono', 'text-xs');
+        wrapper.append(label, input);
+        container.appendChild(wrapper);
+    }
+}
+
+function typeFieldValues() {
+    const values = {};
+    document.querySelectorAll('#typeFields [data-field]').forEach(input => {
+        values[input.dataset.field] = input.value.trim();
+    });
+    return values;
+}
+
+// copyValue picks what the copy button copies: the first required sensitive
+// field, such as a card number, else the password.
+function copyValue(data) {
+    const schema = secretSchema(data.type);
+    const field = schema && schema.fields.find(f => f.required && f.sensitive);
+    return (field && data.metadata && data.metadata[field.name]) || data.password;
+}
+
 function openAddModal() {
     document.getElementById('modalTitle').innerText = 'Add New Secret';
     document.getElementById('secretId').value = '';
+    document.getElementById('secretVersion').value = '';
     document.getElementById('secretForm').reset();
+    document.getElementById('secretType').disabled = false;
+    renderTypeFields();
     document.getElementById('secretModal').classList.remove('hidden');
 }
 
@@ -12,37 +102,92 @@ function closeModal() {
 async function saveSecret(event) {
     event.preventDefault();
     const id = document.getElementById('secretId').value;
+    const type = document.getElementById('secretType').value;
+    const schema = secretSchema(type);
     const title = document.getElementById('title').value;
     const username = document.getElementById('username').value;
     const password = document.getElementById('password').value;
     const url = document.getElementById('url').value;
+    const passwordRules = document.getElementById('passwordRules').value.trim();
+    const totp = document.getElementById('totp').value.trim();
 
     const payload = {
+        type,
         title,
-        username,
-        password,
+        username: schema.username_label ? username : '',
+        password: schema.password_label ? password : '',
         metadata: { url }
     };
+    if (passwordRules) {
+        payload.metadata.password_rules = passwordRules;
+    }
+    if (totp) {
+        payload.metadata.totp = totp;
+    }
+    const typed = type !== 'login';
+    if (typed) {
+        payload.metadata = typeFieldValues();
+    }
+    const folder = currentFolder();
+    if (folder && folder !== 'none') {
+        // New secrets land in the folder being viewed
+        payload.folder_id = folder;
+    }
 
     let method = 'POST';
     let endpoint = '/api/secrets';
 
+    let contentType = 'application/json';
+
     if (id) {
-        method = 'PUT';
+        // A merge patch keeps metadata this form does not show, such as notes
+        method = 'PATCH';
         endpoint = `/api/secrets/${id}`;
+        contentType = 'application/merge-patch+json';
+        // The type cannot change
+        delete payload.type;
+        payload.metadata = { url: url || null, password_rules: passwordRules || null, totp: totp || null };
+        if (typed) {
+            payload.metadata = Object.fromEntries(Object.entries(typeFieldValues()).map(([k, v]) => [k, v || null]));
+        }
+        // Saving fails with 409 if the secret changed since it was opened
+        payload.version = parseInt(document.getElementById('secretVersion').value, 10);
     }
 
     try {
-        const response = await fetch(endpoint, {
+        // In zero-knowledge mode the server only ever sees ciphertext; that
+        // includes the fields the type marks sensitive
+        if (await isZeroKnowledge()) {
+            const fields = { password: payload.password, username: payload.username };
+            for (const field of schema.fields.filter(f => f.sensitive)) {
+                if (payload.metadata[field.name]) fields[field.name] = payload.metadata[field.name];
+                if (id) {
+                    payload.metadata[field.name] = null;
+                } else {
+                    delete payload.metadata[field.name];
+                }
+            }
+            payload.password = await encryptSecretFields(fields);
+            payload.username = '';
+            payload.client_encrypted = true;
+        }
+
+        // Editing answers 423 while the vault is locked
+        const response = await fetchUnlocked(endpoint, {
             method: method,
             headers: {
-                'Content-Type': 'application/json'
+                'Content-Type': contentType
             },
             body: JSON.stringify(payload)
         });
 
         if (response.ok) {
             window.location.reload();
+        } else if (response.status === 409) {
+            const err = await response.json();
+            if (confirm(`This secret was changed elsewhere (now version ${err.current.version}). Discard your edits and load the latest version?`)) {
+                await openEditModal(id);
+            }
         } else {
             const err = await response.json();
             alert('Error: ' + err.error);
@@ -54,10 +199,10 @@ async function saveSecret(event) {
 }
 
 async function deleteSecret(id) {
-    if (!confirm('Are you sure you want to delete this secret?')) return;
+    if (!confirm('Move this secret to the trash? You can restore it from there.')) return;
 
     try {
-        const response = await fetch(`/api/secrets/${id}`, {
+        const response = await fetchUnlocked(`/api/secrets/${id}`, {
             method: 'DELETE'
         });
 
@@ -71,6 +216,563 @@ async function deleteSecret(id) {
     }
 }
 
+async function rotateSecret(id) {
+    if (!confirm('Replace this password with a newly generated one? The current one is kept in history.')) return;
+
+    try {
+        const response = await fetchUnlocked(`/api/secrets/${id}/rotate`, {
+            method: 'POST'
+        });
+        const data = await response.json();
+        if (!response.ok) {
+            alert('Error: ' + data.error);
+            return;
+        }
+        // The new password is only shown once; put it on the clipboard right away.
+        await navigator.clipboard.writeText(data.password);
+        showToast('Password rotated and copied to clipboard');
+        setTimeout(() => window.location.reload(), 1000);
+    } catch (error) {
+        console.error('Error:', error);
+        alert('Failed to rotate password');
+    }
+}
+
+async function openHistory(id) {
+    try {
+        const response = await fetchUnlocked(`/api/secrets/${id}/history`);
+        if (!response.ok) throw new Error('Failed to load history');
+        const history = await response.json();
+
+        const list = document.getElementById('historyList');
+        list.replaceChildren();
+        if (history.length === 0) {
+            const empty = document.createElement('li');
+            empty.className = 'py-3 text-sm text-gray-500';
+            empty.textContent = 'No previous versions yet.';
+            list.appendChild(empty);
+        }
+        for (const v of history) {
+            const item = document.createElement('li');
+            item.className = 'py-3 flex justify-between items-center';
+
+            const info = document.createElement('div');
+            const heading = document.createElement('div');
+            heading.className = 'text-sm font-medium text-gray-900';
+            heading.textContent = `v${v.version} · ${v.title} · ${new Date(v.updated_at).toLocaleString()}`;
+            const changes = document.createElement('div');
+            changes.className = 'text-xs text-gray-500';
+            changes.textContent = v.changes ? 'Then changed: ' + v.changes.join(', ') : 'No changes';
+            info.append(heading, changes);
+
+            const actions = document.createElement('div');
+            actions.className = 'space-x-2 whitespace-nowrap';
+            const view = document.createElement('button');
+            view.className = 'text-gray-500 hover:text-green-600';
+            view.title = 'Copy this version\'s password';
+            view.innerHTML = '<i class="fa-regular fa-copy"></i>';
+            view.onclick = () => copyVersionPassword(id, v.version);
+            const restore = document.createElement('button');
+            restore.className = 'text-primary hover:text-blue-900';
+            restore.title = 'Restore this version';
+            restore.innerHTML = '<i class="fa-solid fa-clock-rotate-left"></i>';
+            restore.onclick = () => restoreVersion(id, v.version);
+            actions.append(view, restore);
+
+            item.append(info, actions);
+            list.appendChild(item);
+        }
+        document.getElementById('historyModal').classList.remove('hidden');
+    } catch (error) {
+        console.error('Error:', error);
+        alert(error.message);
+    }
+}
+
+function closeHistory() {
+    document.getElementById('historyModal').classList.add('hidden');
+}
+
+async function copyVersionPassword(id, version) {
+    try {
+        const response = await fetchUnlocked(`/api/secrets/${id}/history/${version}`);
+        if (!response.ok) throw new Error('Failed to fetch version');
+        const data = await response.json();
+        if (data.client_encrypted) {
+            data.password = (await decryptSecretFields(data.password)).password;
+        }
+        copyToClipboard(data.password);
+    } catch (error) {
+        console.error('Error:', error);
+        alert(error.message);
+    }
+}
+
+async function restoreVersion(id, version) {
+    if (!confirm(`Restore version ${version}? The current state is kept in history.`)) return;
+
+    try {
+        const response = await fetchUnlocked(`/api/secrets/${id}/restore/${version}`, {
+            method: 'POST'
+        });
+        if (!response.ok) {
+            const err = await response.json().catch(() => ({ error: 'version not found' }));
+            alert('Error: ' + err.error);
+            return;
+        }
+        window.location.reload();
+    } catch (error) {
+        console.error('Error:', error);
+        alert('Failed to restore version');
+    }
+}
+
+async function setHistoryLimit() {
+    const settings = await (await fetch('/api/settings')).json();
+    const value = prompt('How many previous versions should be kept per secret? (1-100)', settings.history_limit);
+    if (value === null) return;
+    const response = await fetchUnlocked('/api/settings', {
+        method: 'PUT',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ history_limit: parseInt(value, 10) }),
+    });
+    if (!response.ok) {
+        const err = await response.json();
+        alert('Error: ' + err.error);
+        return;
+    }
+    showToast('History limit updated');
+}
+
+async function openTrash() {
+    try {
+        const response = await fetch('/api/trash');
+        if (!response.ok) throw new Error('Failed to load trash');
+        const trashed = await response.json();
+
+        // Go durations look like "720h0m0s"; show whole days.
+        const retention = response.headers.get('X-Trash-Retention');
+        document.getElementById('trashRetention').textContent = retention
+            ? `Secrets are deleted for good ${Math.round(parseInt(retention, 10) / 24)} days after being moved here.`
+            : 'Secrets stay here until you empty the trash.';
+
+        const list = document.getElementById('trashList');
+        list.replaceChildren();
+        if (trashed.length === 0) {
+            const empty = document.createElement('li');
+            empty.className = 'py-3 text-sm text-gray-500';
+            empty.textContent = 'The trash is empty.';
+            list.appendChild(empty);
+        }
+        for (const s of trashed) {
+            const item = document.createElement('li');
+            item.className = 'py-3 flex justify-between items-center';
+
+            const info = document.createElement('div');
+            const heading = document.createElement('div');
+            heading.className = 'text-sm font-medium text-gray-900';
+            heading.textContent = s.title;
+            const deleted = document.createElement('div');
+            deleted.className = 'text-xs text-gray-500';
+            deleted.textContent = 'Deleted ' + new Date(s.deleted_at).toLocaleString();
+            info.append(heading, deleted);
+
+            const actions = document.createElement('div');
+            actions.className = 'space-x-2 whitespace-nowrap';
+            const restore = document.createElement('button');
+            restore.className = 'text-primary hover:text-blue-900';
+            restore.title = 'Restore';
+            restore.innerHTML = '<i class="fa-solid fa-trash-arrow-up"></i>';
+            restore.onclick = () => restoreFromTrash(s.id);
+            const purge = document.createElement('button');
+            purge.className = 'text-red-600 hover:text-red-900';
+            purge.title = 'Delete for good';
+            purge.innerHTML = '<i class="fa-solid fa-xmark"></i>';
+            purge.onclick = () => purgeSecret(s.id, s.title);
+            actions.append(restore, purge);
+
+            item.append(info, actions);
+            list.appendChild(item);
+        }
+        document.getElementById('trashModal').classList.remove('hidden');
+    } catch (error) {
+        console.error('Error:', error);
+        alert(error.message);
+    }
+}
+
+function closeTrash() {
+    document.getElementById('trashModal').classList.add('hidden');
+}
+
+async function restoreFromTrash(id) {
+    const response = await fetch(`/api/trash/${id}/restore`, { method: 'POST' });
+    if (!response.ok) {
+        const err = await response.json();
+        alert('Error: ' + err.error);
+        return;
+    }
+    window.location.reload();
+}
+
+async function purgeSecret(id, title) {
+    if (!confirm(`Delete "${title}" for good? This cannot be undone.`)) return;
+
+    const response = await fetch(`/api/trash/${id}`, { method: 'DELETE' });
+    if (!response.ok) {
+        const err = await response.json();
+        alert('Error: ' + err.error);
+        return;
+    }
+    openTrash();
+}
+
+async function emptyTrash() {
+    if (!confirm('Delete everything in the trash for good? This cannot be undone.')) return;
+
+    const response = await fetch('/api/trash', { method: 'DELETE' });
+    if (!response.ok) {
+        const err = await response.json();
+        alert('Error: ' + err.error);
+        return;
+    }
+    const data = await response.json();
+    showToast(`${data.purged} secrets deleted`);
+    openTrash();
+}
+
+// The secret whose attachments are shown.
+let attachmentSecretID = null;
+
+function formatBytes(bytes) {
+    if (bytes < 1024) return `${bytes} B`;
+    if (bytes < 1024 * 1024) return `${(bytes / 1024).toFixed(1)} KB`;
+    return `${(bytes / (1024 * 1024)).toFixed(1)} MB`;
+}
+
+async function openAttachments(id) {
+    attachmentSecretID = id;
+    try {
+        const [response, usageResponse] = await Promise.all([
+            fetch(`/api/secrets/${id}/attachments`),
+            fetch('/api/attachments/usage'),
+        ]);
+        if (!response.ok || !usageResponse.ok) throw new Error('Failed to load attachments');
+        const attachments = await response.json();
+        const usage = await usageResponse.json();
+
+        document.getElementById('attachmentUsage').textContent = usage.quota
+            ? `${formatBytes(usage.used)} of ${formatBytes(usage.quota)} used, up to ${formatBytes(usage.max_size)} per file.`
+            : `${formatBytes(usage.used)} used, up to ${formatBytes(usage.max_size)} per file.`;
+
+        const list = document.getElementById('attachmentList');
+        list.replaceChildren();
+        if (attachments.length === 0) {
+            const empty = document.createElement('li');
+            empty.className = 'py-3 text-sm text-gray-500';
+            empty.textContent = 'No files attached yet.';
+            list.appendChild(empty);
+        }
+        for (const a of attachments) {
+            const item = document.createElement('li');
+            item.className = 'py-3 flex justify-between items-center';
+
+            const info = document.createElement('div');
+            const heading = document.createElement('div');
+            heading.className = 'text-sm font-medium text-gray-900';
+            heading.textContent = a.name;
+            const details = document.createElement('div');
+            details.className = 'text-xs text-gray-500';
+            details.textContent = `${formatBytes(a.size)} · ${new Date(a.created_at).toLocaleString()}`;
+            info.append(heading, details);
+
+            const actions = document.createElement('div');
+            actions.className = 'space-x-2 whitespace-nowrap';
+            const download = document.createElement('button');
+            download.className = 'text-primary hover:text-blue-900';
+            download.title = 'Download';
+            download.innerHTML = '<i class="fa-solid fa-download"></i>';
+            download.onclick = () => downloadAttachment(a);
+            const remove = document.createElement('button');
+            remove.className = 'text-red-600 hover:text-red-900';
+            remove.title = 'Delete';
+            remove.innerHTML = '<i class="fa-regular fa-trash-can"></i>';
+            remove.onclick = () => deleteAttachment(a);
+            actions.append(download, remove);
+
+            item.append(info, actions);
+            list.appendChild(item);
+        }
+        document.getElementById('attachmentModal').classList.remove('hidden');
+    } catch (error) {
+        console.error('Error:', error);
+        alert(error.message);
+    }
+}
+
+function closeAttachments() {
+    document.getElementById('attachmentModal').classList.add('hidden');
+    attachmentSecretID = null;
+}
+
+// uploadAttachment attaches the chosen file. In zero-knowledge mode it is
+// encrypted with the vault key first.
+async function uploadAttachment(input) {
+    const file = input.files[0];
+    input.value = '';
+    if (!file) return;
+
+    try {
+        const form = new FormData();
+        if (await isZeroKnowledge()) {
+            const sealed = await encryptFile(new Uint8Array(await file.arrayBuffer()));
+            form.append('file', new File([sealed], file.name, { type: file.type }));
+            form.append('client_encrypted', 'true');
+        } else {
+            form.append('file', file);
+        }
+        const response = await fetchUnlocked(`/api/secrets/${attachmentSecretID}/attachments`, { method: 'POST', body: form });
+        if (!response.ok) {
+            const err = await response.json().catch(() => ({ error: 'upload failed' }));
+            alert('Error: ' + err.error);
+            return;
+        }
+        showToast(`${file.name} attached`);
+        openAttachments(attachmentSecretID);
+    } catch (error) {
+        console.error('Error:', error);
+        alert('Failed to attach file');
+    }
+}
+
+async function downloadAttachment(a) {
+    try {
+        const response = await fetchUnlocked(`/api/secrets/${a.secret_id}/attachments/${a.id}`);
+        if (!response.ok) throw new Error('Failed to download attachment');
+        let data = new Uint8Array(await response.arrayBuffer());
+        if (a.client_encrypted) {
+            data = await decryptFile(data);
+        }
+
+        const url = URL.createObjectURL(new Blob([data], { type: a.content_type }));
+        const link = document.createElement('a');
+        link.href = url;
+        link.download = a.name;
+        link.click();
+        setTimeout(() => URL.revokeObjectURL(url), 1000);
+    } catch (error) {
+        console.error('Error:', error);
+        alert(error.message);
+    }
+}
+
+async function deleteAttachment(a) {
+    if (!confirm(`Delete "${a.name}"? This cannot be undone.`)) return;
+
+    const response = await fetchUnlocked(`/api/secrets/${a.secret_id}/attachments/${a.id}`, { method: 'DELETE' });
+    if (!response.ok) {
+        const err = await response.json();
+        alert('Error: ' + err.error);
+        return;
+    }
+    openAttachments(a.secret_id);
+}
+
+// currentFolder is the folder the dashboard shows: an ID, "none" or "".
+function currentFolder() {
+    const panel = document.getElementById('folderPanel');
+    return panel ? panel.dataset.folder : '';
+}
+
+let folders = [];
+
+// loadFolders fetches the folder list and renders it as a tree, plus the
+// targets of the bulk move.
+async function loadFolders() {
+    if (!document.getElementById('folderPanel')) return;
+    const response = await fetch('/api/folders');
+    if (!response.ok) return;
+    folders = await response.json();
+
+    const children = {};
+    for (const f of folders) {
+        (children[f.parent_id || ''] ||= []).push(f);
+    }
+    const current = currentFolder();
+    const moveTarget = document.getElementById('moveTarget');
+
+    const render = (parentID, list, depth) => {
+        for (const f of children[parentID] || []) {
+            const item = document.createElement('li');
+            const link = document.createElement('a');
+            link.href = `/dashboard?folder=${f.id}`;
+            link.className = 'folder-link block text-gray-700 hover:text-primary truncate';
+            link.dataset.id = f.id;
+            link.style.paddingLeft = `${depth * 0.75}rem`;
+            link.innerHTML = '<i class="fa-regular fa-folder mr-1"></i>';
+            link.append(f.name);
+            item.appendChild(link);
+            list.appendChild(item);
+
+            if (moveTarget) {
+                const option = document.createElement('option');
+                option.value = f.id;
+                option.textContent = '\u00a0\u00a0'.repeat(depth) + f.name;
+                moveTarget.appendChild(option);
+            }
+            render(f.id, list, depth + 1);
+        }
+    };
+    const tree = document.getElementById('folderTree');
+    tree.replaceChildren();
+    render('', tree, 0);
+
+    for (const link of document.querySelectorAll('.folder-link')) {
+        if (link.dataset.id === current) link.classList.add('font-semibold', 'text-primary');
+    }
+    const isFolder = current && current !== 'none';
+    document.getElementById('folderActions').classList.toggle('hidden', !isFolder);
+}
+
+// sendJSON sends a JSON request and alerts on errors; it returns the response
+// when it succeeded and null otherwise.
+async function sendJSON(method, url, body) {
+    const response = await fetch(url, {
+        method,
+        headers: { 'Content-Type': 'application/json' },
+        body: body && JSON.stringify(body),
+    });
+    if (!response.ok) {
+        const err = await response.json();
+        alert('Error: ' + err.error);
+        return null;
+    }
+    return response;
+}
+
+async function createFolder() {
+    const name = prompt('Folder name');
+    if (!name) return;
+    const folder = currentFolder();
+    const parentID = folder && folder !== 'none' ? folder : null;
+    if (await sendJSON('POST', '/api/folders', { name, parent_id: parentID })) loadFolders();
+}
+
+async function renameFolder() {
+    const folder = folders.find(f => f.id === currentFolder());
+    const name = prompt('New name', folder.name);
+    if (!name) return;
+    if (await sendJSON('PUT', `/api/folders/${folder.id}`, { name, parent_id: folder.parent_id })) loadFolders();
+}
+
+async function moveFolder() {
+    const folder = folders.find(f => f.id === currentFolder());
+    const names = folders.filter(f => f.id !== folder.id).map(f => f.name).join(', ');
+    const target = prompt(`Move "${folder.name}" into which folder? Leave empty for the top level.\nFolders: ${names}`);
+    if (target === null) return;
+    let parentID = null;
+    if (target.trim()) {
+        const parent = folders.find(f => f.name === target.trim());
+        if (!parent) {
+            alert(`No folder named "${target}"`);
+            return;
+        }
+        parentID = parent.id;
+    }
+    if (await sendJSON('PUT', `/api/folders/${folder.id}`, { name: folder.name, parent_id: parentID })) loadFolders();
+}
+
+async function deleteFolder() {
+    const folder = folders.find(f => f.id === currentFolder());
+    if (!confirm(`Delete the folder "${folder.name}"? Its secrets and subfolders move up one level.`)) return;
+    if (await sendJSON('DELETE', `/api/folders/${folder.id}`)) {
+        window.location.href = folder.parent_id ? `/dashboard?folder=${folder.parent_id}` : '/dashboard';
+    }
+}
+
+// loadTags lists the vault's tags in the sidebar.
+async function loadTags() {
+    const list = document.getElementById('tagList');
+    if (!list) return;
+    const response = await fetch('/api/tags');
+    if (!response.ok) return;
+    const tags = await response.json();
+
+    const current = document.getElementById('folderPanel').dataset.tag;
+    list.replaceChildren();
+    for (const tag of tags) {
+        const item = document.createElement('li');
+        const link = document.createElement('a');
+        link.href = `/dashboard?tag=${encodeURIComponent(tag.name)}`;
+        link.className = 'flex justify-between text-gray-700 hover:text-primary';
+        if (tag.name === current) link.classList.add('font-semibold', 'text-primary');
+        const name = document.createElement('span');
+        name.textContent = '# ' + tag.name;
+        const count = document.createElement('span');
+        count.className = 'text-xs text-gray-400';
+        count.textContent = tag.secrets;
+        link.append(name, count);
+        item.appendChild(link);
+        list.appendChild(item);
+    }
+    document.getElementById('tagActions').classList.toggle('hidden', !current);
+}
+
+async function addTags(button) {
+    const id = button.closest('tr').dataset.id;
+    const input = prompt('Tags to add, separated by commas (e.g. prod-db, on-call)');
+    if (!input) return;
+    const tags = input.split(',').map(t => t.trim()).filter(Boolean);
+    if (await sendJSON('POST', `/api/secrets/${id}/tags`, { tags })) window.location.reload();
+}
+
+async function removeTag(button, tag) {
+    const id = button.closest('tr').dataset.id;
+    if (await sendJSON('DELETE', `/api/secrets/${id}/tags/${encodeURIComponent(tag)}`)) window.location.reload();
+}
+
+async function renameTag() {
+    const tag = document.getElementById('folderPanel').dataset.tag;
+    const name = prompt(`Rename the tag "${tag}" on every secret. Using the name of another tag merges the two.`, tag);
+    if (!name || name === tag) return;
+    if (await sendJSON('PUT', `/api/tags/${encodeURIComponent(tag)}`, { name })) {
+        window.location.href = `/dashboard?tag=${encodeURIComponent(name.trim().toLowerCase())}`;
+    }
+}
+
+async function deleteTag() {
+    const tag = document.getElementById('folderPanel').dataset.tag;
+    if (!confirm(`Remove the tag "${tag}" from every secret? The secrets are kept.`)) return;
+    if (await sendJSON('DELETE', `/api/tags/${encodeURIComponent(tag)}`)) window.location.href = '/dashboard';
+}
+
+async function toggleFavorite(id, favorite) {
+    if (await sendJSON('PUT', `/api/secrets/${id}/favorite`, { favorite: !favorite })) window.location.reload();
+}
+
+function selectAllSecrets(checked) {
+    for (const box of document.querySelectorAll('.secret-select')) box.checked = checked;
+}
+
+async function moveSelected() {
+    const ids = [...document.querySelectorAll('.secret-select:checked')].map(box => box.value);
+    if (ids.length === 0) {
+        alert('Select the secrets to move first');
+        return;
+    }
+    const target = document.getElementById('moveTarget').value || null;
+    const response = await sendJSON('POST', '/api/folders/move', { secret_ids: ids, folder_id: target });
+    if (response) {
+        const data = await response.json();
+        showToast(`${data.moved} secrets moved`);
+        setTimeout(() => window.location.reload(), 1000);
+    }
+}
+
+loadFolders();
+loadTags();
+loadSecretTypes();
+
 function copyToClipboard(text) {
     navigator.clipboard.writeText(text).then(() => {
         showToast('Copied to clipboard!');
@@ -95,27 +797,80 @@ function showToast(message) {
 // And we can add a "Copy" button that fetches, decrypts, and copies on the fly.
 async function copyPassword(id) {
     try {
-        const response = await fetch(`/api/secrets/${id}`);
-        const data = await response.json();
-        if (data.password) {
-            copyToClipboard(data.password);
+        const data = await fetchSecret(id);
+        const value = copyValue(data);
+        if (value) {
+            copyToClipboard(value);
         }
     } catch (error) {
         console.error("Failed to fetch password", error);
     }
 }
 
+// copyTOTP copies the current one-time code of a login. In zero-knowledge
+// mode the seed is encrypted by the browser, which computes the code itself.
+async function copyTOTP(id) {
+    try {
+        let code;
+        if (await isZeroKnowledge()) {
+            const data = await fetchSecret(id);
+            const seed = data.metadata && data.metadata.totp;
+            code = seed ? await totpCode(seed) : null;
+        } else {
+            const response = await fetchUnlocked(`/api/secrets/${id}/totp`);
+            if (response.ok) {
+                code = await response.json();
+            } else if (response.status !== 404) {
+                throw new Error('Failed to get TOTP code');
+            }
+        }
+        if (!code) {
+            showToast('No TOTP secret saved for this login');
+            return;
+        }
+        await navigator.clipboard.writeText(code.code);
+        showToast(`Code ${code.code} copied, valid for ${code.remaining}s`);
+    } catch (error) {
+        console.error('Failed to get TOTP code', error);
+    }
+}
+
+// importTOTPImage fills the TOTP field from a QR code image, which the server
+// decodes without storing it.
+async function importTOTPImage(input) {
+    const file = input.files[0];
+    input.value = '';
+    if (!file) return;
+    if (await isZeroKnowledge() && !confirm('The image is read on the server, which will see the TOTP secret once. Continue?')) return;
+
+    const form = new FormData();
+    form.append('image', file);
+    const response = await fetch('/api/totp/decode', { method: 'POST', body: form });
+    const data = await response.json();
+    if (!response.ok) {
+        alert('Error: ' + data.error);
+        return;
+    }
+    document.getElementById('totp').value = data.uri;
+    showToast(`TOTP secret read${data.issuer ? ' for ' + data.issuer : ''}`);
+}
+
 async function openEditModal(id) {
     try {
-        const response = await fetch(`/api/secrets/${id}`);
-        const data = await response.json();
+        const data = await fetchSecret(id);
         
         document.getElementById('modalTitle').innerText = 'Edit Secret';
         document.getElementById('secretId').value = data.id;
+        document.getElementById('secretVersion').value = data.version;
+        document.getElementById('secretType').value = data.type || 'login';
+        document.getElementById('secretType').disabled = true;
         document.getElementById('title').value = data.title;
         document.getElementById('username').value = data.username;
-        document.getElementById('password').value = data.password; // This comes decrypted from GET /api/secrets/:id
+        document.getElementById('password').value = data.password; // Decrypted by the server, or by fetchSecret in zero-knowledge mode
         document.getElementById('url').value = data.metadata ? data.metadata.url : '';
+        document.getElementById('passwordRules').value = (data.metadata && data.metadata.password_rules) || '';
+        document.getElementById('totp').value = (data.metadata && data.metadata.totp) || '';
+        renderTypeFields(data.metadata || {});
         
         document.getElementById('secretModal').classList.remove('hidden');
     } catch (error) {
@@ -123,3 +878,129 @@ async function openEditModal(id) {
     }
     
 }
+
+function generatorOptions() {
+    return {
+        mode: document.querySelector('input[name="genMode"]:checked').value,
+        length: parseInt(document.getElementById('genLength').value, 10),
+        uppercase: document.getElementById('genUppercase').checked,
+        lowercase: document.getElementById('genLowercase').checked,
+        numbers: document.getElementById('genNumbers').checked,
+        symbols: document.getElementById('genSymbols').checked,
+        rules: document.getElementById('genRules').value.trim(),
+        words: parseInt(document.getElementById('genWords').value, 10),
+        separator: document.getElementById('genSeparator').value,
+        capitalize: document.getElementById('genCapitalize').checked,
+        include_number: document.getElementById('genIncludeNumber').checked
+    };
+}
+
+function setGeneratorMode(mode) {
+    document.getElementById('genPasswordOptions').classList.toggle('hidden', mode !== 'password');
+    document.getElementById('genPassphraseOptions').classList.toggle('hidden', mode !== 'passphrase');
+    generatePassword();
+}
+
+// requestPassword asks the server for a password, by default using the generator panel's options.
+async function requestPassword(options = generatorOptions()) {
+    const response = await fetch('/api/generate', {
+        method: 'POST',
+        headers: {
+            'Content-Type': 'application/json'
+        },
+        body: JSON.stringify(options)
+    });
+    const data = await response.json();
+    if (!response.ok) {
+        throw new Error(data.error);
+    }
+    document.getElementById('genEntropy').innerText = `~${Math.round(data.entropy_bits)} bits of entropy`;
+    return data.password;
+}
+
+function toggleGenerator() {
+    const panel = document.getElementById('generatorPanel');
+    panel.classList.toggle('hidden');
+    if (!panel.classList.contains('hidden') && !document.getElementById('generatedPassword').value) {
+        generatePassword();
+    }
+}
+
+async function generatePassword() {
+    try {
+        document.getElementById('generatedPassword').value = await requestPassword();
+    } catch (error) {
+        console.error('Error:', error);
+        showToast(error.message);
+    }
+}
+
+function useGeneratedPassword() {
+    const generated = document.getElementById('generatedPassword').value;
+    openAddModal();
+    document.getElementById('password').value = generated;
+    document.getElementById('passwordRules').value = document.getElementById('genRules').value.trim();
+    checkStrength();
+}
+
+// fillGeneratedPassword regenerates the secret's password, following the
+// site's password rules when the secret has them.
+async function fillGeneratedPassword() {
+    const rules = document.getElementById('passwordRules').value.trim();
+    try {
+        document.getElementById('password').value = await requestPassword(rules ? { rules } : generatorOptions());
+        checkStrength();
+    } catch (error) {
+        console.error('Error:', error);
+        alert('Error: ' + error.message);
+    }
+}
+
+const strengthLabels = ['Very weak', 'Weak', 'Fair', 'Strong', 'Very strong'];
+const strengthColors = ['bg-red-500', 'bg-red-500', 'bg-yellow-500', 'bg-green-500', 'bg-green-600'];
+let strengthTimer;
+
+// checkStrength scores the password field after the user stops typing. In
+// zero-knowledge mode the plaintext must not reach the server, so no meter.
+function checkStrength() {
+    clearTimeout(strengthTimer);
+    const meter = document.getElementById('strengthMeter');
+    const value = document.getElementById('password').value;
+    const schema = secretSchema(document.getElementById('secretType').value);
+    if (!value || (schema && !schema.scored)) {
+        meter.classList.add('hidden');
+        return;
+    }
+    strengthTimer = setTimeout(async () => {
+        try {
+            if (await isZeroKnowledge()) return;
+            const response = await fetch('/api/strength', {
+                method: 'POST',
+                headers: {
+                    'Content-Type': 'application/json'
+                },
+                body: JSON.stringify({
+                    password: value,
+                    user_inputs: [document.getElementById('title').value, document.getElementById('username').value]
+                })
+            });
+            if (!response.ok) return;
+            showStrength(await response.json());
+        } catch (error) {
+            console.error('Error:', error);
+        }
+    }, 300);
+}
+
+function showStrength(strength) {
+    document.querySelectorAll('#strengthMeter .strength-bar').forEach((bar, i) => {
+        bar.classList.remove(...strengthColors, 'bg-gray-200');
+        bar.classList.add(i < Math.max(strength.score, 1) ? strengthColors[strength.score] : 'bg-gray-200');
+    });
+    let text = `${strengthLabels[strength.score]}, cracked offline in ${strength.crack_times.offline_slow_hashing.display}`;
+    if (strength.warning) {
+        text += `. ${strength.warning}`;
+    }
+    document.getElementById('strengthText').innerText = text;
+    document.getElementById('strengthMeter').classList.remove('hidden');
+}
@@ -0,0 +1,399 @@
+// Zero-knowledge mode: keys are derived from the master password in the browser
+// with Argon2id and never reach the server. The server only stores the KDF
+// parameters, a verifier of the auth key and the vault key wrapped by the
+// master key. Secret fields are encrypted with the vault key before upload.
+const ZK_PREFIX = 'zk1:';
+const KDF_DEFAULTS = { algorithm: 'argon2id', memory: 64 * 1024, iterations: 3, parallelism: 4 };
+const VAULT_KEY_STORAGE = 'vaultKey';
+
+let vaultInfo = null;
+
+function b64encode(bytes) {
+    let binary = '';
+    bytes.forEach((b) => { binary += String.fromCharCode(b); });
+    return btoa(binary);
+}
+
+function b64decode(text) {
+    return Uint8Array.from(atob(text), (c) => c.charCodeAt(0));
+}
+
+async function loadVault() {
+    if (vaultInfo) return vaultInfo;
+    const response = await fetch('/api/vault');
+    if (!response.ok) throw new Error('Failed to load vault settings');
+    vaultInfo = await response.json();
+    return vaultInfo;
+}
+
+async function isZeroKnowledge() {
+    return (await loadVault()).zero_knowledge;
+}
+
+async function hmac(keyBytes, label) {
+    const key = await crypto.subtle.importKey('raw', keyBytes, { name: 'HMAC', hash: 'SHA-256' }, false, ['sign']);
+    return new Uint8Array(await crypto.subtle.sign('HMAC', key, new TextEncoder().encode(label)));
+}
+
+// deriveKeys turns the master password into an auth key, which the server
+// verifies, and a wrapping key for the vault key, which stays in the browser.
+async function deriveKeys(password, kdf) {
+    const master = await hashwasm.argon2id({
+        password,
+        salt: b64decode(kdf.salt),
+        parallelism: kdf.parallelism,
+        iterations: kdf.iterations,
+        memorySize: kdf.memory,
+        hashLength: 32,
+        outputType: 'binary',
+    });
+    const authKey = await hmac(master, 'auth');
+    const wrapKey = await crypto.subtle.importKey('raw', await hmac(master, 'wrap'), 'AES-GCM', false, ['encrypt', 'decrypt']);
+    return { authKey: b64encode(authKey), wrapKey };
+}
+
+async function sealBlob(key, plainBytes) {
+    const nonce = crypto.getRandomValues(new Uint8Array(12));
+    const sealed = new Uint8Array(await crypto.subtle.encrypt({ name: 'AES-GCM', iv: nonce }, key, plainBytes));
+    const out = new Uint8Array(nonce.length + sealed.length);
+    out.set(nonce);
+    out.set(sealed, nonce.length);
+    return ZK_PREFIX + b64encode(out);
+}
+
+async function openBlob(key, blob) {
+    if (!blob || !blob.startsWith(ZK_PREFIX)) throw new Error('Not a client-encrypted value');
+    const data = b64decode(blob.slice(ZK_PREFIX.length));
+    return new Uint8Array(await crypto.subtle.decrypt({ name: 'AES-GCM', iv: data.slice(0, 12) }, key, data.slice(12)));
+}
+
+function importVaultKey(raw) {
+    return crypto.subtle.importKey('raw', raw, 'AES-GCM', false, ['encrypt', 'decrypt']);
+}
+
+// The unwrapped vault key is kept in sessionStorage so that it survives the
+// page reload after each save; it is dropped when the tab is closed.
+async function getVaultKey() {
+    const stored = sessionStorage.getItem(VAULT_KEY_STORAGE);
+    if (stored) return importVaultKey(b64decode(stored));
+    return unlockVault();
+}
+
+async function unlockVault() {
+    const vault = await loadVault();
+    const password = prompt('Master password');
+    if (!password) throw new Error('Vault is locked');
+
+    const { authKey, wrapKey } = await deriveKeys(password, vault.kdf);
+    // Unlocking also opens the vault for this login session on the server.
+    const response = await fetch('/api/vault/unlock', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ auth_key: authKey }),
+    });
+    if (!response.ok) throw new Error('Wrong master password');
+
+    const raw = await openBlob(wrapKey, vault.wrapped_key);
+    sessionStorage.setItem(VAULT_KEY_STORAGE, b64encode(raw));
+    return importVaultKey(raw);
+}
+
+// The password, username and sensitive typed fields are encrypted together as
+// one blob.
+async function encryptSecretFields(fields) {
+    const key = await getVaultKey();
+    return sealBlob(key, new TextEncoder().encode(JSON.stringify(fields)));
+}
+
+async function decryptSecretFields(blob) {
+    const key = await getVaultKey();
+    return JSON.parse(new TextDecoder().decode(await openBlob(key, blob)));
+}
+
+// Attachments are encrypted as raw bytes, nonce followed by ciphertext, to
+// keep large files from growing by a third in base64. The file name and size
+// stay visible to the server, like a secret's title.
+async function encryptFile(plainBytes) {
+    const key = await getVaultKey();
+    const nonce = crypto.getRandomValues(new Uint8Array(12));
+    const sealed = new Uint8Array(await crypto.subtle.encrypt({ name: 'AES-GCM', iv: nonce }, key, plainBytes));
+    const out = new Uint8Array(nonce.length + sealed.length);
+    out.set(nonce);
+    out.set(sealed, nonce.length);
+    return out;
+}
+
+async function decryptFile(sealedBytes) {
+    const key = await getVaultKey();
+    return new Uint8Array(await crypto.subtle.decrypt({ name: 'AES-GCM', iv: sealedBytes.slice(0, 12) }, key, sealedBytes.slice(12)));
+}
+
+// unlockSession unlocks the vault on the server after login or auto-lock.
+async function unlockSession() {
+    const status = await (await fetch('/api/vault/status')).json();
+    if (!status.locked) return;
+    if (status.methods.includes('master_password')) {
+        await unlockVault();
+        return;
+    }
+
+    const pin = prompt('Vault is locked. Enter your PIN');
+    if (!pin) throw new Error('Vault is locked');
+    const response = await fetch('/api/vault/unlock', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ pin }),
+    });
+    if (!response.ok) {
+        const err = await response.json();
+        alert('Error: ' + err.error);
+        if (response.status === 401 && err.error.includes('log in')) window.location.href = '/login';
+        throw new Error(err.error);
+    }
+}
+
+async function lockVault() {
+    await fetch('/api/vault/lock', { method: 'POST' });
+    sessionStorage.removeItem(VAULT_KEY_STORAGE);
+    showToast('Vault locked');
+}
+
+async function setUnlockPIN() {
+    const pin = prompt('Choose a PIN to unlock your vault (at least 6 characters)');
+    if (!pin) return;
+    if (prompt('Repeat the PIN') !== pin) {
+        alert('PINs do not match');
+        return;
+    }
+    let response = await fetch('/api/vault/pin', {
+        method: 'PUT',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ pin }),
+    });
+    if (response.status === 423) {
+        await unlockSession();
+        response = await fetch('/api/vault/pin', {
+            method: 'PUT',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ pin }),
+        });
+    }
+    if (!response.ok) {
+        const err = await response.json();
+        alert('Error: ' + err.error);
+        return;
+    }
+    showToast('PIN saved');
+}
+
+async function setAutoLock() {
+    const settings = await (await fetch('/api/settings')).json();
+    const value = prompt('Lock the vault after how many idle minutes? (0 = only on logout)', settings.auto_lock_minutes);
+    if (value === null) return;
+    const response = await fetchUnlocked('/api/settings', {
+        method: 'PUT',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ auto_lock_minutes: parseInt(value, 10) }),
+    });
+    if (!response.ok) {
+        const err = await response.json();
+        alert('Error: ' + err.error);
+        return;
+    }
+    showToast('Auto-lock updated');
+}
+
+// fetchUnlocked is fetch for routes that return plaintext or change the
+// vault: if the vault is locked it unlocks it and retries once.
+async function fetchUnlocked(url, options) {
+    let response = await fetch(url, options);
+    if (response.status === 423) {
+        await unlockSession();
+        response = await fetch(url, options);
+    }
+    return response;
+}
+
+// fetchSecret returns a secret with its client-encrypted fields decrypted,
+// unlocking the vault first if needed.
+async function fetchSecret(id) {
+    const response = await fetchUnlocked(`/api/secrets/${id}`);
+    if (!response.ok) throw new Error('Failed to fetch secret');
+    const data = await response.json();
+    if (data.client_encrypted) {
+        // Besides the password and username, the blob holds the fields the
+        // secret's type marks sensitive
+        const { password, username, ...fields } = await decryptSecretFields(data.password);
+        data.password = password;
+        data.username = username || data.username;
+        if (Object.keys(fields).length > 0) data.metadata = { ...data.metadata, ...fields };
+    }
+    return data;
+}
+
+// totpCode computes the current RFC 6238 code of a base32 seed or an
+// otpauth://totp/ URI, like GET /api/secrets/:id/totp does on the server.
+async function totpCode(value) {
+    let secret = value;
+    let algorithm = 'SHA1';
+    let digits = 6;
+    let period = 30;
+    if (value.toLowerCase().startsWith('otpauth:')) {
+        const params = new URL(value).searchParams;
+        secret = params.get('secret') || '';
+        algorithm = (params.get('algorithm') || algorithm).toUpperCase();
+        digits = parseInt(params.get('digits') || digits, 10);
+        period = parseInt(params.get('period') || period, 10);
+    }
+    const hash = { SHA1: 'SHA-1', SHA256: 'SHA-256', SHA512: 'SHA-512' }[algorithm];
+    if (!hash) throw new Error(`Unsupported TOTP algorithm ${algorithm}`);
+
+    const alphabet = 'ABCDEFGHIJKLMNOPQRSTUVWXYZ234567';
+    const seed = [];
+    let bits = 0;
+    let buffer = 0;
+    for (const c of secret.toUpperCase().replace(/[\s=-]/g, '')) {
+        const index = alphabet.indexOf(c);
+        if (index < 0) throw new Error('The TOTP secret is not base32');
+        buffer = ((buffer << 5) | index) & 0xffff;
+        bits += 5;
+        if (bits >= 8) {
+            bits -= 8;
+            seed.push((buffer >> bits) & 0xff);
+        }
+    }
+
+    const now = Math.floor(Date.now() / 1000);
+    const counter = Math.floor(now / period);
+    const message = new DataView(new ArrayBuffer(8));
+    message.setUint32(0, Math.floor(counter / 2 ** 32));
+    message.setUint32(4, counter >>> 0);
+    const key = await crypto.subtle.importKey('raw', new Uint8Array(seed), { name: 'HMAC', hash }, false, ['sign']);
+    const mac = new Uint8Array(await crypto.subtle.sign('HMAC', key, message.buffer));
+
+    const offset = mac[mac.length - 1] & 0x0f;
+    const binary = new DataView(mac.buffer).getUint32(offset) & 0x7fffffff;
+    const code = String(binary % 10 ** digits).padStart(digits, '0');
+    return { code, remaining: period - (now % period), period, digits, algorithm };
+}
+
+async function enableZeroKnowledge() {
+    try {
+        if (await isZeroKnowledge()) {
+            showToast('Zero-knowledge mode is already on');
+            return;
+        }
+        await unlockSession();
+        const password = prompt('Choose a master password. It cannot be recovered if you lose it.');
+        if (!password) return;
+        if (prompt('Repeat the master password') !== password) {
+            alert('Passwords do not match');
+            return;
+        }
+
+        const kdf = { ...KDF_DEFAULTS, salt: b64encode(crypto.getRandomValues(new Uint8Array(16))) };
+        const { authKey, wrapKey } = await deriveKeys(password, kdf);
+        const raw = crypto.getRandomValues(new Uint8Array(32));
+
+        const response = await fetch('/api/vault/zero-knowledge', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({
+                kdf,
+                auth_key: authKey,
+                key_id: crypto.randomUUID(),
+                wrapped_key: await sealBlob(wrapKey, raw),
+            }),
+        });
+        if (!response.ok) {
+            const err = await response.json();
+            alert('Error: ' + err.error);
+            return;
+        }
+
+        sessionStorage.setItem(VAULT_KEY_STORAGE, b64encode(raw));
+        vaultInfo = null;
+        await migrateSecrets();
+        window.location.reload();
+    } catch (error) {
+        console.error('Error:', error);
+        alert('Failed to enable zero-knowledge mode');
+    }
+}
+
+// listAllSecrets follows the pages of the secret list to its end.
+async function listAllSecrets() {
+    const secrets = [];
+    let cursor = '';
+    do {
+        const params = new URLSearchParams({ limit: 200 });
+        if (cursor) params.set('cursor', cursor);
+        const response = await fetch(`/api/secrets?${params}`);
+        secrets.push(...await response.json());
+        cursor = response.headers.get('X-Next-Cursor');
+    } while (cursor);
+    return secrets;
+}
+
+// migrateSecrets re-encrypts secrets stored before zero-knowledge mode was on.
+async function migrateSecrets() {
+    for (const s of await listAllSecrets()) {
+        if (s.client_encrypted) continue;
+        const full = await fetchSecret(s.id);
+        const password = await encryptSecretFields({ password: full.password, username: full.username });
+        await fetchUnlocked(`/api/secrets/${s.id}`, {
+            method: 'PUT',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({
+                title: full.title,
+                username: '',
+                password,
+                metadata: full.metadata,
+                client_encrypted: true,
+                version: full.version,
+            }),
+        });
+    }
+}
+
+// changeMasterPassword re-wraps the vault key; secrets stay as they are.
+async function changeMasterPassword() {
+    try {
+        const vault = await loadVault();
+        const current = prompt('Current master password');
+        if (!current) return;
+        const currentKeys = await deriveKeys(current, vault.kdf);
+        const raw = await openBlob(currentKeys.wrapKey, vault.wrapped_key);
+
+        const password = prompt('New master password');
+        if (!password) return;
+        if (prompt('Repeat the new master password') !== password) {
+            alert('Passwords do not match');
+            return;
+        }
+        const kdf = { ...KDF_DEFAULTS, salt: b64encode(crypto.getRandomValues(new Uint8Array(16))) };
+        const { authKey, wrapKey } = await deriveKeys(password, kdf);
+
+        const response = await fetch('/api/vault/master-password', {
+            method: 'PUT',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({
+                current_auth_key: currentKeys.authKey,
+                kdf,
+                auth_key: authKey,
+                key_id: vault.key_id,
+                wrapped_key: await sealBlob(wrapKey, raw),
+            }),
+        });
+        if (!response.ok) {
+            const err = await response.json();
+            alert('Error: ' + err.error);
+            return;
+        }
+        vaultInfo = null;
+        showToast('Master password changed');
+    } catch (error) {
+        console.error('Error:', error);
+        alert('Failed to change the master password');
+    }
+}
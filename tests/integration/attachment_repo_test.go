@@ -0,0 +1,116 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/internal/repository/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachmentRepo(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Skipping integration test: database not initialized")
+	}
+
+	userRepo := postgres.NewUserRepository(testDB)
+	secretRepo := postgres.NewSecretRepository(testDB)
+	attachmentRepo := postgres.NewAttachmentRepository(testDB)
+	ctx := context.Background()
+
+	user := &domain.User{Email: "attachments@example.com"}
+	require.NoError(t, userRepo.Create(ctx, user))
+	server := &domain.Secret{UserID: user.ID, Title: "Server", EncryptedPassword: "enc"}
+	require.NoError(t, secretRepo.Create(ctx, server))
+	license := &domain.Secret{UserID: user.ID, Title: "License", EncryptedPassword: "enc"}
+	require.NoError(t, secretRepo.Create(ctx, license))
+
+	newAttachment := func(secretID string, size int64) *domain.Attachment {
+		return &domain.Attachment{
+			ID:          uuid.NewString(),
+			SecretID:    secretID,
+			UserID:      user.ID,
+			Name:        "file.bin",
+			ContentType: "application/octet-stream",
+			Size:        size,
+			WrappedKey:  "wrapped",
+		}
+	}
+	usage := func() int64 {
+		used, err := attachmentRepo.Usage(ctx, user.ID)
+		require.NoError(t, err)
+		return used
+	}
+
+	key := newAttachment(server.ID, 400)
+	t.Run("Create", func(t *testing.T) {
+		stored, err := attachmentRepo.Create(ctx, key, 1000)
+		require.NoError(t, err)
+		assert.True(t, stored)
+		assert.False(t, key.CreatedAt.IsZero())
+
+		found, err := attachmentRepo.GetByID(ctx, key.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, server.ID, found.SecretID)
+		assert.Equal(t, int64(400), found.Size)
+		assert.Equal(t, "wrapped", found.WrappedKey)
+
+		missing, err := attachmentRepo.GetByID(ctx, uuid.NewString())
+		require.NoError(t, err)
+		assert.Nil(t, missing)
+	})
+
+	t.Run("Quota", func(t *testing.T) {
+		stored, err := attachmentRepo.Create(ctx, newAttachment(license.ID, 601), 1000)
+		require.NoError(t, err)
+		assert.False(t, stored)
+
+		stored, err = attachmentRepo.Create(ctx, newAttachment(license.ID, 600), 1000)
+		require.NoError(t, err)
+		assert.True(t, stored)
+		assert.Equal(t, int64(1000), usage())
+
+		// 0 means no limit
+		stored, err = attachmentRepo.Create(ctx, newAttachment(license.ID, 5000), 0)
+		require.NoError(t, err)
+		assert.True(t, stored)
+		assert.Equal(t, int64(6000), usage())
+	})
+
+	t.Run("List", func(t *testing.T) {
+		attachments, err := attachmentRepo.ListBySecret(ctx, license.ID)
+		require.NoError(t, err)
+		assert.Len(t, attachments, 2)
+
+		attachments, err = attachmentRepo.ListByUserID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Len(t, attachments, 3)
+	})
+
+	t.Run("Orphans", func(t *testing.T) {
+		// Purging a secret leaves its attachments for the blobs to be deleted
+		require.NoError(t, secretRepo.Delete(ctx, license.ID))
+
+		orphans, err := attachmentRepo.ListOrphans(ctx, 10)
+		require.NoError(t, err)
+		require.Len(t, orphans, 2)
+		assert.Empty(t, orphans[0].SecretID)
+		assert.Equal(t, int64(400), usage())
+
+		attachments, err := attachmentRepo.ListByUserID(ctx, user.ID)
+		require.NoError(t, err)
+		require.Len(t, attachments, 1)
+		assert.Equal(t, key.ID, attachments[0].ID)
+
+		for _, orphan := range orphans {
+			require.NoError(t, attachmentRepo.Delete(ctx, orphan.ID))
+		}
+		orphans, err = attachmentRepo.ListOrphans(ctx, 10)
+		require.NoError(t, err)
+		assert.Empty(t, orphans)
+	})
+}
@@ -0,0 +1,36 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/herdiagusthio/password-manager/internal/repository/blob"
+	"github.com/herdiagusthio/password-manager/internal/repository/blob/blobtest"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go/modules/minio"
+)
+
+// TestS3Store runs the blob store suite against MinIO standing in for S3.
+func TestS3Store(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Skipping integration test: database not initialized")
+	}
+	ctx := context.Background()
+
+	container, err := minio.Run(ctx, "minio/minio:RELEASE.2024-01-16T16-07-38Z")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = container.Terminate(ctx) })
+
+	endpoint, err := container.ConnectionString(ctx)
+	require.NoError(t, err)
+	store, err := blob.NewS3Store(ctx, blob.S3Config{
+		Endpoint:  endpoint,
+		Bucket:    "attachments",
+		AccessKey: container.Username,
+		SecretKey: container.Password,
+		Prefix:    "test/",
+	})
+	require.NoError(t, err)
+
+	blobtest.Run(t, store)
+}
@@ -0,0 +1,102 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/internal/repository/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFolderRepo(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Skipping integration test: database not initialized")
+	}
+
+	userRepo := postgres.NewUserRepository(testDB)
+	folderRepo := postgres.NewFolderRepository(testDB)
+	secretRepo := postgres.NewSecretRepository(testDB)
+	ctx := context.Background()
+
+	user := &domain.User{Email: "folders@example.com"}
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	// Work > Projects, with one secret in each and one unfiled.
+	work := &domain.Folder{UserID: user.ID, Name: "Work"}
+	require.NoError(t, folderRepo.Create(ctx, work))
+	projects := &domain.Folder{UserID: user.ID, Name: "Projects", ParentID: &work.ID}
+	require.NoError(t, folderRepo.Create(ctx, projects))
+
+	inWork := &domain.Secret{UserID: user.ID, Title: "Mail", EncryptedPassword: "enc", FolderID: &work.ID}
+	require.NoError(t, secretRepo.Create(ctx, inWork))
+	inProjects := &domain.Secret{UserID: user.ID, Title: "CI", EncryptedPassword: "enc", FolderID: &projects.ID}
+	require.NoError(t, secretRepo.Create(ctx, inProjects))
+	unfiled := &domain.Secret{UserID: user.ID, Title: "Bank", EncryptedPassword: "enc"}
+	require.NoError(t, secretRepo.Create(ctx, unfiled))
+
+	titles := func(filter domain.SecretFilter) []string {
+		secrets, err := secretRepo.ListByFilter(ctx, user.ID, filter)
+		require.NoError(t, err)
+		var titles []string
+		for _, s := range secrets {
+			titles = append(titles, s.Title)
+		}
+		return titles
+	}
+
+	t.Run("ListByFilter", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{"Mail", "CI"}, titles(domain.SecretFilter{FolderID: work.ID}))
+		assert.ElementsMatch(t, []string{"CI"}, titles(domain.SecretFilter{FolderID: projects.ID}))
+		assert.ElementsMatch(t, []string{"Bank"}, titles(domain.SecretFilter{FolderID: domain.UnfiledFolderID}))
+		assert.Len(t, titles(domain.SecretFilter{}), 3)
+	})
+
+	t.Run("ListAndUpdate", func(t *testing.T) {
+		projects.Name = "Archive"
+		projects.ParentID = nil
+		require.NoError(t, folderRepo.Update(ctx, projects))
+
+		folders, err := folderRepo.ListByUserID(ctx, user.ID)
+		require.NoError(t, err)
+		require.Len(t, folders, 2)
+		assert.Equal(t, "Archive", folders[0].Name)
+		assert.Nil(t, folders[0].ParentID)
+
+		projects.ParentID = &work.ID
+		require.NoError(t, folderRepo.Update(ctx, projects))
+	})
+
+	t.Run("MoveToFolder", func(t *testing.T) {
+		moved, err := secretRepo.MoveToFolder(ctx, user.ID, []string{unfiled.ID}, &projects.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, moved)
+
+		found, err := secretRepo.GetByID(ctx, unfiled.ID)
+		require.NoError(t, err)
+		assert.Equal(t, projects.ID, *found.FolderID)
+		assert.Equal(t, unfiled.Version, found.Version) // Moving is not an edit
+
+		// Another user's secrets are left alone.
+		moved, err = secretRepo.MoveToFolder(ctx, "00000000-0000-0000-0000-000000000000", []string{unfiled.ID}, nil)
+		require.NoError(t, err)
+		assert.Zero(t, moved)
+	})
+
+	t.Run("DeleteMovesContentsUp", func(t *testing.T) {
+		child := &domain.Folder{UserID: user.ID, Name: "Child", ParentID: &projects.ID}
+		require.NoError(t, folderRepo.Create(ctx, child))
+
+		require.NoError(t, folderRepo.Delete(ctx, projects.ID))
+
+		found, err := folderRepo.GetByID(ctx, child.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, work.ID, *found.ParentID)
+
+		secret, err := secretRepo.GetByID(ctx, inProjects.ID)
+		require.NoError(t, err)
+		assert.Equal(t, work.ID, *secret.FolderID)
+	})
+}
@@ -3,6 +3,7 @@ package integration
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/herdiagusthio/password-manager/internal/domain"
 	"github.com/herdiagusthio/password-manager/internal/repository/postgres"
@@ -29,7 +30,7 @@ func TestSecretRepo(t *testing.T) {
 			Title:             "Gmail",
 			Username:          "agent",
 			EncryptedPassword: "enc_password_123",
-			Metadata:          map[string]interface{}{"url": "gmail.com"},
+			Metadata:          map[string]interface{}{"url": "https://gmail.com"},
 		}
 
 		err := secretRepo.Create(ctx, secret)
@@ -72,6 +73,72 @@ func TestSecretRepo(t *testing.T) {
 		assert.Equal(t, 2, found.Version)
 	})
 
+	t.Run("UpdateStaleVersion", func(t *testing.T) {
+		secret := &domain.Secret{
+			UserID:            user.ID,
+			Title:             "Two Tabs",
+			Username:          "tabs",
+			EncryptedPassword: "enc",
+		}
+		require.NoError(t, secretRepo.Create(ctx, secret))
+		assert.Equal(t, 1, secret.Version)
+
+		// Both tabs loaded version 1; the second save must not clobber the first.
+		first, second := *secret, *secret
+		first.Title = "First Tab"
+		require.NoError(t, secretRepo.Update(ctx, &first))
+		second.Title = "Second Tab"
+		assert.ErrorIs(t, secretRepo.Update(ctx, &second), domain.ErrVersionConflict)
+
+		found, err := secretRepo.GetByID(ctx, secret.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "First Tab", found.Title)
+		assert.Equal(t, 2, found.Version)
+	})
+
+	t.Run("StrengthScore", func(t *testing.T) {
+		score := 1
+		secret := &domain.Secret{
+			UserID:            user.ID,
+			Title:             "Scored",
+			EncryptedPassword: "enc",
+			StrengthScore:     &score,
+		}
+		require.NoError(t, secretRepo.Create(ctx, secret))
+
+		found, err := secretRepo.GetByID(ctx, secret.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found.StrengthScore)
+		assert.Equal(t, 1, *found.StrengthScore)
+		assert.True(t, found.WeakPassword())
+
+		// Unknown scores stay NULL
+		found.StrengthScore = nil
+		require.NoError(t, secretRepo.Update(ctx, found))
+		found, err = secretRepo.GetByID(ctx, secret.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found.StrengthScore)
+		assert.False(t, found.WeakPassword())
+	})
+
+	t.Run("Type", func(t *testing.T) {
+		login := &domain.Secret{UserID: user.ID, Title: "Untyped", EncryptedPassword: "enc"}
+		require.NoError(t, secretRepo.Create(ctx, login))
+		card := &domain.Secret{UserID: user.ID, Type: domain.SecretTypeCard, Title: "Visa", EncryptedPassword: "enc",
+			Metadata: map[string]interface{}{"expiry": "03/31"}}
+		require.NoError(t, secretRepo.Create(ctx, card))
+
+		found, err := secretRepo.GetByID(ctx, login.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.SecretTypeLogin, found.Type)
+
+		cards, err := secretRepo.ListByFilter(ctx, user.ID, domain.SecretFilter{Type: domain.SecretTypeCard})
+		require.NoError(t, err)
+		require.Len(t, cards, 1)
+		assert.Equal(t, card.ID, cards[0].ID)
+		assert.Equal(t, "03/31", cards[0].Metadata["expiry"])
+	})
+
 	t.Run("DeleteSecret", func(t *testing.T) {
 		secret := &domain.Secret{
 			UserID:            user.ID,
@@ -88,4 +155,166 @@ func TestSecretRepo(t *testing.T) {
 		require.NoError(t, err)
 		assert.Nil(t, found)
 	})
+	t.Run("ReplaceEncryptedPassword", func(t *testing.T) {
+		secret := &domain.Secret{
+			UserID:            user.ID,
+			Title:             "Rotate Me",
+			Username:          "rot",
+			EncryptedPassword: "legacy",
+		}
+		require.NoError(t, secretRepo.Create(ctx, secret))
+
+		// A stale expected value must not overwrite a concurrent edit.
+		updated, err := secretRepo.ReplaceEncryptedPassword(ctx, secret.ID, "something else", "current")
+		require.NoError(t, err)
+		assert.False(t, updated)
+
+		updated, err = secretRepo.ReplaceEncryptedPassword(ctx, secret.ID, "legacy", "current")
+		require.NoError(t, err)
+		assert.True(t, updated)
+
+		found, err := secretRepo.GetByID(ctx, secret.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "current", found.EncryptedPassword)
+		assert.Equal(t, secret.Version, found.Version) // Re-encryption is not an edit
+	})
+
+	t.Run("Reseal", func(t *testing.T) {
+		secret := &domain.Secret{
+			UserID:            user.ID,
+			Title:             "Seal Me",
+			Username:          "plain",
+			EncryptedPassword: "enc",
+			Metadata:          map[string]interface{}{"notes": "plain"},
+		}
+		require.NoError(t, secretRepo.Create(ctx, secret))
+
+		secret.Username = ""
+		secret.Metadata = nil
+		secret.EncryptedPayload = "sealed"
+		resealed, err := secretRepo.Reseal(ctx, secret, "")
+		require.NoError(t, err)
+		assert.True(t, resealed)
+
+		found, err := secretRepo.GetByID(ctx, secret.ID)
+		require.NoError(t, err)
+		assert.Empty(t, found.Username)
+		assert.Empty(t, found.Metadata)
+		assert.Equal(t, "sealed", found.EncryptedPayload)
+		assert.Equal(t, secret.Version, found.Version)
+
+		// Resealing the state read before another reseal does nothing
+		stale := *found
+		stale.EncryptedPayload = "stale"
+		resealed, err = secretRepo.Reseal(ctx, &stale, "")
+		require.NoError(t, err)
+		assert.False(t, resealed)
+	})
+
+	t.Run("Reseal After Edit", func(t *testing.T) {
+		secret := &domain.Secret{
+			UserID:            user.ID,
+			Title:             "Edited Meanwhile",
+			Username:          "old",
+			EncryptedPassword: "enc",
+		}
+		require.NoError(t, secretRepo.Create(ctx, secret))
+		read, err := secretRepo.GetByID(ctx, secret.ID)
+		require.NoError(t, err)
+
+		// The user saves an edit between the read and the reseal
+		edited := *read
+		edited.Username = "new"
+		require.NoError(t, secretRepo.Update(ctx, &edited))
+
+		read.Username = ""
+		read.EncryptedPayload = "sealed old"
+		resealed, err := secretRepo.Reseal(ctx, read, "")
+		require.NoError(t, err)
+		assert.False(t, resealed)
+
+		found, err := secretRepo.GetByID(ctx, secret.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "new", found.Username)
+		assert.Empty(t, found.EncryptedPayload)
+		assert.Equal(t, edited.Version, found.Version)
+	})
+
+	t.Run("Trash", func(t *testing.T) {
+		owner := &domain.User{Email: "trash@example.com"}
+		require.NoError(t, userRepo.Create(ctx, owner))
+
+		var ids []string
+		for _, title := range []string{"Keep", "Restore Me", "Purge Me"} {
+			secret := &domain.Secret{UserID: owner.ID, Title: title, EncryptedPassword: "enc"}
+			require.NoError(t, secretRepo.Create(ctx, secret))
+			ids = append(ids, secret.ID)
+		}
+		require.NoError(t, secretRepo.Trash(ctx, ids[1]))
+		require.NoError(t, secretRepo.Trash(ctx, ids[2]))
+
+		// Trashed secrets leave the list but can still be loaded by ID.
+		secrets, err := secretRepo.ListByUserID(ctx, owner.ID)
+		require.NoError(t, err)
+		require.Len(t, secrets, 1)
+		assert.Equal(t, ids[0], secrets[0].ID)
+
+		trashed, err := secretRepo.ListTrash(ctx, owner.ID)
+		require.NoError(t, err)
+		assert.Len(t, trashed, 2)
+
+		found, err := secretRepo.GetByID(ctx, ids[1])
+		require.NoError(t, err)
+		require.NotNil(t, found.DeletedAt)
+		assert.Equal(t, 1, found.Version) // Trashing is not an edit
+
+		restored, err := secretRepo.Restore(ctx, ids[1])
+		require.NoError(t, err)
+		assert.True(t, restored)
+		restored, err = secretRepo.Restore(ctx, ids[1])
+		require.NoError(t, err)
+		assert.False(t, restored)
+
+		// A restored secret is no longer purged.
+		purgedOne, err := secretRepo.Purge(ctx, ids[1])
+		require.NoError(t, err)
+		assert.False(t, purgedOne)
+		found, err = secretRepo.GetByID(ctx, ids[1])
+		require.NoError(t, err)
+		assert.NotNil(t, found)
+
+		// Only secrets trashed before the cutoff are purged.
+		purged, err := secretRepo.PurgeTrash(ctx, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Zero(t, purged)
+
+		purged, err = secretRepo.EmptyTrash(ctx, owner.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, purged)
+
+		found, err = secretRepo.GetByID(ctx, ids[2])
+		require.NoError(t, err)
+		assert.Nil(t, found)
+		secrets, err = secretRepo.ListByUserID(ctx, owner.ID)
+		require.NoError(t, err)
+		assert.Len(t, secrets, 2)
+	})
+
+	t.Run("ListBatch", func(t *testing.T) {
+		total, err := secretRepo.Count(ctx)
+		require.NoError(t, err)
+
+		seen := 0
+		after := ""
+		for {
+			batch, err := secretRepo.ListBatch(ctx, after, 2)
+			require.NoError(t, err)
+			if len(batch) == 0 {
+				break
+			}
+			seen += len(batch)
+			after = batch[len(batch)-1].ID
+		}
+		assert.Equal(t, total, seen)
+	})
 }
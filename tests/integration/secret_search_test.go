@@ -0,0 +1,108 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/internal/repository/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretRepo_ListPage(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Skipping integration test: database not initialized")
+	}
+
+	userRepo := postgres.NewUserRepository(testDB)
+	secretRepo := postgres.NewSecretRepository(testDB)
+	ctx := context.Background()
+
+	user := &domain.User{Email: "search@example.com"}
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	create := func(title, username string, metadata map[string]interface{}) *domain.Secret {
+		s := &domain.Secret{UserID: user.ID, Title: title, Username: username, EncryptedPassword: "enc", Metadata: metadata}
+		require.NoError(t, secretRepo.Create(ctx, s))
+		return s
+	}
+	create("GitHub", "octocat", map[string]interface{}{"url": "https://github.com"})
+	gitlab := create("gitlab", "tanuki", nil)
+	bank := create("Bank", "me", map[string]interface{}{"notes": "100% safe_ish"})
+	create("Mail", "me", map[string]interface{}{"url": "https://mail.example.com"})
+
+	titles := func(secrets []*domain.Secret) []string {
+		var result []string
+		for _, s := range secrets {
+			result = append(result, s.Title)
+		}
+		return result
+	}
+
+	t.Run("Search", func(t *testing.T) {
+		tests := []struct {
+			query    string
+			expected []string
+		}{
+			{query: "git", expected: []string{"GitHub", "gitlab"}},
+			{query: "GITHUB.COM", expected: []string{"GitHub"}},
+			{query: "me mail", expected: []string{"Mail"}},
+			{query: "100%", expected: []string{"Bank"}},
+			// LIKE wildcards in the search match literally
+			{query: "safe_", expected: []string{"Bank"}},
+			{query: "s_fe", expected: nil},
+		}
+		for _, tt := range tests {
+			page, err := secretRepo.ListPage(ctx, user.ID, domain.SecretFilter{Query: tt.query}, domain.PageRequest{Sort: domain.SortTitle, Limit: 10})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, titles(page.Secrets), tt.query)
+			assert.Equal(t, len(tt.expected), page.Total, tt.query)
+		}
+	})
+
+	t.Run("Pages", func(t *testing.T) {
+		var seen []string
+		page := domain.PageRequest{Sort: domain.SortTitle, Limit: 3}
+		for {
+			result, err := secretRepo.ListPage(ctx, user.ID, domain.SecretFilter{}, page)
+			require.NoError(t, err)
+			assert.Equal(t, 4, result.Total)
+			seen = append(seen, titles(result.Secrets)...)
+			if result.NextCursor == "" {
+				break
+			}
+			page.Cursor = result.NextCursor
+		}
+		// Titles sort without regard to case
+		assert.Equal(t, []string{"Bank", "GitHub", "gitlab", "Mail"}, seen)
+	})
+
+	t.Run("LastUsed", func(t *testing.T) {
+		require.NoError(t, secretRepo.MarkUsed(ctx, bank.ID))
+		require.NoError(t, secretRepo.MarkUsed(ctx, gitlab.ID))
+
+		first, err := secretRepo.ListPage(ctx, user.ID, domain.SecretFilter{}, domain.PageRequest{Sort: domain.SortLastUsed, Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"gitlab"}, titles(first.Secrets))
+		require.NotNil(t, first.Secrets[0].LastUsedAt)
+
+		// Secrets never used come last, still one page at a time
+		rest, err := secretRepo.ListPage(ctx, user.ID, domain.SecretFilter{}, domain.PageRequest{Sort: domain.SortLastUsed, Cursor: first.NextCursor, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, rest.Secrets, 3)
+		assert.Equal(t, "Bank", rest.Secrets[0].Title)
+		assert.ElementsMatch(t, []string{"GitHub", "Mail"}, titles(rest.Secrets[1:]))
+		assert.Nil(t, rest.Secrets[1].LastUsedAt)
+	})
+
+	t.Run("InvalidCursor", func(t *testing.T) {
+		first, err := secretRepo.ListPage(ctx, user.ID, domain.SecretFilter{}, domain.PageRequest{Sort: domain.SortTitle, Limit: 1})
+		require.NoError(t, err)
+
+		_, err = secretRepo.ListPage(ctx, user.ID, domain.SecretFilter{}, domain.PageRequest{Sort: domain.SortUpdated, Cursor: first.NextCursor, Limit: 1})
+		assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+		_, err = secretRepo.ListPage(ctx, user.ID, domain.SecretFilter{}, domain.PageRequest{Sort: domain.SortTitle, Cursor: "not a cursor", Limit: 1})
+		assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+	})
+}
@@ -0,0 +1,107 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/internal/repository/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretVersionRepo(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Skipping integration test: database not initialized")
+	}
+
+	userRepo := postgres.NewUserRepository(testDB)
+	secretRepo := postgres.NewSecretRepository(testDB)
+	versionRepo := postgres.NewSecretVersionRepository(testDB)
+	ctx := context.Background()
+
+	user := &domain.User{Email: "historian@example.com"}
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	secret := &domain.Secret{
+		UserID:            user.ID,
+		Title:             "Rotated",
+		Username:          "agent",
+		EncryptedPassword: "enc_v1",
+		Metadata:          map[string]interface{}{"url": "https://example.com"},
+	}
+	require.NoError(t, secretRepo.Create(ctx, secret))
+
+	t.Run("Create", func(t *testing.T) {
+		v := domain.NewSecretVersion(secret)
+		require.NoError(t, versionRepo.Create(ctx, v))
+
+		// A retry after a failed update stores nothing new.
+		require.NoError(t, versionRepo.Create(ctx, v))
+
+		var count int
+		var password string
+		err := testDB.QueryRow(ctx, `SELECT COUNT(*), MAX(encrypted_password) FROM secret_versions WHERE secret_id = $1`, secret.ID).Scan(&count, &password)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Equal(t, "enc_v1", password)
+	})
+
+	t.Run("ListGetAndPrune", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			require.NoError(t, secretRepo.Update(ctx, secret))
+			require.NoError(t, versionRepo.Create(ctx, domain.NewSecretVersion(secret)))
+		}
+
+		versions, err := versionRepo.ListBySecretID(ctx, secret.ID)
+		require.NoError(t, err)
+		require.Len(t, versions, 4)
+		assert.Greater(t, versions[0].Version, versions[1].Version) // Newest first
+		assert.Equal(t, "https://example.com", versions[0].Metadata["url"])
+
+		v, err := versionRepo.GetByVersion(ctx, secret.ID, versions[3].Version)
+		require.NoError(t, err)
+		require.NotNil(t, v)
+		assert.Equal(t, "enc_v1", v.EncryptedPassword)
+
+		missing, err := versionRepo.GetByVersion(ctx, secret.ID, 999)
+		require.NoError(t, err)
+		assert.Nil(t, missing)
+
+		require.NoError(t, versionRepo.Prune(ctx, user.ID, 2))
+		pruned, err := versionRepo.ListBySecretID(ctx, secret.ID)
+		require.NoError(t, err)
+		require.Len(t, pruned, 2)
+		assert.Equal(t, versions[0].Version, pruned[0].Version)
+	})
+
+	t.Run("ListByUserAndReseal", func(t *testing.T) {
+		versions, err := versionRepo.ListByUserID(ctx, user.ID)
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		assert.Equal(t, "agent", versions[0].Username)
+
+		v := versions[0]
+		v.Username = ""
+		v.Metadata = nil
+		v.EncryptedPayload = "sealed"
+		require.NoError(t, versionRepo.Reseal(ctx, v))
+
+		found, err := versionRepo.GetByVersion(ctx, secret.ID, v.Version)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Empty(t, found.Username)
+		assert.Empty(t, found.Metadata)
+		assert.Equal(t, "sealed", found.EncryptedPayload)
+		assert.Equal(t, versions[0].EncryptedPassword, found.EncryptedPassword)
+	})
+
+	t.Run("DeletedWithSecret", func(t *testing.T) {
+		require.NoError(t, secretRepo.Delete(ctx, secret.ID))
+
+		var count int
+		err := testDB.QueryRow(ctx, `SELECT COUNT(*) FROM secret_versions WHERE secret_id = $1`, secret.ID).Scan(&count)
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+}
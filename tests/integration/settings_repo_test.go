@@ -0,0 +1,43 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/internal/repository/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettingsRepo(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Skipping integration test: database not initialized")
+	}
+
+	userRepo := postgres.NewUserRepository(testDB)
+	settingsRepo := postgres.NewSettingsRepository(testDB)
+	ctx := context.Background()
+
+	user := &domain.User{Email: "settings@example.com"}
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	t.Run("GetDefaults", func(t *testing.T) {
+		found, err := settingsRepo.Get(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.DefaultPlaintextFields, found.PlaintextFields)
+		assert.Equal(t, domain.DefaultAutoLockMinutes, found.AutoLockMinutes)
+		assert.Equal(t, domain.DefaultHistoryLimit, found.HistoryLimit)
+	})
+
+	t.Run("Upsert", func(t *testing.T) {
+		require.NoError(t, settingsRepo.Upsert(ctx, &domain.UserSettings{UserID: user.ID, PlaintextFields: []string{"url"}}))
+		require.NoError(t, settingsRepo.Upsert(ctx, &domain.UserSettings{UserID: user.ID, PlaintextFields: []string{}, AutoLockMinutes: 5, HistoryLimit: 3}))
+
+		found, err := settingsRepo.Get(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Empty(t, found.PlaintextFields)
+		assert.Equal(t, 5, found.AutoLockMinutes)
+		assert.Equal(t, 3, found.HistoryLimit)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"testing"
 	"time"
 
@@ -49,20 +50,26 @@ func TestMain(m *testing.M) {
 		log.Fatalf("failed to connect to db: %s", err)
 	}
 
-    // 4. Run Migrations (Manual)
+	// 4. Run Migrations (Manual, in file name order)
 	wd, _ := os.Getwd()
 	projectRoot := filepath.Dir(filepath.Dir(wd))
-	migrationFile := filepath.Join(projectRoot, "migrations", "000001_init.up.sql")
-    
-    content, err := os.ReadFile(migrationFile)
-    if err != nil {
-        log.Fatalf("failed to read migration file: %s", err)
-    }
-    
-    _, err = testDB.Exec(ctx, string(content))
-    if err != nil {
-        log.Fatalf("failed to execute migration: %s", err)
-    }
+	migrationFiles, err := filepath.Glob(filepath.Join(projectRoot, "migrations", "*.up.sql"))
+	if err != nil {
+		log.Fatalf("failed to list migration files: %s", err)
+	}
+	sort.Strings(migrationFiles)
+
+	for _, migrationFile := range migrationFiles {
+		content, err := os.ReadFile(migrationFile)
+		if err != nil {
+			log.Fatalf("failed to read migration file: %s", err)
+		}
+
+		_, err = testDB.Exec(ctx, string(content))
+		if err != nil {
+			log.Fatalf("failed to execute migration %s: %s", filepath.Base(migrationFile), err)
+		}
+	}
 
 	code := m.Run()
 
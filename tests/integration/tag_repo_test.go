@@ -0,0 +1,99 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/internal/repository/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagRepo(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Skipping integration test: database not initialized")
+	}
+
+	userRepo := postgres.NewUserRepository(testDB)
+	tagRepo := postgres.NewTagRepository(testDB)
+	secretRepo := postgres.NewSecretRepository(testDB)
+	ctx := context.Background()
+
+	user := &domain.User{Email: "tags@example.com"}
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	db := &domain.Secret{UserID: user.ID, Title: "DB", EncryptedPassword: "enc"}
+	require.NoError(t, secretRepo.Create(ctx, db))
+	pager := &domain.Secret{UserID: user.ID, Title: "Pager", EncryptedPassword: "enc"}
+	require.NoError(t, secretRepo.Create(ctx, pager))
+
+	tagNames := func() map[string]int {
+		tags, err := tagRepo.ListByUserID(ctx, user.ID)
+		require.NoError(t, err)
+		counts := map[string]int{}
+		for _, tag := range tags {
+			counts[tag.Name] = tag.Secrets
+		}
+		return counts
+	}
+
+	t.Run("AddToSecret", func(t *testing.T) {
+		require.NoError(t, tagRepo.AddToSecret(ctx, user.ID, db.ID, []string{"prod-db", "on-call"}))
+		require.NoError(t, tagRepo.AddToSecret(ctx, user.ID, pager.ID, []string{"on-call"}))
+		// Adding a tag twice is harmless.
+		require.NoError(t, tagRepo.AddToSecret(ctx, user.ID, db.ID, []string{"prod-db"}))
+
+		found, err := secretRepo.GetByID(ctx, db.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"on-call", "prod-db"}, found.Tags)
+		assert.Equal(t, db.Version, found.Version)
+		assert.Equal(t, map[string]int{"on-call": 2, "prod-db": 1}, tagNames())
+	})
+
+	t.Run("FilterAndFavorite", func(t *testing.T) {
+		secrets, err := secretRepo.ListByFilter(ctx, user.ID, domain.SecretFilter{Tag: "on-call"})
+		require.NoError(t, err)
+		assert.Len(t, secrets, 2)
+
+		require.NoError(t, secretRepo.SetFavorite(ctx, pager.ID, true))
+		secrets, err = secretRepo.ListByFilter(ctx, user.ID, domain.SecretFilter{Tag: "on-call", Favorite: true})
+		require.NoError(t, err)
+		require.Len(t, secrets, 1)
+		assert.Equal(t, pager.ID, secrets[0].ID)
+		assert.True(t, secrets[0].Favorite)
+	})
+
+	t.Run("RenameAndMerge", func(t *testing.T) {
+		found, err := tagRepo.Rename(ctx, user.ID, "prod-db", "database")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, map[string]int{"on-call": 2, "database": 1}, tagNames())
+
+		// Renaming onto an existing tag merges them.
+		found, err = tagRepo.Rename(ctx, user.ID, "database", "on-call")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, map[string]int{"on-call": 2}, tagNames())
+
+		found, err = tagRepo.Rename(ctx, user.ID, "missing", "other")
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("RemoveAndDelete", func(t *testing.T) {
+		removed, err := tagRepo.RemoveFromSecret(ctx, user.ID, db.ID, "on-call")
+		require.NoError(t, err)
+		assert.True(t, removed)
+		assert.Equal(t, map[string]int{"on-call": 1}, tagNames())
+
+		deleted, err := tagRepo.Delete(ctx, user.ID, "on-call")
+		require.NoError(t, err)
+		assert.True(t, deleted)
+		assert.Empty(t, tagNames())
+
+		found, err := secretRepo.GetByID(ctx, pager.ID)
+		require.NoError(t, err)
+		assert.Empty(t, found.Tags)
+	})
+}
@@ -0,0 +1,40 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/internal/repository/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserKeyRepo(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Skipping integration test: database not initialized")
+	}
+
+	userRepo := postgres.NewUserRepository(testDB)
+	keyRepo := postgres.NewUserKeyRepository(testDB)
+	ctx := context.Background()
+
+	user := &domain.User{Email: "keyholder@example.com"}
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	t.Run("GetMissingKey", func(t *testing.T) {
+		found, err := keyRepo.GetByUserID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("CreateKeepsFirstKey", func(t *testing.T) {
+		require.NoError(t, keyRepo.Create(ctx, &domain.UserKey{UserID: user.ID, WrappedKey: "first"}))
+		require.NoError(t, keyRepo.Create(ctx, &domain.UserKey{UserID: user.ID, WrappedKey: "second"}))
+
+		found, err := keyRepo.GetByUserID(ctx, user.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, "first", found.WrappedKey)
+	})
+}
@@ -0,0 +1,70 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/herdiagusthio/password-manager/internal/domain"
+	"github.com/herdiagusthio/password-manager/internal/repository/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultRepo(t *testing.T) {
+	if testDB == nil {
+		t.Skip("Skipping integration test: database not initialized")
+	}
+
+	userRepo := postgres.NewUserRepository(testDB)
+	vaultRepo := postgres.NewVaultRepository(testDB)
+	ctx := context.Background()
+
+	user := &domain.User{Email: "zeroknowledge@example.com"}
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	t.Run("NotEnabled", func(t *testing.T) {
+		vault, err := vaultRepo.GetByUserID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.False(t, vault.ZeroKnowledge)
+		assert.Nil(t, vault.KDF)
+	})
+
+	t.Run("SaveAndGet", func(t *testing.T) {
+		vault := &domain.Vault{
+			UserID:     user.ID,
+			KDF:        &domain.KDFParams{Algorithm: "argon2id", Memory: 65536, Iterations: 3, Parallelism: 4, Salt: "c2FsdHNhbHRzYWx0c2FsdA=="},
+			KeyID:      "vk-1",
+			WrappedKey: "zk1:d3JhcHBlZA==",
+			Verifier:   "verifier",
+		}
+		require.NoError(t, vaultRepo.Save(ctx, vault))
+		assert.True(t, vault.ZeroKnowledge)
+
+		found, err := vaultRepo.GetByUserID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.True(t, found.ZeroKnowledge)
+		assert.Equal(t, vault.KDF, found.KDF)
+		assert.Equal(t, "vk-1", found.KeyID)
+		assert.Equal(t, "zk1:d3JhcHBlZA==", found.WrappedKey)
+		assert.Equal(t, "verifier", found.Verifier)
+		assert.False(t, found.HasPIN)
+	})
+
+	t.Run("SetAndRemovePIN", func(t *testing.T) {
+		vault, err := vaultRepo.GetByUserID(ctx, user.ID)
+		require.NoError(t, err)
+
+		vault.PINHash = "$argon2id$hash"
+		require.NoError(t, vaultRepo.Save(ctx, vault))
+		found, err := vaultRepo.GetByUserID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.True(t, found.HasPIN)
+		assert.True(t, found.ZeroKnowledge) // Untouched
+
+		found.PINHash = ""
+		require.NoError(t, vaultRepo.Save(ctx, found))
+		found, err = vaultRepo.GetByUserID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.False(t, found.HasPIN)
+	})
+}